@@ -51,6 +51,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VolumeGroupSnapshotList{},
 		&VolumeGroupSnapshotContent{},
 		&VolumeGroupSnapshotContentList{},
+		&ClusterVolumeGroupSnapshot{},
+		&ClusterVolumeGroupSnapshotList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
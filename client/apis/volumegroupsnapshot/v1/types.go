@@ -19,6 +19,7 @@ package v1
 import (
 	core_v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 )
@@ -107,6 +108,47 @@ type VolumeGroupSnapshotStatus struct {
 	// group snapshot creation. Upon success, this error field will be cleared.
 	// +optional
 	Error *snapshotv1.VolumeSnapshotError `json:"error,omitempty" protobuf:"bytes,4,opt,name=error,casttype=VolumeSnapshotError"`
+
+	// Members is the list of PersistentVolumeClaims included in a dynamically
+	// provisioned group snapshot. It is recorded by the snapshot controller the
+	// first time the selector is resolved, before the group snapshot is taken,
+	// and is used instead of the selector from then on. Changes to the set of
+	// selected PersistentVolumeClaims afterwards do not change the members.
+	// +optional
+	// +listType=atomic
+	Members []VolumeGroupSnapshotMember `json:"members,omitempty" protobuf:"bytes,5,rep,name=members"`
+
+	// CrashConsistent indicates if the snapshots of the group were taken by the
+	// storage system at a single point in time. It is false if the CSI driver
+	// does not support group snapshots and the csi-snapshotter emulated the
+	// group snapshot with individual snapshots.
+	// If not specified, the consistency of the group snapshot is unknown.
+	// +optional
+	CrashConsistent *bool `json:"crashConsistent,omitempty" protobuf:"varint,6,opt,name=crashConsistent"`
+}
+
+// VolumeGroupSnapshotMember identifies a PersistentVolumeClaim and the volume
+// bound to it at the time the members of a group snapshot were recorded.
+type VolumeGroupSnapshotMember struct {
+	// PersistentVolumeClaimName is the name of the PersistentVolumeClaim in the
+	// namespace of the VolumeGroupSnapshot.
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName" protobuf:"bytes,1,opt,name=persistentVolumeClaimName"`
+
+	// PersistentVolumeClaimNamespace is the namespace of the PersistentVolumeClaim.
+	// It is only set for the members of a ClusterVolumeGroupSnapshot, the members
+	// of a VolumeGroupSnapshot are in its namespace.
+	// +optional
+	PersistentVolumeClaimNamespace string `json:"persistentVolumeClaimNamespace,omitempty" protobuf:"bytes,5,opt,name=persistentVolumeClaimNamespace"`
+
+	// PersistentVolumeClaimUID is the UID of the PersistentVolumeClaim.
+	PersistentVolumeClaimUID types.UID `json:"persistentVolumeClaimUID" protobuf:"bytes,2,opt,name=persistentVolumeClaimUID,casttype=k8s.io/apimachinery/pkg/types.UID"`
+
+	// PersistentVolumeName is the name of the PersistentVolume bound to the
+	// PersistentVolumeClaim.
+	PersistentVolumeName string `json:"persistentVolumeName" protobuf:"bytes,3,opt,name=persistentVolumeName"`
+
+	// VolumeHandle is the CSI volume handle of the PersistentVolume.
+	VolumeHandle string `json:"volumeHandle" protobuf:"bytes,4,opt,name=volumeHandle"`
 }
 
 //+genclient
@@ -152,6 +194,88 @@ type VolumeGroupSnapshotList struct {
 	Items []VolumeGroupSnapshot `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
 
+// ClusterVolumeGroupSnapshotSpec defines the desired state of a cluster
+// volume group snapshot.
+type ClusterVolumeGroupSnapshotSpec struct {
+	// Source specifies the persistent volume claims, in one or more namespaces,
+	// the group snapshot will be created from.
+	// This field is immutable after creation.
+	// Required.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="source is immutable"
+	Source ClusterVolumeGroupSnapshotSource `json:"source" protobuf:"bytes,1,opt,name=source"`
+
+	// VolumeGroupSnapshotClassName is the name of the VolumeGroupSnapshotClass
+	// requested by the ClusterVolumeGroupSnapshot.
+	// Required.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="volumeGroupSnapshotClassName is immutable"
+	// +kubebuilder:validation:MinLength=1
+	VolumeGroupSnapshotClassName string `json:"volumeGroupSnapshotClassName" protobuf:"bytes,2,opt,name=volumeGroupSnapshotClassName"`
+}
+
+// ClusterVolumeGroupSnapshotSource selects the persistent volume claims
+// of a cluster volume group snapshot.
+type ClusterVolumeGroupSnapshotSource struct {
+	// NamespaceSelector is a label query over the namespaces whose persistent
+	// volume claims are grouped together for snapshotting.
+	// Required.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector" protobuf:"bytes,1,opt,name=namespaceSelector"`
+
+	// Selector is a label query over persistent volume claims in the selected
+	// namespaces that are to be grouped together for snapshotting.
+	// Required.
+	Selector metav1.LabelSelector `json:"selector" protobuf:"bytes,2,opt,name=selector"`
+}
+
+//+genclient
+//+genclient:nonNamespaced
+//+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVolumeGroupSnapshot is a user's request for creating a point-in-time
+// group snapshot of persistent volume claims in several namespaces.
+// The snapshot controller creates a single VolumeGroupSnapshotContent for it,
+// and a VolumeSnapshot for each member in the namespace of its persistent
+// volume claim.
+// ClusterVolumeGroupSnapshots are non-namespaced.
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:resource:scope=Cluster,shortName=cvgs
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ReadyToUse",type=boolean,JSONPath=`.status.readyToUse`,description="Indicates if all the individual snapshots in the group are ready to be used to restore a group of volumes."
+// +kubebuilder:printcolumn:name="VolumeGroupSnapshotClass",type=string,JSONPath=`.spec.volumeGroupSnapshotClassName`,description="The name of the VolumeGroupSnapshotClass requested by the ClusterVolumeGroupSnapshot."
+// +kubebuilder:printcolumn:name="VolumeGroupSnapshotContent",type=string,JSONPath=`.status.boundVolumeGroupSnapshotContentName`,description="Name of the VolumeGroupSnapshotContent object to which the ClusterVolumeGroupSnapshot object intends to bind to."
+// +kubebuilder:printcolumn:name="CreationTime",type=date,JSONPath=`.status.creationTime`,description="Timestamp when the point-in-time group snapshot was taken by the underlying storage system."
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ClusterVolumeGroupSnapshot struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Spec defines the desired characteristics of a cluster group snapshot
+	// requested by a user.
+	// Required.
+	Spec ClusterVolumeGroupSnapshotSpec `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+
+	// Status represents the current information of a cluster group snapshot.
+	// The members recorded in the status include the namespace of each
+	// persistent volume claim.
+	// +optional
+	Status *VolumeGroupSnapshotStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVolumeGroupSnapshotList contains a list of ClusterVolumeGroupSnapshot objects.
+// +kubebuilder:object:root=true
+type ClusterVolumeGroupSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+	// Items is the list of ClusterVolumeGroupSnapshots.
+	Items []ClusterVolumeGroupSnapshot `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
 //+genclient
 //+genclient:nonNamespaced
 //+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -268,10 +392,12 @@ type VolumeGroupSnapshotContentSpec struct {
 	// this VolumeGroupSnapshotContent's name for the bidirectional binding to be valid.
 	// For a pre-existing VolumeGroupSnapshotContent object, name and namespace of the
 	// VolumeGroupSnapshot object MUST be provided for binding to happen.
+	// A ClusterVolumeGroupSnapshot is cluster-scoped and is referenced by kind and
+	// name only.
 	// This field is immutable after creation.
 	// Required.
-	// +kubebuilder:validation:XValidation:rule="has(self.name) && has(self.__namespace__)",message="both volumeGroupSnapshotRef.name and volumeGroupSnapshotRef.namespace must be set"
-	// +kubebuilder:validation:XValidation:rule="self.name == oldSelf.name && self.__namespace__ == oldSelf.__namespace__",message="volumeGroupSnapshotRef.name and volumeGroupSnapshotRef.namespace are immutable"
+	// +kubebuilder:validation:XValidation:rule="has(self.name) && (has(self.__namespace__) || (has(self.kind) && self.kind == 'ClusterVolumeGroupSnapshot'))",message="volumeGroupSnapshotRef.name must be set, and volumeGroupSnapshotRef.namespace unless volumeGroupSnapshotRef.kind is ClusterVolumeGroupSnapshot"
+	// +kubebuilder:validation:XValidation:rule="self.name == oldSelf.name && has(self.__namespace__) == has(oldSelf.__namespace__) && (!has(self.__namespace__) || self.__namespace__ == oldSelf.__namespace__)",message="volumeGroupSnapshotRef.name and volumeGroupSnapshotRef.namespace are immutable"
 	// +kubebuilder:validation:XValidation:rule="!has(oldSelf.uid) || (has(self.uid) && self.uid == oldSelf.uid)",message="volumeGroupSnapshotRef.uid is immutable once set"
 	VolumeGroupSnapshotRef core_v1.ObjectReference `json:"volumeGroupSnapshotRef" protobuf:"bytes,1,opt,name=volumeGroupSnapshotRef"`
 
@@ -340,6 +466,22 @@ type VolumeSnapshotInfo struct {
 	// from this snapshot.
 	// +optional
 	RestoreSize *int64 `json:"restoreSize,omitempty" protobuf:"bytes,5,opt,name=restoreSize"`
+
+	// Error is the last observed error while binding this snapshot to its
+	// VolumeSnapshot and VolumeSnapshotContent, if any.
+	// Upon success after retry, this error field will be cleared.
+	// +optional
+	Error *snapshotv1.VolumeSnapshotError `json:"error,omitempty" protobuf:"bytes,6,opt,name=error,casttype=VolumeSnapshotError"`
+
+	// RetryCount is the number of failed attempts to bind this snapshot to its
+	// VolumeSnapshot and VolumeSnapshotContent.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty" protobuf:"varint,7,opt,name=retryCount"`
+
+	// LastTransitionTime is the last time ReadyToUse or Error of this snapshot
+	// changed.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,8,opt,name=lastTransitionTime"`
 }
 
 // VolumeGroupSnapshotContentStatus defines the observed state of VolumeGroupSnapshotContent.
@@ -388,6 +530,16 @@ type VolumeGroupSnapshotContentStatus struct {
 	// by the CSI driver to identify snapshots on the storage system.
 	// +optional
 	VolumeSnapshotInfoList []VolumeSnapshotInfo `json:"volumeSnapshotInfoList,omitempty" protobuf:"bytes,5,opt,name=volumeSnapshotInfo"`
+
+	// CrashConsistent indicates if the snapshots of the group were taken by the
+	// storage system at a single point in time. It is false if the CSI driver
+	// does not support group snapshots and the csi-snapshotter emulated the
+	// group snapshot with individual snapshots.
+	// This field is the source for the CrashConsistent field in
+	// VolumeGroupSnapshotStatus.
+	// If not specified, the consistency of the group snapshot is unknown.
+	// +optional
+	CrashConsistent *bool `json:"crashConsistent,omitempty" protobuf:"varint,6,opt,name=crashConsistent"`
 }
 
 // VolumeGroupSnapshotContentSource represents the CSI source of a group snapshot.
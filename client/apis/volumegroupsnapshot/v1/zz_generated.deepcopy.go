@@ -27,6 +27,106 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterVolumeGroupSnapshot) DeepCopyInto(out *ClusterVolumeGroupSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(VolumeGroupSnapshotStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterVolumeGroupSnapshot.
+func (in *ClusterVolumeGroupSnapshot) DeepCopy() *ClusterVolumeGroupSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterVolumeGroupSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterVolumeGroupSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterVolumeGroupSnapshotList) DeepCopyInto(out *ClusterVolumeGroupSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterVolumeGroupSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterVolumeGroupSnapshotList.
+func (in *ClusterVolumeGroupSnapshotList) DeepCopy() *ClusterVolumeGroupSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterVolumeGroupSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterVolumeGroupSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterVolumeGroupSnapshotSource) DeepCopyInto(out *ClusterVolumeGroupSnapshotSource) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.Selector.DeepCopyInto(&out.Selector)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterVolumeGroupSnapshotSource.
+func (in *ClusterVolumeGroupSnapshotSource) DeepCopy() *ClusterVolumeGroupSnapshotSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterVolumeGroupSnapshotSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterVolumeGroupSnapshotSpec) DeepCopyInto(out *ClusterVolumeGroupSnapshotSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterVolumeGroupSnapshotSpec.
+func (in *ClusterVolumeGroupSnapshotSpec) DeepCopy() *ClusterVolumeGroupSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterVolumeGroupSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GroupSnapshotHandles) DeepCopyInto(out *GroupSnapshotHandles) {
 	*out = *in
@@ -289,6 +389,11 @@ func (in *VolumeGroupSnapshotContentStatus) DeepCopyInto(out *VolumeGroupSnapsho
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CrashConsistent != nil {
+		in, out := &in.CrashConsistent, &out.CrashConsistent
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -335,6 +440,22 @@ func (in *VolumeGroupSnapshotList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeGroupSnapshotMember) DeepCopyInto(out *VolumeGroupSnapshotMember) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupSnapshotMember.
+func (in *VolumeGroupSnapshotMember) DeepCopy() *VolumeGroupSnapshotMember {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeGroupSnapshotMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeGroupSnapshotSource) DeepCopyInto(out *VolumeGroupSnapshotSource) {
 	*out = *in
@@ -405,6 +526,16 @@ func (in *VolumeGroupSnapshotStatus) DeepCopyInto(out *VolumeGroupSnapshotStatus
 		*out = new(volumesnapshotv1.VolumeSnapshotError)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]VolumeGroupSnapshotMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.CrashConsistent != nil {
+		in, out := &in.CrashConsistent, &out.CrashConsistent
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -452,6 +583,15 @@ func (in *VolumeSnapshotInfo) DeepCopyInto(out *VolumeSnapshotInfo) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Error != nil {
+		in, out := &in.Error, &out.Error
+		*out = new(volumesnapshotv1.VolumeSnapshotError)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
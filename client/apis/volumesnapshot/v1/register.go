@@ -52,6 +52,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VolumeSnapshotList{},
 		&VolumeSnapshotContent{},
 		&VolumeSnapshotContentList{},
+		&VolumeSnapshotCopy{},
+		&VolumeSnapshotCopyList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -194,6 +194,17 @@ type VolumeSnapshotStatus struct {
 	// VolumeSnapshot is a part of.
 	// +optional
 	VolumeGroupSnapshotName *string `json:"volumeGroupSnapshotName,omitempty" protobuf:"bytes,6,opt,name=volumeGroupSnapshotName"`
+
+	// accessibleTopology is copied from the accessibleTopology of the bound
+	// VolumeSnapshotContent. It lists the topologies, e.g. zones, in which a
+	// volume can be restored from this snapshot, in the same format as the
+	// allowedTopologies of a StorageClass.
+	// If not specified, the snapshot can be restored in any topology, or the
+	// accessible topology is unknown.
+	// This field is an alpha field.
+	// +optional
+	// +listType=atomic
+	AccessibleTopology []core_v1.TopologySelectorTerm `json:"accessibleTopology,omitempty" protobuf:"bytes,7,rep,name=accessibleTopology"`
 }
 
 // +genclient
@@ -297,6 +308,7 @@ type VolumeSnapshotContentList struct {
 
 // VolumeSnapshotContentSpec is the specification of a VolumeSnapshotContent
 // +kubebuilder:validation:XValidation:rule="!has(oldSelf.sourceVolumeMode) || has(self.sourceVolumeMode)", message="sourceVolumeMode is required once set"
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.nodeAffinity) || has(self.nodeAffinity)", message="nodeAffinity is required once set"
 type VolumeSnapshotContentSpec struct {
 	// volumeSnapshotRef specifies the VolumeSnapshot object to which this
 	// VolumeSnapshotContent object is bound.
@@ -351,6 +363,19 @@ type VolumeSnapshotContentSpec struct {
 	// +optional
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="sourceVolumeMode is immutable"
 	SourceVolumeMode *core_v1.PersistentVolumeMode `json:"sourceVolumeMode" protobuf:"bytes,6,opt,name=sourceVolumeMode"`
+
+	// nodeAffinity defines constraints that limit the nodes on which the
+	// snapshot can be taken and deleted, i.e. the nodes from which the source
+	// volume is accessible. It is copied from the PersistentVolume by the
+	// snapshot controller when distributed snapshotting is enabled. The
+	// snapshot controller assigns the VolumeSnapshotContent to one of the
+	// matching nodes with the "snapshot.storage.kubernetes.io/managed-by"
+	// label, which selects the CSI snapshotter sidecar deployed on that node.
+	// This field is immutable.
+	// This field is an alpha field.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="nodeAffinity is immutable"
+	NodeAffinity *core_v1.VolumeNodeAffinity `json:"nodeAffinity,omitempty" protobuf:"bytes,7,opt,name=nodeAffinity"`
 }
 
 // VolumeSnapshotContentSource represents the CSI source of a snapshot.
@@ -358,7 +383,8 @@ type VolumeSnapshotContentSpec struct {
 // Members in VolumeSnapshotContentSource are immutable.
 // +kubebuilder:validation:XValidation:rule="!has(oldSelf.volumeHandle) || has(self.volumeHandle)", message="volumeHandle is required once set"
 // +kubebuilder:validation:XValidation:rule="!has(oldSelf.snapshotHandle) || has(self.snapshotHandle)", message="snapshotHandle is required once set"
-// +kubebuilder:validation:XValidation:rule="(has(self.volumeHandle) && !has(self.snapshotHandle)) || (!has(self.volumeHandle) && has(self.snapshotHandle))", message="exactly one of volumeHandle and snapshotHandle must be set"
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.sourceSnapshotHandle) || has(self.sourceSnapshotHandle)", message="sourceSnapshotHandle is required once set"
+// +kubebuilder:validation:XValidation:rule="[has(self.volumeHandle), has(self.snapshotHandle), has(self.sourceSnapshotHandle)].filter(x, x).size() == 1", message="exactly one of volumeHandle, snapshotHandle and sourceSnapshotHandle must be set"
 type VolumeSnapshotContentSource struct {
 	// volumeHandle specifies the CSI "volume_id" of the volume from which a snapshot
 	// should be dynamically taken from.
@@ -374,6 +400,15 @@ type VolumeSnapshotContentSource struct {
 	// +optional
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="snapshotHandle is immutable"
 	SnapshotHandle *string `json:"snapshotHandle,omitempty" protobuf:"bytes,2,opt,name=snapshotHandle"`
+
+	// sourceSnapshotHandle specifies the CSI "snapshot_id" of an existing snapshot
+	// from which a new snapshot should be dynamically copied.
+	// It is set by the snapshot controller for a VolumeSnapshotCopy.
+	// This field is immutable.
+	// This field is an alpha field.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="sourceSnapshotHandle is immutable"
+	SourceSnapshotHandle *string `json:"sourceSnapshotHandle,omitempty" protobuf:"bytes,3,opt,name=sourceSnapshotHandle"`
 }
 
 // VolumeSnapshotContentStatus is the status of a VolumeSnapshotContent object
@@ -440,6 +475,33 @@ type VolumeSnapshotContentStatus struct {
 	// on the underlying storage system.
 	// +optional
 	VolumeGroupSnapshotHandle *string `json:"volumeGroupSnapshotHandle,omitempty" protobuf:"bytes,6,opt,name=volumeGroupSnapshotHandle"`
+
+	// accessibleTopology lists the topologies, e.g. zones, in which a volume
+	// can be restored from this snapshot, in the same format as the
+	// allowedTopologies of a StorageClass.
+	// In dynamic snapshot creation case, it is derived from the node affinity
+	// of the source PersistentVolume. It is filled in by the CSI snapshotter
+	// sidecar from the "snapshot.storage.kubernetes.io/accessible-topology"
+	// annotation of the VolumeSnapshotContent, which can also be set for
+	// pre-existing snapshots, e.g. from metadata of the CSI driver.
+	// If not specified, the snapshot can be restored in any topology, or the
+	// accessible topology is unknown.
+	// This field is an alpha field.
+	// +optional
+	// +listType=atomic
+	AccessibleTopology []core_v1.TopologySelectorTerm `json:"accessibleTopology,omitempty" protobuf:"bytes,7,rep,name=accessibleTopology"`
+
+	// allocatedBytes is the number of bytes allocated to the snapshot on the
+	// underlying storage system, unlike restoreSize, which is the minimum size
+	// of a volume restored from the snapshot.
+	// This field is filled in by the CSI snapshotter sidecar when snapshot usage
+	// accounting is enabled, from the allocated blocks reported by the
+	// SnapshotMetadata service of the CSI driver, and refreshed periodically.
+	// If not specified, it indicates that the allocated size is unknown.
+	// This field is an alpha field.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AllocatedBytes *int64 `json:"allocatedBytes,omitempty" protobuf:"varint,8,opt,name=allocatedBytes"`
 }
 
 // DeletionPolicy describes a policy for end-of-life maintenance of volume snapshot contents
@@ -469,3 +531,139 @@ type VolumeSnapshotError struct {
 	// +optional
 	Message *string `json:"message,omitempty" protobuf:"bytes,2,opt,name=message"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeSnapshotCopy is a user's request to copy an existing VolumeSnapshot
+// into a VolumeSnapshotClass, for example to move it to a cheaper or a remote
+// tier of the storage system.
+// The snapshot controller creates a VolumeSnapshot with the same name as the
+// VolumeSnapshotCopy, bound to a new VolumeSnapshotContent which asks the CSI
+// driver to copy the source snapshot using the parameters of the target class.
+// This is an alpha resource.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=vscopy
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="SourceSnapshot",type=string,JSONPath=`.spec.source.volumeSnapshotName`,description="Name of the VolumeSnapshot which is copied."
+// +kubebuilder:printcolumn:name="SnapshotClass",type=string,JSONPath=`.spec.volumeSnapshotClassName`,description="The name of the VolumeSnapshotClass the snapshot is copied into."
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="Progress of the copy."
+// +kubebuilder:printcolumn:name="SnapshotContent",type=string,JSONPath=`.status.boundVolumeSnapshotContentName`,description="Name of the VolumeSnapshotContent object representing the copied snapshot."
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type VolumeSnapshotCopy struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// spec defines the snapshot to copy and the class to copy it into.
+	// Required.
+	Spec VolumeSnapshotCopySpec `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+
+	// status represents the progress of the copy.
+	// +optional
+	Status *VolumeSnapshotCopyStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// VolumeSnapshotCopyList is a list of VolumeSnapshotCopy objects
+type VolumeSnapshotCopyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// List of VolumeSnapshotCopies
+	Items []VolumeSnapshotCopy `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// VolumeSnapshotCopySpec describes a copy of a volume snapshot.
+type VolumeSnapshotCopySpec struct {
+	// source specifies the VolumeSnapshot to copy.
+	// This field is immutable.
+	// Required.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="source is immutable"
+	Source VolumeSnapshotCopySource `json:"source" protobuf:"bytes,1,opt,name=source"`
+
+	// volumeSnapshotClassName is the name of the VolumeSnapshotClass the snapshot
+	// is copied into. The class must belong to the CSI driver of the source
+	// snapshot; its parameters are passed to the driver to create the copy.
+	// This field is immutable.
+	// Required.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="volumeSnapshotClassName is immutable"
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName" protobuf:"bytes,2,opt,name=volumeSnapshotClassName"`
+}
+
+// VolumeSnapshotCopySource specifies the snapshot to copy.
+type VolumeSnapshotCopySource struct {
+	// volumeSnapshotName specifies the name of the VolumeSnapshot to copy.
+	// The VolumeSnapshot is assumed to be in the same namespace as the
+	// VolumeSnapshotCopy object, and must be ready to use before the copy starts.
+	// Required.
+	VolumeSnapshotName string `json:"volumeSnapshotName" protobuf:"bytes,1,opt,name=volumeSnapshotName"`
+}
+
+// VolumeSnapshotCopyPhase describes the progress of a VolumeSnapshotCopy.
+type VolumeSnapshotCopyPhase string
+
+const (
+	// VolumeSnapshotCopyPending means the copy waits for its source snapshot
+	// to be ready to use.
+	VolumeSnapshotCopyPending VolumeSnapshotCopyPhase = "Pending"
+
+	// VolumeSnapshotCopyInProgress means the copy has been requested from the
+	// CSI driver and is not ready to use yet.
+	VolumeSnapshotCopyInProgress VolumeSnapshotCopyPhase = "InProgress"
+
+	// VolumeSnapshotCopyCompleted means the copied snapshot is ready to use.
+	VolumeSnapshotCopyCompleted VolumeSnapshotCopyPhase = "Completed"
+
+	// VolumeSnapshotCopyFailed means the copy cannot be done, for example
+	// because the target class belongs to another CSI driver. It is not retried.
+	VolumeSnapshotCopyFailed VolumeSnapshotCopyPhase = "Failed"
+)
+
+// VolumeSnapshotCopyStatus is the status of a VolumeSnapshotCopy.
+type VolumeSnapshotCopyStatus struct {
+	// phase is the progress of the copy.
+	// +optional
+	Phase VolumeSnapshotCopyPhase `json:"phase,omitempty" protobuf:"bytes,1,opt,name=phase,casttype=VolumeSnapshotCopyPhase"`
+
+	// sourceSnapshotHandle is the CSI "snapshot_id" of the snapshot being copied.
+	// +optional
+	SourceSnapshotHandle *string `json:"sourceSnapshotHandle,omitempty" protobuf:"bytes,2,opt,name=sourceSnapshotHandle"`
+
+	// boundVolumeSnapshotContentName is the name of the VolumeSnapshotContent
+	// object created for the copied snapshot.
+	// +optional
+	BoundVolumeSnapshotContentName *string `json:"boundVolumeSnapshotContentName,omitempty" protobuf:"bytes,3,opt,name=boundVolumeSnapshotContentName"`
+
+	// snapshotHandle is the CSI "snapshot_id" of the copied snapshot on the
+	// underlying storage system, as returned by the CSI driver.
+	// +optional
+	SnapshotHandle *string `json:"snapshotHandle,omitempty" protobuf:"bytes,4,opt,name=snapshotHandle"`
+
+	// readyToUse indicates if the copied snapshot is ready to be used to restore a volume.
+	// +optional
+	ReadyToUse *bool `json:"readyToUse,omitempty" protobuf:"varint,5,opt,name=readyToUse"`
+
+	// restoreSize represents the minimum size of volume required to create a volume
+	// from the copied snapshot.
+	// +optional
+	RestoreSize *resource.Quantity `json:"restoreSize,omitempty" protobuf:"bytes,6,opt,name=restoreSize"`
+
+	// startTime is the time when the snapshot controller requested the copy.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty" protobuf:"bytes,7,opt,name=startTime"`
+
+	// completionTime is the time when the copied snapshot was first observed
+	// ready to use.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty" protobuf:"bytes,8,opt,name=completionTime"`
+
+	// error is the last observed error during the copy, if any.
+	// The snapshot controller and the CSI snapshotter sidecar keep retrying
+	// unless the phase is Failed. Upon success, this error field will be cleared.
+	// +optional
+	Error *VolumeSnapshotError `json:"error,omitempty" protobuf:"bytes,9,opt,name=error,casttype=VolumeSnapshotError"`
+}
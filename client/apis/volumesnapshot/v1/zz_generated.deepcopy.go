@@ -202,6 +202,11 @@ func (in *VolumeSnapshotContentSource) DeepCopyInto(out *VolumeSnapshotContentSo
 		*out = new(string)
 		**out = **in
 	}
+	if in.SourceSnapshotHandle != nil {
+		in, out := &in.SourceSnapshotHandle, &out.SourceSnapshotHandle
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -230,6 +235,11 @@ func (in *VolumeSnapshotContentSpec) DeepCopyInto(out *VolumeSnapshotContentSpec
 		*out = new(corev1.PersistentVolumeMode)
 		**out = **in
 	}
+	if in.NodeAffinity != nil {
+		in, out := &in.NodeAffinity, &out.NodeAffinity
+		*out = new(corev1.VolumeNodeAffinity)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -276,6 +286,18 @@ func (in *VolumeSnapshotContentStatus) DeepCopyInto(out *VolumeSnapshotContentSt
 		*out = new(string)
 		**out = **in
 	}
+	if in.AccessibleTopology != nil {
+		in, out := &in.AccessibleTopology, &out.AccessibleTopology
+		*out = make([]corev1.TopologySelectorTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllocatedBytes != nil {
+		in, out := &in.AllocatedBytes, &out.AllocatedBytes
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -289,6 +311,158 @@ func (in *VolumeSnapshotContentStatus) DeepCopy() *VolumeSnapshotContentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotCopy) DeepCopyInto(out *VolumeSnapshotCopy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(VolumeSnapshotCopyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotCopy.
+func (in *VolumeSnapshotCopy) DeepCopy() *VolumeSnapshotCopy {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotCopy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeSnapshotCopy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotCopyList) DeepCopyInto(out *VolumeSnapshotCopyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VolumeSnapshotCopy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotCopyList.
+func (in *VolumeSnapshotCopyList) DeepCopy() *VolumeSnapshotCopyList {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotCopyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeSnapshotCopyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotCopySource) DeepCopyInto(out *VolumeSnapshotCopySource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotCopySource.
+func (in *VolumeSnapshotCopySource) DeepCopy() *VolumeSnapshotCopySource {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotCopySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotCopySpec) DeepCopyInto(out *VolumeSnapshotCopySpec) {
+	*out = *in
+	out.Source = in.Source
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotCopySpec.
+func (in *VolumeSnapshotCopySpec) DeepCopy() *VolumeSnapshotCopySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotCopySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotCopyStatus) DeepCopyInto(out *VolumeSnapshotCopyStatus) {
+	*out = *in
+	if in.SourceSnapshotHandle != nil {
+		in, out := &in.SourceSnapshotHandle, &out.SourceSnapshotHandle
+		*out = new(string)
+		**out = **in
+	}
+	if in.BoundVolumeSnapshotContentName != nil {
+		in, out := &in.BoundVolumeSnapshotContentName, &out.BoundVolumeSnapshotContentName
+		*out = new(string)
+		**out = **in
+	}
+	if in.SnapshotHandle != nil {
+		in, out := &in.SnapshotHandle, &out.SnapshotHandle
+		*out = new(string)
+		**out = **in
+	}
+	if in.ReadyToUse != nil {
+		in, out := &in.ReadyToUse, &out.ReadyToUse
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RestoreSize != nil {
+		in, out := &in.RestoreSize, &out.RestoreSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Error != nil {
+		in, out := &in.Error, &out.Error
+		*out = new(VolumeSnapshotError)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotCopyStatus.
+func (in *VolumeSnapshotCopyStatus) DeepCopy() *VolumeSnapshotCopyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotCopyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSnapshotError) DeepCopyInto(out *VolumeSnapshotError) {
 	*out = *in
@@ -427,6 +601,13 @@ func (in *VolumeSnapshotStatus) DeepCopyInto(out *VolumeSnapshotStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AccessibleTopology != nil {
+		in, out := &in.AccessibleTopology, &out.AccessibleTopology
+		*out = make([]corev1.TopologySelectorTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
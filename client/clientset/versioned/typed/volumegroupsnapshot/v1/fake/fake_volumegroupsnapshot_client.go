@@ -28,6 +28,10 @@ type FakeGroupsnapshotV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeGroupsnapshotV1) ClusterVolumeGroupSnapshots() v1.ClusterVolumeGroupSnapshotInterface {
+	return newFakeClusterVolumeGroupSnapshots(c)
+}
+
 func (c *FakeGroupsnapshotV1) VolumeGroupSnapshots(namespace string) v1.VolumeGroupSnapshotInterface {
 	return newFakeVolumeGroupSnapshots(c, namespace)
 }
@@ -18,6 +18,8 @@ limitations under the License.
 
 package v1
 
+type ClusterVolumeGroupSnapshotExpansion interface{}
+
 type VolumeGroupSnapshotExpansion interface{}
 
 type VolumeGroupSnapshotClassExpansion interface{}
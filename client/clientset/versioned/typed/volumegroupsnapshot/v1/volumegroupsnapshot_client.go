@@ -28,6 +28,7 @@ import (
 
 type GroupsnapshotV1Interface interface {
 	RESTClient() rest.Interface
+	ClusterVolumeGroupSnapshotsGetter
 	VolumeGroupSnapshotsGetter
 	VolumeGroupSnapshotClassesGetter
 	VolumeGroupSnapshotContentsGetter
@@ -38,6 +39,10 @@ type GroupsnapshotV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *GroupsnapshotV1Client) ClusterVolumeGroupSnapshots() ClusterVolumeGroupSnapshotInterface {
+	return newClusterVolumeGroupSnapshots(c)
+}
+
 func (c *GroupsnapshotV1Client) VolumeGroupSnapshots(namespace string) VolumeGroupSnapshotInterface {
 	return newVolumeGroupSnapshots(c, namespace)
 }
@@ -40,6 +40,10 @@ func (c *FakeSnapshotV1) VolumeSnapshotContents() v1.VolumeSnapshotContentInterf
 	return &FakeVolumeSnapshotContents{c}
 }
 
+func (c *FakeSnapshotV1) VolumeSnapshotCopies(namespace string) v1.VolumeSnapshotCopyInterface {
+	return &FakeVolumeSnapshotCopies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeSnapshotV1) RESTClient() rest.Interface {
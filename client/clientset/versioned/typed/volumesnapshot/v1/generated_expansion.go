@@ -23,3 +23,5 @@ type VolumeSnapshotExpansion interface{}
 type VolumeSnapshotClassExpansion interface{}
 
 type VolumeSnapshotContentExpansion interface{}
+
+type VolumeSnapshotCopyExpansion interface{}
@@ -31,6 +31,7 @@ type SnapshotV1Interface interface {
 	VolumeSnapshotsGetter
 	VolumeSnapshotClassesGetter
 	VolumeSnapshotContentsGetter
+	VolumeSnapshotCopiesGetter
 }
 
 // SnapshotV1Client is used to interact with features provided by the snapshot.storage.k8s.io group.
@@ -50,6 +51,10 @@ func (c *SnapshotV1Client) VolumeSnapshotContents() VolumeSnapshotContentInterfa
 	return newVolumeSnapshotContents(c)
 }
 
+func (c *SnapshotV1Client) VolumeSnapshotCopies(namespace string) VolumeSnapshotCopyInterface {
+	return newVolumeSnapshotCopies(c, namespace)
+}
+
 // NewForConfig creates a new SnapshotV1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
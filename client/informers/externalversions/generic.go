@@ -56,6 +56,8 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=groupsnapshot.storage.k8s.io, Version=v1
+	case v1.SchemeGroupVersion.WithResource("clustervolumegroupsnapshots"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Groupsnapshot().V1().ClusterVolumeGroupSnapshots().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("volumegroupsnapshots"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Groupsnapshot().V1().VolumeGroupSnapshots().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("volumegroupsnapshotclasses"):
@@ -86,6 +88,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Snapshot().V1().VolumeSnapshotClasses().Informer()}, nil
 	case volumesnapshotv1.SchemeGroupVersion.WithResource("volumesnapshotcontents"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Snapshot().V1().VolumeSnapshotContents().Informer()}, nil
+	case volumesnapshotv1.SchemeGroupVersion.WithResource("volumesnapshotcopies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Snapshot().V1().VolumeSnapshotCopies().Informer()}, nil
 
 	}
 
@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ClusterVolumeGroupSnapshots returns a ClusterVolumeGroupSnapshotInformer.
+	ClusterVolumeGroupSnapshots() ClusterVolumeGroupSnapshotInformer
 	// VolumeGroupSnapshots returns a VolumeGroupSnapshotInformer.
 	VolumeGroupSnapshots() VolumeGroupSnapshotInformer
 	// VolumeGroupSnapshotClasses returns a VolumeGroupSnapshotClassInformer.
@@ -43,6 +45,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// ClusterVolumeGroupSnapshots returns a ClusterVolumeGroupSnapshotInformer.
+func (v *version) ClusterVolumeGroupSnapshots() ClusterVolumeGroupSnapshotInformer {
+	return &clusterVolumeGroupSnapshotInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // VolumeGroupSnapshots returns a VolumeGroupSnapshotInformer.
 func (v *version) VolumeGroupSnapshots() VolumeGroupSnapshotInformer {
 	return &volumeGroupSnapshotInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -30,6 +30,8 @@ type Interface interface {
 	VolumeSnapshotClasses() VolumeSnapshotClassInformer
 	// VolumeSnapshotContents returns a VolumeSnapshotContentInformer.
 	VolumeSnapshotContents() VolumeSnapshotContentInformer
+	// VolumeSnapshotCopies returns a VolumeSnapshotCopyInformer.
+	VolumeSnapshotCopies() VolumeSnapshotCopyInformer
 }
 
 type version struct {
@@ -57,3 +59,8 @@ func (v *version) VolumeSnapshotClasses() VolumeSnapshotClassInformer {
 func (v *version) VolumeSnapshotContents() VolumeSnapshotContentInformer {
 	return &volumeSnapshotContentInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// VolumeSnapshotCopies returns a VolumeSnapshotCopyInformer.
+func (v *version) VolumeSnapshotCopies() VolumeSnapshotCopyInformer {
+	return &volumeSnapshotCopyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
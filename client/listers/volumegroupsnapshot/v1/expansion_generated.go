@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1
 
+// ClusterVolumeGroupSnapshotListerExpansion allows custom methods to be added to
+// ClusterVolumeGroupSnapshotLister.
+type ClusterVolumeGroupSnapshotListerExpansion interface{}
+
 // VolumeGroupSnapshotListerExpansion allows custom methods to be added to
 // VolumeGroupSnapshotLister.
 type VolumeGroupSnapshotListerExpansion interface{}
@@ -33,3 +33,11 @@ type VolumeSnapshotClassListerExpansion interface{}
 // VolumeSnapshotContentListerExpansion allows custom methods to be added to
 // VolumeSnapshotContentLister.
 type VolumeSnapshotContentListerExpansion interface{}
+
+// VolumeSnapshotCopyListerExpansion allows custom methods to be added to
+// VolumeSnapshotCopyLister.
+type VolumeSnapshotCopyListerExpansion interface{}
+
+// VolumeSnapshotCopyNamespaceListerExpansion allows custom methods to be added to
+// VolumeSnapshotCopyNamespaceLister.
+type VolumeSnapshotCopyNamespaceListerExpansion interface{}
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
@@ -35,6 +36,7 @@ import (
 	server "k8s.io/apiserver/pkg/server"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	coreinformers "k8s.io/client-go/informers"
+	corev1informers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -49,6 +51,7 @@ import (
 	"github.com/kubernetes-csi/csi-lib-utils/metrics"
 	csirpc "github.com/kubernetes-csi/csi-lib-utils/rpc"
 	"github.com/kubernetes-csi/csi-lib-utils/standardflags"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/audit"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/features"
 	controller "github.com/kubernetes-csi/external-snapshotter/v8/pkg/sidecar-controller"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
@@ -86,9 +89,30 @@ var (
 	retryIntervalStart          = flag.Duration("retry-interval-start", time.Second, "Initial retry interval of failed volume snapshot creation or deletion. It doubles with each failure, up to retry-interval-max. Default is 1 second.")
 	retryIntervalMax            = flag.Duration("retry-interval-max", 5*time.Minute, "Maximum retry interval of failed volume snapshot creation or deletion. Default is 5 minutes.")
 	enableNodeDeployment        = flag.Bool("node-deployment", false, "Enables deploying the sidecar controller together with a CSI driver on nodes to manage snapshots for node-local volumes.")
+	nodeHeartbeatLeaseDuration  = flag.Duration("node-heartbeat-lease-duration", 0, "Duration of a Lease that the sidecar renews if --node-deployment is set, so that the snapshot controller only assigns VolumeSnapshotContents to nodes with a running sidecar. The Lease is created in the namespace given by the POD_NAMESPACE environment variable. Default is 0, which disables the Lease.")
 	groupSnapshotNamePrefix     = flag.String("groupsnapshot-name-prefix", "groupsnapshot", "Prefix to apply to the name of a created group snapshot")
 	groupSnapshotNameUUIDLength = flag.Int("groupsnapshot-name-uuid-length", -1, "Length in characters for the generated uuid of a created group snapshot. Defaults behavior is to NOT truncate.")
 	featureGates                map[string]bool
+
+	emulateGroupSnapshots           = flag.Bool("emulate-group-snapshots", false, "If the CSI driver does not support GroupControllerCreateVolumeGroupSnapshot, emulate volume group snapshots with individual snapshots taken in parallel. Emulated group snapshots are not crash consistent and are marked as such in their status.")
+	groupSnapshotEmulationWindow    = flag.Duration("group-snapshot-emulation-window", 10*time.Second, "Maximum time between the first and the last individual snapshot of an emulated volume group snapshot. If it is exceeded, the individual snapshots are deleted and the group snapshot is retried. Default is 10 seconds.")
+	groupSnapshotQuiesceHookURL     = flag.String("group-snapshot-quiesce-hook-url", "", "URL that is sent a POST request to quiesce the volumes before and to unquiesce them after the individual snapshots of an emulated volume group snapshot are taken. Default is empty, which means no hook is called.")
+	groupSnapshotQuiesceHookTimeout = flag.Duration("group-snapshot-quiesce-hook-timeout", 30*time.Second, "Timeout of the requests sent to group-snapshot-quiesce-hook-url. Default is 30 seconds.")
+
+	snapshotVerificationInterval = flag.Duration("snapshot-verification-interval", 0, "Interval at which ready VolumeSnapshotContents are checked against the storage system using ListSnapshots. Contents whose snapshot is missing get an error in their status and a warning event. Requires the LIST_SNAPSHOTS capability. Default is 0, which disables the verification.")
+	snapshotUsageInterval        = flag.Duration("snapshot-usage-interval", 0, "Interval at which the bytes allocated to ready VolumeSnapshotContents are read from the SnapshotMetadata service of the CSI driver, recorded in their status and exported as a metric per namespace and VolumeSnapshotClass. Requires the SNAPSHOT_METADATA_SERVICE plugin capability. Default is 0, which disables snapshot usage accounting.")
+
+	auditLogPath        = flag.String("audit-log-path", "", "File that a JSON line is appended to for every snapshot creation and deletion sent to the CSI driver. Default is empty, which means no audit file is written.")
+	auditWebhookURL     = flag.String("audit-webhook-url", "", "URL that every audit record is sent to in a POST request. Default is empty, which means no webhook is called.")
+	auditWebhookTimeout = flag.Duration("audit-webhook-timeout", 10*time.Second, "Timeout of the requests sent to audit-webhook-url. Default is 10 seconds.")
+	auditHMACKeyFile    = flag.String("audit-hmac-key-file", "", "File with the key of the HMAC-SHA256 that chains the audit records. Without a key, the records are chained with a plain SHA-256, which detects lost and accidentally modified records but can be recomputed by anyone who rewrites the audit log. Default is empty.")
+
+	credentialsDir            = flag.String("credentials-dir", "", "Directory with the credentials of the \"file\" secret provider, in <namespace>/<name>/<key> files, e.g. a projected volume. Default is empty, which disables the provider.")
+	credentialProviderAddress = flag.String("credential-provider-address", "", "Unix domain socket of the \"grpc\" secret provider. Default is empty, which disables the provider.")
+	credentialProviderTimeout = flag.Duration("credential-provider-timeout", 10*time.Second, "Timeout of the calls to the \"grpc\" secret provider. Default is 10 seconds.")
+	secretCache               = flag.Bool("secret-cache", false, "Read the Kubernetes Secrets of snapshot classes from an informer instead of the API server, and retry the operations of not yet ready or deleted VolumeSnapshotContents when their Secret changes. Requires --secret-cache-namespace or --secret-cache-label-selector, and list and watch permissions on the selected Secrets. Default is false.")
+	secretCacheNamespace      = flag.String("secret-cache-namespace", "", "Namespace of the Secrets cached with --secret-cache. Secrets in other namespaces are read from the API server. Default is empty, which means all namespaces.")
+	secretCacheLabelSelector  = flag.String("secret-cache-label-selector", "", "Label selector of the Secrets cached with --secret-cache. Secrets that do not match it are read from the API server. Default is empty, which means all Secrets.")
 )
 
 var (
@@ -274,16 +298,53 @@ func main() {
 		supportsCreateVolumeGroupSnapshot, err := supportsGroupControllerCreateVolumeGroupSnapshot(tctx, csiConn)
 		if err != nil {
 			klog.Errorf("error determining if driver supports create/delete group snapshot operations: %v", err)
-		} else if !supportsCreateVolumeGroupSnapshot {
+		} else if !supportsCreateVolumeGroupSnapshot && !*emulateGroupSnapshots {
 			klog.Warningf("CSI driver %s does not support GroupControllerCreateVolumeGroupSnapshot when the --feature-gates=CSIVolumeGroupSnapshot=true flag is set", driverName)
 		}
-		groupSnapshotter = group_snapshotter.NewGroupSnapshotter(csiConn)
+		if err == nil && !supportsCreateVolumeGroupSnapshot && *emulateGroupSnapshots {
+			klog.Warningf("CSI driver %s does not support GroupControllerCreateVolumeGroupSnapshot, volume group snapshots are emulated with individual snapshots and are not crash consistent", driverName)
+			var hook group_snapshotter.QuiesceHook
+			if *groupSnapshotQuiesceHookURL != "" {
+				hook = group_snapshotter.NewHTTPQuiesceHook(*groupSnapshotQuiesceHookURL, &http.Client{Timeout: *groupSnapshotQuiesceHookTimeout})
+			}
+			groupSnapshotter = group_snapshotter.NewEmulatedGroupSnapshotter(snapShotter, hook, *groupSnapshotEmulationWindow)
+		} else {
+			groupSnapshotter = group_snapshotter.NewGroupSnapshotter(csiConn)
+		}
 		if len(*groupSnapshotNamePrefix) == 0 {
 			klog.Error("group snapshot name prefix cannot be of length 0")
 			os.Exit(1)
 		}
 	}
 
+	verificationInterval := *snapshotVerificationInterval
+	if verificationInterval > 0 {
+		tctx, cancel = context.WithTimeout(ctx, *csiTimeout)
+		defer cancel()
+		supportsListSnapshots, err := supportsControllerListSnapshots(tctx, csiConn)
+		if err != nil {
+			klog.Errorf("error determining if driver supports ListSnapshots: %v", err)
+			verificationInterval = 0
+		} else if !supportsListSnapshots {
+			klog.Warningf("CSI driver %s does not support ListSnapshots, snapshot verification is disabled", driverName)
+			verificationInterval = 0
+		}
+	}
+
+	usageInterval := *snapshotUsageInterval
+	if usageInterval > 0 {
+		tctx, cancel = context.WithTimeout(ctx, *csiTimeout)
+		defer cancel()
+		supportsSnapshotMetadata, err := supportsSnapshotMetadataService(tctx, csiConn)
+		if err != nil {
+			klog.Errorf("error determining if driver supports the SnapshotMetadata service: %v", err)
+			usageInterval = 0
+		} else if !supportsSnapshotMetadata {
+			klog.Warningf("CSI driver %s does not support the SnapshotMetadata service, snapshot usage accounting is disabled", driverName)
+			usageInterval = 0
+		}
+	}
+
 	var volumeGroupSnapshotContentInformer groupsnapshotinformers.VolumeGroupSnapshotContentInformer
 	var volumeGroupSnapshotClassInformer groupsnapshotinformers.VolumeGroupSnapshotClassInformer
 	if enableVolumeGroupSnapshots {
@@ -291,6 +352,67 @@ func main() {
 		volumeGroupSnapshotClassInformer = snapshotContentfactory.Groupsnapshot().V1().VolumeGroupSnapshotClasses()
 	}
 
+	var auditSinks []audit.Sink
+	var auditLastHash string
+	var auditKey []byte
+	if *auditHMACKeyFile != "" {
+		data, err := os.ReadFile(*auditHMACKeyFile)
+		if err != nil {
+			klog.Errorf("failed to read audit HMAC key: %v", err)
+			os.Exit(1)
+		}
+		auditKey = bytes.TrimSpace(data)
+		if len(auditKey) == 0 {
+			klog.Errorf("audit HMAC key file %s is empty", *auditHMACKeyFile)
+			os.Exit(1)
+		}
+	}
+	if *auditLogPath != "" {
+		fileSink, err := audit.NewFileSink(*auditLogPath, auditKey)
+		if err != nil {
+			klog.Errorf("failed to open audit log: %v", err)
+			os.Exit(1)
+		}
+		auditSinks = append(auditSinks, fileSink)
+		auditLastHash = fileSink.LastHash()
+	}
+	if *auditWebhookURL != "" {
+		auditSinks = append(auditSinks, audit.NewWebhookSink(*auditWebhookURL, &http.Client{Timeout: *auditWebhookTimeout}))
+	}
+	var auditLogger *audit.Logger
+	if len(auditSinks) > 0 {
+		auditLogger = audit.NewLogger(auditKey, auditLastHash, auditSinks...)
+	}
+
+	var credentialProviderConn grpc.ClientConnInterface
+	if *credentialProviderAddress != "" {
+		conn, err := utils.DialCredentialProvider(*credentialProviderAddress)
+		if err != nil {
+			klog.Errorf("error connecting to credential provider: %v", err)
+			os.Exit(1)
+		}
+		credentialProviderConn = conn
+	}
+	credentialProviders := utils.NewCredentialProviders(kubeClient, *credentialsDir, credentialProviderConn, *credentialProviderTimeout)
+	var secretFactory coreinformers.SharedInformerFactory
+	var secretInformer corev1informers.SecretInformer
+	if *secretCache {
+		if *secretCacheNamespace == "" && *secretCacheLabelSelector == "" {
+			klog.Error("--secret-cache requires --secret-cache-namespace or --secret-cache-label-selector")
+			os.Exit(1)
+		}
+		if _, err := labels.Parse(*secretCacheLabelSelector); err != nil {
+			klog.Errorf("invalid --secret-cache-label-selector: %v", err)
+			os.Exit(1)
+		}
+		secretFactory = coreinformers.NewSharedInformerFactoryWithOptions(kubeClient, *resyncPeriod,
+			coreinformers.WithNamespace(*secretCacheNamespace),
+			coreinformers.WithTweakListOptions(func(options *v1.ListOptions) {
+				options.LabelSelector = *secretCacheLabelSelector
+			}))
+		secretInformer = secretFactory.Core().V1().Secrets()
+	}
+
 	ctrl := controller.NewCSISnapshotSideCarController(
 		snapClient,
 		kubeClient,
@@ -311,8 +433,22 @@ func main() {
 		volumeGroupSnapshotContentInformer,
 		volumeGroupSnapshotClassInformer,
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](*retryIntervalStart, *retryIntervalMax),
+		verificationInterval,
+		usageInterval,
+		auditLogger,
+		credentialProviders,
+		secretInformer,
 	)
 
+	var nodeHeartbeat *controller.NodeHeartbeat
+	if *enableNodeDeployment && *nodeHeartbeatLeaseDuration > 0 {
+		namespace := os.Getenv("POD_NAMESPACE")
+		if namespace == "" {
+			klog.Fatal("The POD_NAMESPACE environment variable must be set when using --node-heartbeat-lease-duration.")
+		}
+		nodeHeartbeat = controller.NewNodeHeartbeat(kubeClient, namespace, driverName, os.Getenv("NODE_NAME"), *nodeHeartbeatLeaseDuration)
+	}
+
 	// handle SIGTERM and SIGINT by cancelling the context.
 	var (
 		terminate       func()          // called when all controllers are finished
@@ -343,10 +479,19 @@ func main() {
 			snapshotContentfactory.Start(stopCh)
 			factory.Start(stopCh)
 			coreFactory.Start(stopCh)
+			if secretFactory != nil {
+				secretFactory.Start(stopCh)
+			}
+			if nodeHeartbeat != nil {
+				go nodeHeartbeat.Run(stopCh)
+			}
 			var controllerWg sync.WaitGroup
 			go ctrl.Run(*threads, stopCh, &controllerWg)
 			<-shutdownHandler
 			controllerWg.Wait()
+			if auditLogger != nil {
+				auditLogger.Close()
+			}
 			terminate()
 		} else {
 			// run...
@@ -354,6 +499,12 @@ func main() {
 			snapshotContentfactory.Start(stopCh)
 			factory.Start(stopCh)
 			coreFactory.Start(stopCh)
+			if secretFactory != nil {
+				secretFactory.Start(stopCh)
+			}
+			if nodeHeartbeat != nil {
+				go nodeHeartbeat.Run(stopCh)
+			}
 			go ctrl.Run(*threads, stopCh, nil)
 
 			// ...until SIGINT
@@ -392,6 +543,15 @@ func supportsControllerCreateSnapshot(ctx context.Context, conn *grpc.ClientConn
 	return capabilities[csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT], nil
 }
 
+func supportsControllerListSnapshots(ctx context.Context, conn *grpc.ClientConn) (bool, error) {
+	capabilities, err := csirpc.GetControllerCapabilities(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+
+	return capabilities[csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS], nil
+}
+
 func supportsGroupControllerCreateVolumeGroupSnapshot(ctx context.Context, conn *grpc.ClientConn) (bool, error) {
 	capabilities, err := csirpc.GetGroupControllerCapabilities(ctx, conn)
 	if err != nil {
@@ -401,6 +561,15 @@ func supportsGroupControllerCreateVolumeGroupSnapshot(ctx context.Context, conn
 	return capabilities[csi.GroupControllerServiceCapability_RPC_CREATE_DELETE_GET_VOLUME_GROUP_SNAPSHOT], nil
 }
 
+func supportsSnapshotMetadataService(ctx context.Context, conn *grpc.ClientConn) (bool, error) {
+	capabilities, err := csirpc.GetPluginCapabilities(ctx, conn)
+	if err != nil {
+		return false, err
+	}
+
+	return capabilities[csi.PluginCapability_Service_SNAPSHOT_METADATA_SERVICE], nil
+}
+
 // ensureVolumeGroupSnapshotCRDsExist checks that the VolumeGroupSnapshot v1 CRDs used by
 // this sidecar (VolumeGroupSnapshotContent and VolumeGroupSnapshotClass) exist in the cluster.
 func ensureVolumeGroupSnapshotCRDsExist(ctx context.Context, client *clientset.Clientset) error {
@@ -0,0 +1,217 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	klog "k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/importer"
+)
+
+var (
+	kubeconfig = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
+
+	// export flags
+	namespace          = flag.String("namespace", "", "export: namespace of the exported snapshots. import: namespace the snapshots are imported into. Default for import is the namespace recorded in the bundle.")
+	snapshotNames      = flag.String("snapshots", "", "export: comma separated names of the VolumeSnapshots to export.")
+	groupSnapshotNames = flag.String("group-snapshots", "", "export: comma separated names of the VolumeGroupSnapshots to export with all their members.")
+	output             = flag.String("output", "-", "export: file the bundle is written to. Default is standard output.")
+
+	// import flags
+	bundleFile             = flag.String("bundle", "-", "import: file the bundle is read from. Default is standard input.")
+	snapshotClassName      = flag.String("snapshot-class", "", "import: VolumeSnapshotClass set on the imported objects instead of the class recorded in the bundle.")
+	groupSnapshotClassName = flag.String("group-snapshot-class", "", "import: VolumeGroupSnapshotClass set on the imported objects instead of the class recorded in the bundle.")
+	deletionPolicy         = flag.String("deletion-policy", string(crdv1.VolumeSnapshotContentRetain), "import: DeletionPolicy of the imported contents, either Retain or Delete.")
+	dryRun                 = flag.Bool("dry-run", false, "import: print the manifests instead of creating them.")
+
+	version = "unknown"
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] export|import\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func main() {
+	c := logsapi.NewLoggingConfiguration()
+	logsapi.AddGoFlags(c, flag.CommandLine)
+	logs.InitLogs()
+	showVersion := flag.Bool("version", false, "Show version.")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(os.Args[0], version)
+		os.Exit(0)
+	}
+	klog.InfoS("Version", "version", version)
+
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	config, err := buildConfig(*kubeconfig)
+	if err != nil {
+		klog.Fatalf("failed to build kubeconfig: %v", err)
+	}
+	snapClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("failed to create snapshot client: %v", err)
+	}
+
+	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "export":
+		if err := exportBundle(ctx, snapClient); err != nil {
+			klog.Fatalf("failed to export snapshots: %v", err)
+		}
+	case "import":
+		kubeClient, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			klog.Fatalf("failed to create kubernetes client: %v", err)
+		}
+		if err := importBundle(ctx, kubeClient, snapClient); err != nil {
+			klog.Fatalf("failed to import snapshots: %v", err)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func exportBundle(ctx context.Context, snapClient clientset.Interface) error {
+	if *namespace == "" {
+		return fmt.Errorf("--namespace is required")
+	}
+	snapshots, groupSnapshots := splitNames(*snapshotNames), splitNames(*groupSnapshotNames)
+	if len(snapshots) == 0 && len(groupSnapshots) == 0 {
+		return fmt.Errorf("at least one of --snapshots and --group-snapshots is required")
+	}
+	bundle, err := importer.Export(ctx, snapClient, *namespace, snapshots, groupSnapshots)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	if *output == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0600)
+}
+
+func importBundle(ctx context.Context, kubeClient kubernetes.Interface, snapClient clientset.Interface) error {
+	policy := crdv1.DeletionPolicy(*deletionPolicy)
+	if policy != crdv1.VolumeSnapshotContentRetain && policy != crdv1.VolumeSnapshotContentDelete {
+		return fmt.Errorf("--deletion-policy must be %s or %s", crdv1.VolumeSnapshotContentRetain, crdv1.VolumeSnapshotContentDelete)
+	}
+
+	var data []byte
+	var err error
+	if *bundleFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*bundleFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %v", err)
+	}
+	bundle := &importer.Bundle{}
+	if err := yaml.UnmarshalStrict(data, bundle); err != nil {
+		return fmt.Errorf("failed to decode bundle: %v", err)
+	}
+
+	imp := importer.NewImporter(nil, kubeClient, snapClient, importer.Config{
+		DriverName:             bundle.Driver,
+		SnapshotClassName:      *snapshotClassName,
+		DeletionPolicy:         policy,
+		Namespace:              *namespace,
+		GroupSnapshotClassName: *groupSnapshotClassName,
+	})
+	plan, err := imp.PlanBundle(ctx, bundle)
+	if err != nil {
+		return err
+	}
+	for _, skipped := range plan.Skipped {
+		klog.Infof("Not importing snapshot %s: %s", skipped.SnapshotID, skipped.Reason)
+	}
+
+	if *dryRun {
+		return printPlan(plan)
+	}
+	if err := imp.Apply(ctx, plan); err != nil {
+		return err
+	}
+	klog.Infof("Imported %d snapshots and %d group snapshots, skipped %d", len(plan.Pairs), len(plan.GroupPairs), len(plan.Skipped))
+	return nil
+}
+
+func printPlan(plan *importer.Plan) error {
+	var objects []interface{}
+	for _, pair := range plan.Pairs {
+		objects = append(objects, pair.Content, pair.Snapshot)
+	}
+	for _, groupPair := range plan.GroupPairs {
+		for _, pair := range groupPair.Members {
+			objects = append(objects, pair.Content, pair.Snapshot)
+		}
+		objects = append(objects, groupPair.GroupContent, groupPair.GroupSnapshot)
+	}
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("---\n%s", data)
+	}
+	return nil
+}
+
+func splitNames(names string) []string {
+	var result []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
@@ -29,6 +29,7 @@ import (
 	"sync"
 	"time"
 
+	coordinationinformers "k8s.io/client-go/informers/coordination/v1"
 	v1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -46,13 +47,16 @@ import (
 	"github.com/kubernetes-csi/csi-lib-utils/leaderelection"
 	"github.com/kubernetes-csi/csi-lib-utils/standardflags"
 	controller "github.com/kubernetes-csi/external-snapshotter/v8/pkg/common-controller"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/dryrun"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/features"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/metrics"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 
 	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
 	snapshotscheme "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/scheme"
 	informers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions"
 	groupsnapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions/volumegroupsnapshot/v1"
+	snapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions/volumesnapshot/v1"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	coreinformers "k8s.io/client-go/informers"
 	utilflag "k8s.io/component-base/cli/flag"
@@ -78,12 +82,17 @@ var (
 	kubeAPIQPS   = flag.Float64("kube-api-qps", 5, "QPS to use while communicating with the kubernetes apiserver. Defaults to 5.0.")
 	kubeAPIBurst = flag.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver. Defaults to 10.")
 
-	httpEndpoint                  = flag.String("http-endpoint", "", "The TCP network address where the HTTP server for diagnostics, including metrics, will listen (example: :8080). The default is empty string, which means the server is disabled.")
-	metricsPath                   = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is `/metrics`.")
-	retryIntervalStart            = flag.Duration("retry-interval-start", time.Second, "Initial retry interval of failed volume snapshot creation or deletion. It doubles with each failure, up to retry-interval-max. Default is 1 second.")
-	retryIntervalMax              = flag.Duration("retry-interval-max", 5*time.Minute, "Maximum retry interval of failed volume snapshot creation or deletion. Default is 5 minutes.")
-	enableDistributedSnapshotting = flag.Bool("enable-distributed-snapshotting", false, "Enables each node to handle snapshotting for the local volumes created on that node")
-	preventVolumeModeConversion   = flag.Bool("prevent-volume-mode-conversion", true, "Prevents an unauthorised user from modifying the volume mode when creating a PVC from an existing VolumeSnapshot.")
+	httpEndpoint                     = flag.String("http-endpoint", "", "The TCP network address where the HTTP server for diagnostics, including metrics, will listen (example: :8080). The default is empty string, which means the server is disabled.")
+	metricsPath                      = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is `/metrics`.")
+	retryIntervalStart               = flag.Duration("retry-interval-start", time.Second, "Initial retry interval of failed volume snapshot creation or deletion. It doubles with each failure, up to retry-interval-max. Default is 1 second.")
+	retryIntervalMax                 = flag.Duration("retry-interval-max", 5*time.Minute, "Maximum retry interval of failed volume snapshot creation or deletion. Default is 5 minutes.")
+	enableDistributedSnapshotting    = flag.Bool("enable-distributed-snapshotting", false, "Enables each node to handle snapshotting for the local volumes created on that node")
+	nodeSnapshotterHeartbeats        = flag.Bool("node-snapshotter-heartbeats", false, "Only assigns VolumeSnapshotContents to nodes whose CSI snapshotter sidecar renews a heartbeat Lease, see --node-heartbeat-lease-duration of the sidecar. Requires list and watch permissions on Leases. Used only if --enable-distributed-snapshotting is set.")
+	preventVolumeModeConversion      = flag.Bool("prevent-volume-mode-conversion", true, "Prevents an unauthorised user from modifying the volume mode when creating a PVC from an existing VolumeSnapshot.")
+	groupSnapshotMemberFailurePolicy = flag.String("group-snapshot-member-failure-policy", string(controller.GroupSnapshotMemberFailureRetry), "How to handle a member of a volume group snapshot that cannot be bound to its VolumeSnapshot. "+
+		"\"Retry\" binds the remaining members and keeps retrying the failed ones, \"FailFast\" stops at the first failed member and marks the group snapshot as failed. Default is \"Retry\".")
+
+	dryRun = flag.Bool("dry-run", false, "Log the writes the controller would make to the API server as a structured plan instead of executing them. Cannot be combined with leader election.")
 
 	retryCRDIntervalMax = flag.Duration("retry-crd-interval-max", 30*time.Second, "Maximum time to wait for CRDs to appear. The default is 30 seconds.")
 	featureGates        map[string]bool
@@ -158,6 +167,36 @@ func ensureVolumeGroupSnapshotCRDsExist(client *clientset.Clientset) error {
 	})
 }
 
+// ensureClusterVolumeGroupSnapshotCRDExists checks that the ClusterVolumeGroupSnapshot v1 CRD exists.
+// It will wait at most the duration specified by retryCRDIntervalMax.
+func ensureClusterVolumeGroupSnapshotCRDExists(client *clientset.Clientset) error {
+	return waitForCRDCondition(func(ctx context.Context) (bool, error) {
+		listOptions := metav1.ListOptions{Limit: 1}
+
+		if _, err := client.GroupsnapshotV1().ClusterVolumeGroupSnapshots().List(ctx, listOptions); err != nil {
+			klog.Errorf("Failed to list v1 clustervolumegroupsnapshots with error=%+v", err)
+			return false, nil
+		}
+
+		return true, nil
+	})
+}
+
+// ensureVolumeSnapshotCopyCRDExists checks that the VolumeSnapshotCopy v1 CRD exists.
+// It will wait at most the duration specified by retryCRDIntervalMax.
+func ensureVolumeSnapshotCopyCRDExists(client *clientset.Clientset) error {
+	return waitForCRDCondition(func(ctx context.Context) (bool, error) {
+		listOptions := metav1.ListOptions{Limit: 1}
+
+		if _, err := client.SnapshotV1().VolumeSnapshotCopies("").List(ctx, listOptions); err != nil {
+			klog.Errorf("Failed to list v1 volumesnapshotcopies with error=%+v", err)
+			return false, nil
+		}
+
+		return true, nil
+	})
+}
+
 func main() {
 	flag.Var(utilflag.NewMapStringBool(&featureGates), "feature-gates", "Comma-seprated list of key=value pairs that describe feature gates for alpha/experimental features. "+
 		"Options are:\n"+strings.Join(utilfeature.DefaultFeatureGate.KnownFeatures(), "\n"))
@@ -183,6 +222,17 @@ func main() {
 	}
 	klog.InfoS("Version", "version", version)
 
+	memberFailurePolicy := controller.GroupSnapshotMemberFailurePolicy(*groupSnapshotMemberFailurePolicy)
+	if memberFailurePolicy != controller.GroupSnapshotMemberFailureRetry && memberFailurePolicy != controller.GroupSnapshotMemberFailureFailFast {
+		klog.Errorf("Invalid group-snapshot-member-failure-policy %q, must be %q or %q", memberFailurePolicy, controller.GroupSnapshotMemberFailureRetry, controller.GroupSnapshotMemberFailureFailFast)
+		os.Exit(1)
+	}
+
+	if *dryRun && *leaderElection {
+		klog.Error("dry-run cannot be combined with leader election")
+		os.Exit(1)
+	}
+
 	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
 	config, err := buildConfig(*kubeconfig)
 	if err != nil {
@@ -195,6 +245,13 @@ func main() {
 
 	coreConfig := rest.CopyConfig(config)
 	coreConfig.ContentType = runtime.ContentTypeProtobuf
+	if *dryRun {
+		// Keep the logged requests readable.
+		klog.InfoS("Running in dry-run mode, no object is changed")
+		coreConfig.ContentType = runtime.ContentTypeJSON
+		coreConfig = dryrun.WrapConfig(coreConfig, dryrun.LogWrite)
+		config = dryrun.WrapConfig(config, dryrun.LogWrite)
+	}
 	kubeClient, err := kubernetes.NewForConfig(coreConfig)
 	if err != nil {
 		klog.Error(err.Error())
@@ -211,8 +268,17 @@ func main() {
 	coreFactory := coreinformers.NewSharedInformerFactory(kubeClient, *resyncPeriod)
 	var nodeInformer v1.NodeInformer
 
+	// Leases are only watched for the heartbeats of CSI snapshotter sidecars.
+	leaseFactory := coreinformers.NewSharedInformerFactoryWithOptions(kubeClient, *resyncPeriod, coreinformers.WithTweakListOptions(func(lo *metav1.ListOptions) {
+		lo.LabelSelector = utils.NodeHeartbeatDriverLabel
+	}))
+	var leaseInformer coordinationinformers.LeaseInformer
+
 	if *enableDistributedSnapshotting {
 		nodeInformer = coreFactory.Core().V1().Nodes()
+		if *nodeSnapshotterHeartbeats {
+			leaseInformer = leaseFactory.Coordination().V1().Leases()
+		}
 	}
 
 	// Create and register metrics manager
@@ -253,6 +319,28 @@ func main() {
 		}
 	}
 
+	// ClusterVolumeGroupSnapshots are built on top of VolumeGroupSnapshots.
+	enableClusterVolumeGroupSnapshots := utilfeature.DefaultFeatureGate.Enabled(features.ClusterVolumeGroupSnapshot)
+	if enableClusterVolumeGroupSnapshots {
+		if !enableVolumeGroupSnapshots {
+			klog.Warningf("The %s feature requires the %s feature; disabling it for this run", features.ClusterVolumeGroupSnapshot, features.VolumeGroupSnapshot)
+			enableClusterVolumeGroupSnapshots = false
+		} else if err := ensureClusterVolumeGroupSnapshotCRDExists(snapClient); err != nil {
+			klog.Warningf("ClusterVolumeGroupSnapshot CRD was not found; disabling the %s feature for this run. "+
+				"Install the ClusterVolumeGroupSnapshot CRD to use this feature: %v", features.ClusterVolumeGroupSnapshot, err)
+			enableClusterVolumeGroupSnapshots = false
+		}
+	}
+
+	enableSnapshotCopy := utilfeature.DefaultFeatureGate.Enabled(features.SnapshotCopy)
+	if enableSnapshotCopy {
+		if err := ensureVolumeSnapshotCopyCRDExists(snapClient); err != nil {
+			klog.Warningf("VolumeSnapshotCopy CRD was not found; disabling the %s feature for this run. "+
+				"Install the VolumeSnapshotCopy CRD to use this feature: %v", features.SnapshotCopy, err)
+			enableSnapshotCopy = false
+		}
+	}
+
 	var volumeGroupSnapshotInformer groupsnapshotinformers.VolumeGroupSnapshotInformer
 	var volumeGroupSnapshotContentInformer groupsnapshotinformers.VolumeGroupSnapshotContentInformer
 	var volumeGroupSnapshotClassInformer groupsnapshotinformers.VolumeGroupSnapshotClassInformer
@@ -261,6 +349,19 @@ func main() {
 		volumeGroupSnapshotContentInformer = factory.Groupsnapshot().V1().VolumeGroupSnapshotContents()
 		volumeGroupSnapshotClassInformer = factory.Groupsnapshot().V1().VolumeGroupSnapshotClasses()
 	}
+	enableSnapshotClassNamespacePolicy := utilfeature.DefaultFeatureGate.Enabled(features.SnapshotClassNamespacePolicy)
+	var clusterVolumeGroupSnapshotInformer groupsnapshotinformers.ClusterVolumeGroupSnapshotInformer
+	var namespaceInformer v1.NamespaceInformer
+	if enableClusterVolumeGroupSnapshots {
+		clusterVolumeGroupSnapshotInformer = factory.Groupsnapshot().V1().ClusterVolumeGroupSnapshots()
+	}
+	if enableClusterVolumeGroupSnapshots || enableSnapshotClassNamespacePolicy {
+		namespaceInformer = coreFactory.Core().V1().Namespaces()
+	}
+	var volumeSnapshotCopyInformer snapshotinformers.VolumeSnapshotCopyInformer
+	if enableSnapshotCopy {
+		volumeSnapshotCopyInformer = factory.Snapshot().V1().VolumeSnapshotCopies()
+	}
 
 	klog.V(2).Infof("Start NewCSISnapshotController with kubeconfig [%s] resyncPeriod [%+v]", *kubeconfig, *resyncPeriod)
 
@@ -273,18 +374,28 @@ func main() {
 		volumeGroupSnapshotInformer,
 		volumeGroupSnapshotContentInformer,
 		volumeGroupSnapshotClassInformer,
+		clusterVolumeGroupSnapshotInformer,
+		volumeSnapshotCopyInformer,
 		coreFactory.Core().V1().PersistentVolumeClaims(),
 		coreFactory.Core().V1().PersistentVolumes(),
 		nodeInformer,
+		leaseInformer,
+		namespaceInformer,
 		metricsManager,
 		*resyncPeriod,
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](*retryIntervalStart, *retryIntervalMax),
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](*retryIntervalStart, *retryIntervalMax),
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](*retryIntervalStart, *retryIntervalMax),
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](*retryIntervalStart, *retryIntervalMax),
+		workqueue.NewTypedItemExponentialFailureRateLimiter[string](*retryIntervalStart, *retryIntervalMax),
+		workqueue.NewTypedItemExponentialFailureRateLimiter[string](*retryIntervalStart, *retryIntervalMax),
 		*enableDistributedSnapshotting,
 		*preventVolumeModeConversion,
 		enableVolumeGroupSnapshots,
+		enableClusterVolumeGroupSnapshots,
+		enableSnapshotCopy,
+		enableSnapshotClassNamespacePolicy,
+		memberFailurePolicy,
 	)
 
 	ctx := context.Background()
@@ -318,6 +429,7 @@ func main() {
 			stopCh := controllerCtx.Done()
 			factory.Start(stopCh)
 			coreFactory.Start(stopCh)
+			leaseFactory.Start(stopCh)
 			var controllerWg sync.WaitGroup
 			go ctrl.Run(*threads, stopCh, &controllerWg)
 			<-shutdownHandler
@@ -328,6 +440,7 @@ func main() {
 			stopCh := make(chan struct{})
 			factory.Start(stopCh)
 			coreFactory.Start(stopCh)
+			leaseFactory.Start(stopCh)
 			go ctrl.Run(*threads, stopCh, nil)
 
 			// ...until SIGINT
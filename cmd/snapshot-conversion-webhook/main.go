@@ -18,9 +18,15 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"flag"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/component-base/logs"
 	logsapi "k8s.io/component-base/logs/api/v1"
 	"k8s.io/klog/v2"
@@ -40,13 +46,81 @@ var (
 		"",
 		"File containing the x509 private key matching --tls-cert-file. Required.",
 	)
+	clientCAFile = flag.String(
+		"tls-client-ca-file",
+		"",
+		"File containing the CA bundle used to verify client certificates. When set, only clients presenting a certificate signed by this CA (e.g. the kube-apiserver) can call the webhook. The file is reloaded when it changes.",
+	)
 	port = flag.Int(
 		"port",
 		443,
 		"Secure port that the webhook listens on",
 	)
+	httpEndpoint = flag.String(
+		"http-endpoint",
+		"",
+		"The TCP network address where the plain HTTP server for diagnostics, including /healthz, /readyz and metrics, will listen (example: :8080). The default is empty string, which means the server is disabled.",
+	)
+	metricsPath = flag.String(
+		"metrics-path",
+		"/metrics",
+		"The HTTP path where prometheus metrics will be exposed on --http-endpoint. Default is `/metrics`.",
+	)
+
+	selfManagedCerts = flag.Bool(
+		"self-managed-certs",
+		false,
+		"Generate a self-signed CA and serving certificate, store them in --cert-secret-name, patch the CA bundle into the conversion configuration of --crds and rotate them before expiry. --tls-cert-file and --tls-private-key-file must not be set in this mode.",
+	)
+	kubeconfig = flag.String(
+		"kubeconfig",
+		"",
+		"Absolute path to the kubeconfig file. Required only when running out of cluster with --self-managed-certs.",
+	)
+	namespace = flag.String(
+		"namespace",
+		"",
+		"Namespace of the webhook Service and of the certificate Secret. Defaults to the pod namespace. Used with --self-managed-certs.",
+	)
+	serviceName = flag.String(
+		"service-name",
+		"snapshot-conversion-webhook-service",
+		"Name of the Service fronting the webhook. The serving certificate is issued for its DNS names. Used with --self-managed-certs.",
+	)
+	certSecretName = flag.String(
+		"cert-secret-name",
+		"snapshot-conversion-webhook-secret",
+		"Name of the Secret storing the generated CA and serving certificate. Used with --self-managed-certs.",
+	)
+	crdNames = flag.String(
+		"crds",
+		"volumegroupsnapshotcontents.groupsnapshot.storage.k8s.io",
+		"Comma-separated list of CRDs whose conversion webhook caBundle is kept in sync with the generated CA. Used with --self-managed-certs.",
+	)
+	certDir = flag.String(
+		"cert-dir",
+		filepath.Join(os.TempDir(), "snapshot-conversion-webhook"),
+		"Directory the generated serving certificate and key are written to. Used with --self-managed-certs.",
+	)
+	certValidity = flag.Duration(
+		"cert-validity",
+		webhook.DefaultSelfManagedCertValidity,
+		"Validity of the generated serving certificate. Used with --self-managed-certs.",
+	)
+	certRotationThreshold = flag.Duration(
+		"cert-rotation-threshold",
+		webhook.DefaultSelfManagedRotationThreshold,
+		"How long before expiry the generated CA and serving certificate are regenerated. Used with --self-managed-certs.",
+	)
+	certCheckInterval = flag.Duration(
+		"cert-check-interval",
+		webhook.DefaultSelfManagedCheckInterval,
+		"How often the generated certificates are checked for rotation. Used with --self-managed-certs.",
+	)
 )
 
+const podNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
 func main() {
 	c := logsapi.NewLoggingConfiguration()
 	logsapi.AddGoFlags(c, flag.CommandLine)
@@ -56,6 +130,22 @@ func main() {
 
 	klog.Info("Starting conversion webhook server")
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel() // stops certwatcher and certificate rotation
+
+	if *selfManagedCerts {
+		if *certFile != "" || *keyFile != "" {
+			klog.Fatal("--tls-cert-file and --tls-private-key-file must not be specified with --self-managed-certs")
+		}
+		bootstrapper := newCertBootstrapper()
+		if err := bootstrapper.Bootstrap(ctx); err != nil {
+			klog.Fatalf("failed to bootstrap self-managed certificates: %v", err)
+		}
+		*certFile = bootstrapper.CertFile()
+		*keyFile = bootstrapper.KeyFile()
+		go bootstrapper.Run(ctx)
+	}
+
 	if certFile == nil || *certFile == "" {
 		klog.Fatal("--tls-cert-file must be specified")
 	}
@@ -64,19 +154,78 @@ func main() {
 	}
 
 	// Create new cert watcher
-	cw, err := webhook.NewCertWatcher(*certFile, *keyFile)
+	cw, err := webhook.NewCertWatcherWithClientCA(*certFile, *keyFile, *clientCAFile)
 	if err != nil {
 		klog.Fatalf("failed to initialize new cert watcher: %v", err)
 	}
-	tlsConfig := &tls.Config{
-		GetCertificate: cw.GetCertificate,
-	}
+	tlsConfig := webhook.NewTLSConfig(cw)
 
 	// Start the webhook server
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel() // stops certwatcher
+	var conversionMetrics *webhook.ConversionMetrics
+	if *httpEndpoint != "" {
+		conversionMetrics = webhook.NewConversionMetrics()
+		go func() {
+			klog.Infof("Starting diagnostics server on %s", *httpEndpoint)
+			if err := webhook.StartDiagnosticsServer(ctx, *httpEndpoint, *metricsPath, cw, conversionMetrics); err != nil {
+				klog.Fatalf("diagnostics server stopped: %v", err)
+			}
+		}()
+	}
 
-	if err := webhook.StartServer(ctx, tlsConfig, cw, *port); err != nil {
+	if err := webhook.StartServer(ctx, tlsConfig, cw, *port, conversionMetrics); err != nil {
 		klog.Fatalf("server stopped: %v", err)
 	}
 }
+
+func newCertBootstrapper() *webhook.CertBootstrapper {
+	config, err := buildConfig(*kubeconfig)
+	if err != nil {
+		klog.Fatalf("failed to build kubeconfig: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("failed to create kubernetes client: %v", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("failed to create dynamic client: %v", err)
+	}
+
+	ns := *namespace
+	if ns == "" {
+		data, err := os.ReadFile(podNamespaceFile)
+		if err != nil {
+			klog.Fatalf("--namespace must be specified when not running in a pod: %v", err)
+		}
+		ns = strings.TrimSpace(string(data))
+	}
+
+	var crds []string
+	for _, name := range strings.Split(*crdNames, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			crds = append(crds, name)
+		}
+	}
+
+	bootstrapper, err := webhook.NewCertBootstrapper(kubeClient, dynamicClient, webhook.SelfManagedCertConfig{
+		SecretName:        *certSecretName,
+		Namespace:         ns,
+		ServiceName:       *serviceName,
+		CRDNames:          crds,
+		CertDir:           *certDir,
+		CertValidity:      *certValidity,
+		RotationThreshold: *certRotationThreshold,
+		CheckInterval:     *certCheckInterval,
+	})
+	if err != nil {
+		klog.Fatalf("invalid self-managed certificate configuration: %v", err)
+	}
+	return bootstrapper
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	klog "k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubernetes-csi/csi-lib-utils/connection"
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	csirpc "github.com/kubernetes-csi/csi-lib-utils/rpc"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/importer"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+var (
+	csiAddress        = flag.String("csi-address", "/run/csi/socket", "Address of the CSI driver socket.")
+	kubeconfig        = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
+	csiTimeout        = flag.Duration("timeout", time.Minute, "The timeout for any RPCs to the CSI driver. Default is 1 minute.")
+	sourceVolumeID    = flag.String("source-volume-id", "", "Only import snapshots of the volume with this CSI volume handle. Default is to import the snapshots of all volumes.")
+	snapshotClassName = flag.String("snapshot-class", "", "VolumeSnapshotClass set on the imported objects. Its snapshotter list secret is used to list the snapshots.")
+	deletionPolicy    = flag.String("deletion-policy", string(crdv1.VolumeSnapshotContentRetain), "DeletionPolicy of the imported VolumeSnapshotContents, either Retain or Delete.")
+	dryRun            = flag.Bool("dry-run", false, "Print the VolumeSnapshot and VolumeSnapshotContent manifests instead of creating them.")
+
+	credentialsDir            = flag.String("credentials-dir", "", "Directory with the credentials of the \"file\" secret provider, in <namespace>/<name>/<key> files.")
+	credentialProviderAddress = flag.String("credential-provider-address", "", "Unix domain socket of the \"grpc\" secret provider.")
+
+	version = "unknown"
+)
+
+func main() {
+	c := logsapi.NewLoggingConfiguration()
+	logsapi.AddGoFlags(c, flag.CommandLine)
+	logs.InitLogs()
+	showVersion := flag.Bool("version", false, "Show version.")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(os.Args[0], version)
+		os.Exit(0)
+	}
+	klog.InfoS("Version", "version", version)
+
+	policy := crdv1.DeletionPolicy(*deletionPolicy)
+	if policy != crdv1.VolumeSnapshotContentRetain && policy != crdv1.VolumeSnapshotContentDelete {
+		klog.Fatalf("--deletion-policy must be %s or %s", crdv1.VolumeSnapshotContentRetain, crdv1.VolumeSnapshotContentDelete)
+	}
+
+	config, err := buildConfig(*kubeconfig)
+	if err != nil {
+		klog.Fatalf("failed to build kubeconfig: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("failed to create kubernetes client: %v", err)
+	}
+	snapClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("failed to create snapshot client: %v", err)
+	}
+
+	ctx := context.Background()
+	csiConn, err := connection.Connect(ctx, *csiAddress, metrics.NewCSIMetricsManager("" /* driverName */))
+	if err != nil {
+		klog.Fatalf("error connecting to CSI driver: %v", err)
+	}
+	defer csiConn.Close()
+
+	tctx, cancel := context.WithTimeout(ctx, *csiTimeout)
+	defer cancel()
+	driverName, err := csirpc.GetDriverName(tctx, csiConn)
+	if err != nil {
+		klog.Fatalf("error getting CSI driver name: %v", err)
+	}
+	klog.V(2).Infof("CSI driver name: %q", driverName)
+
+	var credentialProviderConn grpc.ClientConnInterface
+	if *credentialProviderAddress != "" {
+		conn, err := utils.DialCredentialProvider(*credentialProviderAddress)
+		if err != nil {
+			klog.Fatalf("error connecting to credential provider: %v", err)
+		}
+		defer conn.Close()
+		credentialProviderConn = conn
+	}
+
+	imp := importer.NewImporter(snapshotter.NewSnapshotter(csiConn), kubeClient, snapClient, importer.Config{
+		DriverName:          driverName,
+		SourceVolumeID:      *sourceVolumeID,
+		SnapshotClassName:   *snapshotClassName,
+		DeletionPolicy:      policy,
+		CredentialProviders: utils.NewCredentialProviders(kubeClient, *credentialsDir, credentialProviderConn, *csiTimeout),
+	})
+	plan, err := imp.Plan(tctx)
+	if err != nil {
+		klog.Fatalf("failed to plan the import: %v", err)
+	}
+	for _, skipped := range plan.Skipped {
+		klog.Infof("Not importing snapshot %s of volume %s: %s", skipped.SnapshotID, skipped.SourceVolumeID, skipped.Reason)
+	}
+
+	if *dryRun {
+		if err := printPlan(plan); err != nil {
+			klog.Fatalf("failed to print manifests: %v", err)
+		}
+		return
+	}
+	if err := imp.Apply(ctx, plan); err != nil {
+		klog.Fatalf("failed to import snapshots: %v", err)
+	}
+	klog.Infof("Imported %d snapshots, skipped %d", len(plan.Pairs), len(plan.Skipped))
+}
+
+func printPlan(plan *importer.Plan) error {
+	for _, pair := range plan.Pairs {
+		for _, obj := range []interface{}{pair.Content, pair.Snapshot} {
+			data, err := yaml.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("---\n%s", data)
+		}
+	}
+	return nil
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
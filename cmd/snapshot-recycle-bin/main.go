@@ -0,0 +1,117 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	klog "k8s.io/klog/v2"
+
+	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/recyclebin"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+var (
+	kubeconfig = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
+
+	// undelete flags
+	namespace = flag.String("namespace", "", "undelete: namespace of the restored VolumeSnapshot. Default is the namespace of the deleted VolumeSnapshot.")
+	name      = flag.String("name", "", "undelete: name of the restored VolumeSnapshot. Default is the name of the deleted VolumeSnapshot.")
+
+	version = "unknown"
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] list|undelete <content>\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func main() {
+	c := logsapi.NewLoggingConfiguration()
+	logsapi.AddGoFlags(c, flag.CommandLine)
+	logs.InitLogs()
+	showVersion := flag.Bool("version", false, "Show version.")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(os.Args[0], version)
+		os.Exit(0)
+	}
+	klog.InfoS("Version", "version", version)
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	config, err := buildConfig(*kubeconfig)
+	if err != nil {
+		klog.Fatalf("failed to build kubeconfig: %v", err)
+	}
+	snapClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("failed to create snapshot client: %v", err)
+	}
+
+	ctx := context.Background()
+	switch {
+	case flag.Arg(0) == "list" && flag.NArg() == 1:
+		if err := list(ctx, snapClient); err != nil {
+			klog.Fatalf("failed to list the recycle bin: %v", err)
+		}
+	case flag.Arg(0) == "undelete" && flag.NArg() == 2:
+		snapshot, err := recyclebin.Undelete(ctx, snapClient, flag.Arg(1), *namespace, *name)
+		if err != nil {
+			klog.Fatalf("failed to undelete %s: %v", flag.Arg(1), err)
+		}
+		klog.Infof("Restored VolumeSnapshotContent %s as VolumeSnapshot %s/%s", flag.Arg(1), snapshot.Namespace, snapshot.Name)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func list(ctx context.Context, snapClient clientset.Interface) error {
+	contents, err := recyclebin.List(ctx, snapClient)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDRIVER\tDELETED SNAPSHOT\tPURGE AFTER")
+	for _, content := range contents {
+		ref := content.Spec.VolumeSnapshotRef
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\n", content.Name, content.Spec.Driver, ref.Namespace, ref.Name, content.Annotations[utils.AnnPurgeAfter])
+	}
+	return w.Flush()
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records the snapshot operations sent to the storage system
+// as JSON lines. Every record carries the hash of the previous record, so
+// that removed, reordered or modified records can be detected with Verify.
+// The hashes are only tamper-evident if they are keyed: without a key,
+// anyone who can rewrite the log can also recompute a consistent chain.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	klog "k8s.io/klog/v2"
+)
+
+// queueSize is the number of records that are buffered for every sink.
+const queueSize = 1024
+
+// Operations recorded in the audit log.
+const (
+	OperationCreateSnapshot      = "CreateSnapshot"
+	OperationDeleteSnapshot      = "DeleteSnapshot"
+	OperationCreateGroupSnapshot = "CreateGroupSnapshot"
+	OperationDeleteGroupSnapshot = "DeleteGroupSnapshot"
+)
+
+// Results of the recorded operations.
+const (
+	ResultSuccess = "Success"
+	ResultFailure = "Failure"
+)
+
+// ObjectRef identifies the object that requested an operation.
+type ObjectRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// Record is a single entry of the audit log.
+type Record struct {
+	Operation string `json:"operation"`
+	Driver    string `json:"driver"`
+	// Content is the name of the VolumeSnapshotContent or
+	// VolumeGroupSnapshotContent the operation was done for.
+	Content string `json:"content"`
+	// RequestedBy is the VolumeSnapshot or VolumeGroupSnapshot bound to
+	// Content.
+	RequestedBy ObjectRef `json:"requestedBy"`
+	Class       string    `json:"class,omitempty"`
+
+	SnapshotHandle       string   `json:"snapshotHandle,omitempty"`
+	GroupSnapshotHandle  string   `json:"groupSnapshotHandle,omitempty"`
+	SnapshotHandles      []string `json:"snapshotHandles,omitempty"`
+	SourceVolumeHandles  []string `json:"sourceVolumeHandles,omitempty"`
+	SourceSnapshotHandle string   `json:"sourceSnapshotHandle,omitempty"`
+
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	// PreviousHash is the Hash of the previous record, empty for the first
+	// record of a log.
+	PreviousHash string `json:"previousHash"`
+	// Hash is the SHA-256 of the record encoded without Hash, or its
+	// HMAC-SHA256 if the log has a key.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Sink stores the encoded records.
+type Sink interface {
+	// Write stores a single JSON encoded record, terminated by a newline.
+	Write(line []byte) error
+}
+
+// Logger chains records and writes them to its sinks. Every sink is written
+// by its own goroutine from a bounded queue, so that a slow sink, e.g. a
+// webhook, does not block the callers of Log.
+type Logger struct {
+	mu       sync.Mutex
+	key      []byte
+	lastHash string
+	queues   []chan []byte
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewLogger returns a Logger that writes to all sinks. key is the key of the
+// HMAC of the records, empty for a plain SHA-256. lastHash is the hash of the
+// last record already stored, so that a restarted logger continues the chain.
+func NewLogger(key []byte, lastHash string, sinks ...Sink) *Logger {
+	return newLogger(key, lastHash, queueSize, sinks...)
+}
+
+func newLogger(key []byte, lastHash string, size int, sinks ...Sink) *Logger {
+	l := &Logger{
+		key:      key,
+		lastHash: lastHash,
+	}
+	for _, sink := range sinks {
+		queue := make(chan []byte, size)
+		l.queues = append(l.queues, queue)
+		l.wg.Add(1)
+		go l.write(sink, queue)
+	}
+	return l
+}
+
+func (l *Logger) write(sink Sink, queue <-chan []byte) {
+	defer l.wg.Done()
+	for line := range queue {
+		if err := sink.Write(line); err != nil {
+			klog.Errorf("failed to write audit record: %v", err)
+		}
+	}
+}
+
+// Log sets the hashes of record and queues it for all sinks. Errors of the
+// sinks are logged. An error is returned if the queue of a sink is full. The
+// record is part of the chain even then, so that a record missing from a
+// sink is detected as a gap.
+func (l *Logger) Log(record Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("audit logger is closed")
+	}
+
+	record.PreviousHash = l.lastHash
+	hash, err := hashRecord(record, l.key)
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	l.lastHash = hash
+
+	var errs []error
+	for i, queue := range l.queues {
+		select {
+		case queue <- line:
+		default:
+			errs = append(errs, fmt.Errorf("queue of audit sink %d is full, record %s is dropped", i, hash))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close waits until all queued records are written. Log fails afterwards.
+func (l *Logger) Close() {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		for _, queue := range l.queues {
+			close(queue)
+		}
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+}
+
+func hashRecord(record Record, key []byte) (string, error) {
+	record.Hash = ""
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	if len(key) == 0 {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify checks the chain of the records read from r with the key of the
+// log and returns the hash of the last record.
+func Verify(r io.Reader, key []byte) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	lastHash := ""
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return "", fmt.Errorf("line %d: %v", line, err)
+		}
+		if record.PreviousHash != lastHash {
+			return "", fmt.Errorf("line %d: previous hash %q does not match %q", line, record.PreviousHash, lastHash)
+		}
+		hash, err := hashRecord(record, key)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %v", line, err)
+		}
+		if record.Hash != hash {
+			return "", fmt.Errorf("line %d: hash %q does not match the record", line, record.Hash)
+		}
+		lastHash = hash
+	}
+	return lastHash, scanner.Err()
+}
+
+// FileSink appends the records to a file.
+type FileSink struct {
+	file     *os.File
+	lastHash string
+}
+
+// NewFileSink opens or creates the file at path. The records already in the
+// file are verified with key, see LastHash.
+func NewFileSink(path string, key []byte) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	lastHash, err := Verify(file, key)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("audit log %s is corrupted: %v", path, err)
+	}
+	return &FileSink{file: file, lastHash: lastHash}, nil
+}
+
+// LastHash returns the hash of the last record in the file when it was
+// opened.
+func (s *FileSink) LastHash() string {
+	return s.lastHash
+}
+
+// Write appends line to the file and flushes it to disk.
+func (s *FileSink) Write(line []byte) error {
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a Sink that POSTs every record to url. Any
+// response status other than 2xx fails the write.
+func NewWebhookSink(url string, client *http.Client) Sink {
+	return &webhookSink{
+		url:    url,
+		client: client,
+	}
+}
+
+func (s *webhookSink) Write(line []byte) error {
+	rsp, err := s.client.Post(s.url, "application/json", bytes.NewReader(line))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return fmt.Errorf("audit webhook returned %s: %s", rsp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
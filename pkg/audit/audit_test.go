@@ -0,0 +1,229 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRecord(handle string) Record {
+	return Record{
+		Operation:      OperationCreateSnapshot,
+		Driver:         "hostpath.csi.k8s.io",
+		Content:        "snapcontent-" + handle,
+		RequestedBy:    ObjectRef{Kind: "VolumeSnapshot", Namespace: "default", Name: "snap-" + handle},
+		SnapshotHandle: handle,
+		Result:         ResultSuccess,
+		StartTime:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 1, 1, 0, 0, 1, 0, time.UTC),
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileSink(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error opening the audit log: %v", err)
+	}
+	if sink.LastHash() != "" {
+		t.Errorf("expected an empty hash for a new audit log, got %q", sink.LastHash())
+	}
+	logger := NewLogger(nil, sink.LastHash(), sink)
+	for _, handle := range []string{"a", "b"} {
+		if err := logger.Log(newRecord(handle)); err != nil {
+			t.Fatalf("unexpected error logging: %v", err)
+		}
+	}
+	logger.Close()
+
+	// A reopened audit log continues the chain.
+	sink, err = NewFileSink(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error reopening the audit log: %v", err)
+	}
+	if sink.LastHash() == "" {
+		t.Errorf("expected the hash of the last record")
+	}
+	logger = NewLogger(nil, sink.LastHash(), sink)
+	if err := logger.Log(newRecord("c")); err != nil {
+		t.Fatalf("unexpected error logging: %v", err)
+	}
+	logger.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading the audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(lines))
+	}
+	if _, err := Verify(bytes.NewReader(data), nil); err != nil {
+		t.Errorf("unexpected error verifying the audit log: %v", err)
+	}
+
+	tests := map[string]string{
+		"modified":  strings.Join([]string{lines[0], strings.Replace(lines[1], `"b"`, `"x"`, 1), lines[2]}, "\n"),
+		"removed":   strings.Join([]string{lines[0], lines[2]}, "\n"),
+		"reordered": strings.Join([]string{lines[1], lines[0], lines[2]}, "\n"),
+	}
+	for name, tampered := range tests {
+		if _, err := Verify(strings.NewReader(tampered), nil); err == nil {
+			t.Errorf("%s: expected the audit log to fail verification", name)
+		}
+		if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+			t.Fatalf("unexpected error writing the audit log: %v", err)
+		}
+		if _, err := NewFileSink(path, nil); err == nil {
+			t.Errorf("%s: expected a tampered audit log to be rejected", name)
+		}
+	}
+}
+
+func TestFileSinkHMAC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("audit-key")
+
+	sink, err := NewFileSink(path, key)
+	if err != nil {
+		t.Fatalf("unexpected error opening the audit log: %v", err)
+	}
+	logger := NewLogger(key, sink.LastHash(), sink)
+	for _, handle := range []string{"a", "b"} {
+		if err := logger.Log(newRecord(handle)); err != nil {
+			t.Fatalf("unexpected error logging: %v", err)
+		}
+	}
+	logger.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading the audit log: %v", err)
+	}
+	if _, err := Verify(bytes.NewReader(data), key); err != nil {
+		t.Errorf("unexpected error verifying the audit log: %v", err)
+	}
+	if _, err := Verify(bytes.NewReader(data), []byte("other-key")); err == nil {
+		t.Errorf("expected the audit log to fail verification with another key")
+	}
+
+	// A log rewritten with a recomputed chain is rejected without the key.
+	rewritten := filepath.Join(t.TempDir(), "rewritten.log")
+	plain, err := NewFileSink(rewritten, nil)
+	if err != nil {
+		t.Fatalf("unexpected error opening the audit log: %v", err)
+	}
+	logger = NewLogger(nil, "", plain)
+	for _, handle := range []string{"a", "x"} {
+		if err := logger.Log(newRecord(handle)); err != nil {
+			t.Fatalf("unexpected error logging: %v", err)
+		}
+	}
+	logger.Close()
+	if _, err := NewFileSink(rewritten, key); err == nil {
+		t.Errorf("expected a rewritten audit log to be rejected")
+	}
+}
+
+// blockingSink blocks every write until release is closed.
+type blockingSink struct {
+	started chan struct{}
+	release chan struct{}
+	lines   [][]byte
+}
+
+func (s *blockingSink) Write(line []byte) error {
+	if len(s.lines) == 0 {
+		close(s.started)
+	}
+	<-s.release
+	s.lines = append(s.lines, line)
+	return nil
+}
+
+func TestLoggerQueue(t *testing.T) {
+	sink := &blockingSink{started: make(chan struct{}), release: make(chan struct{})}
+	logger := newLogger(nil, "", 1, sink)
+
+	// The first record is written, the second one is queued.
+	if err := logger.Log(newRecord("a")); err != nil {
+		t.Fatalf("unexpected error logging: %v", err)
+	}
+	<-sink.started
+	if err := logger.Log(newRecord("b")); err != nil {
+		t.Fatalf("unexpected error logging while the sink is blocked: %v", err)
+	}
+	if err := logger.Log(newRecord("c")); err == nil || !strings.Contains(err.Error(), "full") {
+		t.Errorf("expected the record to be dropped, got %v", err)
+	}
+
+	close(sink.release)
+	logger.Close()
+	if len(sink.lines) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(sink.lines))
+	}
+	if _, err := Verify(bytes.NewReader(bytes.Join(sink.lines, nil)), nil); err != nil {
+		t.Errorf("unexpected error verifying the written records: %v", err)
+	}
+	if err := logger.Log(newRecord("d")); err == nil {
+		t.Errorf("expected an error logging to a closed logger")
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received [][]byte
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s with content type %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, body)
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	logger := NewLogger(nil, "", NewWebhookSink(server.URL, server.Client()))
+	for _, handle := range []string{"a", "b"} {
+		if err := logger.Log(newRecord(handle)); err != nil {
+			t.Errorf("unexpected error logging: %v", err)
+		}
+	}
+	logger.Close()
+	if len(received) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(received))
+	}
+	if _, err := Verify(bytes.NewReader(bytes.Join(received, nil)), nil); err != nil {
+		t.Errorf("unexpected error verifying the received records: %v", err)
+	}
+
+	fail = true
+	if err := NewWebhookSink(server.URL, server.Client()).Write(received[0]); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("expected the webhook error, got %v", err)
+	}
+}
@@ -0,0 +1,617 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+
+	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// A ClusterVolumeGroupSnapshot selects PVCs in several namespaces. It is
+// provisioned like a dynamic VolumeGroupSnapshot: the controller records the
+// members, creates a single VolumeGroupSnapshotContent pointing back to the
+// ClusterVolumeGroupSnapshot, and once the driver has cut the group snapshot,
+// creates the VolumeSnapshot of each member in the namespace of its PVC.
+// Pre-provisioned cluster group snapshots are not supported.
+
+func (ctrl *csiSnapshotCommonController) storeClusterGroupSnapshotUpdate(groupSnapshot interface{}) (bool, error) {
+	return utils.StoreObjectUpdate(ctrl.clusterGroupSnapshotStore, groupSnapshot, "clustergroupsnapshot")
+}
+
+// enqueueClusterGroupSnapshotWork adds cluster group snapshot to given work queue.
+func (ctrl *csiSnapshotCommonController) enqueueClusterGroupSnapshotWork(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	if groupSnapshot, ok := obj.(*groupsnapshotv1.ClusterVolumeGroupSnapshot); ok {
+		objName, err := cache.DeletionHandlingMetaNamespaceKeyFunc(groupSnapshot)
+		if err != nil {
+			klog.Errorf("failed to get key from object: %v, %v", err, groupSnapshot)
+			return
+		}
+		klog.V(5).Infof("enqueued %q for sync", objName)
+		ctrl.clusterGroupSnapshotQueue.Add(objName)
+	}
+}
+
+// clusterGroupSnapshotWorker is the main worker for ClusterVolumeGroupSnapshots.
+func (ctrl *csiSnapshotCommonController) clusterGroupSnapshotWorker() {
+	key, quit := ctrl.clusterGroupSnapshotQueue.Get()
+	if quit {
+		return
+	}
+	defer ctrl.clusterGroupSnapshotQueue.Done(key)
+
+	if err := ctrl.syncClusterGroupSnapshotByKey(context.Background(), key); err != nil {
+		// Rather than wait for a full resync, re-add the key to the
+		// queue to be processed.
+		ctrl.clusterGroupSnapshotQueue.AddRateLimited(key)
+		klog.V(4).Infof("Failed to sync cluster group snapshot %q, will retry again: %v", key, err)
+	} else {
+		// Finally, if no error occurs we forget this item so it does not
+		// get queued again until another change happens.
+		ctrl.clusterGroupSnapshotQueue.Forget(key)
+	}
+}
+
+// syncClusterGroupSnapshotByKey processes a ClusterVolumeGroupSnapshot request.
+func (ctrl *csiSnapshotCommonController) syncClusterGroupSnapshotByKey(ctx context.Context, key string) error {
+	klog.V(5).Infof("syncClusterGroupSnapshotByKey[%s]", key)
+
+	groupSnapshot, err := ctrl.clusterGroupSnapshotLister.Get(key)
+	if err == nil {
+		// The cluster group snapshot still exists in informer cache, the event
+		// must have been add/update/sync
+		return ctrl.updateClusterGroupSnapshot(ctx, groupSnapshot)
+	}
+	if !apierrs.IsNotFound(err) {
+		klog.V(2).Infof("error getting cluster group snapshot %q from informer: %v", key, err)
+		return err
+	}
+	// The cluster group snapshot is not in informer cache, the event must have been "delete"
+	obj, found, err := ctrl.clusterGroupSnapshotStore.GetByKey(key)
+	if err != nil {
+		klog.V(2).Infof("error getting cluster group snapshot %q from cache: %v", key, err)
+		return nil
+	}
+	if !found {
+		// The controller has already processed the delete event and
+		// deleted the cluster group snapshot from its cache
+		klog.V(2).Infof("deletion of cluster group snapshot %q was already processed", key)
+		return nil
+	}
+	groupSnapshot, ok := obj.(*groupsnapshotv1.ClusterVolumeGroupSnapshot)
+	if !ok {
+		klog.Errorf("expected cvgs, got %+v", obj)
+		return nil
+	}
+
+	klog.V(5).Infof("deleting cluster group snapshot %q", key)
+	ctrl.deleteClusterGroupSnapshot(groupSnapshot)
+
+	return nil
+}
+
+// updateClusterGroupSnapshot runs in worker thread and handles "clustergroupsnapshot added",
+// "clustergroupsnapshot updated" and "periodic sync" events.
+func (ctrl *csiSnapshotCommonController) updateClusterGroupSnapshot(ctx context.Context, groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) error {
+	// Store the new cluster group snapshot version in the cache and do not
+	// process it if this is an old version.
+	klog.V(5).Infof("updateClusterGroupSnapshot %q", groupSnapshot.Name)
+	newGroupSnapshot, err := ctrl.storeClusterGroupSnapshotUpdate(groupSnapshot)
+	if err != nil {
+		klog.Errorf("%v", err)
+	}
+	if !newGroupSnapshot {
+		return nil
+	}
+
+	err = ctrl.syncClusterGroupSnapshot(ctx, groupSnapshot)
+	if err != nil {
+		if apierrs.IsConflict(err) {
+			// Version conflict error happens quite often and the controller
+			// recovers from it easily.
+			klog.V(3).Infof("could not sync cluster group snapshot %q: %+v", groupSnapshot.Name, err)
+		} else {
+			klog.Errorf("could not sync cluster group snapshot %q: %+v", groupSnapshot.Name, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteClusterGroupSnapshot runs in worker thread and handles "clustergroupsnapshot deleted" event.
+func (ctrl *csiSnapshotCommonController) deleteClusterGroupSnapshot(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) {
+	_ = ctrl.clusterGroupSnapshotStore.Delete(groupSnapshot)
+	klog.V(4).Infof("cluster group snapshot %q deleted", groupSnapshot.Name)
+
+	if groupSnapshot.Status == nil || groupSnapshot.Status.BoundVolumeGroupSnapshotContentName == nil {
+		klog.V(5).Infof("deleteClusterGroupSnapshot[%q]: group snapshot content not bound", groupSnapshot.Name)
+		return
+	}
+
+	// sync the group snapshot content when its cluster group snapshot is deleted,
+	// so that it does not wait until the next sync period for its release.
+	groupSnapshotContentName := *groupSnapshot.Status.BoundVolumeGroupSnapshotContentName
+	klog.V(5).Infof("deleteClusterGroupSnapshot[%q]: scheduling sync of group snapshot content %s", groupSnapshot.Name, groupSnapshotContentName)
+	ctrl.groupSnapshotContentQueue.Add(groupSnapshotContentName)
+}
+
+// syncClusterGroupSnapshot is the main controller method to decide what to do
+// with a cluster group snapshot. It is split into syncUnreadyClusterGroupSnapshot
+// and syncReadyClusterGroupSnapshot like syncGroupSnapshot.
+func (ctrl *csiSnapshotCommonController) syncClusterGroupSnapshot(ctx context.Context, groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) error {
+	klog.V(5).Infof("synchronizing ClusterVolumeGroupSnapshot[%s]", groupSnapshot.Name)
+
+	if groupSnapshot.ObjectMeta.DeletionTimestamp != nil {
+		return ctrl.processClusterGroupSnapshotWithDeletionTimestamp(ctx, groupSnapshot)
+	}
+
+	groupSnapshotContent, err := ctrl.getClusterGroupSnapshotContentFromStore(groupSnapshot)
+	if err != nil {
+		return err
+	}
+
+	// A bound finalizer is needed once the cluster group snapshot content
+	// exists, as the member snapshots are deleted with the cluster group snapshot.
+	if groupSnapshotContent != nil && !slices.Contains(groupSnapshot.ObjectMeta.Finalizers, utils.VolumeGroupSnapshotBoundFinalizer) {
+		klog.V(5).Infof("syncClusterGroupSnapshot: Add Finalizer for ClusterVolumeGroupSnapshot[%s]", groupSnapshot.Name)
+		if err := ctrl.addClusterGroupSnapshotFinalizer(groupSnapshot); err != nil {
+			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "GroupSnapshotFinalizerError", fmt.Sprintf("Failed to check and update group snapshot: %s", err.Error()))
+			return err
+		}
+		return nil
+	}
+
+	if !isGroupSnapshotStatusReady(groupSnapshot.Status) || groupSnapshot.Status.BoundVolumeGroupSnapshotContentName == nil {
+		return ctrl.syncUnreadyClusterGroupSnapshot(ctx, groupSnapshot, groupSnapshotContent)
+	}
+	return ctrl.syncReadyClusterGroupSnapshot(groupSnapshot, groupSnapshotContent)
+}
+
+// syncReadyClusterGroupSnapshot checks that the group snapshot content of a
+// ready cluster group snapshot still exists.
+func (ctrl *csiSnapshotCommonController) syncReadyClusterGroupSnapshot(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) error {
+	if groupSnapshotContent == nil {
+		return ctrl.updateClusterGroupSnapshotErrorStatusWithEvent(groupSnapshot, true, v1.EventTypeWarning, "GroupSnapshotContentMissing", "VolumeGroupSnapshotContent is missing")
+	}
+	klog.V(5).Infof("syncReadyClusterGroupSnapshot[%s]: VolumeGroupSnapshotContent %q found", groupSnapshot.Name, groupSnapshotContent.Name)
+	return nil
+}
+
+// syncUnreadyClusterGroupSnapshot creates the group snapshot content of a
+// cluster group snapshot, and its member snapshots once the group snapshot
+// has been cut.
+func (ctrl *csiSnapshotCommonController) syncUnreadyClusterGroupSnapshot(ctx context.Context, groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) error {
+	klog.V(5).Infof("syncUnreadyClusterGroupSnapshot %s", groupSnapshot.Name)
+
+	if groupSnapshotContent != nil {
+		err := ctrl.createSnapshotsForClusterGroupSnapshotContent(ctx, groupSnapshotContent, groupSnapshot)
+		var memberErr groupSnapshotMemberFailedError
+		if errors.As(err, &memberErr) {
+			ctrl.updateClusterGroupSnapshotErrorStatusWithEvent(groupSnapshot, true, v1.EventTypeWarning, "GroupSnapshotMemberFailed", memberErr.Error())
+			return nil
+		}
+		if err != nil {
+			klog.V(4).Infof("createSnapshotsForClusterGroupSnapshotContent[%s]: failed to create snapshots and snapshotcontents for cluster group snapshot %v: %v",
+				groupSnapshotContent.Name, groupSnapshot.Name, err.Error())
+			return err
+		}
+		if _, err := ctrl.updateClusterGroupSnapshotStatus(groupSnapshot, groupSnapshotContent); err != nil {
+			ctrl.updateClusterGroupSnapshotErrorStatusWithEvent(groupSnapshot, false, v1.EventTypeWarning, "GroupSnapshotStatusUpdateFailed", fmt.Sprintf("GroupSnapshot status update failed, %v", err))
+			return err
+		}
+		return nil
+	}
+
+	groupSnapshotContent, err := ctrl.createClusterGroupSnapshotContent(groupSnapshot)
+	if err != nil {
+		ctrl.updateClusterGroupSnapshotErrorStatusWithEvent(groupSnapshot, true, v1.EventTypeWarning, "GroupSnapshotContentCreationFailed", fmt.Sprintf("failed to create group snapshot content with error %v", err))
+		return err
+	}
+
+	// Update cluster group snapshot status with BoundVolumeGroupSnapshotContentName
+	if _, err = ctrl.updateClusterGroupSnapshotStatus(groupSnapshot, groupSnapshotContent); err != nil {
+		ctrl.updateClusterGroupSnapshotErrorStatusWithEvent(groupSnapshot, false, v1.EventTypeWarning, "GroupSnapshotStatusUpdateFailed", fmt.Sprintf("GroupSnapshot status update failed, %v", err))
+		return err
+	}
+	return nil
+}
+
+// getClusterGroupSnapshotContentFromStore returns the dynamically provisioned
+// group snapshot content of a cluster group snapshot, or nil if it does not
+// exist yet. An error is returned if the content is bound to another object.
+func (ctrl *csiSnapshotCommonController) getClusterGroupSnapshotContentFromStore(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
+	contentName := utils.GetDynamicSnapshotContentNameForClusterGroupSnapshot(groupSnapshot)
+	groupSnapshotContent, err := ctrl.getGroupSnapshotContentFromStore(contentName)
+	if err != nil || groupSnapshotContent == nil {
+		return nil, err
+	}
+	ref := groupSnapshotContent.Spec.VolumeGroupSnapshotRef
+	if !utils.IsClusterVolumeGroupSnapshotRef(&ref) || ref.Name != groupSnapshot.Name || ref.UID != groupSnapshot.UID {
+		msg := fmt.Sprintf("VolumeGroupSnapshotContent [%s] is bound to a different group snapshot", contentName)
+		ctrl.updateClusterGroupSnapshotErrorStatusWithEvent(groupSnapshot, true, v1.EventTypeWarning, "GroupSnapshotContentMisbound", msg)
+		return nil, errors.New(msg)
+	}
+	return groupSnapshotContent, nil
+}
+
+// getClaimsFromClusterGroupSnapshot returns the PVCs selected by a cluster
+// group snapshot in all the selected namespaces, sorted by namespace and name.
+func (ctrl *csiSnapshotCommonController) getClaimsFromClusterGroupSnapshot(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) ([]*v1.PersistentVolumeClaim, error) {
+	source := groupSnapshot.Spec.Source
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(&source.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector %s for cluster group snapshot: %v", metav1.FormatLabelSelector(&source.NamespaceSelector), err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&source.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %s for cluster group snapshot: %v", metav1.FormatLabelSelector(&source.Selector), err)
+	}
+
+	namespaces, err := ctrl.namespaceLister.List(namespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces with label selector %s: %q", metav1.FormatLabelSelector(&source.NamespaceSelector), err)
+	}
+	var pvcs []*v1.PersistentVolumeClaim
+	for _, namespace := range namespaces {
+		namespacePVCs, err := ctrl.pvcLister.PersistentVolumeClaims(namespace.Name).List(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PVCs with label selector %s in namespace %s: %q", metav1.FormatLabelSelector(&source.Selector), namespace.Name, err)
+		}
+		pvcs = append(pvcs, namespacePVCs...)
+	}
+	if len(pvcs) == 0 {
+		return nil, fmt.Errorf("label selector %s for cluster group snapshot not applied to any PVC in namespaces selected by %s",
+			metav1.FormatLabelSelector(&source.Selector), metav1.FormatLabelSelector(&source.NamespaceSelector))
+	}
+	sort.Slice(pvcs, func(i, j int) bool {
+		if pvcs[i].Namespace != pvcs[j].Namespace {
+			return pvcs[i].Namespace < pvcs[j].Namespace
+		}
+		return pvcs[i].Name < pvcs[j].Name
+	})
+	return pvcs, nil
+}
+
+// getVolumesFromClusterGroupSnapshot returns the PersistentVolumes of a
+// cluster group snapshot, from its recorded members if any, otherwise from
+// the PVCs it selects.
+func (ctrl *csiSnapshotCommonController) getVolumesFromClusterGroupSnapshot(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot, driverName string) ([]*v1.PersistentVolume, error) {
+	memberErr := newClusterGroupSnapshotMemberError(groupSnapshot)
+	var pvs []*v1.PersistentVolume
+	if groupSnapshot.Status != nil && len(groupSnapshot.Status.Members) > 0 {
+		pvs = ctrl.getVolumesFromMembers(groupSnapshot.Status.Members, "", driverName, memberErr)
+	} else {
+		pvcs, err := ctrl.getClaimsFromClusterGroupSnapshot(groupSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		pvs = ctrl.getVolumesFromClaims(pvcs, driverName, memberErr)
+	}
+	if memberErr.hasReasons() {
+		return nil, memberErr
+	}
+	return pvs, nil
+}
+
+// recordClusterGroupSnapshotMembers saves the PVCs and volumes of a cluster
+// group snapshot in its status, unless they are already recorded.
+func (ctrl *csiSnapshotCommonController) recordClusterGroupSnapshotMembers(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot, volumes []*v1.PersistentVolume) (*groupsnapshotv1.ClusterVolumeGroupSnapshot, error) {
+	if groupSnapshot.Status != nil && len(groupSnapshot.Status.Members) > 0 {
+		return groupSnapshot, nil
+	}
+
+	members, claimNames := groupSnapshotMembersFromVolumes(volumes, true)
+	patches := groupSnapshotMembersPatches(groupSnapshot.Status, members)
+	newGroupSnapshot, err := utils.PatchClusterVolumeGroupSnapshot(groupSnapshot, patches, ctrl.clientset, "status")
+	if err != nil {
+		return nil, newControllerUpdateError(groupSnapshot.Name, err.Error())
+	}
+	if _, err := ctrl.storeClusterGroupSnapshotUpdate(newGroupSnapshot); err != nil {
+		klog.V(4).Infof("recordClusterGroupSnapshotMembers [%s]: cannot update internal cache %v", groupSnapshot.Name, err)
+	}
+
+	msg := fmt.Sprintf("Recorded %d members of the group snapshot: %s", len(members), strings.Join(claimNames, ", "))
+	ctrl.eventRecorder.Event(newGroupSnapshot, v1.EventTypeNormal, "GroupSnapshotMembersRecorded", msg)
+	return newGroupSnapshot, nil
+}
+
+// createClusterGroupSnapshotContent records the members of a cluster group
+// snapshot and creates its VolumeGroupSnapshotContent.
+func (ctrl *csiSnapshotCommonController) createClusterGroupSnapshotContent(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
+	klog.Infof("createClusterGroupSnapshotContent: Creating group snapshot content for cluster group snapshot %s through the plugin ...", groupSnapshot.Name)
+
+	groupSnapshotClass, err := ctrl.getGroupSnapshotClass(groupSnapshot.Spec.VolumeGroupSnapshotClassName)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := ctrl.getVolumesFromClusterGroupSnapshot(groupSnapshot, groupSnapshotClass.Driver)
+	if err != nil {
+		return nil, err
+	}
+	contentName := utils.GetDynamicSnapshotContentNameForClusterGroupSnapshot(groupSnapshot)
+	// The cluster group snapshot has no namespace, only the group snapshot
+	// content name can be used in the secret templates.
+	snapshotterSecretRef, err := utils.GetGroupSnapshotSecretReference(utils.GroupSnapshotterSecretParams, groupSnapshotClass.Parameters, contentName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the members before the content is created, so that retries and
+	// label changes cannot change the volumes of the group snapshot.
+	if _, err := ctrl.recordClusterGroupSnapshotMembers(groupSnapshot, volumes); err != nil {
+		return nil, fmt.Errorf("failed to record members of cluster group snapshot %s: %v", groupSnapshot.Name, err)
+	}
+
+	groupSnapshotRef := v1.ObjectReference{
+		APIVersion:      groupsnapshotv1.SchemeGroupVersion.String(),
+		Kind:            "ClusterVolumeGroupSnapshot",
+		Name:            groupSnapshot.Name,
+		UID:             groupSnapshot.UID,
+		ResourceVersion: groupSnapshot.ResourceVersion,
+	}
+	groupSnapshotContent, err := buildGroupSnapshotContent(contentName, groupSnapshotRef, groupSnapshotClass, volumes, snapshotterSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	return ctrl.saveGroupSnapshotContent(groupSnapshot, groupSnapshot.Name, groupSnapshotContent)
+}
+
+// createSnapshotsForClusterGroupSnapshotContent creates the VolumeSnapshot and
+// VolumeSnapshotContent of every member of a cluster group snapshot. Each
+// VolumeSnapshot is created in the namespace of the PVC it was taken from.
+func (ctrl *csiSnapshotCommonController) createSnapshotsForClusterGroupSnapshotContent(
+	ctx context.Context,
+	groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent,
+	groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot,
+) error {
+	if !ctrl.isGroupSnapshotContentReadyForSnapshotCreation(groupSnapshotContent) {
+		return nil
+	}
+
+	groupSnapshotClass, err := ctrl.getGroupSnapshotClass(groupSnapshot.Spec.VolumeGroupSnapshotClassName)
+	if err != nil {
+		return err
+	}
+	groupSnapshotSecret, err := utils.GetGroupSnapshotSecretReference(utils.GroupSnapshotterSecretParams, groupSnapshotClass.Parameters, groupSnapshotContent.Name, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get secret reference for group snapshot content %s: %v", groupSnapshotContent.Name, err)
+	}
+
+	// The recorded members know the namespace of every volume, even if the
+	// PV cannot be found anymore.
+	memberNamespaces := map[string]string{}
+	if groupSnapshot.Status != nil {
+		for _, member := range groupSnapshot.Status.Members {
+			memberNamespaces[member.VolumeHandle] = member.PersistentVolumeClaimNamespace
+		}
+	}
+
+	_, err = ctrl.bindGroupSnapshotMembers(groupSnapshotContent, func(snapshotInfo groupsnapshotv1.VolumeSnapshotInfo) error {
+		pv, err := ctrl.findPersistentVolumeByCSIDriverHandle(groupSnapshotContent.Spec.Driver, snapshotInfo.VolumeHandle)
+		if err != nil {
+			klog.Errorf("createSnapshotsForClusterGroupSnapshotContent: error while finding PV for volumeHandle:[%s] and CSI driver:[%s]: %s",
+				snapshotInfo.VolumeHandle, groupSnapshotContent.Spec.Driver, err)
+		}
+		namespace := memberNamespaces[snapshotInfo.VolumeHandle]
+		if namespace == "" && pv != nil && pv.Spec.ClaimRef != nil {
+			namespace = pv.Spec.ClaimRef.Namespace
+		}
+		if namespace == "" {
+			return fmt.Errorf("cannot find the namespace of volume %s in cluster group snapshot %s", snapshotInfo.VolumeHandle, groupSnapshot.Name)
+		}
+
+		member := groupSnapshotMemberSpec{
+			groupSnapshotUID: groupSnapshot.UID,
+			namespace:        namespace,
+			ownerReference:   utils.BuildClusterVolumeGroupSnapshotOwnerReference(groupSnapshot),
+		}
+		return ctrl.createAndBindGroupSnapshotMember(ctx, snapshotInfo, groupSnapshotContent, member, pv, groupSnapshotSecret)
+	})
+	return err
+}
+
+// updateClusterGroupSnapshotStatus updates cluster group snapshot status based
+// on group snapshot content status.
+func (ctrl *csiSnapshotCommonController) updateClusterGroupSnapshotStatus(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) (*groupsnapshotv1.ClusterVolumeGroupSnapshot, error) {
+	klog.V(5).Infof("updateClusterGroupSnapshotStatus[%s]", groupSnapshot.Name)
+
+	groupSnapshotObj, err := ctrl.clientset.GroupsnapshotV1().ClusterVolumeGroupSnapshots().Get(context.TODO(), groupSnapshot.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error get cluster group snapshot %s from api server: %v", groupSnapshot.Name, err)
+	}
+
+	newStatus, updated := groupSnapshotStatusFromContent(groupSnapshotObj.Status, groupSnapshotContent)
+	if !updated {
+		return groupSnapshotObj, nil
+	}
+	groupSnapshotClone := groupSnapshotObj.DeepCopy()
+	groupSnapshotClone.Status = newStatus
+	ctrl.emitGroupSnapshotStatusEvents(groupSnapshot, groupSnapshot.Name, groupSnapshotObj.Status, newStatus)
+	newGroupSnapshotObj, err := ctrl.clientset.GroupsnapshotV1().ClusterVolumeGroupSnapshots().UpdateStatus(context.TODO(), groupSnapshotClone, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, newControllerUpdateError(groupSnapshot.Name, err.Error())
+	}
+	if _, err := ctrl.storeClusterGroupSnapshotUpdate(newGroupSnapshotObj); err != nil {
+		klog.Errorf("%v", err)
+	}
+	return newGroupSnapshotObj, nil
+}
+
+// updateClusterGroupSnapshotErrorStatusWithEvent saves the error in the status
+// of a cluster group snapshot and emits the given event, like
+// updateGroupSnapshotErrorStatusWithEvent.
+func (ctrl *csiSnapshotCommonController) updateClusterGroupSnapshotErrorStatusWithEvent(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot, setReadyToFalse bool, eventtype, reason, message string) error {
+	klog.V(5).Infof("updateClusterGroupSnapshotErrorStatusWithEvent[%s]", groupSnapshot.Name)
+
+	newStatus := groupSnapshotErrorStatus(groupSnapshot.Status, setReadyToFalse, message)
+	if newStatus == nil {
+		klog.V(4).Infof("updateClusterGroupSnapshotErrorStatusWithEvent[%s]: the same error %v is already set", groupSnapshot.Name, groupSnapshot.Status.Error)
+		return nil
+	}
+	groupSnapshotClone := groupSnapshot.DeepCopy()
+	groupSnapshotClone.Status = newStatus
+	newGroupSnapshot, err := ctrl.clientset.GroupsnapshotV1().ClusterVolumeGroupSnapshots().UpdateStatus(context.TODO(), groupSnapshotClone, metav1.UpdateOptions{})
+
+	// Emit the event even if the status update fails so that user can see the error
+	ctrl.eventRecorder.Event(groupSnapshotClone, eventtype, reason, message)
+
+	if err != nil {
+		klog.V(4).Infof("updating ClusterVolumeGroupSnapshot[%s] error status failed %v", groupSnapshot.Name, err)
+		return err
+	}
+
+	if _, err = ctrl.storeClusterGroupSnapshotUpdate(newGroupSnapshot); err != nil {
+		klog.V(4).Infof("updating ClusterVolumeGroupSnapshot[%s] error status: cannot update internal cache %v", groupSnapshot.Name, err)
+		return err
+	}
+	return nil
+}
+
+// addClusterGroupSnapshotFinalizer adds the bound finalizer to a ClusterVolumeGroupSnapshot.
+func (ctrl *csiSnapshotCommonController) addClusterGroupSnapshotFinalizer(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) error {
+	patches := addFinalizerPatches(groupSnapshot.Finalizers, utils.VolumeGroupSnapshotBoundFinalizer)
+	newGroupSnapshot, err := utils.PatchClusterVolumeGroupSnapshot(groupSnapshot, patches, ctrl.clientset)
+	if err != nil {
+		return newControllerUpdateError(groupSnapshot.Name, err.Error())
+	}
+
+	if _, err = ctrl.storeClusterGroupSnapshotUpdate(newGroupSnapshot); err != nil {
+		klog.Errorf("failed to update cluster group snapshot store %v", err)
+	}
+
+	klog.V(5).Infof("Added protection finalizer to cluster volume group snapshot %s", newGroupSnapshot.Name)
+	return nil
+}
+
+// processClusterGroupSnapshotWithDeletionTimestamp deletes the member
+// snapshots of a cluster group snapshot in all namespaces, deletes the group
+// snapshot content according to its deletion policy and removes the finalizer.
+func (ctrl *csiSnapshotCommonController) processClusterGroupSnapshotWithDeletionTimestamp(ctx context.Context, groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) error {
+	klog.V(5).Infof("processClusterGroupSnapshotWithDeletionTimestamp ClusterVolumeGroupSnapshot[%s]", groupSnapshot.Name)
+
+	if !slices.Contains(groupSnapshot.ObjectMeta.Finalizers, utils.VolumeGroupSnapshotBoundFinalizer) {
+		return nil
+	}
+
+	groupSnapshotContent, err := ctrl.getGroupSnapshotContentFromStore(utils.GetDynamicSnapshotContentNameForClusterGroupSnapshot(groupSnapshot))
+	if err != nil {
+		return err
+	}
+	if groupSnapshotContent != nil {
+		ref := groupSnapshotContent.Spec.VolumeGroupSnapshotRef
+		if !utils.IsClusterVolumeGroupSnapshotRef(&ref) || ref.Name != groupSnapshot.Name || ref.UID != groupSnapshot.UID {
+			groupSnapshotContent = nil
+		}
+	}
+	deleteGroupSnapshotContent := groupSnapshotContent != nil && groupSnapshotContent.Spec.DeletionPolicy == crdv1.VolumeSnapshotContentDelete
+
+	snapshotMembers, err := ctrl.findGroupSnapshotMembers(types.NamespacedName{Name: groupSnapshot.Name})
+	if err != nil {
+		klog.Errorf("processClusterGroupSnapshotWithDeletionTimestamp[%s]: Failed to look for snapshot members: %v", groupSnapshot.Name, err.Error())
+		return err
+	}
+
+	// Wait until no PVC is being restored from any member snapshot.
+	if err := ctrl.checkGroupSnapshotMembersNotInUse(groupSnapshot, "ClusterVolumeGroupSnapshot", groupSnapshot.Name, snapshotMembers); err != nil {
+		return err
+	}
+
+	if groupSnapshotContent != nil {
+		if groupSnapshotContent, err = ctrl.setAnnVolumeGroupSnapshotBeingDeleted(groupSnapshotContent); err != nil {
+			klog.V(4).Infof("processClusterGroupSnapshotWithDeletionTimestamp[%s]: failed to set VolumeGroupSnapshotBeingDeleted annotation on the group snapshot content: %v", groupSnapshot.Name, err)
+			return err
+		}
+	}
+
+	if deleteGroupSnapshotContent {
+		klog.V(5).Infof("processClusterGroupSnapshotWithDeletionTimestamp[%s]: set DeletionTimeStamp on group snapshot content [%s].", groupSnapshot.Name, groupSnapshotContent.Name)
+		if err := ctrl.deleteGroupSnapshotContentObject(ctx, groupSnapshot, groupSnapshotContent); err != nil {
+			return err
+		}
+	}
+
+	if err := ctrl.deleteGroupSnapshotMembers(ctx, groupSnapshot, groupSnapshot.Name, snapshotMembers); err != nil {
+		return err
+	}
+
+	// Keep the finalizer until the group snapshot content is removed by the
+	// sidecar, like for a VolumeGroupSnapshot.
+	if deleteGroupSnapshotContent {
+		return nil
+	}
+
+	groupSnapshotClone := groupSnapshot.DeepCopy()
+	groupSnapshotClone.ObjectMeta.Finalizers = utils.RemoveString(groupSnapshotClone.ObjectMeta.Finalizers, utils.VolumeGroupSnapshotBoundFinalizer)
+	newGroupSnapshot, err := ctrl.clientset.GroupsnapshotV1().ClusterVolumeGroupSnapshots().Update(ctx, groupSnapshotClone, metav1.UpdateOptions{})
+	if err != nil {
+		return newControllerUpdateError(groupSnapshot.Name, err.Error())
+	}
+	if _, err = ctrl.storeClusterGroupSnapshotUpdate(newGroupSnapshot); err != nil {
+		klog.Errorf("failed to update cluster group snapshot store %v", err)
+	}
+	klog.V(5).Infof("Removed protection finalizer from cluster volume group snapshot %s", groupSnapshot.Name)
+	return nil
+}
+
+// syncClusterGroupSnapshotContent triggers a sync of the cluster group
+// snapshot a group snapshot content is bound to when its status changed.
+func (ctrl *csiSnapshotCommonController) syncClusterGroupSnapshotContent(groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) error {
+	if !ctrl.enableClusterVolumeGroupSnapshots {
+		klog.V(4).Infof("syncClusterGroupSnapshotContent[%s]: cluster group snapshots are disabled", groupSnapshotContent.Name)
+		return nil
+	}
+	groupSnapshotName := groupSnapshotContent.Spec.VolumeGroupSnapshotRef.Name
+	obj, found, err := ctrl.clusterGroupSnapshotStore.GetByKey(groupSnapshotName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		klog.V(4).Infof("syncClusterGroupSnapshotContent[%s]: cluster group snapshot %s not found", groupSnapshotContent.Name, groupSnapshotName)
+		return nil
+	}
+	groupSnapshot, ok := obj.(*groupsnapshotv1.ClusterVolumeGroupSnapshot)
+	if !ok {
+		return fmt.Errorf("cannot convert object from cluster group snapshot cache to cluster group snapshot %q!?: %#v", groupSnapshotName, obj)
+	}
+	if groupSnapshot.UID != groupSnapshotContent.Spec.VolumeGroupSnapshotRef.UID {
+		klog.V(4).Infof("syncClusterGroupSnapshotContent [%s]: cluster group snapshot %s has different UID, the old one must have been deleted", groupSnapshotContent.Name, groupSnapshotName)
+		return nil
+	}
+	if groupSnapshotStatusNeedsUpdate(groupSnapshot.Status, groupSnapshotContent.Status) {
+		klog.V(4).Infof("synchronizing VolumeGroupSnapshotContent for cluster group snapshot [%s]: update group snapshot status if needed.", groupSnapshotName)
+		ctrl.clusterGroupSnapshotQueue.Add(groupSnapshotName)
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	groupstoragelisters "github.com/kubernetes-csi/external-snapshotter/client/v8/listers/volumegroupsnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newClusterGroupSnapshotSetup returns a helper setup whose listers contain
+// the "app=db" PVCs of the labeled namespaces ns-a and ns-b, a PVC in the
+// unlabeled namespace ns-c, and their volumes.
+func newClusterGroupSnapshotSetup(t *testing.T) *helperSetup {
+	h := newHelperSetup(t)
+
+	namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for name, labels := range map[string]map[string]string{
+		"ns-a": {"backup": "nightly"},
+		"ns-b": {"backup": "nightly"},
+		"ns-c": {},
+	} {
+		namespaceIndexer.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}})
+	}
+	h.ctrl.namespaceLister = corelisters.NewNamespaceLister(namespaceIndexer)
+
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, member := range []struct{ namespace, name string }{
+		{"ns-b", "db"},
+		{"ns-a", "web"},
+		{"ns-a", "db"},
+		{"ns-c", "db"},
+	} {
+		pvName := "pv-" + member.namespace + "-" + member.name
+		claim := newClaim(member.name, member.namespace+"-"+member.name+"-uid", "1Gi", pvName, v1.ClaimBound, &classGold, false)
+		claim.Namespace = member.namespace
+		claim.Labels = map[string]string{"app": "db"}
+		pvcIndexer.Add(claim)
+
+		pv := makeCSIPersistentVolume(pvName, mockDriverName, pvName+"-handle", member.name, member.namespace)
+		pv.Spec.ClaimRef.UID = claim.UID
+		pvIndexer.Add(pv)
+		h.ctrl.pvIndexer.Add(pv)
+	}
+	h.ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	h.ctrl.pvLister = corelisters.NewPersistentVolumeLister(pvIndexer)
+
+	classIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	classIndexer.Add(&groupsnapshotv1.VolumeGroupSnapshotClass{
+		ObjectMeta:     metav1.ObjectMeta{Name: "gold"},
+		Driver:         mockDriverName,
+		DeletionPolicy: crdv1.VolumeSnapshotContentDelete,
+	})
+	h.ctrl.groupSnapshotClassLister = groupstoragelisters.NewVolumeGroupSnapshotClassLister(classIndexer)
+	return h
+}
+
+func newTestClusterGroupSnapshot() *groupsnapshotv1.ClusterVolumeGroupSnapshot {
+	return &groupsnapshotv1.ClusterVolumeGroupSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", UID: "cvgs-uid", ResourceVersion: "1"},
+		Spec: groupsnapshotv1.ClusterVolumeGroupSnapshotSpec{
+			Source: groupsnapshotv1.ClusterVolumeGroupSnapshotSource{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"backup": "nightly"}},
+				Selector:          metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+			VolumeGroupSnapshotClassName: "gold",
+		},
+	}
+}
+
+// addClusterGroupSnapshotReactor serves get, patch and update calls of the
+// given cluster group snapshot, which is updated in place.
+func addClusterGroupSnapshotReactor(h *helperSetup, groupSnapshot **groupsnapshotv1.ClusterVolumeGroupSnapshot) {
+	h.client.PrependReactor("*", "clustervolumegroupsnapshots", func(action core.Action) (bool, runtime.Object, error) {
+		switch action := action.(type) {
+		case core.PatchAction:
+			data, err := json.Marshal(*groupSnapshot)
+			if err != nil {
+				return true, nil, err
+			}
+			patch, err := jsonpatch.DecodePatch(action.GetPatch())
+			if err != nil {
+				return true, nil, err
+			}
+			modified, err := patch.Apply(data)
+			if err != nil {
+				return true, nil, err
+			}
+			patched := &groupsnapshotv1.ClusterVolumeGroupSnapshot{}
+			if err := json.Unmarshal(modified, patched); err != nil {
+				return true, nil, err
+			}
+			*groupSnapshot = patched
+		case core.UpdateAction:
+			*groupSnapshot = action.GetObject().(*groupsnapshotv1.ClusterVolumeGroupSnapshot).DeepCopy()
+		}
+		return true, (*groupSnapshot).DeepCopy(), nil
+	})
+}
+
+func TestGetVolumesFromClusterGroupSnapshot(t *testing.T) {
+	h := newClusterGroupSnapshotSetup(t)
+
+	pvs, err := h.ctrl.getVolumesFromClusterGroupSnapshot(newTestClusterGroupSnapshot(), mockDriverName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, pv := range pvs {
+		names = append(names, pv.Name)
+	}
+	expected := []string{"pv-ns-a-db", "pv-ns-a-web", "pv-ns-b-db"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected volumes %v, got %v", expected, names)
+	}
+
+	_, err = h.ctrl.getVolumesFromClusterGroupSnapshot(newTestClusterGroupSnapshot(), "other.csi.driver")
+	expectedErr := "invalid members in group snapshot nightly: " +
+		"PVC ns-a/db: PersistentVolume pv-ns-a-db belongs to CSI driver csi-mock-plugin, not other.csi.driver; " +
+		"PVC ns-a/web: PersistentVolume pv-ns-a-web belongs to CSI driver csi-mock-plugin, not other.csi.driver; " +
+		"PVC ns-b/db: PersistentVolume pv-ns-b-db belongs to CSI driver csi-mock-plugin, not other.csi.driver"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %v", expectedErr, err)
+	}
+}
+
+func TestCreateClusterGroupSnapshotContent(t *testing.T) {
+	h := newClusterGroupSnapshotSetup(t)
+	groupSnapshot := newTestClusterGroupSnapshot()
+	addClusterGroupSnapshotReactor(h, &groupSnapshot)
+
+	if err := h.ctrl.syncClusterGroupSnapshot(context.Background(), groupSnapshot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if groupSnapshot.Status == nil || len(groupSnapshot.Status.Members) != 3 {
+		t.Fatalf("expected 3 recorded members, got status %+v", groupSnapshot.Status)
+	}
+	member := groupSnapshot.Status.Members[2]
+	if member.PersistentVolumeClaimNamespace != "ns-b" || member.PersistentVolumeClaimName != "db" {
+		t.Errorf("expected the last member to be ns-b/db, got %+v", member)
+	}
+	contentName := "groupsnapcontent-cvgs-uid"
+	if groupSnapshot.Status.BoundVolumeGroupSnapshotContentName == nil || *groupSnapshot.Status.BoundVolumeGroupSnapshotContentName != contentName {
+		t.Errorf("expected bound content %s, got %v", contentName, groupSnapshot.Status.BoundVolumeGroupSnapshotContentName)
+	}
+
+	content, found := h.reactor.groupContents[contentName]
+	if !found {
+		t.Fatalf("group snapshot content %s was not created", contentName)
+	}
+	ref := content.Spec.VolumeGroupSnapshotRef
+	if !utils.IsClusterVolumeGroupSnapshotRef(&ref) || ref.Name != "nightly" || ref.Namespace != "" || ref.UID != "cvgs-uid" {
+		t.Errorf("unexpected group snapshot reference %+v", ref)
+	}
+	expectedHandles := []string{"pv-ns-a-db-handle", "pv-ns-a-web-handle", "pv-ns-b-db-handle"}
+	if !reflect.DeepEqual(content.Spec.Source.VolumeHandles, expectedHandles) {
+		t.Errorf("expected volume handles %v, got %v", expectedHandles, content.Spec.Source.VolumeHandles)
+	}
+}
+
+func TestCreateSnapshotsForClusterGroupSnapshotContent(t *testing.T) {
+	h := newClusterGroupSnapshotSetup(t)
+	groupSnapshot := newTestClusterGroupSnapshot()
+
+	groupHandle := "group-handle"
+	ready := true
+	content := makeTestGroupSnapshotContent("groupsnapcontent-cvgs-uid", mockDriverName, "", groupHandle, crdv1.VolumeSnapshotContentDelete)
+	for _, handle := range []string{"pv-ns-a-db-handle", "pv-ns-b-db-handle"} {
+		content.Status.VolumeSnapshotInfoList = append(content.Status.VolumeSnapshotInfoList, groupsnapshotv1.VolumeSnapshotInfo{
+			VolumeHandle:   handle,
+			SnapshotHandle: handle + "-snapshot",
+			ReadyToUse:     &ready,
+		})
+	}
+
+	// Only the PV in ns-a has a node affinity.
+	obj, found, err := h.ctrl.pvIndexer.GetByKey("pv-ns-a-db")
+	if err != nil || !found {
+		t.Fatalf("failed to get PV pv-ns-a-db: %v", err)
+	}
+	pv := obj.(*v1.PersistentVolume).DeepCopy()
+	pv.Spec.NodeAffinity = &v1.VolumeNodeAffinity{
+		Required: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{
+					MatchExpressions: []v1.NodeSelectorRequirement{
+						{
+							Key:      "topology.kubernetes.io/zone",
+							Operator: v1.NodeSelectorOpIn,
+							Values:   []string{"zone-a"},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := h.ctrl.pvIndexer.Update(pv); err != nil {
+		t.Fatalf("failed to update PV: %v", err)
+	}
+
+	if err := h.ctrl.createSnapshotsForClusterGroupSnapshotContent(context.Background(), content, groupSnapshot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, member := range []struct{ handle, namespace, claim, topology string }{
+		{"pv-ns-a-db-handle", "ns-a", "db", `[{"matchLabelExpressions":[{"key":"topology.kubernetes.io/zone","values":["zone-a"]}]}]`},
+		{"pv-ns-b-db-handle", "ns-b", "db", ""},
+	} {
+		name := getSnapshotNameForVolumeGroupSnapshotContent("cvgs-uid", member.handle)
+		snapshot, found := h.reactor.snapshots[name]
+		if !found {
+			t.Fatalf("snapshot %s for volume %s was not created", name, member.handle)
+		}
+		if snapshot.Namespace != member.namespace {
+			t.Errorf("snapshot %s created in namespace %q, want %q", name, snapshot.Namespace, member.namespace)
+		}
+		if snapshot.Spec.Source.PersistentVolumeClaimName == nil || *snapshot.Spec.Source.PersistentVolumeClaimName != member.claim {
+			t.Errorf("snapshot %s has PVC %v, want %s", name, snapshot.Spec.Source.PersistentVolumeClaimName, member.claim)
+		}
+		if !utils.IsClusterVolumeGroupSnapshotMember(snapshot) {
+			t.Errorf("snapshot %s is not owned by the cluster group snapshot: %+v", name, snapshot.OwnerReferences)
+		}
+		if key := utils.VolumeSnapshotParentGroupKeyFunc(snapshot); key != "^nightly" {
+			t.Errorf("snapshot %s has parent group key %q, want %q", name, key, "^nightly")
+		}
+
+		snapshotContent, found := h.reactor.contents[getSnapshotContentNameForVolumeGroupSnapshotContent("cvgs-uid", member.handle)]
+		if !found {
+			t.Fatalf("snapshot content for volume %s was not created", member.handle)
+		}
+		if snapshotContent.Spec.VolumeSnapshotRef.Namespace != member.namespace || snapshotContent.Spec.VolumeSnapshotRef.UID != types.UID(name+"-uid") {
+			t.Errorf("snapshot content for volume %s is bound to %+v", member.handle, snapshotContent.Spec.VolumeSnapshotRef)
+		}
+		if topology := snapshotContent.Annotations[utils.AnnAccessibleTopology]; topology != member.topology {
+			t.Errorf("snapshot content for volume %s has accessible topology %q, want %q", member.handle, topology, member.topology)
+		}
+	}
+}
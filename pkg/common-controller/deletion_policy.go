@@ -0,0 +1,240 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// recordDeletionPolicyChange records a change of the DeletionPolicy of content
+// that was made directly on the content, with an event and in the
+// AnnDeletionPolicyHistory annotation. The previous policy is the last one of
+// the history, or else the one of previous, the last version of content seen
+// by the controller. A change of a content under legal hold is reverted
+// instead. Returns the updated content.
+func (ctrl *csiSnapshotCommonController) recordDeletionPolicyChange(content, previous *crdv1.VolumeSnapshotContent) (*crdv1.VolumeSnapshotContent, error) {
+	history, err := utils.GetDeletionPolicyHistory(content)
+	if err != nil {
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "DeletionPolicyHistoryError", err.Error())
+		return content, err
+	}
+	var from crdv1.DeletionPolicy
+	if len(history) > 0 {
+		from = history[len(history)-1].To
+	} else if previous != nil {
+		from = previous.Spec.DeletionPolicy
+	}
+	if from == "" || from == content.Spec.DeletionPolicy {
+		return content, nil
+	}
+	if utils.IsUnderLegalHold(content.ObjectMeta) {
+		return ctrl.revertDeletionPolicy(content, from)
+	}
+	return ctrl.updateDeletionPolicy(content, history, utils.DeletionPolicyChange{
+		Time: metav1.Now(),
+		From: from,
+		To:   content.Spec.DeletionPolicy,
+	})
+}
+
+// syncRequestedDeletionPolicy changes the DeletionPolicy of the content bound
+// to snapshot to the one requested by the AnnDeletionPolicy annotation of
+// snapshot, if it is a new request. The handled request is recorded in the
+// AnnDeletionPolicyRequestHandled annotation of snapshot, so that a request
+// is handled once and a later change made directly on the content is kept. A
+// change from Retain to Delete is only made if deletionPolicyRequestAllowed.
+// Returns the updated snapshot.
+func (ctrl *csiSnapshotCommonController) syncRequestedDeletionPolicy(snapshot *crdv1.VolumeSnapshot, content *crdv1.VolumeSnapshotContent) (*crdv1.VolumeSnapshot, error) {
+	value, requested := snapshot.Annotations[utils.AnnDeletionPolicy]
+	handled, isHandled := snapshot.Annotations[utils.AnnDeletionPolicyRequestHandled]
+	switch {
+	case !requested && !isHandled:
+		return snapshot, nil
+	case !requested:
+		// The request was withdrawn, the same policy can be requested
+		// again.
+		return ctrl.setDeletionPolicyRequestHandled(snapshot, nil)
+	case isHandled && handled == value:
+		return snapshot, nil
+	}
+
+	policy := crdv1.DeletionPolicy(value)
+	switch {
+	case policy != crdv1.VolumeSnapshotContentDelete && policy != crdv1.VolumeSnapshotContentRetain:
+		ctrl.eventRecorder.Event(snapshot, v1.EventTypeWarning, "InvalidDeletionPolicy", fmt.Sprintf("Invalid annotation %s: the deletion policy must be %s or %s", utils.AnnDeletionPolicy, crdv1.VolumeSnapshotContentDelete, crdv1.VolumeSnapshotContentRetain))
+	case policy == content.Spec.DeletionPolicy:
+		klog.V(4).Infof("syncRequestedDeletionPolicy[%s]: content %s already has the deletion policy %s", utils.SnapshotKey(snapshot), content.Name, policy)
+	case utils.IsUnderLegalHold(content.ObjectMeta):
+		ctrl.eventRecorder.Event(snapshot, v1.EventTypeWarning, "DeletionPolicyChangeRefused", fmt.Sprintf("The deletion policy of content %s is not changed while it is under legal hold %q", content.Name, content.Annotations[utils.AnnLegalHold]))
+	case policy == crdv1.VolumeSnapshotContentDelete && !ctrl.deletionPolicyRequestAllowed(snapshot, content):
+		ctrl.eventRecorder.Event(snapshot, v1.EventTypeWarning, "DeletionPolicyChangeRefused", fmt.Sprintf("The deletion policy of content %s is not changed to %s: the content was not created for the snapshot, and neither the content nor its class has the annotation %s", content.Name, policy, utils.AnnAllowDeletionPolicyRequest))
+	default:
+		history, err := utils.GetDeletionPolicyHistory(content)
+		if err != nil {
+			// Retry when the history is fixed.
+			ctrl.reportedEvents.Warn(ctrl.eventRecorder, snapshot, utils.SnapshotKey(snapshot), "DeletionPolicyHistoryError", err.Error())
+			return snapshot, nil
+		}
+		klog.V(4).Infof("syncRequestedDeletionPolicy[%s]: changing the deletion policy of content %s to %s", utils.SnapshotKey(snapshot), content.Name, policy)
+		_, err = ctrl.updateDeletionPolicy(content, history, utils.DeletionPolicyChange{
+			Time:        metav1.Now(),
+			From:        content.Spec.DeletionPolicy,
+			To:          policy,
+			RequestedBy: utils.SnapshotKey(snapshot),
+		})
+		if err != nil {
+			return snapshot, err
+		}
+		ctrl.eventRecorder.Event(snapshot, v1.EventTypeNormal, "DeletionPolicyChanged", fmt.Sprintf("Changed the deletion policy of content %s from %s to %s", content.Name, content.Spec.DeletionPolicy, policy))
+	}
+	return ctrl.setDeletionPolicyRequestHandled(snapshot, &value)
+}
+
+// setDeletionPolicyRequestHandled sets the AnnDeletionPolicyRequestHandled
+// annotation of snapshot to value, or removes it if value is nil. Returns the
+// updated snapshot.
+func (ctrl *csiSnapshotCommonController) setDeletionPolicyRequestHandled(snapshot *crdv1.VolumeSnapshot, value *string) (*crdv1.VolumeSnapshot, error) {
+	path := "/metadata/annotations/" + strings.ReplaceAll(utils.AnnDeletionPolicyRequestHandled, "/", "~1")
+	var patches []utils.PatchOp
+	if value == nil {
+		patches = []utils.PatchOp{
+			{
+				Op:   "remove",
+				Path: path,
+			},
+		}
+	} else {
+		// The snapshot has the AnnDeletionPolicy annotation, so the
+		// annotations exist.
+		patches = []utils.PatchOp{
+			{
+				Op:    "add",
+				Path:  path,
+				Value: *value,
+			},
+		}
+	}
+	newSnapshot, err := utils.PatchVolumeSnapshot(snapshot, patches, ctrl.clientset)
+	if err != nil {
+		return snapshot, newControllerUpdateError(utils.SnapshotKey(snapshot), err.Error())
+	}
+	if _, err := ctrl.storeSnapshotUpdate(newSnapshot); err != nil {
+		klog.V(4).Infof("setDeletionPolicyRequestHandled[%s]: cannot update internal cache %v", utils.SnapshotKey(snapshot), err)
+	}
+	return newSnapshot, nil
+}
+
+// deletionPolicyRequestAllowed returns true if the AnnDeletionPolicy annotation
+// of snapshot may change the DeletionPolicy of content to Delete. This is
+// allowed for contents created dynamically for snapshot, or if the content or
+// its class has the AnnAllowDeletionPolicyRequest annotation. Otherwise the
+// snapshot users could delete pre-provisioned snapshots they were only given
+// access to, or that an administrator chose to retain.
+func (ctrl *csiSnapshotCommonController) deletionPolicyRequestAllowed(snapshot *crdv1.VolumeSnapshot, content *crdv1.VolumeSnapshotContent) bool {
+	if content.Annotations[utils.AnnAllowDeletionPolicyRequest] == "true" {
+		return true
+	}
+	if content.Spec.VolumeSnapshotClassName != nil {
+		class, err := ctrl.getSnapshotClass(*content.Spec.VolumeSnapshotClassName)
+		if err == nil && class.Annotations[utils.AnnAllowDeletionPolicyRequest] == "true" {
+			return true
+		}
+	}
+	return content.Spec.Source.VolumeHandle != nil && content.Name == utils.GetDynamicSnapshotContentNameForSnapshot(snapshot)
+}
+
+// revertDeletionPolicy sets the DeletionPolicy of a content under legal hold
+// back to policy, the one it had before it was changed directly on the
+// content.
+func (ctrl *csiSnapshotCommonController) revertDeletionPolicy(content *crdv1.VolumeSnapshotContent, policy crdv1.DeletionPolicy) (*crdv1.VolumeSnapshotContent, error) {
+	klog.V(4).Infof("revertDeletionPolicy[%s]: reverting the deletion policy of content under legal hold from %s to %s", content.Name, content.Spec.DeletionPolicy, policy)
+	patches := []utils.PatchOp{
+		// Fail if the policy was changed in the meantime.
+		{
+			Op:    "test",
+			Path:  "/spec/deletionPolicy",
+			Value: content.Spec.DeletionPolicy,
+		},
+		{
+			Op:    "replace",
+			Path:  "/spec/deletionPolicy",
+			Value: policy,
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset)
+	if err != nil {
+		return content, newControllerUpdateError(content.Name, err.Error())
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("revertDeletionPolicy[%s]: cannot update internal cache %v", content.Name, err)
+	}
+	ctrl.eventRecorder.Event(newContent, v1.EventTypeWarning, "DeletionPolicyChangeReverted", fmt.Sprintf("Deletion policy change from %s to %s is reverted while the content is under legal hold %q", policy, content.Spec.DeletionPolicy, content.Annotations[utils.AnnLegalHold]))
+	return newContent, nil
+}
+
+// updateDeletionPolicy sets the DeletionPolicy of content to change.To and
+// appends change to its history.
+func (ctrl *csiSnapshotCommonController) updateDeletionPolicy(content *crdv1.VolumeSnapshotContent, history []utils.DeletionPolicyChange, change utils.DeletionPolicyChange) (*crdv1.VolumeSnapshotContent, error) {
+	value, err := utils.AppendDeletionPolicyHistory(history, change)
+	if err != nil {
+		return content, err
+	}
+	annotations := map[string]string{}
+	for key, value := range content.Annotations {
+		annotations[key] = value
+	}
+	annotations[utils.AnnDeletionPolicyHistory] = value
+	patches := []utils.PatchOp{
+		// Fail if the policy was changed in the meantime.
+		{
+			Op:    "test",
+			Path:  "/spec/deletionPolicy",
+			Value: content.Spec.DeletionPolicy,
+		},
+		{
+			Op:    "add",
+			Path:  "/metadata/annotations",
+			Value: annotations,
+		},
+		{
+			Op:    "replace",
+			Path:  "/spec/deletionPolicy",
+			Value: change.To,
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset)
+	if err != nil {
+		return content, newControllerUpdateError(content.Name, err.Error())
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("updateDeletionPolicy[%s]: cannot update internal cache %v", content.Name, err)
+	}
+	message := fmt.Sprintf("Deletion policy changed from %s to %s", change.From, change.To)
+	if change.RequestedBy != "" {
+		message += fmt.Sprintf(" as requested by VolumeSnapshot %s", change.RequestedBy)
+	}
+	ctrl.eventRecorder.Event(content, v1.EventTypeNormal, "DeletionPolicyChanged", message)
+	return newContent, nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	storagelisters "github.com/kubernetes-csi/external-snapshotter/client/v8/listers/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDeletionPolicyChanges(t *testing.T) {
+	content := newContent("snapcontent-snapuid10-1", "snapuid10-1", "snap10-1", "sid10-1", classGold, "", "volume10-1", deletePolicy, nil, nil, true, true)
+	snapshot := withSnapshotAnnotations(newSnapshotArray("snap10-1", "snapuid10-1", "claim10-1", "", classGold, "snapcontent-snapuid10-1", &True, nil, nil, nil, false, true, nil),
+		map[string]string{utils.AnnDeletionPolicy: string(crdv1.VolumeSnapshotContentRetain)})[0]
+
+	client := fake.NewSimpleClientset(content, snapshot)
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, nil, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	recorder := ctrl.eventRecorder.(*record.FakeRecorder)
+	getContent := func() *crdv1.VolumeSnapshotContent {
+		content, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), "snapcontent-snapuid10-1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get content: %v", err)
+		}
+		return content
+	}
+	getHistory := func(content *crdv1.VolumeSnapshotContent) []utils.DeletionPolicyChange {
+		history, err := utils.GetDeletionPolicyHistory(content)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return history
+	}
+	sync := func(snapshot *crdv1.VolumeSnapshot, content *crdv1.VolumeSnapshotContent) *crdv1.VolumeSnapshot {
+		snapshot, err := ctrl.syncRequestedDeletionPolicy(snapshot, content)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return snapshot
+	}
+
+	// The snapshot requests Retain.
+	snapshot = sync(snapshot, content)
+	if handled := snapshot.Annotations[utils.AnnDeletionPolicyRequestHandled]; handled != string(crdv1.VolumeSnapshotContentRetain) {
+		t.Errorf("expected the request to be handled, got %q", handled)
+	}
+	requested := getContent()
+	if requested.Spec.DeletionPolicy != crdv1.VolumeSnapshotContentRetain {
+		t.Errorf("expected deletion policy %s, got %s", crdv1.VolumeSnapshotContentRetain, requested.Spec.DeletionPolicy)
+	}
+	history := getHistory(requested)
+	if len(history) != 1 || history[0].From != deletePolicy || history[0].To != retainPolicy || history[0].RequestedBy != utils.SnapshotKey(snapshot) {
+		t.Errorf("expected the requested change in the history, got %+v", history)
+	}
+
+	// The policy is changed back directly on the content.
+	changed := requested.DeepCopy()
+	changed.Spec.DeletionPolicy = deletePolicy
+	changed, err = client.SnapshotV1().VolumeSnapshotContents().Update(context.TODO(), changed, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("failed to update content: %v", err)
+	}
+	if _, err := ctrl.recordDeletionPolicyChange(changed, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	history = getHistory(getContent())
+	if len(history) != 2 || history[1].From != retainPolicy || history[1].To != deletePolicy || history[1].RequestedBy != "" {
+		t.Errorf("expected the direct change in the history, got %+v", history)
+	}
+
+	// The handled request does not override the direct change.
+	snapshot = sync(snapshot, getContent())
+	if policy := getContent().Spec.DeletionPolicy; policy != deletePolicy {
+		t.Errorf("expected the direct change to be kept, got %s", policy)
+	}
+
+	// The request is withdrawn.
+	delete(snapshot.Annotations, utils.AnnDeletionPolicy)
+	snapshot, err = client.SnapshotV1().VolumeSnapshots(snapshot.Namespace).Update(context.TODO(), snapshot, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("failed to update snapshot: %v", err)
+	}
+	snapshot = sync(snapshot, getContent())
+	if _, ok := snapshot.Annotations[utils.AnnDeletionPolicyRequestHandled]; ok {
+		t.Errorf("expected the handled request to be removed with the request")
+	}
+
+	// A new request for a content under legal hold is refused once.
+	metav1.SetMetaDataAnnotation(&snapshot.ObjectMeta, utils.AnnDeletionPolicy, string(crdv1.VolumeSnapshotContentRetain))
+	snapshot, err = client.SnapshotV1().VolumeSnapshots(snapshot.Namespace).Update(context.TODO(), snapshot, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("failed to update snapshot: %v", err)
+	}
+	held := getContent()
+	metav1.SetMetaDataAnnotation(&held.ObjectMeta, utils.AnnLegalHold, "case-42")
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	snapshot = sync(snapshot, held)
+	snapshot = sync(snapshot, held)
+	if policy := getContent().Spec.DeletionPolicy; policy != deletePolicy {
+		t.Errorf("expected the deletion policy of a held content not to change, got %s", policy)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected 1 event for the refused request, got %d", len(recorder.Events))
+	}
+
+	// A direct change of a content under legal hold is reverted.
+	held, err = client.SnapshotV1().VolumeSnapshotContents().Update(context.TODO(), held, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("failed to update content: %v", err)
+	}
+	changed = held.DeepCopy()
+	changed.Spec.DeletionPolicy = retainPolicy
+	changed, err = client.SnapshotV1().VolumeSnapshotContents().Update(context.TODO(), changed, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("failed to update content: %v", err)
+	}
+	if _, err := ctrl.recordDeletionPolicyChange(changed, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reverted := getContent()
+	if reverted.Spec.DeletionPolicy != deletePolicy {
+		t.Errorf("expected the deletion policy of a held content to be reverted to %s, got %s", deletePolicy, reverted.Spec.DeletionPolicy)
+	}
+	if history := getHistory(reverted); len(history) != 2 {
+		t.Errorf("expected the reverted change not to be in the history, got %+v", history)
+	}
+}
+
+func TestRequestedDeletionPolicyDelete(t *testing.T) {
+	optIn := map[string]string{utils.AnnAllowDeletionPolicyRequest: "true"}
+	tests := []struct {
+		name     string
+		content  *crdv1.VolumeSnapshotContent
+		class    *crdv1.VolumeSnapshotClass
+		expected crdv1.DeletionPolicy
+	}{
+		{
+			name:     "dynamically created content is changed",
+			content:  newContent("snapcontent-snapuid10-2", "snapuid10-2", "snap10-2", "sid10-2", classGold, "", "volume10-2", retainPolicy, nil, nil, true, true),
+			expected: deletePolicy,
+		},
+		{
+			name:     "pre-provisioned content is not changed",
+			content:  newContent("content10-2", "snapuid10-2", "snap10-2", "sid10-2", classGold, "sid10-2", "", retainPolicy, nil, nil, true, true),
+			expected: retainPolicy,
+		},
+		{
+			name:     "content of another snapshot is not changed",
+			content:  newContent("snapcontent-snapuid10-3", "snapuid10-2", "snap10-2", "sid10-2", classGold, "", "volume10-2", retainPolicy, nil, nil, true, true),
+			expected: retainPolicy,
+		},
+		{
+			name:     "pre-provisioned content with the annotation is changed",
+			content:  withContentAnnotations([]*crdv1.VolumeSnapshotContent{newContent("content10-2", "snapuid10-2", "snap10-2", "sid10-2", classGold, "sid10-2", "", retainPolicy, nil, nil, true, true)}, optIn)[0],
+			expected: deletePolicy,
+		},
+		{
+			name:     "pre-provisioned content of a class with the annotation is changed",
+			content:  newContent("content10-2", "snapuid10-2", "snap10-2", "sid10-2", classGold, "sid10-2", "", retainPolicy, nil, nil, true, true),
+			class:    &crdv1.VolumeSnapshotClass{ObjectMeta: metav1.ObjectMeta{Name: classGold, Annotations: optIn}, Driver: mockDriverName},
+			expected: deletePolicy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snapshot := withSnapshotAnnotations(newSnapshotArray("snap10-2", "snapuid10-2", "claim10-2", "", classGold, test.content.Name, &True, nil, nil, nil, false, true, nil),
+				map[string]string{utils.AnnDeletionPolicy: string(crdv1.VolumeSnapshotContentDelete)})[0]
+
+			client := fake.NewSimpleClientset(test.content, snapshot)
+			ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, nil, t, controllerTest{})
+			if err != nil {
+				t.Fatalf("failed to create test controller: %v", err)
+			}
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if test.class != nil {
+				indexer.Add(test.class)
+			}
+			ctrl.classLister = storagelisters.NewVolumeSnapshotClassLister(indexer)
+
+			if _, err := ctrl.syncRequestedDeletionPolicy(snapshot, test.content); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			content, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), test.content.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get content: %v", err)
+			}
+			if content.Spec.DeletionPolicy != test.expected {
+				t.Errorf("expected deletion policy %s, got %s", test.expected, content.Spec.DeletionPolicy)
+			}
+		})
+	}
+}
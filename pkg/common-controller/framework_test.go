@@ -246,6 +246,15 @@ func withSnapshotFinalizers(snapshots []*crdv1.VolumeSnapshot, finalizers ...str
 	return snapshots
 }
 
+func withSnapshotAnnotations(snapshots []*crdv1.VolumeSnapshot, annotations map[string]string) []*crdv1.VolumeSnapshot {
+	for i := range snapshots {
+		for key, value := range annotations {
+			metav1.SetMetaDataAnnotation(&snapshots[i].ObjectMeta, key, value)
+		}
+	}
+	return snapshots
+}
+
 func withGroupSnapshotFinalizers(groupSnapshots []*groupsnapshotv1.VolumeGroupSnapshot, finalizers ...string) []*groupsnapshotv1.VolumeGroupSnapshot {
 	for i := range groupSnapshots {
 		for _, f := range finalizers {
@@ -255,6 +264,16 @@ func withGroupSnapshotFinalizers(groupSnapshots []*groupsnapshotv1.VolumeGroupSn
 	return groupSnapshots
 }
 
+func withGroupSnapshotMembers(groupSnapshots []*groupsnapshotv1.VolumeGroupSnapshot, members ...groupsnapshotv1.VolumeGroupSnapshotMember) []*groupsnapshotv1.VolumeGroupSnapshot {
+	for i := range groupSnapshots {
+		if groupSnapshots[i].Status == nil {
+			groupSnapshots[i].Status = &groupsnapshotv1.VolumeGroupSnapshotStatus{}
+		}
+		groupSnapshots[i].Status.Members = append(groupSnapshots[i].Status.Members, members...)
+	}
+	return groupSnapshots
+}
+
 func withGroupSnapshotContentFinalizers(groupSnapshotContents []*groupsnapshotv1.VolumeGroupSnapshotContent, finalizers ...string) []*groupsnapshotv1.VolumeGroupSnapshotContent {
 	for i := range groupSnapshotContents {
 		for _, f := range finalizers {
@@ -593,6 +612,8 @@ func (r *snapshotReactor) React(action core.Action) (handled bool, ret runtime.O
 				return true, nil, err
 			}
 
+			// Don't modify the existing object
+			storedGroupSnapshot = &groupsnapshotv1.VolumeGroupSnapshot{}
 			err = json.Unmarshal(modified, storedGroupSnapshot)
 			if err != nil {
 				return true, nil, err
@@ -1258,18 +1279,28 @@ func newTestController(kubeClient kubernetes.Interface, clientset clientset.Inte
 		informerFactory.Groupsnapshot().V1().VolumeGroupSnapshots(),
 		informerFactory.Groupsnapshot().V1().VolumeGroupSnapshotContents(),
 		informerFactory.Groupsnapshot().V1().VolumeGroupSnapshotClasses(),
+		informerFactory.Groupsnapshot().V1().ClusterVolumeGroupSnapshots(),
+		informerFactory.Snapshot().V1().VolumeSnapshotCopies(),
 		coreFactory.Core().V1().PersistentVolumeClaims(),
 		coreFactory.Core().V1().PersistentVolumes(),
 		nil,
+		nil,
+		coreFactory.Core().V1().Namespaces(),
 		metricsManager,
 		60*time.Second,
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](1*time.Millisecond, 1*time.Minute),
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](1*time.Millisecond, 1*time.Minute),
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](1*time.Millisecond, 1*time.Minute),
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](1*time.Millisecond, 1*time.Minute),
+		workqueue.NewTypedItemExponentialFailureRateLimiter[string](1*time.Millisecond, 1*time.Minute),
+		workqueue.NewTypedItemExponentialFailureRateLimiter[string](1*time.Millisecond, 1*time.Minute),
 		false,
 		false,
 		true,
+		true,
+		true,
+		false,
+		GroupSnapshotMemberFailureRetry,
 	)
 
 	ctrl.eventRecorder = record.NewFakeRecorder(1000)
@@ -2005,9 +2036,12 @@ func runSyncTests(t *testing.T, tests []controllerTest, snapshotClasses []*crdv1
 		}
 		ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
 
+		pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
 		for _, volume := range test.initialVolumes {
 			reactor.volumes[volume.Name] = volume
+			pvIndexer.Add(volume)
 		}
+		ctrl.pvLister = corelisters.NewPersistentVolumeLister(pvIndexer)
 		for _, secret := range test.initialSecrets {
 			reactor.secrets[secret.Name] = secret
 		}
@@ -2074,9 +2108,12 @@ func runFinalizerTests(t *testing.T, tests []controllerTest, snapshotClasses []*
 		}
 		ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
 
+		pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
 		for _, volume := range test.initialVolumes {
 			reactor.volumes[volume.Name] = volume
+			pvIndexer.Add(volume)
 		}
+		ctrl.pvLister = corelisters.NewPersistentVolumeLister(pvIndexer)
 		for _, secret := range test.initialSecrets {
 			reactor.secrets[secret.Name] = secret
 		}
@@ -2216,9 +2253,12 @@ func runUpdateSnapshotClassTests(t *testing.T, tests []controllerTest, snapshotC
 		}
 		ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
 
+		pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
 		for _, volume := range test.initialVolumes {
 			reactor.volumes[volume.Name] = volume
+			pvIndexer.Add(volume)
 		}
+		ctrl.pvLister = corelisters.NewPersistentVolumeLister(pvIndexer)
 		for _, secret := range test.initialSecrets {
 			reactor.secrets[secret.Name] = secret
 		}
@@ -19,15 +19,20 @@ package common_controller
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/kubernetes/scheme"
 	ref "k8s.io/client-go/tools/reference"
 	klog "k8s.io/klog/v2"
@@ -75,26 +80,13 @@ func (ctrl *csiSnapshotCommonController) getGroupSnapshotClass(className string)
 func (ctrl *csiSnapshotCommonController) updateGroupSnapshotErrorStatusWithEvent(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot, setReadyToFalse bool, eventtype, reason, message string) error {
 	klog.V(5).Infof("updateGroupSnapshotErrorStatusWithEvent[%s]", utils.GroupSnapshotKey(groupSnapshot))
 
-	if groupSnapshot.Status != nil && groupSnapshot.Status.Error != nil && *groupSnapshot.Status.Error.Message == message {
+	newStatus := groupSnapshotErrorStatus(groupSnapshot.Status, setReadyToFalse, message)
+	if newStatus == nil {
 		klog.V(4).Infof("updateGroupSnapshotErrorStatusWithEvent[%s]: the same error %v is already set", groupSnapshot.Name, groupSnapshot.Status.Error)
 		return nil
 	}
 	groupSnapshotClone := groupSnapshot.DeepCopy()
-	if groupSnapshotClone.Status == nil {
-		groupSnapshotClone.Status = &groupsnapshotv1.VolumeGroupSnapshotStatus{}
-	}
-	statusError := &crdv1.VolumeSnapshotError{
-		Time: &metav1.Time{
-			Time: time.Now(),
-		},
-		Message: &message,
-	}
-	groupSnapshotClone.Status.Error = statusError
-	// Only update ReadyToUse in VolumeGroupSnapshot's Status to false if setReadyToFalse is true.
-	if setReadyToFalse {
-		ready := false
-		groupSnapshotClone.Status.ReadyToUse = &ready
-	}
+	groupSnapshotClone.Status = newStatus
 	newSnapshot, err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshots(groupSnapshotClone.Namespace).UpdateStatus(context.TODO(), groupSnapshotClone, metav1.UpdateOptions{})
 
 	// Emit the event even if the status update fails so that user can see the error
@@ -114,6 +106,30 @@ func (ctrl *csiSnapshotCommonController) updateGroupSnapshotErrorStatusWithEvent
 	return nil
 }
 
+// groupSnapshotErrorStatus returns a copy of the group snapshot status with
+// the error message. ReadyToUse is set to false only if setReadyToFalse is
+// true. It returns nil if status already has the error.
+func groupSnapshotErrorStatus(status *groupsnapshotv1.VolumeGroupSnapshotStatus, setReadyToFalse bool, message string) *groupsnapshotv1.VolumeGroupSnapshotStatus {
+	if status != nil && status.Error != nil && *status.Error.Message == message {
+		return nil
+	}
+	newStatus := &groupsnapshotv1.VolumeGroupSnapshotStatus{}
+	if status != nil {
+		newStatus = status.DeepCopy()
+	}
+	newStatus.Error = &crdv1.VolumeSnapshotError{
+		Time: &metav1.Time{
+			Time: time.Now(),
+		},
+		Message: &message,
+	}
+	if setReadyToFalse {
+		ready := false
+		newStatus.ReadyToUse = &ready
+	}
+	return newStatus
+}
+
 // SetDefaultGroupSnapshotClass is a helper function to figure out the default
 // group snapshot class.
 // For pre-provisioned case, it's an no-op.
@@ -171,66 +187,305 @@ func (ctrl *csiSnapshotCommonController) SetDefaultGroupSnapshotClass(groupSnaps
 	return defaultClasses[0], newGroupSnapshot, nil
 }
 
-// pvDriverFromGroupSnapshot is a helper function to get the CSI driver name from the targeted persistent volume.
-// It looks up every PVC from which the group snapshot is specified to be created from, and looks for the PVC's
-// corresponding PV. Bi-directional binding will be verified between PVC and PV before the PV's CSI driver is returned.
-// For an non-CSI volume, it returns an error immediately as it's not supported.
+// pvDriverFromGroupSnapshot is a helper function to get the CSI driver name from the targeted persistent volumes.
+// It looks up every PVC selected by the group snapshot, and looks for the PVC's corresponding PV. Bi-directional
+// binding will be verified between PVC and PV, and all PVs must belong to the same CSI driver.
+// For an non-CSI volume, it returns an error as it's not supported.
 func (ctrl *csiSnapshotCommonController) pvDriverFromGroupSnapshot(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) (string, error) {
-	pvs, err := ctrl.getVolumesFromVolumeGroupSnapshot(groupSnapshot)
+	pvs, err := ctrl.getVolumesFromVolumeGroupSnapshot(groupSnapshot, "")
 	if err != nil {
 		return "", err
 	}
-	// Take any volume to get the driver
-	if pvs[0].Spec.PersistentVolumeSource.CSI == nil {
-		return "", fmt.Errorf("snapshotting non-CSI volumes is not supported, group snapshot:%s/%s", groupSnapshot.Namespace, groupSnapshot.Name)
+	// Take any volume to get the driver, the others must match it
+	driverName := pvs[0].Spec.PersistentVolumeSource.CSI.Driver
+	memberErr := newGroupSnapshotMemberError(groupSnapshot)
+	for _, pv := range pvs[1:] {
+		if pv.Spec.CSI.Driver != driverName {
+			memberErr.add(pv.Spec.ClaimRef.Name, "PersistentVolume %s belongs to CSI driver %s, not %s like PersistentVolume %s", pv.Name, pv.Spec.CSI.Driver, driverName, pvs[0].Name)
+		}
+	}
+	if memberErr.hasReasons() {
+		return "", memberErr
 	}
-	return pvs[0].Spec.PersistentVolumeSource.CSI.Driver, nil
+	return driverName, nil
 }
 
 // getVolumesFromVolumeGroupSnapshot returns the list of PersistentVolume from a VolumeGroupSnapshot.
-func (ctrl *csiSnapshotCommonController) getVolumesFromVolumeGroupSnapshot(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) ([]*v1.PersistentVolume, error) {
-	var pvReturnList []*v1.PersistentVolume
+// Every selected PVC must be bound to a CSI volume. If driverName is not empty, the volumes must
+// belong to that driver. All invalid PVCs are reported at once in a *groupSnapshotMemberError.
+func (ctrl *csiSnapshotCommonController) getVolumesFromVolumeGroupSnapshot(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot, driverName string) ([]*v1.PersistentVolume, error) {
+	if groupSnapshot.Status != nil && len(groupSnapshot.Status.Members) > 0 {
+		return ctrl.getVolumesFromGroupSnapshotMembers(groupSnapshot, driverName)
+	}
+
 	pvcs, err := ctrl.getClaimsFromVolumeGroupSnapshot(groupSnapshot)
 	if err != nil {
 		return nil, err
 	}
 
+	memberErr := newGroupSnapshotMemberError(groupSnapshot)
+	pvs := ctrl.getVolumesFromClaims(pvcs, driverName, memberErr)
+	if memberErr.hasReasons() {
+		return nil, memberErr
+	}
+
+	return pvs, nil
+}
+
+// getVolumesFromClaims returns the PersistentVolumes bound to the given PVCs.
+// Every PVC must be bound to a CSI volume. If driverName is not empty, the
+// volumes must belong to that driver. Invalid PVCs are added to memberErr.
+func (ctrl *csiSnapshotCommonController) getVolumesFromClaims(pvcs []*v1.PersistentVolumeClaim, driverName string, memberErr *groupSnapshotMemberError) []*v1.PersistentVolume {
+	var pvReturnList []*v1.PersistentVolume
 	for _, pvc := range pvcs {
 		if pvc.Status.Phase != v1.ClaimBound {
-			return nil, fmt.Errorf("the PVC %s is not yet bound to a PV, will not attempt to take a group snapshot", pvc.Name)
+			memberErr.addClaim(pvc.Namespace, pvc.Name, "not yet bound to a PersistentVolume")
+			continue
 		}
 		pvName := pvc.Spec.VolumeName
-		pv, err := ctrl.client.CoreV1().PersistentVolumes().Get(context.TODO(), pvName, metav1.GetOptions{})
+		pv, err := ctrl.pvLister.Get(pvName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to retrieve PV %s from the API server: %q", pvName, err)
+			memberErr.addClaim(pvc.Namespace, pvc.Name, "failed to retrieve PersistentVolume %s from the lister: %v", pvName, err)
+			continue
 		}
 
 		// Verify binding between PV/PVC is still valid
-		bound := ctrl.isVolumeBoundToClaim(pv, &pvc)
-		if bound == false {
+		if !ctrl.isVolumeBoundToClaim(pv, pvc) {
 			klog.Warningf("binding between PV %s and PVC %s is broken", pvName, pvc.Name)
-			return nil, fmt.Errorf("claim in dataSource not bound or invalid")
+			memberErr.addClaim(pvc.Namespace, pvc.Name, "binding to PersistentVolume %s is broken", pvName)
+			continue
+		}
+		if pv.Spec.CSI == nil {
+			memberErr.addClaim(pvc.Namespace, pvc.Name, "PersistentVolume %s is not a CSI volume", pvName)
+			continue
+		}
+		if driverName != "" && pv.Spec.CSI.Driver != driverName {
+			memberErr.addClaim(pvc.Namespace, pvc.Name, "PersistentVolume %s belongs to CSI driver %s, not %s", pvName, pv.Spec.CSI.Driver, driverName)
+			continue
 		}
 		pvReturnList = append(pvReturnList, pv)
-		klog.V(5).Infof("getVolumeFromVolumeGroupSnapshot: group snapshot [%s] PV name [%s]", groupSnapshot.Name, pvName)
+		klog.V(5).Infof("getVolumesFromClaims: PVC [%s/%s] PV name [%s]", pvc.Namespace, pvc.Name, pvName)
 	}
 
-	return pvReturnList, nil
+	return pvReturnList
+}
+
+// getVolumesFromGroupSnapshotMembers returns the PersistentVolumes recorded in
+// the status of a VolumeGroupSnapshot, ignoring its selector. Every volume must
+// still be bound to the recorded PVC and have the recorded volume handle.
+func (ctrl *csiSnapshotCommonController) getVolumesFromGroupSnapshotMembers(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot, driverName string) ([]*v1.PersistentVolume, error) {
+	memberErr := newGroupSnapshotMemberError(groupSnapshot)
+	pvs := ctrl.getVolumesFromMembers(groupSnapshot.Status.Members, groupSnapshot.Namespace, driverName, memberErr)
+	if memberErr.hasReasons() {
+		return nil, memberErr
+	}
+
+	return pvs, nil
+}
+
+// getVolumesFromMembers returns the PersistentVolumes of the given recorded
+// members. Members without a PVC namespace belong to the given namespace.
+// Invalid members are added to memberErr.
+func (ctrl *csiSnapshotCommonController) getVolumesFromMembers(members []groupsnapshotv1.VolumeGroupSnapshotMember, namespace, driverName string, memberErr *groupSnapshotMemberError) []*v1.PersistentVolume {
+	var pvReturnList []*v1.PersistentVolume
+	for _, member := range members {
+		claimNamespace := member.PersistentVolumeClaimNamespace
+		if claimNamespace == "" {
+			claimNamespace = namespace
+		}
+		pvName := member.PersistentVolumeName
+		pv, err := ctrl.pvLister.Get(pvName)
+		if err != nil {
+			memberErr.addClaim(claimNamespace, member.PersistentVolumeClaimName, "failed to retrieve PersistentVolume %s from the lister: %v", pvName, err)
+			continue
+		}
+		claimRef := pv.Spec.ClaimRef
+		if claimRef == nil || claimRef.Namespace != claimNamespace || claimRef.Name != member.PersistentVolumeClaimName || claimRef.UID != member.PersistentVolumeClaimUID {
+			memberErr.addClaim(claimNamespace, member.PersistentVolumeClaimName, "binding to PersistentVolume %s is broken", pvName)
+			continue
+		}
+		if pv.Spec.CSI == nil || pv.Spec.CSI.VolumeHandle != member.VolumeHandle {
+			memberErr.addClaim(claimNamespace, member.PersistentVolumeClaimName, "PersistentVolume %s does not have volume handle %s anymore", pvName, member.VolumeHandle)
+			continue
+		}
+		if driverName != "" && pv.Spec.CSI.Driver != driverName {
+			memberErr.addClaim(claimNamespace, member.PersistentVolumeClaimName, "PersistentVolume %s belongs to CSI driver %s, not %s", pvName, pv.Spec.CSI.Driver, driverName)
+			continue
+		}
+		pvReturnList = append(pvReturnList, pv)
+	}
+
+	return pvReturnList
+}
+
+// recordGroupSnapshotMembers saves the PVCs and volumes of a dynamically
+// provisioned group snapshot in its status, unless they are already recorded.
+// From then on the group snapshot is reconciled against the recorded members.
+func (ctrl *csiSnapshotCommonController) recordGroupSnapshotMembers(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot, volumes []*v1.PersistentVolume) (*groupsnapshotv1.VolumeGroupSnapshot, error) {
+	if groupSnapshot.Status != nil && len(groupSnapshot.Status.Members) > 0 {
+		return groupSnapshot, nil
+	}
+
+	members, claimNames := groupSnapshotMembersFromVolumes(volumes, false)
+	patches := groupSnapshotMembersPatches(groupSnapshot.Status, members)
+	newGroupSnapshot, err := utils.PatchVolumeGroupSnapshot(groupSnapshot, patches, ctrl.clientset, "status")
+	if err != nil {
+		return nil, newControllerUpdateError(utils.GroupSnapshotKey(groupSnapshot), err.Error())
+	}
+	if _, err := ctrl.storeGroupSnapshotUpdate(newGroupSnapshot); err != nil {
+		klog.V(4).Infof("recordGroupSnapshotMembers [%s]: cannot update internal cache %v", utils.GroupSnapshotKey(groupSnapshot), err)
+	}
+
+	msg := fmt.Sprintf("Recorded %d members of the group snapshot: %s", len(members), strings.Join(claimNames, ", "))
+	ctrl.eventRecorder.Event(newGroupSnapshot, v1.EventTypeNormal, "GroupSnapshotMembersRecorded", msg)
+	return newGroupSnapshot, nil
+}
+
+// groupSnapshotMembersPatches returns the patches which record members in
+// the group snapshot status.
+func groupSnapshotMembersPatches(status *groupsnapshotv1.VolumeGroupSnapshotStatus, members []groupsnapshotv1.VolumeGroupSnapshotMember) []utils.PatchOp {
+	if status == nil {
+		return []utils.PatchOp{
+			{
+				Op:    "add",
+				Path:  "/status",
+				Value: &groupsnapshotv1.VolumeGroupSnapshotStatus{Members: members},
+			},
+		}
+	}
+	return []utils.PatchOp{
+		{
+			Op:    "add",
+			Path:  "/status/members",
+			Value: members,
+		},
+	}
+}
+
+// groupSnapshotMembersFromVolumes builds the members of a group snapshot from
+// its volumes, along with the names of their PVCs. The PVC namespace is only
+// recorded when withNamespace is true.
+func groupSnapshotMembersFromVolumes(volumes []*v1.PersistentVolume, withNamespace bool) ([]groupsnapshotv1.VolumeGroupSnapshotMember, []string) {
+	members := make([]groupsnapshotv1.VolumeGroupSnapshotMember, 0, len(volumes))
+	claimNames := make([]string, 0, len(volumes))
+	for _, pv := range volumes {
+		member := groupsnapshotv1.VolumeGroupSnapshotMember{
+			PersistentVolumeClaimName: pv.Spec.ClaimRef.Name,
+			PersistentVolumeClaimUID:  pv.Spec.ClaimRef.UID,
+			PersistentVolumeName:      pv.Name,
+			VolumeHandle:              pv.Spec.CSI.VolumeHandle,
+		}
+		claimName := pv.Spec.ClaimRef.Name
+		if withNamespace {
+			member.PersistentVolumeClaimNamespace = pv.Spec.ClaimRef.Namespace
+			claimName = pv.Spec.ClaimRef.Namespace + "/" + claimName
+		}
+		members = append(members, member)
+		claimNames = append(claimNames, claimName)
+	}
+	return members, claimNames
+}
+
+// checkGroupSnapshotMembers emits a warning event when the PVCs selected by a
+// group snapshot no longer match its recorded members before the group
+// snapshot is taken. The recorded members are not changed.
+func (ctrl *csiSnapshotCommonController) checkGroupSnapshotMembers(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) {
+	if groupSnapshot.Status == nil || len(groupSnapshot.Status.Members) == 0 || utils.IsGroupSnapshotCreated(groupSnapshot) {
+		return
+	}
+
+	pvcs, err := ctrl.getClaimsFromVolumeGroupSnapshot(groupSnapshot)
+	if err != nil {
+		// The selector does not match any PVC anymore.
+		klog.V(4).Infof("checkGroupSnapshotMembers [%s]: %v", utils.GroupSnapshotKey(groupSnapshot), err)
+		pvcs = nil
+	}
+
+	selected := make(map[types.UID]bool, len(pvcs))
+	var added, removed []string
+	for _, pvc := range pvcs {
+		selected[pvc.UID] = true
+	}
+	recorded := make(map[types.UID]bool, len(groupSnapshot.Status.Members))
+	for _, member := range groupSnapshot.Status.Members {
+		recorded[member.PersistentVolumeClaimUID] = true
+		if !selected[member.PersistentVolumeClaimUID] {
+			removed = append(removed, member.PersistentVolumeClaimName)
+		}
+	}
+	for _, pvc := range pvcs {
+		if !recorded[pvc.UID] {
+			added = append(added, pvc.Name)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("PVCs selected by the group snapshot changed after its members were recorded, only the recorded members are snapshotted: added [%s], removed [%s]", strings.Join(added, ", "), strings.Join(removed, ", "))
+	klog.V(4).Infof("checkGroupSnapshotMembers [%s]: %s", utils.GroupSnapshotKey(groupSnapshot), msg)
+	ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "GroupSnapshotMembersChanged", msg)
 }
 
 // getClaimsFromVolumeGroupSnapshot is a helper function to get a list of PVCs from VolumeGroupSnapshot.
-func (ctrl *csiSnapshotCommonController) getClaimsFromVolumeGroupSnapshot(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) ([]v1.PersistentVolumeClaim, error) {
+// The PVCs are selected from the lister with the complete label selector and sorted by name.
+func (ctrl *csiSnapshotCommonController) getClaimsFromVolumeGroupSnapshot(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) ([]*v1.PersistentVolumeClaim, error) {
 	labelSelector := groupSnapshot.Spec.Source.Selector
+	if labelSelector == nil {
+		return nil, fmt.Errorf("group snapshot %s has no label selector", utils.GroupSnapshotKey(groupSnapshot))
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %s for group snapshot: %v", metav1.FormatLabelSelector(labelSelector), err)
+	}
 
 	// Get PVC that has group snapshot label applied.
-	pvcList, err := ctrl.client.CoreV1().PersistentVolumeClaims(groupSnapshot.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labels.Set(labelSelector.MatchLabels).String()})
+	pvcs, err := ctrl.pvcLister.PersistentVolumeClaims(groupSnapshot.Namespace).List(selector)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list PVCs with label selector %s: %q", metav1.FormatLabelSelector(labelSelector), err)
 	}
-	if len(pvcList.Items) == 0 {
+	if len(pvcs) == 0 {
 		return nil, fmt.Errorf("label selector %s for group snapshot not applied to any PVC", metav1.FormatLabelSelector(labelSelector))
 	}
-	return pvcList.Items, nil
+	sort.Slice(pvcs, func(i, j int) bool { return pvcs[i].Name < pvcs[j].Name })
+	return pvcs, nil
+}
+
+// groupSnapshotMemberError lists the PVCs selected by a group snapshot that
+// cannot be part of the group, each with the reason.
+type groupSnapshotMemberError struct {
+	groupSnapshotKey string
+	// namespace of the group snapshot, PVCs in other namespaces are
+	// reported with their namespace.
+	namespace string
+	reasons   []string
+}
+
+func newGroupSnapshotMemberError(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) *groupSnapshotMemberError {
+	return &groupSnapshotMemberError{groupSnapshotKey: utils.GroupSnapshotKey(groupSnapshot), namespace: groupSnapshot.Namespace}
+}
+
+func newClusterGroupSnapshotMemberError(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) *groupSnapshotMemberError {
+	return &groupSnapshotMemberError{groupSnapshotKey: groupSnapshot.Name}
+}
+
+func (e *groupSnapshotMemberError) add(pvcName, format string, args ...interface{}) {
+	e.reasons = append(e.reasons, fmt.Sprintf("PVC %s: %s", pvcName, fmt.Sprintf(format, args...)))
+}
+
+func (e *groupSnapshotMemberError) addClaim(pvcNamespace, pvcName, format string, args ...interface{}) {
+	if pvcNamespace != e.namespace {
+		pvcName = pvcNamespace + "/" + pvcName
+	}
+	e.add(pvcName, format, args...)
+}
+
+func (e *groupSnapshotMemberError) hasReasons() bool {
+	return len(e.reasons) > 0
+}
+
+func (e *groupSnapshotMemberError) Error() string {
+	return fmt.Sprintf("invalid members in group snapshot %s: %s", e.groupSnapshotKey, strings.Join(e.reasons, "; "))
 }
 
 // updateGroupSnapshot runs in worker thread and handles "groupsnapshot added",
@@ -447,6 +702,8 @@ func (ctrl *csiSnapshotCommonController) syncUnreadyGroupSnapshot(ctx context.Co
 	}
 
 	// groupSnapshot.Spec.Source.VolumeGroupSnapshotContentName == nil - dynamically created group snapshot
+	ctrl.checkGroupSnapshotMembers(groupSnapshot)
+
 	klog.V(5).Infof("getDynamicallyProvisionedGroupContentFromStore for snapshot %s", uniqueGroupSnapshotName)
 	contentObj, err := ctrl.getDynamicallyProvisionedGroupContentFromStore(groupSnapshot)
 	if err != nil {
@@ -462,6 +719,13 @@ func (ctrl *csiSnapshotCommonController) syncUnreadyGroupSnapshot(ctx context.Co
 		}
 
 		newGroupSnapshotContentObj, err := ctrl.createSnapshotsForGroupSnapshotContent(ctx, contentObj, groupSnapshot)
+		var memberErr groupSnapshotMemberFailedError
+		if errors.As(err, &memberErr) {
+			// The failure policy does not allow retrying the member, the
+			// group snapshot will not become ready.
+			ctrl.updateGroupSnapshotErrorStatusWithEvent(groupSnapshot, true, v1.EventTypeWarning, "GroupSnapshotMemberFailed", memberErr.Error())
+			return nil
+		}
 		if err != nil {
 			klog.V(4).Infof("createSnapshotsForGroupSnapshotContent[%s]: failed to create snapshots and snapshotcontents for group snapshot %v: %v",
 				contentObj.Name, groupSnapshot.Name, err.Error())
@@ -517,15 +781,153 @@ func (ctrl *csiSnapshotCommonController) createSnapshotsForGroupSnapshotContent(
 		groupSnapshotContent.Name)
 
 	// Create individual snapshots for each volume in the group
-	for _, snapshotInfo := range groupSnapshotContent.Status.VolumeSnapshotInfoList {
-		if err := ctrl.createIndividualSnapshotForGroupSnapshot(ctx, snapshotInfo, groupSnapshotContent, groupSnapshot, groupSnapshotSecret); err != nil {
+	return ctrl.bindGroupSnapshotMembers(groupSnapshotContent, func(snapshotInfo groupsnapshotv1.VolumeSnapshotInfo) error {
+		return ctrl.createIndividualSnapshotForGroupSnapshot(ctx, snapshotInfo, groupSnapshotContent, groupSnapshot, groupSnapshotSecret)
+	})
+}
+
+// groupSnapshotMemberFailedError is returned when a member of a group snapshot
+// failed to bind and the member failure policy does not allow a retry.
+type groupSnapshotMemberFailedError struct {
+	message string
+}
+
+func (e groupSnapshotMemberFailedError) Error() string {
+	return e.message
+}
+
+// bindGroupSnapshotMembers calls bindMember for each snapshot of a group
+// snapshot content and records the outcome in the VolumeSnapshotInfoList of
+// the group snapshot content status.
+//
+// With the Retry policy all members are tried on every sync and the error of
+// the failed members is returned, so that the group snapshot is requeued.
+// With the FailFast policy binding stops at the first failed member and a
+// groupSnapshotMemberFailedError is returned, also on later syncs.
+func (ctrl *csiSnapshotCommonController) bindGroupSnapshotMembers(
+	groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent,
+	bindMember func(snapshotInfo groupsnapshotv1.VolumeSnapshotInfo) error,
+) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
+	failFast := ctrl.groupSnapshotMemberFailurePolicy == GroupSnapshotMemberFailureFailFast
+	if failFast {
+		for _, snapshotInfo := range groupSnapshotContent.Status.VolumeSnapshotInfoList {
+			if snapshotInfo.Error != nil {
+				return groupSnapshotContent, newGroupSnapshotMemberFailedError(groupSnapshotContent, snapshotInfo)
+			}
+		}
+	}
+
+	infoList := make([]groupsnapshotv1.VolumeSnapshotInfo, len(groupSnapshotContent.Status.VolumeSnapshotInfoList))
+	for i := range groupSnapshotContent.Status.VolumeSnapshotInfoList {
+		groupSnapshotContent.Status.VolumeSnapshotInfoList[i].DeepCopyInto(&infoList[i])
+	}
+
+	var errs []error
+	var failedMember *groupsnapshotv1.VolumeSnapshotInfo
+	updated := false
+	now := metav1.Now()
+	for i := range infoList {
+		err := bindMember(infoList[i])
+		if setGroupSnapshotMemberStatus(&infoList[i], err, now) {
+			updated = true
+		}
+		if err == nil {
+			continue
+		}
+		klog.V(4).Infof("bindGroupSnapshotMembers[%s]: failed to bind snapshot of volume %s: %v", groupSnapshotContent.Name, infoList[i].VolumeHandle, err)
+		errs = append(errs, fmt.Errorf("volume %s: %v", infoList[i].VolumeHandle, err))
+		if failFast {
+			failedMember = &infoList[i]
+			break
+		}
+	}
+
+	if updated {
+		newGroupSnapshotContent, err := ctrl.updateGroupSnapshotContentMemberStatus(groupSnapshotContent, infoList)
+		if err != nil {
+			// Without the recorded member status the failure would be
+			// forgotten, so retry even with the FailFast policy.
 			return groupSnapshotContent, err
 		}
+		groupSnapshotContent = newGroupSnapshotContent
 	}
 
+	if failedMember != nil {
+		return groupSnapshotContent, newGroupSnapshotMemberFailedError(groupSnapshotContent, *failedMember)
+	}
+	if len(errs) > 0 {
+		return groupSnapshotContent, fmt.Errorf("failed to bind %d of %d snapshots of group snapshot content %s: %v",
+			len(errs), len(infoList), groupSnapshotContent.Name, utilerrors.NewAggregate(errs))
+	}
 	return groupSnapshotContent, nil
 }
 
+func newGroupSnapshotMemberFailedError(groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent, snapshotInfo groupsnapshotv1.VolumeSnapshotInfo) error {
+	message := "unknown error"
+	if snapshotInfo.Error != nil && snapshotInfo.Error.Message != nil {
+		message = *snapshotInfo.Error.Message
+	}
+	return groupSnapshotMemberFailedError{
+		message: fmt.Sprintf("snapshot of volume %s in group snapshot content %s failed and is not retried: %s",
+			snapshotInfo.VolumeHandle, groupSnapshotContent.Name, message),
+	}
+}
+
+// setGroupSnapshotMemberStatus records the outcome of an attempt to bind a
+// group snapshot member. It returns true if the member status was changed.
+func setGroupSnapshotMemberStatus(snapshotInfo *groupsnapshotv1.VolumeSnapshotInfo, err error, now metav1.Time) bool {
+	if err == nil {
+		if snapshotInfo.Error == nil {
+			return false
+		}
+		snapshotInfo.Error = nil
+		snapshotInfo.LastTransitionTime = &now
+		return true
+	}
+
+	message := err.Error()
+	if snapshotInfo.Error == nil {
+		snapshotInfo.LastTransitionTime = &now
+	}
+	snapshotInfo.Error = &crdv1.VolumeSnapshotError{
+		Time:    &now,
+		Message: &message,
+	}
+	snapshotInfo.RetryCount++
+	return true
+}
+
+// updateGroupSnapshotContentMemberStatus saves the status of the members of a
+// group snapshot content to the API server. The patch fails if the list was
+// changed in the meantime, e.g. by the sidecar.
+func (ctrl *csiSnapshotCommonController) updateGroupSnapshotContentMemberStatus(
+	groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent,
+	infoList []groupsnapshotv1.VolumeSnapshotInfo,
+) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
+	patches := []utils.PatchOp{
+		{
+			Op:    "test",
+			Path:  "/status/volumeSnapshotInfoList",
+			Value: groupSnapshotContent.Status.VolumeSnapshotInfoList,
+		},
+		{
+			Op:    "replace",
+			Path:  "/status/volumeSnapshotInfoList",
+			Value: infoList,
+		},
+	}
+	newGroupSnapshotContent, err := utils.PatchVolumeGroupSnapshotContent(groupSnapshotContent, patches, ctrl.clientset, "status")
+	if err != nil {
+		return groupSnapshotContent, newControllerUpdateError(groupSnapshotContent.Name, err.Error())
+	}
+
+	_, err = ctrl.storeGroupSnapshotContentUpdate(newGroupSnapshotContent)
+	if err != nil {
+		klog.V(4).Infof("updateGroupSnapshotContentMemberStatus[%s]: cannot update internal cache %v", groupSnapshotContent.Name, err)
+	}
+	return newGroupSnapshotContent, nil
+}
+
 // isGroupSnapshotContentReadyForSnapshotCreation checks if the group snapshot content
 // has all required information to create individual snapshots.
 func (ctrl *csiSnapshotCommonController) isGroupSnapshotContentReadyForSnapshotCreation(
@@ -596,11 +998,39 @@ func (ctrl *csiSnapshotCommonController) createIndividualSnapshotForGroupSnapsho
 			err)
 	}
 
+	member := groupSnapshotMemberSpec{
+		groupSnapshotUID: groupSnapshot.UID,
+		namespace:        groupSnapshotContent.Spec.VolumeGroupSnapshotRef.Namespace,
+		ownerReference:   utils.BuildVolumeGroupSnapshotOwnerReference(groupSnapshot),
+	}
+	return ctrl.createAndBindGroupSnapshotMember(ctx, snapshotInfo, groupSnapshotContent, member, pv, groupSnapshotSecret)
+}
+
+// groupSnapshotMemberSpec describes where the VolumeSnapshot of a group
+// snapshot member is created and which object owns it.
+type groupSnapshotMemberSpec struct {
+	groupSnapshotUID types.UID
+	namespace        string
+	ownerReference   metav1.OwnerReference
+}
+
+// createAndBindGroupSnapshotMember creates the VolumeSnapshot and VolumeSnapshotContent
+// of a group snapshot member and binds them together.
+func (ctrl *csiSnapshotCommonController) createAndBindGroupSnapshotMember(
+	ctx context.Context,
+	snapshotInfo groupsnapshotv1.VolumeSnapshotInfo,
+	groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent,
+	member groupSnapshotMemberSpec,
+	pv *v1.PersistentVolume,
+	groupSnapshotSecret *v1.SecretReference,
+) error {
+	volumeHandle := snapshotInfo.VolumeHandle
+
 	// Build the VolumeSnapshotContent and VolumeSnapshot specs
 	volumeSnapshotContent := ctrl.buildVolumeSnapshotContentSpecForGroupSnapshot(
-		groupSnapshot, groupSnapshotContent, volumeHandle, pv, groupSnapshotSecret)
+		member, groupSnapshotContent, volumeHandle, pv, groupSnapshotSecret)
 	volumeSnapshot := ctrl.buildVolumeSnapshotSpecForGroupSnapshot(
-		groupSnapshot, groupSnapshotContent, volumeHandle, pv)
+		member, volumeHandle, pv)
 
 	// Create the VolumeSnapshotContent and get the created object from the API
 	createdVolumeSnapshotContent, err := ctrl.createOrGetVolumeSnapshotContent(ctx, volumeSnapshotContent)
@@ -638,17 +1068,16 @@ func (ctrl *csiSnapshotCommonController) createIndividualSnapshotForGroupSnapsho
 
 // buildVolumeSnapshotContentSpecForGroupSnapshot constructs a VolumeSnapshotContent spec for an individual snapshot within a group snapshot.
 func (ctrl *csiSnapshotCommonController) buildVolumeSnapshotContentSpecForGroupSnapshot(
-	groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot,
+	member groupSnapshotMemberSpec,
 	groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent,
 	volumeHandle string,
 	pv *v1.PersistentVolume,
 	groupSnapshotSecret *v1.SecretReference,
 ) *crdv1.VolumeSnapshotContent {
 	volumeSnapshotContentName := getSnapshotContentNameForVolumeGroupSnapshotContent(
-		string(groupSnapshot.UID), volumeHandle)
+		string(member.groupSnapshotUID), volumeHandle)
 	volumeSnapshotName := getSnapshotNameForVolumeGroupSnapshotContent(
-		string(groupSnapshot.UID), volumeHandle)
-	volumeSnapshotNamespace := groupSnapshotContent.Spec.VolumeGroupSnapshotRef.Namespace
+		string(member.groupSnapshotUID), volumeHandle)
 
 	volumeSnapshotContent := &crdv1.VolumeSnapshotContent{
 		ObjectMeta: metav1.ObjectMeta{
@@ -661,7 +1090,7 @@ func (ctrl *csiSnapshotCommonController) buildVolumeSnapshotContentSpecForGroupS
 			VolumeSnapshotRef: v1.ObjectReference{
 				Kind:      "VolumeSnapshot",
 				Name:      volumeSnapshotName,
-				Namespace: volumeSnapshotNamespace,
+				Namespace: member.namespace,
 			},
 			DeletionPolicy: groupSnapshotContent.Spec.DeletionPolicy,
 			Driver:         groupSnapshotContent.Spec.Driver,
@@ -673,6 +1102,18 @@ func (ctrl *csiSnapshotCommonController) buildVolumeSnapshotContentSpecForGroupS
 
 	if pv != nil {
 		volumeSnapshotContent.Spec.SourceVolumeMode = pv.Spec.VolumeMode
+
+		// Set AnnAccessibleTopology for the sidecar to fill in the status
+		if topology := utils.NodeAffinityToTopology(pv.Spec.NodeAffinity); topology != nil {
+			value, err := json.Marshal(topology)
+			if err != nil {
+				klog.Errorf("buildVolumeSnapshotContentSpecForGroupSnapshot: failed to encode the accessible topology of volume snapshot content [%s]: %v", volumeSnapshotContent.Name, err)
+			} else {
+				klog.V(5).Infof("buildVolumeSnapshotContentSpecForGroupSnapshot: set annotation [%s] on volume snapshot content [%s].",
+					utils.AnnAccessibleTopology, volumeSnapshotContent.Name)
+				metav1.SetMetaDataAnnotation(&volumeSnapshotContent.ObjectMeta, utils.AnnAccessibleTopology, string(value))
+			}
+		}
 	}
 
 	if groupSnapshotSecret != nil {
@@ -683,6 +1124,11 @@ func (ctrl *csiSnapshotCommonController) buildVolumeSnapshotContentSpecForGroupS
 		klog.V(5).Infof("buildVolumeSnapshotContentSpecForGroupSnapshot: set annotation [%s] on volume snapshot content [%s].",
 			utils.AnnDeletionSecretRefNamespace, volumeSnapshotContent.Name)
 		metav1.SetMetaDataAnnotation(&volumeSnapshotContent.ObjectMeta, utils.AnnDeletionSecretRefNamespace, groupSnapshotSecret.Namespace)
+
+		// Members are deleted with the secret of the group, read from the same provider.
+		if provider, ok := groupSnapshotContent.Annotations[utils.AnnDeletionGroupSecretRefProvider]; ok {
+			metav1.SetMetaDataAnnotation(&volumeSnapshotContent.ObjectMeta, utils.AnnDeletionSecretRefProvider, provider)
+		}
 	}
 
 	return volumeSnapshotContent
@@ -690,21 +1136,19 @@ func (ctrl *csiSnapshotCommonController) buildVolumeSnapshotContentSpecForGroupS
 
 // buildVolumeSnapshotSpecForGroupSnapshot constructs a VolumeSnapshot spec for an individual snapshot within a group snapshot.
 func (ctrl *csiSnapshotCommonController) buildVolumeSnapshotSpecForGroupSnapshot(
-	groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot,
-	groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent,
+	member groupSnapshotMemberSpec,
 	volumeHandle string,
 	pv *v1.PersistentVolume,
 ) *crdv1.VolumeSnapshot {
 	volumeSnapshotName := getSnapshotNameForVolumeGroupSnapshotContent(
-		string(groupSnapshot.UID), volumeHandle)
-	volumeSnapshotNamespace := groupSnapshotContent.Spec.VolumeGroupSnapshotRef.Namespace
+		string(member.groupSnapshotUID), volumeHandle)
 
 	volumeSnapshot := &crdv1.VolumeSnapshot{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      volumeSnapshotName,
-			Namespace: volumeSnapshotNamespace,
+			Namespace: member.namespace,
 			OwnerReferences: []metav1.OwnerReference{
-				utils.BuildVolumeGroupSnapshotOwnerReference(groupSnapshot),
+				member.ownerReference,
 			},
 			Finalizers: []string{utils.VolumeSnapshotInGroupFinalizer},
 		},
@@ -975,6 +1419,48 @@ func (ctrl *csiSnapshotCommonController) checkAndBindGroupSnapshotContent(groupS
 func (ctrl *csiSnapshotCommonController) updateGroupSnapshotStatus(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) (*groupsnapshotv1.VolumeGroupSnapshot, error) {
 	klog.V(5).Infof("updateGroupSnapshotStatus[%s]", utils.GroupSnapshotKey(groupSnapshot))
 
+	klog.V(5).Infof("updateGroupSnapshotStatus: updating VolumeGroupSnapshot [%+v] based on VolumeGroupSnapshotContentStatus [%+v]", groupSnapshot, groupSnapshotContent.Status)
+
+	groupSnapshotObj, err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshots(groupSnapshot.Namespace).Get(context.TODO(), groupSnapshot.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error get group snapshot %s from api server: %v", utils.GroupSnapshotKey(groupSnapshot), err)
+	}
+
+	newStatus, updated := groupSnapshotStatusFromContent(groupSnapshotObj.Status, groupSnapshotContent)
+	if !updated {
+		return groupSnapshotObj, nil
+	}
+	groupSnapshotClone := groupSnapshotObj.DeepCopy()
+	groupSnapshotClone.Status = newStatus
+
+	// We need to record metrics before updating the status due to a bug causing cache entries after a failed UpdateStatus call.
+	ctrl.emitGroupSnapshotStatusEvents(groupSnapshot, utils.GroupSnapshotKey(groupSnapshot), groupSnapshotObj.Status, newStatus)
+	driverName := groupSnapshotContent.Spec.Driver
+	// Must meet the following criteria to emit a successful CreateGroupSnapshot status
+	// 1. Previous status was nil OR Previous status had a nil CreationTime
+	// 2. New status must be non-nil with a non-nil CreationTime
+	if !isGroupSnapshotStatusCreated(groupSnapshotObj.Status) && isGroupSnapshotStatusCreated(newStatus) {
+		createOperationKey := metrics.NewOperationKey(metrics.CreateGroupSnapshotOperationName, groupSnapshot.UID)
+		ctrl.metricsManager.RecordVolumeGroupSnapshotMetrics(createOperationKey, metrics.NewSnapshotOperationStatus(metrics.SnapshotStatusTypeSuccess), driverName)
+	}
+	// Must meet the following criteria to emit a successful CreateGroupSnapshotAndReady status
+	// 1. Previous status was nil OR Previous status had a nil ReadyToUse OR Previous status had a false ReadyToUse
+	// 2. New status must be non-nil with a ReadyToUse as true
+	if !isGroupSnapshotStatusReady(groupSnapshotObj.Status) && isGroupSnapshotStatusReady(newStatus) {
+		createAndReadyOperation := metrics.NewOperationKey(metrics.CreateGroupSnapshotAndReadyOperationName, groupSnapshot.UID)
+		ctrl.metricsManager.RecordMetrics(createAndReadyOperation, metrics.NewSnapshotOperationStatus(metrics.SnapshotStatusTypeSuccess), driverName)
+	}
+
+	newGroupSnapshotObj, err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshots(groupSnapshotClone.Namespace).UpdateStatus(context.TODO(), groupSnapshotClone, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, newControllerUpdateError(utils.GroupSnapshotKey(groupSnapshot), err.Error())
+	}
+	return newGroupSnapshotObj, nil
+}
+
+// groupSnapshotStatusFromContent returns the group snapshot status updated
+// from the status of its group snapshot content, and whether it changed.
+func groupSnapshotStatusFromContent(status *groupsnapshotv1.VolumeGroupSnapshotStatus, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) (*groupsnapshotv1.VolumeGroupSnapshotStatus, bool) {
 	boundContentName := groupSnapshotContent.Name
 	var createdAt *time.Time
 	if groupSnapshotContent.Status != nil && groupSnapshotContent.Status.CreationTime != nil {
@@ -988,18 +1474,14 @@ func (ctrl *csiSnapshotCommonController) updateGroupSnapshotStatus(groupSnapshot
 	if groupSnapshotContent.Status != nil && groupSnapshotContent.Status.Error != nil {
 		volumeSnapshotErr = groupSnapshotContent.Status.Error.DeepCopy()
 	}
-
-	klog.V(5).Infof("updateGroupSnapshotStatus: updating VolumeGroupSnapshot [%+v] based on VolumeGroupSnapshotContentStatus [%+v]", groupSnapshot, groupSnapshotContent.Status)
-
-	groupSnapshotObj, err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshots(groupSnapshot.Namespace).Get(context.TODO(), groupSnapshot.Name, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("error get group snapshot %s from api server: %v", utils.GroupSnapshotKey(groupSnapshot), err)
+	var crashConsistent *bool
+	if groupSnapshotContent.Status != nil && groupSnapshotContent.Status.CrashConsistent != nil {
+		value := *groupSnapshotContent.Status.CrashConsistent
+		crashConsistent = &value
 	}
 
-	var newStatus *groupsnapshotv1.VolumeGroupSnapshotStatus
-	updated := false
-	if groupSnapshotObj.Status == nil {
-		newStatus = &groupsnapshotv1.VolumeGroupSnapshotStatus{
+	if status == nil {
+		newStatus := &groupsnapshotv1.VolumeGroupSnapshotStatus{
 			BoundVolumeGroupSnapshotContentName: &boundContentName,
 			ReadyToUse:                          &readyToUse,
 		}
@@ -1009,70 +1491,54 @@ func (ctrl *csiSnapshotCommonController) updateGroupSnapshotStatus(groupSnapshot
 		if volumeSnapshotErr != nil {
 			newStatus.Error = volumeSnapshotErr
 		}
+		newStatus.CrashConsistent = crashConsistent
+		return newStatus, true
+	}
 
+	newStatus := status.DeepCopy()
+	updated := false
+	if newStatus.BoundVolumeGroupSnapshotContentName == nil {
+		newStatus.BoundVolumeGroupSnapshotContentName = &boundContentName
 		updated = true
-	} else {
-		newStatus = groupSnapshotObj.Status.DeepCopy()
-		if newStatus.BoundVolumeGroupSnapshotContentName == nil {
-			newStatus.BoundVolumeGroupSnapshotContentName = &boundContentName
-			updated = true
-		}
-		if newStatus.CreationTime == nil && createdAt != nil {
-			newStatus.CreationTime = &metav1.Time{Time: *createdAt}
-			updated = true
-		}
-		if newStatus.ReadyToUse == nil || *newStatus.ReadyToUse != readyToUse {
-			newStatus.ReadyToUse = &readyToUse
-			updated = true
-			if readyToUse && newStatus.Error != nil {
-				newStatus.Error = nil
-			}
-		}
-		if (newStatus.Error == nil && volumeSnapshotErr != nil) || (newStatus.Error != nil && volumeSnapshotErr != nil && newStatus.Error.Time != nil && volumeSnapshotErr.Time != nil && &newStatus.Error.Time != &volumeSnapshotErr.Time) || (newStatus.Error != nil && volumeSnapshotErr == nil) {
-			newStatus.Error = volumeSnapshotErr
-			updated = true
-		}
 	}
-
-	if updated {
-		groupSnapshotClone := groupSnapshotObj.DeepCopy()
-		groupSnapshotClone.Status = newStatus
-
-		// We need to record metrics before updating the status due to a bug causing cache entries after a failed UpdateStatus call.
-		// Must meet the following criteria to emit a successful CreateGroupSnapshot status
-		// 1. Previous status was nil OR Previous status had a nil CreationTime
-		// 2. New status must be non-nil with a non-nil CreationTime
-		driverName := groupSnapshotContent.Spec.Driver
-		createOperationKey := metrics.NewOperationKey(metrics.CreateGroupSnapshotOperationName, groupSnapshot.UID)
-
-		// Must meet the following criteria to emit a successful CreateGroupSnapshot status
-		// 1. Previous status was nil OR Previous status had a nil CreationTime
-		// 2. New status must be non-nil with a non-nil CreationTime
-		if !utils.IsGroupSnapshotCreated(groupSnapshotObj) && utils.IsGroupSnapshotCreated(groupSnapshotClone) {
-			msg := fmt.Sprintf("GroupSnapshot %s was successfully created by the CSI driver.", utils.GroupSnapshotKey(groupSnapshot))
-			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeNormal, "GroupSnapshotCreated", msg)
-			ctrl.metricsManager.RecordVolumeGroupSnapshotMetrics(createOperationKey, metrics.NewSnapshotOperationStatus(metrics.SnapshotStatusTypeSuccess), driverName)
-		}
-
-		// Must meet the following criteria to emit a successful CreateGroupSnapshotAndReady status
-		// 1. Previous status was nil OR Previous status had a nil ReadyToUse OR Previous status had a false ReadyToUse
-		// 2. New status must be non-nil with a ReadyToUse as true
-		if !utils.IsGroupSnapshotReady(groupSnapshotObj) && utils.IsGroupSnapshotReady(groupSnapshotClone) {
-			msg := fmt.Sprintf("GroupSnapshot %s is ready to use.", utils.GroupSnapshotKey(groupSnapshot))
-			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeNormal, "GroupSnapshotReady", msg)
-			createAndReadyOperation := metrics.NewOperationKey(metrics.CreateGroupSnapshotAndReadyOperationName, groupSnapshot.UID)
-			ctrl.metricsManager.RecordMetrics(createAndReadyOperation, metrics.NewSnapshotOperationStatus(metrics.SnapshotStatusTypeSuccess), driverName)
-		}
-
-		newGroupSnapshotObj, err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshots(groupSnapshotClone.Namespace).UpdateStatus(context.TODO(), groupSnapshotClone, metav1.UpdateOptions{})
-		if err != nil {
-			return nil, newControllerUpdateError(utils.GroupSnapshotKey(groupSnapshot), err.Error())
+	if newStatus.CreationTime == nil && createdAt != nil {
+		newStatus.CreationTime = &metav1.Time{Time: *createdAt}
+		updated = true
+	}
+	if newStatus.ReadyToUse == nil || *newStatus.ReadyToUse != readyToUse {
+		newStatus.ReadyToUse = &readyToUse
+		updated = true
+		if readyToUse && newStatus.Error != nil {
+			newStatus.Error = nil
 		}
-
-		return newGroupSnapshotObj, nil
 	}
+	if (newStatus.Error == nil && volumeSnapshotErr != nil) || (newStatus.Error != nil && volumeSnapshotErr != nil && newStatus.Error.Time != nil && volumeSnapshotErr.Time != nil && &newStatus.Error.Time != &volumeSnapshotErr.Time) || (newStatus.Error != nil && volumeSnapshotErr == nil) {
+		newStatus.Error = volumeSnapshotErr
+		updated = true
+	}
+	if newStatus.CrashConsistent == nil && crashConsistent != nil {
+		newStatus.CrashConsistent = crashConsistent
+		updated = true
+	}
+	return newStatus, updated
+}
 
-	return groupSnapshotObj, nil
+// emitGroupSnapshotStatusEvents emits the events of the changes from
+// oldStatus to newStatus on the group snapshot groupSnapshot, whose key is
+// key.
+func (ctrl *csiSnapshotCommonController) emitGroupSnapshotStatusEvents(groupSnapshot runtime.Object, key string, oldStatus, newStatus *groupsnapshotv1.VolumeGroupSnapshotStatus) {
+	if !isGroupSnapshotStatusCreated(oldStatus) && isGroupSnapshotStatusCreated(newStatus) {
+		msg := fmt.Sprintf("GroupSnapshot %s was successfully created by the CSI driver.", key)
+		ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeNormal, "GroupSnapshotCreated", msg)
+	}
+	if !isGroupSnapshotStatusReady(oldStatus) && isGroupSnapshotStatusReady(newStatus) {
+		msg := fmt.Sprintf("GroupSnapshot %s is ready to use.", key)
+		ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeNormal, "GroupSnapshotReady", msg)
+	}
+	if isGroupSnapshotStatusNotCrashConsistent(newStatus) && !isGroupSnapshotStatusNotCrashConsistent(oldStatus) {
+		msg := fmt.Sprintf("GroupSnapshot %s was emulated with individual snapshots and is not crash consistent.", key)
+		ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "GroupSnapshotNotCrashConsistent", msg)
+	}
 }
 
 // getDynamicallyProvisionedGroupContentFromStore tries to find a dynamically created
@@ -1165,42 +1631,30 @@ func (ctrl *csiSnapshotCommonController) createGroupSnapshotContent(groupSnapsho
 		return nil, fmt.Errorf("failed to get input parameters to create group snapshot %s: %q", groupSnapshot.Name, err)
 	}
 
+	// Record the members before the content is created, so that retries and
+	// label changes cannot change the volumes of the group snapshot.
+	if _, err := ctrl.recordGroupSnapshotMembers(groupSnapshot, volumes); err != nil {
+		return nil, fmt.Errorf("failed to record members of group snapshot %s: %v", groupSnapshot.Name, err)
+	}
+
 	snapshotRef, err := ref.GetReference(scheme.Scheme, groupSnapshot)
 	if err != nil {
 		return nil, err
 	}
+	// The volumes have been validated against the class driver by getCreateGroupSnapshotInput.
+	groupSnapshotContent, err := buildGroupSnapshotContent(contentName, *snapshotRef, groupSnapshotClass, volumes, snapshotterSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	return ctrl.saveGroupSnapshotContent(groupSnapshot, utils.GroupSnapshotKey(groupSnapshot), groupSnapshotContent)
+}
+
+// buildGroupSnapshotContent returns the VolumeGroupSnapshotContent named
+// contentName which dynamically provisions the group snapshot of volumes
+// referenced by groupSnapshotRef, with groupSnapshotClass.
+func buildGroupSnapshotContent(contentName string, groupSnapshotRef v1.ObjectReference, groupSnapshotClass *groupsnapshotv1.VolumeGroupSnapshotClass, volumes []*v1.PersistentVolume, snapshotterSecretRef *v1.SecretReference) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
 	var volumeHandles []string
 	for _, pv := range volumes {
-		if pv.Spec.CSI == nil {
-			err := fmt.Errorf(
-				"cannot snapshot a non-CSI volume for group snapshot %s: %s",
-				utils.GroupSnapshotKey(groupSnapshot), pv.Name)
-			klog.Error(err.Error())
-			ctrl.eventRecorder.Event(
-				groupSnapshot,
-				v1.EventTypeWarning,
-				"CreateGroupSnapshotContentFailed",
-				fmt.Sprintf("Cannot snapshot a non-CSI volume: %s", pv.Name),
-			)
-			return nil, err
-		}
-
-		volumeCSIDriver := pv.Spec.CSI.Driver
-		classCSIDriver := groupSnapshotClass.Driver
-		if volumeCSIDriver != classCSIDriver {
-			strErr := fmt.Sprintf(
-				"Volume CSI driver (%s) mismatch with VolumeGroupSnapshotClass (%s) %s: %s",
-				volumeCSIDriver, classCSIDriver, utils.GroupSnapshotKey(groupSnapshot), pv.Name)
-			klog.Error(strErr)
-			ctrl.eventRecorder.Event(
-				groupSnapshot,
-				v1.EventTypeWarning,
-				"CreateGroupSnapshotContentFailed",
-				strErr,
-			)
-			return nil, newControllerUpdateError(utils.GroupSnapshotKey(groupSnapshot), strErr)
-
-		}
 		volumeHandles = append(volumeHandles, pv.Spec.CSI.VolumeHandle)
 	}
 
@@ -1209,7 +1663,7 @@ func (ctrl *csiSnapshotCommonController) createGroupSnapshotContent(groupSnapsho
 			Name: contentName,
 		},
 		Spec: groupsnapshotv1.VolumeGroupSnapshotContentSpec{
-			VolumeGroupSnapshotRef: *snapshotRef,
+			VolumeGroupSnapshotRef: groupSnapshotRef,
 			Source: groupsnapshotv1.VolumeGroupSnapshotContentSource{
 				VolumeHandles: volumeHandles,
 			},
@@ -1223,36 +1677,43 @@ func (ctrl *csiSnapshotCommonController) createGroupSnapshotContent(groupSnapsho
 		Add secret reference details
 	*/
 	if snapshotterSecretRef != nil {
-		klog.V(5).Infof("createGroupSnapshotContent: set annotation [%s] on volume group snapshot content [%s].", utils.AnnDeletionGroupSecretRefName, groupSnapshotContent.Name)
+		klog.V(5).Infof("buildGroupSnapshotContent: set annotation [%s] on volume group snapshot content [%s].", utils.AnnDeletionGroupSecretRefName, groupSnapshotContent.Name)
 		metav1.SetMetaDataAnnotation(&groupSnapshotContent.ObjectMeta, utils.AnnDeletionGroupSecretRefName, snapshotterSecretRef.Name)
 
-		klog.V(5).Infof("creategroupSnapshotContent: set annotation [%s] on volume group snapshot content [%s].", utils.AnnDeletionGroupSecretRefNamespace, groupSnapshotContent.Name)
+		klog.V(5).Infof("buildGroupSnapshotContent: set annotation [%s] on volume group snapshot content [%s].", utils.AnnDeletionGroupSecretRefNamespace, groupSnapshotContent.Name)
 		metav1.SetMetaDataAnnotation(&groupSnapshotContent.ObjectMeta, utils.AnnDeletionGroupSecretRefNamespace, snapshotterSecretRef.Namespace)
+
+		if err := utils.SetSecretProviderAnnotation(&groupSnapshotContent.ObjectMeta, utils.AnnDeletionGroupSecretRefProvider, groupSnapshotClass.Parameters); err != nil {
+			return nil, err
+		}
 	}
+	return groupSnapshotContent, nil
+}
 
-	var updateGroupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent
+// saveGroupSnapshotContent creates groupSnapshotContent for the group
+// snapshot groupSnapshot, whose key is key. An existing group snapshot
+// content with the same name is reused.
+func (ctrl *csiSnapshotCommonController) saveGroupSnapshotContent(groupSnapshot runtime.Object, key string, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
 	klog.V(5).Infof("volume group snapshot content %#v", groupSnapshotContent)
 	// Try to create the VolumeGroupSnapshotContent object
-	klog.V(5).Infof("createGroupSnapshotContent [%s]: trying to save volume group snapshot content %s", utils.GroupSnapshotKey(groupSnapshot), groupSnapshotContent.Name)
-	if updateGroupSnapshotContent, err = ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshotContents().Create(context.TODO(), groupSnapshotContent, metav1.CreateOptions{}); err == nil || apierrs.IsAlreadyExists(err) {
-		// Save succeeded.
-		if err != nil {
-			klog.V(3).Infof("volume group snapshot content %q for group snapshot %q already exists, reusing", groupSnapshotContent.Name, utils.GroupSnapshotKey(groupSnapshot))
-			err = nil
-			updateGroupSnapshotContent = groupSnapshotContent
-		} else {
-			klog.V(3).Infof("volume group snapshot content %q for group snapshot %q saved, %v", groupSnapshotContent.Name, utils.GroupSnapshotKey(groupSnapshot), groupSnapshotContent)
-		}
+	klog.V(5).Infof("saveGroupSnapshotContent [%s]: trying to save volume group snapshot content %s", key, groupSnapshotContent.Name)
+	updateGroupSnapshotContent, err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshotContents().Create(context.TODO(), groupSnapshotContent, metav1.CreateOptions{})
+	switch {
+	case apierrs.IsAlreadyExists(err):
+		klog.V(3).Infof("volume group snapshot content %q for group snapshot %q already exists, reusing", groupSnapshotContent.Name, key)
+		updateGroupSnapshotContent, err = groupSnapshotContent, nil
+	case err == nil:
+		klog.V(3).Infof("volume group snapshot content %q for group snapshot %q saved, %v", groupSnapshotContent.Name, key, groupSnapshotContent)
 	}
 
 	if err != nil {
-		strerr := fmt.Sprintf("Error creating volume group snapshot content object for group snapshot %s: %v.", utils.GroupSnapshotKey(groupSnapshot), err)
+		strerr := fmt.Sprintf("Error creating volume group snapshot content object for group snapshot %s: %v.", key, err)
 		klog.Error(strerr)
 		ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "CreateGroupSnapshotContentFailed", strerr)
-		return nil, newControllerUpdateError(utils.GroupSnapshotKey(groupSnapshot), err.Error())
+		return nil, newControllerUpdateError(key, err.Error())
 	}
 
-	msg := fmt.Sprintf("Waiting for a group snapshot %s to be created by the CSI driver.", utils.GroupSnapshotKey(groupSnapshot))
+	msg := fmt.Sprintf("Waiting for a group snapshot %s to be created by the CSI driver.", key)
 	ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeNormal, "CreatingGroupSnapshot", msg)
 
 	// Update group snapshot content in the cache store
@@ -1280,7 +1741,7 @@ func (ctrl *csiSnapshotCommonController) getCreateGroupSnapshotInput(groupSnapsh
 		return nil, nil, "", nil, fmt.Errorf("failed to take group snapshot %s without a group snapshot class", groupSnapshot.Name)
 	}
 
-	volumes, err := ctrl.getVolumesFromVolumeGroupSnapshot(groupSnapshot)
+	volumes, err := ctrl.getVolumesFromVolumeGroupSnapshot(groupSnapshot, groupSnapshotClass.Driver)
 	if err != nil {
 		klog.Errorf("getCreateGroupSnapshotInput failed to get PersistentVolume objects [%s]: Error: [%#v]", groupSnapshot.Name, err)
 		return nil, nil, "", nil, err
@@ -1327,6 +1788,10 @@ func (ctrl *csiSnapshotCommonController) syncGroupSnapshotContent(groupSnapshotC
 		return ctrl.addGroupSnapshotContentFinalizer(groupSnapshotContent)
 	}
 
+	if utils.IsClusterVolumeGroupSnapshotRef(&groupSnapshotContent.Spec.VolumeGroupSnapshotRef) {
+		return ctrl.syncClusterGroupSnapshotContent(groupSnapshotContent)
+	}
+
 	// Check if group snapshot exists in cache store
 	// If getGroupSnapshotFromStore returns (nil, nil), it means group snapshot not found
 	// and it may have already been deleted, and it will fall into the
@@ -1388,22 +1853,49 @@ func (ctrl *csiSnapshotCommonController) getGroupSnapshotFromStore(groupSnapshot
 func (ctrl *csiSnapshotCommonController) needsUpdateGroupSnapshotStatus(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) bool {
 	klog.V(5).Infof("needsUpdateGroupSnapshotStatus[%s]", utils.GroupSnapshotKey(groupSnapshot))
 
-	if groupSnapshot.Status == nil && groupSnapshotContent.Status != nil {
+	return groupSnapshotStatusNeedsUpdate(groupSnapshot.Status, groupSnapshotContent.Status)
+}
+
+// isGroupSnapshotStatusCreated returns true if the group snapshot has been
+// cut on the storage system.
+func isGroupSnapshotStatusCreated(status *groupsnapshotv1.VolumeGroupSnapshotStatus) bool {
+	return status != nil && status.CreationTime != nil
+}
+
+// isGroupSnapshotStatusReady returns true if the group snapshot is ready to
+// use.
+func isGroupSnapshotStatusReady(status *groupsnapshotv1.VolumeGroupSnapshotStatus) bool {
+	return status != nil && status.ReadyToUse != nil && *status.ReadyToUse
+}
+
+// isGroupSnapshotStatusNotCrashConsistent returns true if the group snapshot
+// is known to not be crash consistent.
+func isGroupSnapshotStatusNotCrashConsistent(status *groupsnapshotv1.VolumeGroupSnapshotStatus) bool {
+	return status != nil && status.CrashConsistent != nil && !*status.CrashConsistent
+}
+
+// groupSnapshotStatusNeedsUpdate compares the status of a group snapshot with
+// the status of its group snapshot content.
+func groupSnapshotStatusNeedsUpdate(status *groupsnapshotv1.VolumeGroupSnapshotStatus, contentStatus *groupsnapshotv1.VolumeGroupSnapshotContentStatus) bool {
+	if status == nil && contentStatus != nil {
 		return true
 	}
-	if groupSnapshotContent.Status == nil {
+	if contentStatus == nil {
 		return false
 	}
-	if groupSnapshot.Status.BoundVolumeGroupSnapshotContentName == nil {
+	if status.BoundVolumeGroupSnapshotContentName == nil {
 		return true
 	}
-	if groupSnapshot.Status.CreationTime == nil && groupSnapshotContent.Status.CreationTime != nil {
+	if status.CreationTime == nil && contentStatus.CreationTime != nil {
 		return true
 	}
-	if groupSnapshot.Status.ReadyToUse == nil && groupSnapshotContent.Status.ReadyToUse != nil {
+	if status.ReadyToUse == nil && contentStatus.ReadyToUse != nil {
 		return true
 	}
-	if groupSnapshot.Status.ReadyToUse != nil && groupSnapshotContent.Status.ReadyToUse != nil && groupSnapshot.Status.ReadyToUse != groupSnapshotContent.Status.ReadyToUse {
+	if status.ReadyToUse != nil && contentStatus.ReadyToUse != nil && status.ReadyToUse != contentStatus.ReadyToUse {
+		return true
+	}
+	if status.CrashConsistent == nil && contentStatus.CrashConsistent != nil {
 		return true
 	}
 
@@ -1412,22 +1904,7 @@ func (ctrl *csiSnapshotCommonController) needsUpdateGroupSnapshotStatus(groupSna
 
 // addGroupSnapshotContentFinalizer adds a Finalizer for VolumeGroupSnapshotContent.
 func (ctrl *csiSnapshotCommonController) addGroupSnapshotContentFinalizer(groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) error {
-	var patches []utils.PatchOp
-	if len(groupSnapshotContent.Finalizers) > 0 {
-		// Add to the end of the finalizers if we have any other finalizers
-		patches = append(patches, utils.PatchOp{
-			Op:    "add",
-			Path:  "/metadata/finalizers/-",
-			Value: utils.VolumeGroupSnapshotContentFinalizer,
-		})
-	} else {
-		// Replace finalizers with new array if there are no other finalizers
-		patches = append(patches, utils.PatchOp{
-			Op:    "add",
-			Path:  "/metadata/finalizers",
-			Value: []string{utils.VolumeGroupSnapshotContentFinalizer},
-		})
-	}
+	patches := addFinalizerPatches(groupSnapshotContent.Finalizers, utils.VolumeGroupSnapshotContentFinalizer)
 	newGroupSnapshotContent, err := utils.PatchVolumeGroupSnapshotContent(groupSnapshotContent, patches, ctrl.clientset)
 	if err != nil {
 		return newControllerUpdateError(groupSnapshotContent.Name, err.Error())
@@ -1442,6 +1919,29 @@ func (ctrl *csiSnapshotCommonController) addGroupSnapshotContentFinalizer(groupS
 	return nil
 }
 
+// addFinalizerPatches returns the patches which add finalizer to an object
+// with finalizers.
+func addFinalizerPatches(finalizers []string, finalizer string) []utils.PatchOp {
+	if len(finalizers) > 0 {
+		// Add to the end of the finalizers if we have any other finalizers
+		return []utils.PatchOp{
+			{
+				Op:    "add",
+				Path:  "/metadata/finalizers/-",
+				Value: finalizer,
+			},
+		}
+	}
+	// Replace finalizers with new array if there are no other finalizers
+	return []utils.PatchOp{
+		{
+			Op:    "add",
+			Path:  "/metadata/finalizers",
+			Value: []string{finalizer},
+		},
+	}
+}
+
 // checkandAddGroupSnapshotFinalizers checks and adds group snapshot finailzers when needed
 func (ctrl *csiSnapshotCommonController) checkandAddGroupSnapshotFinalizers(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) error {
 	// get the group snapshot content for this group snapshot
@@ -1599,22 +2099,8 @@ func (ctrl *csiSnapshotCommonController) processGroupSnapshotWithDeletionTimesta
 	// check if an individual snapshot belonging to the group snapshot is being
 	// used for restore a PVC
 	// If yes, do nothing and wait until PVC restoration finishes
-	for _, snapshot := range snapshotMembers {
-		snapshot, err := ctrl.snapshotLister.VolumeSnapshots(groupSnapshot.Namespace).Get(snapshot.Name)
-		if err != nil {
-			if apierrs.IsNotFound(err) {
-				continue
-			}
-			return err
-		}
-		if ctrl.isVolumeBeingCreatedFromSnapshot(snapshot) {
-			msg := fmt.Sprintf("Snapshot %s belonging to VolumeGroupSnapshot %s is being used to restore a PVC", utils.SnapshotKey(snapshot), utils.GroupSnapshotKey(groupSnapshot))
-			klog.V(4).Info(msg)
-			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "SnapshotDeletePending", msg)
-			// Return error so the workqueue requeues until no PVC is still being created from this snapshot.
-			return fmt.Errorf("%s: snapshot is in use, will retry deletion", msg)
-		}
-
+	if err := ctrl.checkGroupSnapshotMembersNotInUse(groupSnapshot, "VolumeGroupSnapshot", utils.GroupSnapshotKey(groupSnapshot), snapshotMembers); err != nil {
+		return err
 	}
 
 	// regardless of the deletion policy, set VolumeGroupSnapshotBeingDeleted on
@@ -1637,30 +2123,14 @@ func (ctrl *csiSnapshotCommonController) processGroupSnapshotWithDeletionTimesta
 	// VolumeGroupSnapshotContent won't be deleted immediately due to the VolumeGroupSnapshotContentFinalizer
 	if groupSnapshotContent != nil && deleteGroupSnapshotContent {
 		klog.V(5).Infof("processGroupSnapshotWithDeletionTimestamp[%s]: set DeletionTimeStamp on group snapshot content [%s].", utils.GroupSnapshotKey(groupSnapshot), groupSnapshotContent.Name)
-		err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshotContents().Delete(ctx, groupSnapshotContent.Name, metav1.DeleteOptions{})
-		if err != nil {
-			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "GroupSnapshotContentObjectDeleteError", "Failed to delete group snapshot content API object")
-			return fmt.Errorf("failed to delete VolumeGroupSnapshotContent %s from API server: %q", groupSnapshotContent.Name, err)
+		if err := ctrl.deleteGroupSnapshotContentObject(ctx, groupSnapshot, groupSnapshotContent); err != nil {
+			return err
 		}
 	}
 
 	klog.V(5).Infof("processGroupSnapshotWithDeletionTimestamp[%s]: Delete individual snapshots that are part of the group snapshot", utils.GroupSnapshotKey(groupSnapshot))
-
-	// Delete the individual snapshots part of the group snapshot
-	for _, snapshot := range snapshotMembers {
-		err := ctrl.clientset.SnapshotV1().
-			VolumeSnapshots(groupSnapshot.Namespace).
-			Delete(context.TODO(), snapshot.Name, metav1.DeleteOptions{})
-		if err != nil && !apierrs.IsNotFound(err) {
-			msg := fmt.Sprintf(
-				"failed to delete snapshot API object %s/%s part of group snapshot %s: %v",
-				groupSnapshot.Namespace,
-				snapshot.Name,
-				utils.GroupSnapshotKey(groupSnapshot), err)
-			klog.Error(msg)
-			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "SnapshotDeleteError", msg)
-			return errors.New(msg)
-		}
+	if err := ctrl.deleteGroupSnapshotMembers(ctx, groupSnapshot, utils.GroupSnapshotKey(groupSnapshot), snapshotMembers); err != nil {
+		return err
 	}
 
 	klog.V(5).Infof("processGroupSnapshotWithDeletionTimestamp[%s] : Remove Finalizer for VolumeGroupSnapshot", utils.GroupSnapshotKey(groupSnapshot))
@@ -1676,6 +2146,55 @@ func (ctrl *csiSnapshotCommonController) processGroupSnapshotWithDeletionTimesta
 	return ctrl.removeGroupSnapshotFinalizer(groupSnapshot, removeBoundFinalizer)
 }
 
+// checkGroupSnapshotMembersNotInUse returns an error, and emits an event on
+// the group snapshot groupSnapshot of the given kind whose key is key, if a
+// PVC is being restored from one of its snapshotMembers.
+func (ctrl *csiSnapshotCommonController) checkGroupSnapshotMembersNotInUse(groupSnapshot runtime.Object, kind, key string, snapshotMembers []*crdv1.VolumeSnapshot) error {
+	for _, snapshot := range snapshotMembers {
+		snapshot, err := ctrl.snapshotLister.VolumeSnapshots(snapshot.Namespace).Get(snapshot.Name)
+		if err != nil {
+			if apierrs.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if ctrl.isVolumeBeingCreatedFromSnapshot(snapshot) {
+			msg := fmt.Sprintf("Snapshot %s belonging to %s %s is being used to restore a PVC", utils.SnapshotKey(snapshot), kind, key)
+			klog.V(4).Info(msg)
+			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "SnapshotDeletePending", msg)
+			// Return error so the workqueue requeues until no PVC is still being created from this snapshot.
+			return fmt.Errorf("%s: snapshot is in use, will retry deletion", msg)
+		}
+	}
+	return nil
+}
+
+// deleteGroupSnapshotContentObject deletes the group snapshot content of the
+// group snapshot groupSnapshot.
+func (ctrl *csiSnapshotCommonController) deleteGroupSnapshotContentObject(ctx context.Context, groupSnapshot runtime.Object, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) error {
+	err := ctrl.clientset.GroupsnapshotV1().VolumeGroupSnapshotContents().Delete(ctx, groupSnapshotContent.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrs.IsNotFound(err) {
+		ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "GroupSnapshotContentObjectDeleteError", "Failed to delete group snapshot content API object")
+		return fmt.Errorf("failed to delete VolumeGroupSnapshotContent %s from API server: %q", groupSnapshotContent.Name, err)
+	}
+	return nil
+}
+
+// deleteGroupSnapshotMembers deletes the snapshotMembers of the group
+// snapshot groupSnapshot, whose key is key.
+func (ctrl *csiSnapshotCommonController) deleteGroupSnapshotMembers(ctx context.Context, groupSnapshot runtime.Object, key string, snapshotMembers []*crdv1.VolumeSnapshot) error {
+	for _, snapshot := range snapshotMembers {
+		err := ctrl.clientset.SnapshotV1().VolumeSnapshots(snapshot.Namespace).Delete(ctx, snapshot.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			msg := fmt.Sprintf("failed to delete snapshot API object %s part of group snapshot %s: %v", utils.SnapshotKey(snapshot), key, err)
+			klog.Error(msg)
+			ctrl.eventRecorder.Event(groupSnapshot, v1.EventTypeWarning, "SnapshotDeleteError", msg)
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
 func (ctrl *csiSnapshotCommonController) setAnnVolumeGroupSnapshotBeingDeleted(groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
 	if groupSnapshotContent == nil {
 		return groupSnapshotContent, nil
@@ -64,6 +64,21 @@ var groupSnapshotClasses = []*groupsnapshotv1.VolumeGroupSnapshotClass{
 	},
 }
 
+var (
+	groupSnapshotMember1 = groupsnapshotv1.VolumeGroupSnapshotMember{
+		PersistentVolumeClaimName: "1-claim1-1",
+		PersistentVolumeClaimUID:  "1-pvc-uid6-1",
+		PersistentVolumeName:      "1-volume6-1",
+		VolumeHandle:              "1-pv-handle6-1",
+	}
+	groupSnapshotMember2 = groupsnapshotv1.VolumeGroupSnapshotMember{
+		PersistentVolumeClaimName: "2-claim1-1",
+		PersistentVolumeClaimUID:  "2-pvc-uid6-1",
+		PersistentVolumeName:      "2-volume6-1",
+		VolumeHandle:              "2-pv-handle6-1",
+	}
+)
+
 func TestCreateGroupSnapshotSync(t *testing.T) {
 	tests := []controllerTest{
 		{
@@ -74,12 +89,12 @@ func TestCreateGroupSnapshotSync(t *testing.T) {
 				},
 				"", classGold, "", nil, nil, nil, true, false, nil,
 			),
-			expectedGroupSnapshots: newGroupSnapshotArray(
+			expectedGroupSnapshots: withGroupSnapshotMembers(newGroupSnapshotArray(
 				"group-snap-1-1", "group-snapuid1-1", map[string]string{
 					"app.kubernetes.io/name": "postgresql",
 				},
 				"", classGold, "groupsnapcontent-group-snapuid1-1", &False, nil, nil, false, false, nil,
-			),
+			), groupSnapshotMember1, groupSnapshotMember2),
 			initialGroupContents: nogroupcontents,
 			expectedGroupContents: newGroupSnapshotContentArray(
 				"groupsnapcontent-group-snapuid1-1", "group-snapuid1-1", "group-snap-1-1", "group-snapshot-handle", classGold, []string{
@@ -190,7 +205,7 @@ func TestCreateGroupSnapshotSync(t *testing.T) {
 					"app.kubernetes.io/name": "postgresql",
 				},
 				"", classGold, "", &False, nil,
-				newVolumeError(`failed to create group snapshot content with error failed to get input parameters to create group snapshot group-snap-1-1: "the PVC claim1-1 is not yet bound to a PV, will not attempt to take a group snapshot"`),
+				newVolumeError(`failed to create group snapshot content with error failed to get input parameters to create group snapshot group-snap-1-1: "invalid members in group snapshot default/group-snap-1-1: PVC claim1-1: not yet bound to a PersistentVolume"`),
 				false, false, nil,
 			),
 			initialGroupContents:  nogroupcontents,
@@ -218,7 +233,7 @@ func TestCreateGroupSnapshotSync(t *testing.T) {
 					"app.kubernetes.io/name": "postgresql",
 				},
 				"", classGold, "", &False, nil,
-				newVolumeError("failed to create group snapshot content with error cannot snapshot a non-CSI volume for group snapshot default/group-snap-1-1: volume6-1"),
+				newVolumeError(`failed to create group snapshot content with error failed to get input parameters to create group snapshot group-snap-1-1: "invalid members in group snapshot default/group-snap-1-1: PVC claim1-1: PersistentVolume volume6-1 is not a CSI volume"`),
 				false, false, nil,
 			),
 			initialGroupContents:  nogroupcontents,
@@ -249,7 +264,7 @@ func TestCreateGroupSnapshotSync(t *testing.T) {
 					"app.kubernetes.io/name": "postgresql",
 				},
 				"", classGold, "", &False, nil,
-				newVolumeError("failed to create group snapshot content with error snapshot controller failed to update default/group-snap-1-1 on API server: Volume CSI driver (test.csi.driver.name) mismatch with VolumeGroupSnapshotClass (csi-mock-plugin) default/group-snap-1-1: volume6-1"),
+				newVolumeError(`failed to create group snapshot content with error failed to get input parameters to create group snapshot group-snap-1-1: "invalid members in group snapshot default/group-snap-1-1: PVC claim1-1: PersistentVolume volume6-1 belongs to CSI driver test.csi.driver.name, not csi-mock-plugin"`),
 				false, false, nil,
 			),
 			initialGroupContents:  nogroupcontents,
@@ -375,6 +390,37 @@ func TestCreateGroupSnapshotSync(t *testing.T) {
 			test:           testSyncGroupSnapshot,
 			expectSuccess:  false,
 		},
+		{
+			name: "1-12 - dynamically-provisioned group snapshot is created from the recorded members only",
+			initialGroupSnapshots: withGroupSnapshotMembers(newGroupSnapshotArray(
+				"group-snap-1-1", "group-snapuid1-1", map[string]string{
+					"app.kubernetes.io/name": "postgresql",
+				},
+				"", classGold, "", nil, nil, nil, false, false, nil,
+			), groupSnapshotMember1),
+			expectedGroupSnapshots: withGroupSnapshotMembers(newGroupSnapshotArray(
+				"group-snap-1-1", "group-snapuid1-1", map[string]string{
+					"app.kubernetes.io/name": "postgresql",
+				},
+				"", classGold, "groupsnapcontent-group-snapuid1-1", &False, nil, nil, false, false, nil,
+			), groupSnapshotMember1),
+			initialGroupContents: nogroupcontents,
+			expectedGroupContents: newGroupSnapshotContentArray(
+				"groupsnapcontent-group-snapuid1-1", "group-snapuid1-1", "group-snap-1-1", "group-snapshot-handle", classGold, []string{
+					"1-pv-handle6-1",
+				}, "", deletionPolicy, nil, false, false,
+			),
+			initialClaims: withClaimLabels(
+				newClaimCoupleArray("claim1-1", "pvc-uid6-1", "1Gi", "volume6-1", v1.ClaimBound, &classGold),
+				map[string]string{
+					"app.kubernetes.io/name": "postgresql",
+				}),
+			initialVolumes: newVolumeCoupleArray("volume6-1", "pv-uid6-1", "pv-handle6-1", "1Gi", "pvc-uid6-1", "claim1-1", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classGold),
+			expectedEvents: []string{"Warning GroupSnapshotMembersChanged", "Normal CreatingGroupSnapshot"},
+			errors:         noerrors,
+			test:           testSyncGroupSnapshot,
+			expectSuccess:  true,
+		},
 	}
 	runSyncTests(t, tests, nil, groupSnapshotClasses)
 }
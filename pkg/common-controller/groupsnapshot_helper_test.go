@@ -18,7 +18,9 @@ package common_controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
@@ -32,7 +34,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 )
 
 // helperSetup is shared infrastructure for direct unit-tests of the group-snapshot helper methods.
@@ -613,6 +617,49 @@ func TestCreateIndividualSnapshotForGroupSnapshot(t *testing.T) {
 		assertReactorStateAfterIndividualSnapshot(t, h, "gs-uid-1", "vol-with-pv", info, groupHandle, "pvc-1", nil)
 	})
 
+	t.Run("accessible topology from the node affinity of the PV", func(t *testing.T) {
+		h := newHelperSetup(t)
+
+		pv := makeCSIPersistentVolume("pv-1", mockDriverName, "vol-with-topology", "pvc-1", testNamespace)
+		pv.Spec.NodeAffinity = &v1.VolumeNodeAffinity{
+			Required: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      "topology.kubernetes.io/zone",
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{"zone-a"},
+							},
+						},
+					},
+				},
+			},
+		}
+		if err := h.ctrl.pvIndexer.Add(pv); err != nil {
+			t.Fatalf("failed to add PV to indexer: %v", err)
+		}
+
+		err := h.ctrl.createIndividualSnapshotForGroupSnapshot(
+			context.Background(),
+			newInfo("vol-with-topology"),
+			newGSC(mockDriverName, testNamespace),
+			newGS(testNamespace),
+			nil,
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content, found := h.reactor.contents[getSnapshotContentNameForVolumeGroupSnapshotContent("gs-uid-1", "vol-with-topology")]
+		if !found {
+			t.Fatal("snapshot content was not created")
+		}
+		expected := `[{"matchLabelExpressions":[{"key":"topology.kubernetes.io/zone","values":["zone-a"]}]}]`
+		if value := content.Annotations[utils.AnnAccessibleTopology]; value != expected {
+			t.Errorf("expected annotation %s=%s, got %q", utils.AnnAccessibleTopology, expected, value)
+		}
+	})
+
 	t.Run("full success when no PV in indexer", func(t *testing.T) {
 		h := newHelperSetup(t)
 
@@ -708,3 +755,227 @@ func TestCreateIndividualSnapshotForGroupSnapshot(t *testing.T) {
 		assertReactorStateAfterIndividualSnapshot(t, h, "gs-uid-1", "vol-with-secret", info, groupHandle, "", secret)
 	})
 }
+
+// TestGetVolumesFromVolumeGroupSnapshot verifies that group members are
+// selected with the complete label selector and that every invalid member is
+// reported with its reason.
+func TestGetVolumesFromVolumeGroupSnapshot(t *testing.T) {
+	newMember := func(name, tier, volumeName string, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+		claim := newClaim(name, name+"-uid", "1Gi", volumeName, phase, &classGold, false)
+		claim.Labels = map[string]string{"app": "db", "tier": tier}
+		return claim
+	}
+	newGroupSnapshot := func(tiers ...string) *groupsnapshotv1.VolumeGroupSnapshot {
+		gs := makeTestGroupSnapshot("test-gs", testNamespace, "gs-uid")
+		gs.Spec.Source.Selector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "db"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: tiers},
+			},
+		}
+		return gs
+	}
+	newMemberVolume := func(name, claimName, driver string) *v1.PersistentVolume {
+		pv := makeCSIPersistentVolume(name, driver, name+"-handle", claimName, testNamespace)
+		pv.Spec.ClaimRef.UID = types.UID(claimName + "-uid")
+		return pv
+	}
+
+	claims := []*v1.PersistentVolumeClaim{
+		newMember("data", "primary", "pv-data", v1.ClaimBound),
+		newMember("wal", "primary", "pv-wal", v1.ClaimBound),
+		newMember("replica", "replica", "pv-replica", v1.ClaimBound),
+		newMember("pending", "staging", "", v1.ClaimPending),
+		newMember("foreign", "external", "pv-foreign", v1.ClaimBound),
+	}
+	volumes := []*v1.PersistentVolume{
+		newMemberVolume("pv-data", "data", mockDriverName),
+		newMemberVolume("pv-wal", "wal", mockDriverName),
+		newMemberVolume("pv-replica", "replica", mockDriverName),
+		newMemberVolume("pv-foreign", "foreign", "other.csi.driver"),
+	}
+
+	h := newHelperSetup(t)
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, claim := range claims {
+		pvcIndexer.Add(claim)
+	}
+	h.ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, volume := range volumes {
+		pvIndexer.Add(volume)
+	}
+	h.ctrl.pvLister = corelisters.NewPersistentVolumeLister(pvIndexer)
+
+	t.Run("match expressions", func(t *testing.T) {
+		pvs, err := h.ctrl.getVolumesFromVolumeGroupSnapshot(newGroupSnapshot("primary"), mockDriverName)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, pv := range pvs {
+			names = append(names, pv.Name)
+		}
+		if !reflect.DeepEqual(names, []string{"pv-data", "pv-wal"}) {
+			t.Errorf("expected volumes [pv-data pv-wal], got %v", names)
+		}
+	})
+
+	t.Run("invalid members", func(t *testing.T) {
+		_, err := h.ctrl.getVolumesFromVolumeGroupSnapshot(newGroupSnapshot("primary", "staging", "external"), mockDriverName)
+		expected := "invalid members in group snapshot default/test-gs: " +
+			"PVC foreign: PersistentVolume pv-foreign belongs to CSI driver other.csi.driver, not csi-mock-plugin; " +
+			"PVC pending: not yet bound to a PersistentVolume"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+
+	t.Run("recorded members", func(t *testing.T) {
+		gs := newGroupSnapshot("replica")
+		gs.Status = &groupsnapshotv1.VolumeGroupSnapshotStatus{
+			Members: []groupsnapshotv1.VolumeGroupSnapshotMember{
+				{PersistentVolumeClaimName: "data", PersistentVolumeClaimUID: "data-uid", PersistentVolumeName: "pv-data", VolumeHandle: "pv-data-handle"},
+			},
+		}
+		pvs, err := h.ctrl.getVolumesFromVolumeGroupSnapshot(gs, mockDriverName)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(pvs) != 1 || pvs[0].Name != "pv-data" {
+			t.Errorf("expected the recorded volume pv-data instead of the selected ones, got %v", pvs)
+		}
+
+		gs.Status.Members = append(gs.Status.Members, groupsnapshotv1.VolumeGroupSnapshotMember{
+			PersistentVolumeClaimName: "wal", PersistentVolumeClaimUID: "recreated-uid", PersistentVolumeName: "pv-wal", VolumeHandle: "pv-wal-handle",
+		})
+		_, err = h.ctrl.getVolumesFromVolumeGroupSnapshot(gs, mockDriverName)
+		expected := "invalid members in group snapshot default/test-gs: PVC wal: binding to PersistentVolume pv-wal is broken"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+
+	t.Run("mixed drivers without class", func(t *testing.T) {
+		_, err := h.ctrl.pvDriverFromGroupSnapshot(newGroupSnapshot("replica", "external"))
+		expected := "invalid members in group snapshot default/test-gs: " +
+			"PVC replica: PersistentVolume pv-replica belongs to CSI driver csi-mock-plugin, not other.csi.driver like PersistentVolume pv-foreign"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	})
+}
+
+// TestBindGroupSnapshotMembers verifies that the outcome of binding each
+// member is recorded in the group snapshot content status and that the
+// member failure policy is applied.
+func TestBindGroupSnapshotMembers(t *testing.T) {
+	newContent := func(h *helperSetup) *groupsnapshotv1.VolumeGroupSnapshotContent {
+		content := makeTestGroupSnapshotContent("gsc-1", mockDriverName, testNamespace, "grp-handle", deletionPolicy)
+		for _, volumeHandle := range []string{"vol-a", "vol-b", "vol-c"} {
+			content.Status.VolumeSnapshotInfoList = append(content.Status.VolumeSnapshotInfoList, groupsnapshotv1.VolumeSnapshotInfo{
+				VolumeHandle:   volumeHandle,
+				SnapshotHandle: volumeHandle + "-snap",
+			})
+		}
+		h.reactor.groupContents[content.Name] = content
+		return content
+	}
+	// bindMember fails for the volumes in failing and records every attempt.
+	bindMember := func(attempts *[]string, failing ...string) func(groupsnapshotv1.VolumeSnapshotInfo) error {
+		return func(snapshotInfo groupsnapshotv1.VolumeSnapshotInfo) error {
+			*attempts = append(*attempts, snapshotInfo.VolumeHandle)
+			for _, volumeHandle := range failing {
+				if snapshotInfo.VolumeHandle == volumeHandle {
+					return fmt.Errorf("mock bind error")
+				}
+			}
+			return nil
+		}
+	}
+
+	t.Run("retry binds the remaining members and clears the error on success", func(t *testing.T) {
+		h := newHelperSetup(t)
+		h.ctrl.groupSnapshotMemberFailurePolicy = GroupSnapshotMemberFailureRetry
+
+		var attempts []string
+		content, err := h.ctrl.bindGroupSnapshotMembers(newContent(h), bindMember(&attempts, "vol-b"))
+		var memberErr groupSnapshotMemberFailedError
+		if err == nil || errors.As(err, &memberErr) {
+			t.Fatalf("expected a retriable error, got %v", err)
+		}
+		if !reflect.DeepEqual(attempts, []string{"vol-a", "vol-b", "vol-c"}) {
+			t.Errorf("expected all members to be bound, got %v", attempts)
+		}
+		failed := content.Status.VolumeSnapshotInfoList[1]
+		if failed.Error == nil || *failed.Error.Message != "mock bind error" || failed.RetryCount != 1 || failed.LastTransitionTime == nil {
+			t.Errorf("unexpected status of the failed member: %+v", failed)
+		}
+		for _, i := range []int{0, 2} {
+			if info := content.Status.VolumeSnapshotInfoList[i]; info.Error != nil || info.RetryCount != 0 {
+				t.Errorf("unexpected status of member %s: %+v", info.VolumeHandle, info)
+			}
+		}
+
+		attempts = nil
+		content, err = h.ctrl.bindGroupSnapshotMembers(content, bindMember(&attempts))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		recovered := content.Status.VolumeSnapshotInfoList[1]
+		if recovered.Error != nil || recovered.RetryCount != 1 {
+			t.Errorf("unexpected status of the recovered member: %+v", recovered)
+		}
+		if stored := h.reactor.groupContents["gsc-1"].Status.VolumeSnapshotInfoList[1]; stored.Error != nil {
+			t.Errorf("member error was not cleared on the API server: %+v", stored)
+		}
+	})
+
+	t.Run("fail fast stops at the first failed member and does not retry it", func(t *testing.T) {
+		h := newHelperSetup(t)
+		h.ctrl.groupSnapshotMemberFailurePolicy = GroupSnapshotMemberFailureFailFast
+
+		var attempts []string
+		content, err := h.ctrl.bindGroupSnapshotMembers(newContent(h), bindMember(&attempts, "vol-b"))
+		var memberErr groupSnapshotMemberFailedError
+		if !errors.As(err, &memberErr) {
+			t.Fatalf("expected a groupSnapshotMemberFailedError, got %v", err)
+		}
+		if !reflect.DeepEqual(attempts, []string{"vol-a", "vol-b"}) {
+			t.Errorf("expected binding to stop at vol-b, got %v", attempts)
+		}
+		if stored := h.reactor.groupContents["gsc-1"].Status.VolumeSnapshotInfoList[1]; stored.Error == nil || stored.RetryCount != 1 {
+			t.Errorf("member error was not recorded on the API server: %+v", stored)
+		}
+
+		attempts = nil
+		_, err = h.ctrl.bindGroupSnapshotMembers(content, bindMember(&attempts))
+		if !errors.As(err, &memberErr) || err.Error() != memberErr.Error() {
+			t.Fatalf("expected the same groupSnapshotMemberFailedError, got %v", err)
+		}
+		if len(attempts) != 0 {
+			t.Errorf("expected no member to be bound again, got %v", attempts)
+		}
+	})
+
+	t.Run("concurrent member status update is not overwritten", func(t *testing.T) {
+		h := newHelperSetup(t)
+		h.ctrl.groupSnapshotMemberFailurePolicy = GroupSnapshotMemberFailureRetry
+
+		content := newContent(h)
+		// The sidecar updates the status after the controller read it.
+		stored := content.DeepCopy()
+		stored.Status.VolumeSnapshotInfoList[2].ReadyToUse = &True
+		h.reactor.groupContents[content.Name] = stored
+
+		var attempts []string
+		_, err := h.ctrl.bindGroupSnapshotMembers(content, bindMember(&attempts, "vol-b"))
+		var updateErr controllerUpdateError
+		if !errors.As(err, &updateErr) {
+			t.Fatalf("expected a controllerUpdateError, got %v", err)
+		}
+		if !reflect.DeepEqual(h.reactor.groupContents[content.Name], stored) {
+			t.Errorf("member status of the sidecar was overwritten: %+v", h.reactor.groupContents[content.Name].Status.VolumeSnapshotInfoList)
+		}
+	})
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// getManagedByNode returns the first node, by name, that matches affinity and
+// can handle the snapshots of driver. If there is none, an empty node name and
+// the reason are returned.
+func (ctrl *csiSnapshotCommonController) getManagedByNode(affinity *v1.VolumeNodeAffinity, driver string) (string, string, error) {
+	if affinity == nil || affinity.Required == nil {
+		return "", "the volume has no node affinity", nil
+	}
+
+	nodes, err := ctrl.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to get the list of nodes: %q", err)
+		return "", "", err
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	reason := "no node matches the node affinity"
+	for _, node := range nodes {
+		match, _ := corev1helpers.MatchNodeSelectorTerms(node, affinity.Required)
+		if !match {
+			continue
+		}
+		unavailable := ctrl.getNodeUnavailableReason(node, driver)
+		if unavailable == "" {
+			return node.Name, "", nil
+		}
+		reason = unavailable
+	}
+	klog.Errorf("failed to find a node for the node affinity %v: %s", affinity.Required, reason)
+	return "", reason, nil
+}
+
+// getNodeUnavailableReason returns why node cannot handle the snapshots of
+// driver, or an empty string if it can. Nodes are unavailable when they are
+// cordoned, not ready, or, if heartbeats are checked, do not run a CSI
+// snapshotter sidecar of driver.
+func (ctrl *csiSnapshotCommonController) getNodeUnavailableReason(node *v1.Node, driver string) string {
+	if node.Spec.Unschedulable {
+		return fmt.Sprintf("node %s is cordoned", node.Name)
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady && condition.Status != v1.ConditionTrue {
+			return fmt.Sprintf("node %s is not ready", node.Name)
+		}
+	}
+	if ctrl.leaseLister == nil {
+		return ""
+	}
+	leases, err := ctrl.leaseLister.List(utils.NodeHeartbeatLabels(driver, node.Name).AsSelector())
+	if err != nil {
+		return fmt.Sprintf("failed to list the heartbeats of node %s: %v", node.Name, err)
+	}
+	for _, lease := range leases {
+		if lease.Spec.RenewTime != nil && lease.Spec.LeaseDurationSeconds != nil &&
+			time.Since(lease.Spec.RenewTime.Time) < time.Duration(*lease.Spec.LeaseDurationSeconds)*time.Second {
+			return ""
+		}
+	}
+	return fmt.Sprintf("no CSI snapshotter sidecar of driver %s is running on node %s", driver, node.Name)
+}
+
+// needsManagedByNode returns true if content has a node affinity and still
+// needs a CSI snapshotter sidecar, i.e. the snapshot is not ready or content
+// is being deleted.
+func needsManagedByNode(content *crdv1.VolumeSnapshotContent) bool {
+	if content.Spec.NodeAffinity == nil {
+		return false
+	}
+	ready := content.Status != nil && content.Status.ReadyToUse != nil && *content.Status.ReadyToUse
+	return !ready || content.DeletionTimestamp != nil ||
+		metav1.HasAnnotation(content.ObjectMeta, utils.AnnVolumeSnapshotBeingDeleted) ||
+		utils.IsContentInRecycleBin(content)
+}
+
+// syncManagedByNode assigns content to another node with the
+// VolumeSnapshotContentManagedByLabel label if it still needs a CSI
+// snapshotter sidecar and the node it is assigned to is unavailable.
+// Returns the updated content.
+func (ctrl *csiSnapshotCommonController) syncManagedByNode(content *crdv1.VolumeSnapshotContent) (*crdv1.VolumeSnapshotContent, error) {
+	if !needsManagedByNode(content) {
+		return content, nil
+	}
+
+	current := content.Labels[utils.VolumeSnapshotContentManagedByLabel]
+	reason := "the content is not assigned to a node"
+	if current != "" {
+		node, err := ctrl.nodeLister.Get(current)
+		switch {
+		case err == nil && node != nil:
+			reason = ctrl.getNodeUnavailableReason(node, content.Spec.Driver)
+		case err == nil || apierrs.IsNotFound(err):
+			reason = fmt.Sprintf("node %s does not exist", current)
+		default:
+			return content, err
+		}
+		if reason == "" {
+			ctrl.forgetSnapshotterNotAvailable(content)
+			return content, nil
+		}
+	}
+
+	nodeName, unavailable, err := ctrl.getManagedByNode(content.Spec.NodeAffinity, content.Spec.Driver)
+	if err != nil {
+		return content, err
+	}
+	if nodeName == "" {
+		// Wait for a node or a sidecar to become available, which enqueues
+		// the content again.
+		message := fmt.Sprintf("No node can handle the snapshot content: %s, %s", reason, unavailable)
+		if !ctrl.reportedEvents.Warn(ctrl.eventRecorder, content, content.Name, "SnapshotterNotAvailable", message) {
+			klog.V(4).Infof("syncManagedByNode[%s]: %s", content.Name, message)
+		}
+		if snapshot, err := ctrl.getSnapshotFromStore(utils.SnapshotRefKey(&content.Spec.VolumeSnapshotRef)); err == nil && snapshot != nil {
+			ctrl.reportedEvents.Warn(ctrl.eventRecorder, snapshot, utils.SnapshotKey(snapshot), "SnapshotterNotAvailable", message)
+		}
+		return content, nil
+	}
+	ctrl.forgetSnapshotterNotAvailable(content)
+
+	klog.V(4).Infof("syncManagedByNode[%s]: assigning content to node %s, %s", content.Name, nodeName, reason)
+	newContent, err := utils.PatchVolumeSnapshotContent(content, managedByNodePatches(content, nodeName), ctrl.clientset)
+	if err != nil {
+		return content, newControllerUpdateError(content.Name, err.Error())
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("syncManagedByNode[%s]: cannot update internal cache %v", content.Name, err)
+	}
+	ctrl.eventRecorder.Event(newContent, v1.EventTypeNormal, "SnapshotContentReassigned", fmt.Sprintf("Assigned to node %s because %s", nodeName, reason))
+	return newContent, nil
+}
+
+// forgetSnapshotterNotAvailable forgets the SnapshotterNotAvailable events
+// emitted for content and its snapshot, so that the next unavailability is
+// reported again.
+func (ctrl *csiSnapshotCommonController) forgetSnapshotterNotAvailable(content *crdv1.VolumeSnapshotContent) {
+	ctrl.reportedEvents.Forget(content.Name, "SnapshotterNotAvailable")
+	ctrl.reportedEvents.Forget(utils.SnapshotRefKey(&content.Spec.VolumeSnapshotRef), "SnapshotterNotAvailable")
+}
+
+// managedByNodePatches returns the patches which set the
+// VolumeSnapshotContentManagedByLabel label of content to nodeName. They fail
+// if the label was changed in the meantime, so that a concurrent assignment
+// is not overwritten.
+func managedByNodePatches(content *crdv1.VolumeSnapshotContent, nodeName string) []utils.PatchOp {
+	path := "/metadata/labels/" + strings.ReplaceAll(utils.VolumeSnapshotContentManagedByLabel, "/", "~1")
+	if current := content.Labels[utils.VolumeSnapshotContentManagedByLabel]; current != "" {
+		return []utils.PatchOp{
+			{
+				Op:    "test",
+				Path:  path,
+				Value: current,
+			},
+			{
+				Op:    "replace",
+				Path:  path,
+				Value: nodeName,
+			},
+		}
+	}
+
+	// The absence of the label cannot be tested, test that content did not
+	// change instead.
+	patches := []utils.PatchOp{
+		{
+			Op:    "test",
+			Path:  "/metadata/resourceVersion",
+			Value: content.ResourceVersion,
+		},
+	}
+	if content.Labels == nil {
+		return append(patches, utils.PatchOp{
+			Op:    "add",
+			Path:  "/metadata/labels",
+			Value: map[string]string{utils.VolumeSnapshotContentManagedByLabel: nodeName},
+		})
+	}
+	return append(patches, utils.PatchOp{
+		Op:    "add",
+		Path:  path,
+		Value: nodeName,
+	})
+}
+
+// enqueueContentsWaitingForNode enqueues the contents that need a CSI
+// snapshotter sidecar after a node or a sidecar heartbeat changed.
+func (ctrl *csiSnapshotCommonController) enqueueContentsWaitingForNode() {
+	contents, err := ctrl.contentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list contents: %v", err)
+		return
+	}
+	for _, content := range contents {
+		if needsManagedByNode(content) {
+			ctrl.contentQueue.Add(content.Name)
+		}
+	}
+}
+
+// nodeAvailabilityChanged returns true if the availability of a node for
+// snapshots may have changed between old and new.
+func nodeAvailabilityChanged(old, new *v1.Node) bool {
+	if old.Spec.Unschedulable != new.Spec.Unschedulable {
+		return true
+	}
+	return getNodeReadyStatus(old) != getNodeReadyStatus(new)
+}
+
+func getNodeReadyStatus(node *v1.Node) v1.ConditionStatus {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status
+		}
+	}
+	return ""
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSyncManagedByNode(t *testing.T) {
+	newNode := func(name string, unschedulable bool, ready v1.ConditionStatus) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"key1": "value1"},
+			},
+			Spec: v1.NodeSpec{Unschedulable: unschedulable},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	affinity := &v1.VolumeNodeAffinity{
+		Required: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{
+					MatchExpressions: []v1.NodeSelectorRequirement{
+						{
+							Key:      "key1",
+							Operator: v1.NodeSelectorOpIn,
+							Values:   []string{"value1"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	content := newContent("snapcontent-snapuid11-1", "snapuid11-1", "snap11-1", "sid11-1", classGold, "", "volume11-1", deletePolicy, nil, nil, true, false)
+	content.Spec.NodeAffinity = affinity
+	content.Labels = map[string]string{utils.VolumeSnapshotContentManagedByLabel: "node1"}
+
+	client := fake.NewSimpleClientset(content)
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, nil, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+
+	// The content stays on its node while the node is available.
+	ctrl.nodeLister = FakeNodeLister{NodeList: []*v1.Node{
+		newNode("node1", false, v1.ConditionTrue),
+		newNode("node2", false, v1.ConditionTrue),
+	}}
+	updated, err := ctrl.syncManagedByNode(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if node := updated.Labels[utils.VolumeSnapshotContentManagedByLabel]; node != "node1" {
+		t.Errorf("expected the content to stay on node1, got %q", node)
+	}
+
+	// The content is reassigned when its node is cordoned, skipping nodes
+	// that are not ready.
+	ctrl.nodeLister = FakeNodeLister{NodeList: []*v1.Node{
+		newNode("node1", true, v1.ConditionTrue),
+		newNode("node2", false, v1.ConditionFalse),
+		newNode("node3", false, v1.ConditionTrue),
+	}}
+	if _, err := ctrl.syncManagedByNode(content); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reassigned, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if node := reassigned.Labels[utils.VolumeSnapshotContentManagedByLabel]; node != "node3" {
+		t.Errorf("expected the content to be reassigned to node3, got %q", node)
+	}
+
+	// The content is not reassigned when no node is available, which is
+	// reported once.
+	ctrl.nodeLister = FakeNodeLister{NodeList: []*v1.Node{
+		newNode("node3", false, v1.ConditionUnknown),
+	}}
+	recorder := ctrl.eventRecorder.(*record.FakeRecorder)
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+	for i := 0; i < 2; i++ {
+		updated, err = ctrl.syncManagedByNode(reassigned)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if node := updated.Labels[utils.VolumeSnapshotContentManagedByLabel]; node != "node3" {
+		t.Errorf("expected the content to stay on node3, got %q", node)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected 1 SnapshotterNotAvailable event, got %d", len(recorder.Events))
+	}
+
+	// The next unavailability is reported again once the node is available.
+	ctrl.nodeLister = FakeNodeLister{NodeList: []*v1.Node{
+		newNode("node3", false, v1.ConditionTrue),
+	}}
+	if _, err := ctrl.syncManagedByNode(reassigned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctrl.nodeLister = FakeNodeLister{NodeList: []*v1.Node{
+		newNode("node3", false, v1.ConditionUnknown),
+	}}
+	if _, err := ctrl.syncManagedByNode(reassigned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("expected 2 SnapshotterNotAvailable events, got %d", len(recorder.Events))
+	}
+
+	// A concurrent assignment is not overwritten.
+	ctrl.nodeLister = FakeNodeLister{NodeList: []*v1.Node{
+		newNode("node3", true, v1.ConditionTrue),
+		newNode("node4", false, v1.ConditionTrue),
+	}}
+	concurrent := reassigned.DeepCopy()
+	concurrent.Labels[utils.VolumeSnapshotContentManagedByLabel] = "node5"
+	if _, err := client.SnapshotV1().VolumeSnapshotContents().Update(context.TODO(), concurrent, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update content: %v", err)
+	}
+	if _, err := ctrl.syncManagedByNode(reassigned); err == nil {
+		t.Errorf("expected an error when the content was reassigned concurrently")
+	}
+	current, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if node := current.Labels[utils.VolumeSnapshotContentManagedByLabel]; node != "node5" {
+		t.Errorf("expected the concurrent assignment to node5 to be kept, got %q", node)
+	}
+}
+
+func TestManagedByNodePatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "content without labels",
+			expected: map[string]string{utils.VolumeSnapshotContentManagedByLabel: "node2"},
+		},
+		{
+			name:     "content with other labels",
+			labels:   map[string]string{"app": "db"},
+			expected: map[string]string{"app": "db", utils.VolumeSnapshotContentManagedByLabel: "node2"},
+		},
+		{
+			name:     "content assigned to another node",
+			labels:   map[string]string{"app": "db", utils.VolumeSnapshotContentManagedByLabel: "node1"},
+			expected: map[string]string{"app": "db", utils.VolumeSnapshotContentManagedByLabel: "node2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := newContent("snapcontent-snapuid11-2", "snapuid11-2", "snap11-2", "sid11-2", classGold, "", "volume11-2", deletePolicy, nil, nil, true, false)
+			content.Labels = test.labels
+
+			client := fake.NewSimpleClientset(content)
+			ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, nil, t, controllerTest{})
+			if err != nil {
+				t.Fatalf("failed to create test controller: %v", err)
+			}
+			if _, err := utils.PatchVolumeSnapshotContent(content, managedByNodePatches(content, "node2"), ctrl.clientset); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			patched, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get content: %v", err)
+			}
+			if !reflect.DeepEqual(patched.Labels, test.expected) {
+				t.Errorf("expected labels %v, got %v", test.expected, patched.Labels)
+			}
+		})
+	}
+}
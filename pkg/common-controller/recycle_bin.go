@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// moveContentToRecycleBin moves the content of a deleted snapshot to the
+// recycle bin if the content, the snapshot or its class has the
+// AnnRecycleBinPeriod annotation. The content is unbound from the snapshot
+// and gets the AnnPurgeAfter annotation, after which the sidecar deletes it.
+// Returns false if the content is not moved to the recycle bin.
+func (ctrl *csiSnapshotCommonController) moveContentToRecycleBin(snapshot *crdv1.VolumeSnapshot, content *crdv1.VolumeSnapshotContent) (bool, error) {
+	value := ctrl.getDeletionAnnotation(content, snapshot, utils.AnnRecycleBinPeriod)
+	if value == "" {
+		return false, nil
+	}
+	period, err := utils.ParseDurationAnnotation(utils.AnnRecycleBinPeriod, value)
+	if err != nil {
+		// Do not delete a snapshot that was meant to be kept for a while.
+		ctrl.eventRecorder.Event(snapshot, v1.EventTypeWarning, "SnapshotDeletePending", err.Error())
+		return false, err
+	}
+
+	purgeAfter := time.Now().Add(period).UTC().Format(time.RFC3339)
+	klog.V(4).Infof("moveContentToRecycleBin[%s]: moving content %s to the recycle bin until %s", utils.SnapshotKey(snapshot), content.Name, purgeAfter)
+	annotations := map[string]string{}
+	for key, value := range content.Annotations {
+		annotations[key] = value
+	}
+	// The content may have been marked by syncContent already.
+	delete(annotations, utils.AnnVolumeSnapshotBeingDeleted)
+	annotations[utils.AnnPurgeAfter] = purgeAfter
+	patches := []utils.PatchOp{
+		{
+			Op:    "add",
+			Path:  "/metadata/annotations",
+			Value: annotations,
+		},
+		{
+			Op:   "remove",
+			Path: "/spec/volumeSnapshotRef/uid",
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset)
+	if err != nil {
+		ctrl.eventRecorder.Event(snapshot, v1.EventTypeWarning, "SnapshotContentObjectDeleteError", "Failed to move snapshot content to the recycle bin")
+		return false, newControllerUpdateError(content.Name, err.Error())
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("moveContentToRecycleBin[%s]: cannot update internal cache %v", content.Name, err)
+	}
+	ctrl.eventRecorder.Event(snapshot, v1.EventTypeNormal, "SnapshotContentRecycled", fmt.Sprintf("Snapshot content %s is kept in the recycle bin until %s", content.Name, purgeAfter))
+	return true, nil
+}
+
+// isContentRestoredFromRecycleBin returns true if content is a dynamically
+// provisioned content which was restored from the recycle bin for the
+// pre-provisioned snapshot. Such a content is not bound to any snapshot, or
+// already bound to snapshot; dynamically provisioned contents are otherwise
+// always bound to the snapshot they were created for.
+func isContentRestoredFromRecycleBin(snapshot *crdv1.VolumeSnapshot, content *crdv1.VolumeSnapshotContent) bool {
+	if content.Spec.Source.VolumeHandle == nil || utils.IsContentInRecycleBin(content) {
+		return false
+	}
+	return content.Spec.VolumeSnapshotRef.UID == "" || content.Spec.VolumeSnapshotRef.UID == snapshot.UID
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMoveContentToRecycleBin(t *testing.T) {
+	content := newContent("snapcontent-snapuid9-1", "snapuid9-1", "snap9-1", "sid9-1", classGold, "", "volume9-1", deletePolicy, nil, nil, true, true)
+	metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnVolumeSnapshotBeingDeleted, "yes")
+	snapshot := withSnapshotAnnotations(newSnapshotArray("snap9-1", "snapuid9-1", "claim9-1", "", classGold, "snapcontent-snapuid9-1", &True, nil, nil, nil, false, true, &timeNowMetav1),
+		map[string]string{utils.AnnRecycleBinPeriod: "72h"})[0]
+
+	client := fake.NewSimpleClientset(content, snapshot)
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, nil, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	if err := ctrl.contentStore.Add(content); err != nil {
+		t.Fatalf("failed to add content to the store: %v", err)
+	}
+
+	if err := ctrl.processSnapshotWithDeletionTimestamp(snapshot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	recycled, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the content to be kept, got %v", err)
+	}
+	if !utils.IsContentInRecycleBin(recycled) {
+		t.Errorf("expected the content to be in the recycle bin, got annotations %v and reference %+v", recycled.Annotations, recycled.Spec.VolumeSnapshotRef)
+	}
+	if metav1.HasAnnotation(recycled.ObjectMeta, utils.AnnVolumeSnapshotBeingDeleted) {
+		t.Errorf("expected annotation %s to be removed", utils.AnnVolumeSnapshotBeingDeleted)
+	}
+	purgeAfter, err := utils.GetPurgeAfter(recycled)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := time.Now().Add(72 * time.Hour); purgeAfter.Before(expected.Add(-time.Minute)) || purgeAfter.After(expected.Add(time.Minute)) {
+		t.Errorf("expected the content to be purged after %v, got %v", expected, purgeAfter)
+	}
+	deleted, err := client.SnapshotV1().VolumeSnapshots(snapshot.Namespace).Get(context.TODO(), snapshot.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get snapshot: %v", err)
+	}
+	if slices.Contains(deleted.Finalizers, utils.VolumeSnapshotBoundFinalizer) {
+		t.Errorf("expected the bound finalizer to be removed from the snapshot, got %v", deleted.Finalizers)
+	}
+
+	// A content in the recycle bin cannot be bound by a pre-provisioned
+	// snapshot until it is restored.
+	restoring := newSnapshot("snap9-1", "snapuid9-2", "", "snapcontent-snapuid9-1", classGold, "", nil, nil, nil, nil, true, false, nil)
+	if isContentRestoredFromRecycleBin(restoring, recycled) {
+		t.Errorf("expected a content in the recycle bin not to be restored")
+	}
+	delete(recycled.Annotations, utils.AnnPurgeAfter)
+	if !isContentRestoredFromRecycleBin(restoring, recycled) {
+		t.Errorf("expected the content to be restored")
+	}
+	bound := content.DeepCopy()
+	if isContentRestoredFromRecycleBin(restoring, bound) {
+		t.Errorf("expected a content bound to another snapshot not to be restored")
+	}
+}
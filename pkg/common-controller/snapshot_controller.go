@@ -18,12 +18,14 @@ package common_controller
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"slices"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,9 +33,9 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	ref "k8s.io/client-go/tools/reference"
 	"k8s.io/client-go/util/retry"
-	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	klog "k8s.io/klog/v2"
 
+	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/metrics"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
@@ -92,14 +94,33 @@ func (ctrl *csiSnapshotCommonController) syncContent(content *crdv1.VolumeSnapsh
 
 	klog.V(5).Infof("syncContent[%s]: check if we should add invalid label on content", content.Name)
 
-	if (content.Spec.Source.VolumeHandle == nil && content.Spec.Source.SnapshotHandle == nil) ||
-		(content.Spec.Source.VolumeHandle != nil && content.Spec.Source.SnapshotHandle != nil) {
-		err := fmt.Errorf("Exactly one of VolumeHandle and SnapshotHandle should be specified")
+	sources := 0
+	for _, handle := range []*string{content.Spec.Source.VolumeHandle, content.Spec.Source.SnapshotHandle, content.Spec.Source.SourceSnapshotHandle} {
+		if handle != nil {
+			sources++
+		}
+	}
+	if sources != 1 {
+		err := fmt.Errorf("Exactly one of VolumeHandle, SnapshotHandle and SourceSnapshotHandle should be specified")
 		klog.Errorf("syncContent[%s]: validation error, %s", content.Name, err.Error())
 		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "ContentValidationError", err.Error())
 		return err
 	}
 
+	if ctrl.enableDistributedSnapshotting {
+		var err error
+		content, err = ctrl.syncManagedByNode(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Let the VolumeSnapshotCopy which created the content track the progress
+	// of the copy.
+	if content.Spec.Source.SourceSnapshotHandle != nil {
+		ctrl.enqueueSnapshotCopyForContent(content)
+	}
+
 	// The VolumeSnapshotContent is reserved for a VolumeSnapshot;
 	// that VolumeSnapshot has not yet been bound to this VolumeSnapshotContent;
 	// syncSnapshot will handle it.
@@ -159,10 +180,20 @@ func (ctrl *csiSnapshotCommonController) syncContent(content *crdv1.VolumeSnapsh
 	// Snapshot won't be deleted until content is deleted
 	// due to the finalizer.
 	if snapshot != nil && utils.IsSnapshotDeletionCandidate(snapshot) {
+		if isDeletionProtected(snapshot, content) || utils.IsUnderLegalHold(content.ObjectMeta) {
+			klog.V(4).Infof("syncContent [%s]: deletion of snapshot %s is protected", content.Name, snapshotName)
+			return nil
+		}
 		// Do not need to use the returned content here, as syncContent will get
 		// the correct version from the cache next time. It is also not used after this.
-		_, err = ctrl.setAnnVolumeSnapshotBeingDeleted(content)
-		return err
+		_, err = ctrl.setAnnVolumeSnapshotBeingDeleted(content, snapshot)
+		if err != nil {
+			return err
+		}
+		// The deletion of the snapshot may have waited for the protection
+		// of the content to be removed.
+		ctrl.snapshotQueue.Add(snapshotName)
+		return nil
 	}
 
 	return nil
@@ -269,6 +300,18 @@ func (ctrl *csiSnapshotCommonController) processSnapshotWithDeletionTimestamp(sn
 		content = nil
 	}
 
+	if utils.IsSnapshotDeletionCandidate(snapshot) && content != nil && utils.IsUnderLegalHold(content.ObjectMeta) {
+		klog.V(4).Infof("processSnapshotWithDeletionTimestamp[%s]: deletion is refused because of the legal hold of content %s", utils.SnapshotKey(snapshot), content.Name)
+		ctrl.reportedEvents.Warn(ctrl.eventRecorder, snapshot, utils.SnapshotKey(snapshot), "SnapshotDeletionRefused", fmt.Sprintf("Snapshot is not deleted while its content %s is under legal hold %q", content.Name, content.Annotations[utils.AnnLegalHold]))
+		return nil
+	}
+
+	if utils.IsSnapshotDeletionCandidate(snapshot) && isDeletionProtected(snapshot, content) {
+		klog.V(4).Infof("processSnapshotWithDeletionTimestamp[%s]: deletion is protected", utils.SnapshotKey(snapshot))
+		ctrl.reportedEvents.Warn(ctrl.eventRecorder, snapshot, utils.SnapshotKey(snapshot), "SnapshotDeletionProtected", fmt.Sprintf("Snapshot is not deleted until the %s annotation is removed from the snapshot and its content", utils.AnnDeletionProtection))
+		return nil
+	}
+
 	klog.V(5).Infof("processSnapshotWithDeletionTimestamp[%s]: delete snapshot content and remove finalizer from snapshot if needed", utils.SnapshotKey(snapshot))
 
 	return ctrl.checkandRemoveSnapshotFinalizersAndCheckandDeleteContent(snapshot, content, deleteContent)
@@ -294,9 +337,9 @@ func (ctrl *csiSnapshotCommonController) checkandRemoveSnapshotFinalizersAndChec
 	removeGroupFinalizer := false
 	// Block deletion if this snapshot belongs to a group snapshot.
 	if snapshot.Status != nil && snapshot.Status.VolumeGroupSnapshotName != nil {
-		groupSnapshot, err := ctrl.groupSnapshotLister.VolumeGroupSnapshots(snapshot.Namespace).Get(*snapshot.Status.VolumeGroupSnapshotName)
+		groupSnapshotKey, err := ctrl.getParentGroupSnapshotKey(snapshot, *snapshot.Status.VolumeGroupSnapshotName)
 		if err == nil {
-			msg := fmt.Sprintf("deletion of the individual volume snapshot %s is not allowed as it belongs to group snapshot %s. Deleting the group snapshot will trigger the deletion of all the individual volume snapshots that are part of the group.", utils.SnapshotKey(snapshot), utils.GroupSnapshotKey(groupSnapshot))
+			msg := fmt.Sprintf("deletion of the individual volume snapshot %s is not allowed as it belongs to group snapshot %s. Deleting the group snapshot will trigger the deletion of all the individual volume snapshots that are part of the group.", utils.SnapshotKey(snapshot), groupSnapshotKey)
 			klog.Error(msg)
 			ctrl.eventRecorder.Event(snapshot, v1.EventTypeWarning, "SnapshotDeletePending", msg)
 			return errors.New(msg)
@@ -311,12 +354,24 @@ func (ctrl *csiSnapshotCommonController) checkandRemoveSnapshotFinalizersAndChec
 		removeGroupFinalizer = true
 	}
 
+	// A content moved to the recycle bin is not bound to the snapshot any
+	// more and is neither annotated nor deleted.
+	if content != nil && deleteContent {
+		recycled, err := ctrl.moveContentToRecycleBin(snapshot, content)
+		if err != nil {
+			return err
+		}
+		if recycled {
+			content, deleteContent = nil, false
+		}
+	}
+
 	// regardless of the deletion policy, set the VolumeSnapshotBeingDeleted on
 	// content object, this is to allow snapshotter sidecar controller to conduct
 	// a delete operation whenever the content has deletion timestamp set.
 	if content != nil {
 		klog.V(5).Infof("checkandRemoveSnapshotFinalizersAndCheckandDeleteContent[%s]: Set VolumeSnapshotBeingDeleted annotation on the content [%s]", utils.SnapshotKey(snapshot), content.Name)
-		updatedContent, err := ctrl.setAnnVolumeSnapshotBeingDeleted(content)
+		updatedContent, err := ctrl.setAnnVolumeSnapshotBeingDeleted(content, snapshot)
 		if err != nil {
 			klog.V(4).Infof("checkandRemoveSnapshotFinalizersAndCheckandDeleteContent[%s]: failed to set VolumeSnapshotBeingDeleted annotation on the content [%s]", utils.SnapshotKey(snapshot), content.Name)
 			return err
@@ -415,6 +470,19 @@ func (ctrl *csiSnapshotCommonController) syncReadySnapshot(ctx context.Context,
 		return ctrl.updateSnapshotErrorStatusWithEvent(snapshot, true, v1.EventTypeWarning, "SnapshotMisbound", "VolumeSnapshotContent is not bound to the VolumeSnapshot correctly")
 	}
 
+	snapshot, err = ctrl.syncRequestedDeletionPolicy(snapshot, content)
+	if err != nil {
+		return err
+	}
+
+	// The accessible topology of a pre-existing snapshot may be filled in
+	// after it is ready.
+	if content.Status != nil && !equality.Semantic.DeepEqual(snapshot.Status.AccessibleTopology, content.Status.AccessibleTopology) {
+		if _, err := ctrl.updateSnapshotStatus(snapshot, content); err != nil {
+			return err
+		}
+	}
+
 	// If this snapshot is a member of a volume group snapshot, ensure we have
 	// the correct ownership. This happens when the user
 	// statically provisioned volume group snapshot members.
@@ -428,6 +496,28 @@ func (ctrl *csiSnapshotCommonController) syncReadySnapshot(ctx context.Context,
 	return nil
 }
 
+// getParentGroupSnapshotKey looks up the group snapshot a member snapshot
+// belongs to, which is a ClusterVolumeGroupSnapshot if the snapshot is owned
+// by one, and returns its key. A NotFound error is returned if the group
+// snapshot does not exist anymore.
+func (ctrl *csiSnapshotCommonController) getParentGroupSnapshotKey(snapshot *crdv1.VolumeSnapshot, groupSnapshotName string) (string, error) {
+	if utils.IsClusterVolumeGroupSnapshotMember(snapshot) {
+		if !ctrl.enableClusterVolumeGroupSnapshots {
+			return "", apierrs.NewNotFound(groupsnapshotv1.Resource("clustervolumegroupsnapshot"), groupSnapshotName)
+		}
+		groupSnapshot, err := ctrl.clusterGroupSnapshotLister.Get(groupSnapshotName)
+		if err != nil {
+			return "", err
+		}
+		return groupSnapshot.Name, nil
+	}
+	groupSnapshot, err := ctrl.groupSnapshotLister.VolumeGroupSnapshots(snapshot.Namespace).Get(groupSnapshotName)
+	if err != nil {
+		return "", err
+	}
+	return utils.GroupSnapshotKey(groupSnapshot), nil
+}
+
 // addVolumeGroupSnapshotOwnership adds the ownership information to a statically provisioned VolumeSnapshot
 // that is a member of a volume group snapshot
 func (ctrl *csiSnapshotCommonController) addVolumeGroupSnapshotOwnership(ctx context.Context, snapshot *crdv1.VolumeSnapshot) (*crdv1.VolumeSnapshot, error) {
@@ -618,6 +708,8 @@ func (ctrl *csiSnapshotCommonController) syncUnreadySnapshot(snapshot *crdv1.Vol
 // Otherwise, the found content will be returned.
 // A content is considered to be a pre-provisioned one if its Spec.Source.SnapshotHandle
 // is not nil, or a dynamically provisioned one if its Spec.Source.VolumeHandle is not nil.
+// A content created for a VolumeSnapshotCopy, with Spec.Source.SourceSnapshotHandle,
+// is bound like a pre-provisioned one.
 func (ctrl *csiSnapshotCommonController) getPreprovisionedContentFromStore(snapshot *crdv1.VolumeSnapshot) (*crdv1.VolumeSnapshotContent, error) {
 	contentName := *snapshot.Spec.Source.VolumeSnapshotContentName
 	if contentName == "" {
@@ -631,8 +723,9 @@ func (ctrl *csiSnapshotCommonController) getPreprovisionedContentFromStore(snaps
 		// can not find the desired VolumeSnapshotContent from cache store
 		return nil, nil
 	}
-	// check whether the content is a pre-provisioned VolumeSnapshotContent
-	if content.Spec.Source.SnapshotHandle == nil {
+	// check whether the content is a pre-provisioned VolumeSnapshotContent,
+	// or a dynamically provisioned one restored from the recycle bin
+	if content.Spec.Source.SnapshotHandle == nil && content.Spec.Source.SourceSnapshotHandle == nil && !isContentRestoredFromRecycleBin(snapshot, content) {
 		// found a content which represents a dynamically provisioned snapshot
 		// update the snapshot and return an error
 		ctrl.updateSnapshotErrorStatusWithEvent(snapshot, true, v1.EventTypeWarning, "SnapshotContentMismatch", "VolumeSnapshotContent is dynamically provisioned while expecting a pre-provisioned one")
@@ -759,8 +852,9 @@ func (ctrl *csiSnapshotCommonController) createSnapshotContent(snapshot *crdv1.V
 		},
 	}
 
-	if ctrl.enableDistributedSnapshotting {
-		nodeName, err := ctrl.getManagedByNode(volume)
+	if ctrl.enableDistributedSnapshotting && volume.Spec.NodeAffinity != nil {
+		snapshotContent.Spec.NodeAffinity = volume.Spec.NodeAffinity.DeepCopy()
+		nodeName, reason, err := ctrl.getManagedByNode(volume.Spec.NodeAffinity, class.Driver)
 		if err != nil {
 			return nil, err
 		}
@@ -768,6 +862,10 @@ func (ctrl *csiSnapshotCommonController) createSnapshotContent(snapshot *crdv1.V
 			snapshotContent.Labels = map[string]string{
 				utils.VolumeSnapshotContentManagedByLabel: nodeName,
 			}
+		} else {
+			// The content is assigned to a node by syncContent when one
+			// becomes available.
+			ctrl.eventRecorder.Event(snapshot, v1.EventTypeWarning, "SnapshotterNotAvailable", fmt.Sprintf("No node can take the snapshot yet: %s", reason))
 		}
 	}
 
@@ -778,6 +876,16 @@ func (ctrl *csiSnapshotCommonController) createSnapshotContent(snapshot *crdv1.V
 		}
 	}
 
+	// Set AnnAccessibleTopology for the sidecar to fill in the status
+	if topology := utils.NodeAffinityToTopology(volume.Spec.NodeAffinity); topology != nil {
+		value, err := json.Marshal(topology)
+		if err != nil {
+			return nil, err
+		}
+		klog.V(5).Infof("createSnapshotContent: set annotation [%s] on content [%s].", utils.AnnAccessibleTopology, snapshotContent.Name)
+		metav1.SetMetaDataAnnotation(&snapshotContent.ObjectMeta, utils.AnnAccessibleTopology, string(value))
+	}
+
 	// Set AnnDeletionSecretRefName and AnnDeletionSecretRefNamespace
 	if snapshotterSecretRef != nil {
 		klog.V(5).Infof("createSnapshotContent: set annotation [%s] on content [%s].", utils.AnnDeletionSecretRefName, snapshotContent.Name)
@@ -785,6 +893,10 @@ func (ctrl *csiSnapshotCommonController) createSnapshotContent(snapshot *crdv1.V
 
 		klog.V(5).Infof("createSnapshotContent: set annotation [%s] on content [%s].", utils.AnnDeletionSecretRefNamespace, snapshotContent.Name)
 		metav1.SetMetaDataAnnotation(&snapshotContent.ObjectMeta, utils.AnnDeletionSecretRefNamespace, snapshotterSecretRef.Namespace)
+
+		if err := utils.SetSecretProviderAnnotation(&snapshotContent.ObjectMeta, utils.AnnDeletionSecretRefProvider, class.Parameters); err != nil {
+			return nil, err
+		}
 	}
 
 	var updateContent *crdv1.VolumeSnapshotContent
@@ -846,8 +958,15 @@ func (ctrl *csiSnapshotCommonController) getCreateSnapshotInput(snapshot *crdv1.
 	// Create VolumeSnapshotContent name
 	contentName := utils.GetDynamicSnapshotContentNameForSnapshot(snapshot)
 
+	// The claim was already read by getVolumeFromVolumeSnapshot, it is
+	// passed to the secret templates.
+	pvc, err := ctrl.getClaimFromVolumeSnapshot(snapshot)
+	if err != nil {
+		return nil, nil, "", nil, err
+	}
+
 	// Resolve snapshotting secret credentials.
-	snapshotterSecretRef, err := utils.GetSecretReference(utils.SnapshotterSecretParams, class.Parameters, contentName, snapshot)
+	snapshotterSecretRef, err := utils.GetSecretReference(utils.SnapshotterSecretParams, class.Parameters, contentName, snapshot, pvc, volume)
 	if err != nil {
 		return nil, nil, "", nil, err
 	}
@@ -1204,6 +1323,9 @@ func (ctrl *csiSnapshotCommonController) needsUpdateSnapshotStatus(snapshot *crd
 	if snapshot.Status.RestoreSize != nil && snapshot.Status.RestoreSize.IsZero() && content.Status.RestoreSize != nil && *content.Status.RestoreSize > 0 {
 		return true
 	}
+	if !equality.Semantic.DeepEqual(snapshot.Status.AccessibleTopology, content.Status.AccessibleTopology) {
+		return true
+	}
 
 	return false
 }
@@ -1231,6 +1353,11 @@ func (ctrl *csiSnapshotCommonController) updateSnapshotStatus(snapshot *crdv1.Vo
 		volumeSnapshotErr = content.Status.Error.DeepCopy()
 	}
 
+	var accessibleTopology []v1.TopologySelectorTerm
+	if content.Status != nil {
+		accessibleTopology = content.Status.AccessibleTopology
+	}
+
 	var groupSnapshotName string
 	if content.Status != nil && content.Status.VolumeGroupSnapshotHandle != nil {
 		// If this snapshot belongs to a group snapshot, find the group snapshot
@@ -1278,6 +1405,7 @@ func (ctrl *csiSnapshotCommonController) updateSnapshotStatus(snapshot *crdv1.Vo
 		if groupSnapshotName != "" {
 			newStatus.VolumeGroupSnapshotName = &groupSnapshotName
 		}
+		newStatus.AccessibleTopology = accessibleTopology
 		updated = true
 	} else {
 		newStatus = snapshotObj.Status.DeepCopy()
@@ -1308,6 +1436,10 @@ func (ctrl *csiSnapshotCommonController) updateSnapshotStatus(snapshot *crdv1.Vo
 			newStatus.VolumeGroupSnapshotName = &groupSnapshotName
 			updated = true
 		}
+		if !equality.Semantic.DeepEqual(newStatus.AccessibleTopology, accessibleTopology) {
+			newStatus.AccessibleTopology = accessibleTopology
+			updated = true
+		}
 	}
 
 	if updated {
@@ -1420,6 +1552,33 @@ func (ctrl *csiSnapshotCommonController) getSnapshotClass(className string) (*cr
 	return class, nil
 }
 
+// shouldCheckSnapshotClassPolicy returns true if the class of snapshot must be
+// allowed in its namespace. Snapshots that are bound or being deleted are not
+// checked, so that changing the policy does not affect existing snapshots.
+func shouldCheckSnapshotClassPolicy(snapshot *crdv1.VolumeSnapshot) bool {
+	return snapshot.ObjectMeta.DeletionTimestamp == nil && !utils.IsBoundVolumeSnapshotContentNameSet(snapshot)
+}
+
+// checkSnapshotClassAllowed returns an error if the namespace annotations of
+// class do not allow its use in namespace.
+func (ctrl *csiSnapshotCommonController) checkSnapshotClassAllowed(class *crdv1.VolumeSnapshotClass, namespace string) error {
+	if !ctrl.enableSnapshotClassNamespacePolicy {
+		return nil
+	}
+	ns, err := ctrl.namespaceLister.Get(namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get namespace %s: %v", namespace, err)
+	}
+	allowed, err := utils.IsVolumeSnapshotClassAllowedInNamespace(class.ObjectMeta, ns)
+	if err != nil {
+		return fmt.Errorf("failed to check VolumeSnapshotClass %s: %v", class.Name, err)
+	}
+	if !allowed {
+		return fmt.Errorf("VolumeSnapshotClass %s is not allowed in namespace %s", class.Name, namespace)
+	}
+	return nil
+}
+
 // getSnapshotDriverName is a helper function to get snapshot driver from the VolumeSnapshot.
 // We try to get the driverName in multiple ways, as snapshot controller metrics depend on the correct driverName.
 func (ctrl *csiSnapshotCommonController) getSnapshotDriverName(vs *crdv1.VolumeSnapshot) (string, error) {
@@ -1463,6 +1622,8 @@ func (ctrl *csiSnapshotCommonController) getSnapshotDriverName(vs *crdv1.VolumeS
 // For pre-provisioned case, it's an no-op.
 // For dynamic provisioning, it gets the default SnapshotClasses in the system if there is any(could be multiple),
 // and finds the one with the same CSI Driver as the PV from which a snapshot will be taken.
+// If the namespace policy is enabled, a class that is the default of the namespace
+// of the snapshot takes precedence over the default classes of the cluster.
 func (ctrl *csiSnapshotCommonController) SetDefaultSnapshotClass(snapshot *crdv1.VolumeSnapshot) (*crdv1.VolumeSnapshotClass, *crdv1.VolumeSnapshot, error) {
 	klog.V(5).Infof("SetDefaultSnapshotClass for snapshot [%s]", snapshot.Name)
 
@@ -1497,6 +1658,15 @@ func (ctrl *csiSnapshotCommonController) SetDefaultSnapshotClass(snapshot *crdv1
 			klog.V(5).Infof("get defaultClass added: %s, driver: %s", class.Name, pvDriver)
 		}
 	}
+	if ctrl.enableSnapshotClassNamespacePolicy {
+		namespaceDefaultClasses, err := ctrl.getNamespaceDefaultSnapshotClasses(list, snapshot.Namespace, pvDriver)
+		if err != nil {
+			return nil, snapshot, err
+		}
+		if len(namespaceDefaultClasses) > 0 {
+			defaultClasses = namespaceDefaultClasses
+		}
+	}
 	if len(defaultClasses) == 0 {
 		return nil, snapshot, fmt.Errorf("cannot find default snapshot class")
 	}
@@ -1504,6 +1674,11 @@ func (ctrl *csiSnapshotCommonController) SetDefaultSnapshotClass(snapshot *crdv1
 		klog.V(4).Infof("get DefaultClass %d defaults found", len(defaultClasses))
 		return nil, snapshot, fmt.Errorf("%d default snapshot classes were found", len(defaultClasses))
 	}
+	if shouldCheckSnapshotClassPolicy(snapshot) {
+		if err := ctrl.checkSnapshotClassAllowed(defaultClasses[0], snapshot.Namespace); err != nil {
+			return nil, snapshot, err
+		}
+	}
 	klog.V(5).Infof("setDefaultSnapshotClass [%s]: default VolumeSnapshotClassName [%s]", snapshot.Name, defaultClasses[0].Name)
 	snapshotClone := snapshot.DeepCopy()
 	patches := []utils.PatchOp{
@@ -1527,6 +1702,30 @@ func (ctrl *csiSnapshotCommonController) SetDefaultSnapshotClass(snapshot *crdv1
 	return defaultClasses[0], newSnapshot, nil
 }
 
+// getNamespaceDefaultSnapshotClasses returns the classes of driver that are
+// the default classes of namespace.
+func (ctrl *csiSnapshotCommonController) getNamespaceDefaultSnapshotClasses(classes []*crdv1.VolumeSnapshotClass, namespace, driver string) ([]*crdv1.VolumeSnapshotClass, error) {
+	ns, err := ctrl.namespaceLister.Get(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %v", namespace, err)
+	}
+	defaultClasses := []*crdv1.VolumeSnapshotClass{}
+	for _, class := range classes {
+		if class.Driver != driver {
+			continue
+		}
+		isDefault, err := utils.IsVolumeSnapshotClassDefaultForNamespace(class.ObjectMeta, ns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check VolumeSnapshotClass %s: %v", class.Name, err)
+		}
+		if isDefault {
+			defaultClasses = append(defaultClasses, class)
+			klog.V(5).Infof("get namespace defaultClass added: %s, namespace: %s, driver: %s", class.Name, namespace, driver)
+		}
+	}
+	return defaultClasses, nil
+}
+
 // getClaimFromVolumeSnapshot is a helper function to get PVC from VolumeSnapshot.
 func (ctrl *csiSnapshotCommonController) getClaimFromVolumeSnapshot(snapshot *crdv1.VolumeSnapshot) (*v1.PersistentVolumeClaim, error) {
 	if snapshot.Spec.Source.PersistentVolumeClaimName == nil {
@@ -1687,7 +1886,7 @@ func (ctrl *csiSnapshotCommonController) getSnapshotFromStore(snapshotName strin
 	return snapshot, nil
 }
 
-func (ctrl *csiSnapshotCommonController) setAnnVolumeSnapshotBeingDeleted(content *crdv1.VolumeSnapshotContent) (*crdv1.VolumeSnapshotContent, error) {
+func (ctrl *csiSnapshotCommonController) setAnnVolumeSnapshotBeingDeleted(content *crdv1.VolumeSnapshotContent, snapshot *crdv1.VolumeSnapshot) (*crdv1.VolumeSnapshotContent, error) {
 	if content == nil {
 		return content, nil
 	}
@@ -1696,6 +1895,11 @@ func (ctrl *csiSnapshotCommonController) setAnnVolumeSnapshotBeingDeleted(conten
 		klog.V(5).Infof("setAnnVolumeSnapshotBeingDeleted: set annotation [%s] on content [%s].", utils.AnnVolumeSnapshotBeingDeleted, content.Name)
 		var patches []utils.PatchOp
 		metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnVolumeSnapshotBeingDeleted, "yes")
+		// Hand the deletion grace period over to the sidecar, which defers
+		// the deletion on the storage system.
+		if gracePeriod := ctrl.getDeletionAnnotation(content, snapshot, utils.AnnDeletionGracePeriod); gracePeriod != "" {
+			metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnDeletionGracePeriod, gracePeriod)
+		}
 		patches = append(patches, utils.PatchOp{
 			Op:    "replace",
 			Path:  "/metadata/annotations",
@@ -1720,26 +1924,31 @@ func (ctrl *csiSnapshotCommonController) setAnnVolumeSnapshotBeingDeleted(conten
 	return content, nil
 }
 
-func (ctrl *csiSnapshotCommonController) getManagedByNode(pv *v1.PersistentVolume) (string, error) {
-	if pv.Spec.NodeAffinity == nil {
-		klog.V(5).Infof("NodeAffinity not set for pv %s", pv.Name)
-		return "", nil
+// getDeletionAnnotation returns the annotation key of content, or else of
+// snapshot, or else of the class of content. It is used for the annotations
+// that control the deletion of a content, like AnnDeletionGracePeriod.
+func (ctrl *csiSnapshotCommonController) getDeletionAnnotation(content *crdv1.VolumeSnapshotContent, snapshot *crdv1.VolumeSnapshot, key string) string {
+	if value, ok := content.Annotations[key]; ok {
+		return value
 	}
-	nodeSelectorTerms := pv.Spec.NodeAffinity.Required
-
-	nodes, err := ctrl.nodeLister.List(labels.Everything())
-	if err != nil {
-		klog.Errorf("failed to get the list of nodes: %q", err)
-		return "", err
-	}
-
-	for _, node := range nodes {
-		match, _ := corev1helpers.MatchNodeSelectorTerms(node, nodeSelectorTerms)
-		if match {
-			return node.Name, nil
+	if snapshot != nil {
+		if value, ok := snapshot.Annotations[key]; ok {
+			return value
 		}
 	}
+	if content.Spec.VolumeSnapshotClassName == nil {
+		return ""
+	}
+	class, err := ctrl.classLister.Get(*content.Spec.VolumeSnapshotClassName)
+	if err != nil {
+		klog.V(4).Infof("getDeletionAnnotation: failed to get class of content %s: %v", content.Name, err)
+		return ""
+	}
+	return class.Annotations[key]
+}
 
-	klog.Errorf("failed to find nodes that match the node affinity requirements for pv[%s]", pv.Name)
-	return "", nil
+// isDeletionProtected returns true if snapshot or its bound content have the
+// AnnDeletionProtection annotation.
+func isDeletionProtected(snapshot *crdv1.VolumeSnapshot, content *crdv1.VolumeSnapshotContent) bool {
+	return utils.IsDeletionProtected(snapshot.ObjectMeta) || (content != nil && utils.IsDeletionProtected(content.ObjectMeta))
 }
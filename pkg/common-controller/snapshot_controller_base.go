@@ -38,10 +38,12 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	coordinationinformers "k8s.io/client-go/informers/coordination/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	coordinationlisters "k8s.io/client-go/listers/coordination/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -49,6 +51,20 @@ import (
 	klog "k8s.io/klog/v2"
 )
 
+// GroupSnapshotMemberFailurePolicy defines how the controller handles a member
+// of a group snapshot that cannot be bound to its VolumeSnapshot and
+// VolumeSnapshotContent.
+type GroupSnapshotMemberFailurePolicy string
+
+const (
+	// GroupSnapshotMemberFailureRetry binds the remaining members and keeps
+	// retrying the failed ones with exponential backoff.
+	GroupSnapshotMemberFailureRetry GroupSnapshotMemberFailurePolicy = "Retry"
+	// GroupSnapshotMemberFailureFailFast stops binding members at the first
+	// failure and marks the group snapshot as failed without further retries.
+	GroupSnapshotMemberFailureFailFast GroupSnapshotMemberFailurePolicy = "FailFast"
+)
+
 type csiSnapshotCommonController struct {
 	clientset                 clientset.Interface
 	client                    kubernetes.Interface
@@ -57,6 +73,8 @@ type csiSnapshotCommonController struct {
 	contentQueue              workqueue.TypedRateLimitingInterface[string]
 	groupSnapshotQueue        workqueue.TypedRateLimitingInterface[string]
 	groupSnapshotContentQueue workqueue.TypedRateLimitingInterface[string]
+	clusterGroupSnapshotQueue workqueue.TypedRateLimitingInterface[string]
+	snapshotCopyQueue         workqueue.TypedRateLimitingInterface[string]
 
 	snapshotLister                   snapshotlisters.VolumeSnapshotLister
 	snapshotListerSynced             cache.InformerSynced
@@ -70,25 +88,45 @@ type csiSnapshotCommonController struct {
 	pvListerSynced                   cache.InformerSynced
 	nodeLister                       corelisters.NodeLister
 	nodeListerSynced                 cache.InformerSynced
+	leaseLister                      coordinationlisters.LeaseLister
+	leaseListerSynced                cache.InformerSynced
 	groupSnapshotLister              groupsnapshotlisters.VolumeGroupSnapshotLister
 	groupSnapshotListerSynced        cache.InformerSynced
 	groupSnapshotContentLister       groupsnapshotlisters.VolumeGroupSnapshotContentLister
 	groupSnapshotContentListerSynced cache.InformerSynced
 	groupSnapshotClassLister         groupsnapshotlisters.VolumeGroupSnapshotClassLister
 	groupSnapshotClassListerSynced   cache.InformerSynced
+	clusterGroupSnapshotLister       groupsnapshotlisters.ClusterVolumeGroupSnapshotLister
+	clusterGroupSnapshotListerSynced cache.InformerSynced
+	namespaceLister                  corelisters.NamespaceLister
+	namespaceListerSynced            cache.InformerSynced
+	snapshotCopyLister               snapshotlisters.VolumeSnapshotCopyLister
+	snapshotCopyListerSynced         cache.InformerSynced
 
 	snapshotStore             cache.Store
 	contentStore              cache.Store
 	groupSnapshotStore        cache.Store
 	groupSnapshotContentStore cache.Store
+	clusterGroupSnapshotStore cache.Store
 
 	metricsManager metrics.MetricsManager
 
+	// reportedEvents are the Warning events emitted for snapshots and
+	// contents that are blocked, so that they are not emitted on every sync.
+	reportedEvents *utils.ReportedEvents
+
 	resyncPeriod time.Duration
 
 	enableDistributedSnapshotting bool
 	preventVolumeModeConversion   bool
 	enableVolumeGroupSnapshots    bool
+	// enableClusterVolumeGroupSnapshots is only set together with enableVolumeGroupSnapshots.
+	enableClusterVolumeGroupSnapshots bool
+	enableSnapshotCopy                bool
+	// enableSnapshotClassNamespacePolicy enforces the namespace annotations
+	// of VolumeSnapshotClasses.
+	enableSnapshotClassNamespacePolicy bool
+	groupSnapshotMemberFailurePolicy   GroupSnapshotMemberFailurePolicy
 
 	pvIndexer       cache.Indexer
 	snapshotIndexer cache.Indexer
@@ -104,18 +142,28 @@ func NewCSISnapshotCommonController(
 	volumeGroupSnapshotInformer groupsnapshotinformers.VolumeGroupSnapshotInformer,
 	volumeGroupSnapshotContentInformer groupsnapshotinformers.VolumeGroupSnapshotContentInformer,
 	volumeGroupSnapshotClassInformer groupsnapshotinformers.VolumeGroupSnapshotClassInformer,
+	clusterVolumeGroupSnapshotInformer groupsnapshotinformers.ClusterVolumeGroupSnapshotInformer,
+	volumeSnapshotCopyInformer snapshotinformers.VolumeSnapshotCopyInformer,
 	pvcInformer coreinformers.PersistentVolumeClaimInformer,
 	pvInformer coreinformers.PersistentVolumeInformer,
 	nodeInformer coreinformers.NodeInformer,
+	leaseInformer coordinationinformers.LeaseInformer,
+	namespaceInformer coreinformers.NamespaceInformer,
 	metricsManager metrics.MetricsManager,
 	resyncPeriod time.Duration,
 	snapshotRateLimiter workqueue.TypedRateLimiter[string],
 	contentRateLimiter workqueue.TypedRateLimiter[string],
 	groupSnapshotRateLimiter workqueue.TypedRateLimiter[string],
 	groupSnapshotContentRateLimiter workqueue.TypedRateLimiter[string],
+	clusterGroupSnapshotRateLimiter workqueue.TypedRateLimiter[string],
+	snapshotCopyRateLimiter workqueue.TypedRateLimiter[string],
 	enableDistributedSnapshotting bool,
 	preventVolumeModeConversion bool,
 	enableVolumeGroupSnapshots bool,
+	enableClusterVolumeGroupSnapshots bool,
+	enableSnapshotCopy bool,
+	enableSnapshotClassNamespacePolicy bool,
+	groupSnapshotMemberFailurePolicy GroupSnapshotMemberFailurePolicy,
 ) *csiSnapshotCommonController {
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartLogging(klog.Infof)
@@ -136,7 +184,9 @@ func NewCSISnapshotCommonController(
 		contentQueue: workqueue.NewTypedRateLimitingQueueWithConfig(contentRateLimiter,
 			workqueue.TypedRateLimitingQueueConfig[string]{
 				Name: "snapshot-controller-content"}),
-		metricsManager: metricsManager,
+		metricsManager:                   metricsManager,
+		reportedEvents:                   utils.NewReportedEvents(),
+		groupSnapshotMemberFailurePolicy: groupSnapshotMemberFailurePolicy,
 	}
 
 	ctrl.pvcLister = pvcInformer.Lister()
@@ -198,8 +248,34 @@ func NewCSISnapshotCommonController(
 	ctrl.enableDistributedSnapshotting = enableDistributedSnapshotting
 
 	if enableDistributedSnapshotting {
+		nodeInformer.Informer().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) { ctrl.enqueueContentsWaitingForNode() },
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					oldNode, oldOK := oldObj.(*v1.Node)
+					newNode, newOK := newObj.(*v1.Node)
+					if oldOK && newOK && nodeAvailabilityChanged(oldNode, newNode) {
+						ctrl.enqueueContentsWaitingForNode()
+					}
+				},
+				DeleteFunc: func(obj interface{}) { ctrl.enqueueContentsWaitingForNode() },
+			},
+		)
 		ctrl.nodeLister = nodeInformer.Lister()
 		ctrl.nodeListerSynced = nodeInformer.Informer().HasSynced
+
+		if leaseInformer != nil {
+			// Renewals do not change the availability of a node, expired
+			// heartbeats are found by the periodic resync.
+			leaseInformer.Informer().AddEventHandler(
+				cache.ResourceEventHandlerFuncs{
+					AddFunc:    func(obj interface{}) { ctrl.enqueueContentsWaitingForNode() },
+					DeleteFunc: func(obj interface{}) { ctrl.enqueueContentsWaitingForNode() },
+				},
+			)
+			ctrl.leaseLister = leaseInformer.Lister()
+			ctrl.leaseListerSynced = leaseInformer.Informer().HasSynced
+		}
 	}
 
 	ctrl.preventVolumeModeConversion = preventVolumeModeConversion
@@ -244,6 +320,52 @@ func NewCSISnapshotCommonController(
 
 	}
 
+	ctrl.enableClusterVolumeGroupSnapshots = enableVolumeGroupSnapshots && enableClusterVolumeGroupSnapshots
+
+	if ctrl.enableClusterVolumeGroupSnapshots {
+		ctrl.clusterGroupSnapshotStore = cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc)
+
+		ctrl.clusterGroupSnapshotQueue = workqueue.NewTypedRateLimitingQueueWithConfig(
+			clusterGroupSnapshotRateLimiter, workqueue.TypedRateLimitingQueueConfig[string]{
+				Name: "snapshot-controller-cluster-group-snapshot"})
+
+		clusterVolumeGroupSnapshotInformer.Informer().AddEventHandlerWithResyncPeriod(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctrl.enqueueClusterGroupSnapshotWork(obj) },
+				UpdateFunc: func(oldObj, newObj interface{}) { ctrl.enqueueClusterGroupSnapshotWork(newObj) },
+				DeleteFunc: func(obj interface{}) { ctrl.enqueueClusterGroupSnapshotWork(obj) },
+			},
+			ctrl.resyncPeriod,
+		)
+		ctrl.clusterGroupSnapshotLister = clusterVolumeGroupSnapshotInformer.Lister()
+		ctrl.clusterGroupSnapshotListerSynced = clusterVolumeGroupSnapshotInformer.Informer().HasSynced
+	}
+
+	ctrl.enableSnapshotClassNamespacePolicy = enableSnapshotClassNamespacePolicy
+
+	if enableClusterVolumeGroupSnapshots || enableSnapshotClassNamespacePolicy {
+		ctrl.namespaceLister = namespaceInformer.Lister()
+		ctrl.namespaceListerSynced = namespaceInformer.Informer().HasSynced
+	}
+
+	ctrl.enableSnapshotCopy = enableSnapshotCopy
+
+	if enableSnapshotCopy {
+		ctrl.snapshotCopyQueue = workqueue.NewTypedRateLimitingQueueWithConfig(
+			snapshotCopyRateLimiter, workqueue.TypedRateLimitingQueueConfig[string]{
+				Name: "snapshot-controller-snapshot-copy"})
+
+		volumeSnapshotCopyInformer.Informer().AddEventHandlerWithResyncPeriod(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { ctrl.enqueueSnapshotCopyWork(obj) },
+				UpdateFunc: func(oldObj, newObj interface{}) { ctrl.enqueueSnapshotCopyWork(newObj) },
+			},
+			ctrl.resyncPeriod,
+		)
+		ctrl.snapshotCopyLister = volumeSnapshotCopyInformer.Lister()
+		ctrl.snapshotCopyListerSynced = volumeSnapshotCopyInformer.Informer().HasSynced
+	}
+
 	return ctrl
 }
 
@@ -254,6 +376,12 @@ func (ctrl *csiSnapshotCommonController) Run(workers int, stopCh <-chan struct{}
 		defer ctrl.groupSnapshotQueue.ShutDown()
 		defer ctrl.groupSnapshotContentQueue.ShutDown()
 	}
+	if ctrl.enableClusterVolumeGroupSnapshots {
+		defer ctrl.clusterGroupSnapshotQueue.ShutDown()
+	}
+	if ctrl.enableSnapshotCopy {
+		defer ctrl.snapshotCopyQueue.ShutDown()
+	}
 
 	klog.Infof("Starting snapshot controller")
 	defer klog.Infof("Shutting snapshot controller")
@@ -268,9 +396,21 @@ func (ctrl *csiSnapshotCommonController) Run(workers int, stopCh <-chan struct{}
 	if ctrl.enableDistributedSnapshotting {
 		informersSynced = append(informersSynced, ctrl.nodeListerSynced)
 	}
+	if ctrl.leaseListerSynced != nil {
+		informersSynced = append(informersSynced, ctrl.leaseListerSynced)
+	}
 	if ctrl.enableVolumeGroupSnapshots {
 		informersSynced = append(informersSynced, []cache.InformerSynced{ctrl.groupSnapshotListerSynced, ctrl.groupSnapshotContentListerSynced, ctrl.groupSnapshotClassListerSynced}...)
 	}
+	if ctrl.enableClusterVolumeGroupSnapshots {
+		informersSynced = append(informersSynced, ctrl.clusterGroupSnapshotListerSynced)
+	}
+	if ctrl.namespaceListerSynced != nil {
+		informersSynced = append(informersSynced, ctrl.namespaceListerSynced)
+	}
+	if ctrl.enableSnapshotCopy {
+		informersSynced = append(informersSynced, ctrl.snapshotCopyListerSynced)
+	}
 
 	if !cache.WaitForCacheSync(stopCh, informersSynced...) {
 		klog.Errorf("Cannot sync caches")
@@ -304,6 +444,22 @@ func (ctrl *csiSnapshotCommonController) Run(workers int, stopCh <-chan struct{}
 					wait.Until(ctrl.groupSnapshotContentWorker, 0, stopCh)
 				}()
 			}
+
+			if ctrl.enableClusterVolumeGroupSnapshots {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					wait.Until(ctrl.clusterGroupSnapshotWorker, 0, stopCh)
+				}()
+			}
+
+			if ctrl.enableSnapshotCopy {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					wait.Until(ctrl.snapshotCopyWorker, 0, stopCh)
+				}()
+			}
 		}
 	} else {
 		for i := 0; i < workers; i++ {
@@ -313,6 +469,12 @@ func (ctrl *csiSnapshotCommonController) Run(workers int, stopCh <-chan struct{}
 				go wait.Until(ctrl.groupSnapshotWorker, 0, stopCh)
 				go wait.Until(ctrl.groupSnapshotContentWorker, 0, stopCh)
 			}
+			if ctrl.enableClusterVolumeGroupSnapshots {
+				go wait.Until(ctrl.clusterGroupSnapshotWorker, 0, stopCh)
+			}
+			if ctrl.enableSnapshotCopy {
+				go wait.Until(ctrl.snapshotCopyWorker, 0, stopCh)
+			}
 		}
 	}
 
@@ -510,6 +672,13 @@ func (ctrl *csiSnapshotCommonController) checkAndUpdateSnapshotClass(snapshot *c
 			// we need to return the original snapshot even if the class isn't found, as it may need to be deleted
 			return newSnapshot, err
 		}
+		if shouldCheckSnapshotClassPolicy(snapshot) {
+			if err := ctrl.checkSnapshotClassAllowed(class, snapshot.Namespace); err != nil {
+				klog.Errorf("checkAndUpdateSnapshotClass: %v", err)
+				ctrl.updateSnapshotErrorStatusWithEvent(snapshot, false, v1.EventTypeWarning, "SnapshotClassNotAllowed", err.Error())
+				return newSnapshot, err
+			}
+		}
 	} else {
 		klog.V(5).Infof("checkAndUpdateSnapshotClass [%s]: SetDefaultSnapshotClass", snapshot.Name)
 		class, newSnapshot, err = ctrl.SetDefaultSnapshotClass(snapshot)
@@ -560,6 +729,10 @@ func (ctrl *csiSnapshotCommonController) updateSnapshot(ctx context.Context, sna
 func (ctrl *csiSnapshotCommonController) updateContent(content *crdv1.VolumeSnapshotContent) error {
 	// Store the new content version in the cache and do not process it if this is
 	// an old version.
+	previous, err := ctrl.getContentFromStore(content.Name)
+	if err != nil {
+		klog.Errorf("%v", err)
+	}
 	new, err := ctrl.storeContentUpdate(content)
 	if err != nil {
 		klog.Errorf("%v", err)
@@ -567,6 +740,11 @@ func (ctrl *csiSnapshotCommonController) updateContent(content *crdv1.VolumeSnap
 	if !new {
 		return nil
 	}
+	content, err = ctrl.recordDeletionPolicyChange(content, previous)
+	if err != nil {
+		// The change is recorded again from the history, if there is one.
+		klog.Errorf("could not record deletion policy change of content %q: %+v", content.Name, err)
+	}
 	err = ctrl.syncContent(content)
 	if err != nil {
 		if errors.IsConflict(err) {
@@ -584,6 +762,7 @@ func (ctrl *csiSnapshotCommonController) updateContent(content *crdv1.VolumeSnap
 // deleteSnapshot runs in worker thread and handles "snapshot deleted" event.
 func (ctrl *csiSnapshotCommonController) deleteSnapshot(snapshot *crdv1.VolumeSnapshot) {
 	_ = ctrl.snapshotStore.Delete(snapshot)
+	ctrl.reportedEvents.ForgetObject(utils.SnapshotKey(snapshot))
 	klog.V(4).Infof("snapshot %q deleted", utils.SnapshotKey(snapshot))
 	driverName, err := ctrl.getSnapshotDriverName(snapshot)
 	if err != nil {
@@ -612,6 +791,7 @@ func (ctrl *csiSnapshotCommonController) deleteSnapshot(snapshot *crdv1.VolumeSn
 // deleteContent runs in worker thread and handles "content deleted" event.
 func (ctrl *csiSnapshotCommonController) deleteContent(content *crdv1.VolumeSnapshotContent) {
 	_ = ctrl.contentStore.Delete(content)
+	ctrl.reportedEvents.ForgetObject(content.Name)
 	klog.V(4).Infof("content %q deleted", content.Name)
 
 	snapshotName := utils.SnapshotRefKey(&content.Spec.VolumeSnapshotRef)
@@ -680,6 +860,20 @@ func (ctrl *csiSnapshotCommonController) initializeCaches() {
 		}
 	}
 
+	if ctrl.enableClusterVolumeGroupSnapshots {
+		clusterGroupSnapshotList, err := ctrl.clusterGroupSnapshotLister.List(labels.Everything())
+		if err != nil {
+			klog.Errorf("CSISnapshotController can't initialize caches: %v", err)
+			return
+		}
+		for _, clusterGroupSnapshot := range clusterGroupSnapshotList {
+			clusterGroupSnapshotClone := clusterGroupSnapshot.DeepCopy()
+			if _, err = ctrl.storeClusterGroupSnapshotUpdate(clusterGroupSnapshotClone); err != nil {
+				klog.Errorf("error updating cluster volume group snapshot cache: %v", err)
+			}
+		}
+	}
+
 	klog.V(4).Infof("controller initialized")
 }
 
@@ -920,6 +1114,14 @@ func (ctrl *csiSnapshotCommonController) deleteGroupSnapshotContent(content *gro
 	_ = ctrl.groupSnapshotContentStore.Delete(content)
 	klog.V(4).Infof("group snapshot content %q deleted", content.Name)
 
+	if utils.IsClusterVolumeGroupSnapshotRef(&content.Spec.VolumeGroupSnapshotRef) {
+		if ctrl.enableClusterVolumeGroupSnapshots {
+			klog.V(5).Infof("deleteGroupContent[%q]: scheduling sync of cluster group snapshot %s", content.Name, content.Spec.VolumeGroupSnapshotRef.Name)
+			ctrl.clusterGroupSnapshotQueue.Add(content.Spec.VolumeGroupSnapshotRef.Name)
+		}
+		return
+	}
+
 	groupSnapshotName := utils.GroupSnapshotRefKey(&content.Spec.VolumeGroupSnapshotRef)
 	if groupSnapshotName == "" {
 		klog.V(5).Infof("deleteGroupContent[%q]: group snapshot content not bound", content.Name)
@@ -40,6 +40,11 @@ func (l FakeNodeLister) List(selector labels.Selector) (ret []*v1.Node, err erro
 }
 
 func (l FakeNodeLister) Get(name string) (*v1.Node, error) {
+	for _, node := range l.NodeList {
+		if node.Name == name {
+			return node, nil
+		}
+	}
 	return nil, nil
 }
 
@@ -151,7 +156,7 @@ func TestGetManagedByNode(t *testing.T) {
 		},
 	}
 
-	nodeName, err := ctrl.getManagedByNode(pv)
+	nodeName, _, err := ctrl.getManagedByNode(pv.Spec.NodeAffinity, "")
 	if err != nil {
 		t.Errorf("Unexpected error occurred: %v", err)
 	}
@@ -171,7 +176,7 @@ func TestGetManagedByNode(t *testing.T) {
 		nodeLister: FakeNodeLister{NodeList: []*v1.Node{node1}},
 	}
 
-	nodeName, _ = ctrl.getManagedByNode(pv)
+	nodeName, _, _ = ctrl.getManagedByNode(pv.Spec.NodeAffinity, "")
 	if nodeName != "" {
 		t.Errorf("Expected no node, Found node(%s)", nodeName)
 	}
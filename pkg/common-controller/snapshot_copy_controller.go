@@ -0,0 +1,412 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	ref "k8s.io/client-go/tools/reference"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// A VolumeSnapshotCopy copies a ready VolumeSnapshot into another
+// VolumeSnapshotClass of the same CSI driver. The controller creates a
+// VolumeSnapshot named after the VolumeSnapshotCopy and a VolumeSnapshotContent
+// bound to it, whose Spec.Source.SourceSnapshotHandle asks the sidecar to copy
+// the source snapshot with the parameters of the target class. The
+// VolumeSnapshot is then bound like a pre-provisioned one, and the progress of
+// the copy is mirrored from the content status into the VolumeSnapshotCopy.
+// Deleting a VolumeSnapshotCopy does not delete the copied snapshot.
+
+// enqueueSnapshotCopyWork adds snapshot copy to given work queue.
+func (ctrl *csiSnapshotCommonController) enqueueSnapshotCopyWork(obj interface{}) {
+	if snapshotCopy, ok := obj.(*crdv1.VolumeSnapshotCopy); ok {
+		objName, err := cache.MetaNamespaceKeyFunc(snapshotCopy)
+		if err != nil {
+			klog.Errorf("failed to get key from object: %v, %v", err, snapshotCopy)
+			return
+		}
+		klog.V(5).Infof("enqueued %q for sync", objName)
+		ctrl.snapshotCopyQueue.Add(objName)
+	}
+}
+
+// enqueueSnapshotCopyForContent adds the snapshot copy which created the given
+// content to the snapshot copy work queue. The VolumeSnapshotCopy has the
+// name and namespace of the VolumeSnapshot the content is bound to.
+func (ctrl *csiSnapshotCommonController) enqueueSnapshotCopyForContent(content *crdv1.VolumeSnapshotContent) {
+	if !ctrl.enableSnapshotCopy {
+		return
+	}
+	key := utils.SnapshotRefKey(&content.Spec.VolumeSnapshotRef)
+	klog.V(5).Infof("enqueued snapshot copy %q for sync of content %s", key, content.Name)
+	ctrl.snapshotCopyQueue.Add(key)
+}
+
+// snapshotCopyWorker is the main worker for VolumeSnapshotCopies.
+func (ctrl *csiSnapshotCommonController) snapshotCopyWorker() {
+	key, quit := ctrl.snapshotCopyQueue.Get()
+	if quit {
+		return
+	}
+	defer ctrl.snapshotCopyQueue.Done(key)
+
+	if err := ctrl.syncSnapshotCopyByKey(context.Background(), key); err != nil {
+		// Rather than wait for a full resync, re-add the key to the
+		// queue to be processed.
+		ctrl.snapshotCopyQueue.AddRateLimited(key)
+		klog.V(4).Infof("Failed to sync snapshot copy %q, will retry again: %v", key, err)
+	} else {
+		// Finally, if no error occurs we forget this item so it does not
+		// get queued again until another change happens.
+		ctrl.snapshotCopyQueue.Forget(key)
+	}
+}
+
+// syncSnapshotCopyByKey processes a VolumeSnapshotCopy request.
+func (ctrl *csiSnapshotCommonController) syncSnapshotCopyByKey(ctx context.Context, key string) error {
+	klog.V(5).Infof("syncSnapshotCopyByKey[%s]", key)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.Errorf("error getting namespace & name of snapshot copy %q to get snapshot copy from informer: %v", key, err)
+		return nil
+	}
+	snapshotCopy, err := ctrl.snapshotCopyLister.VolumeSnapshotCopies(namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		// Nothing is held on behalf of a deleted snapshot copy.
+		klog.V(4).Infof("snapshot copy %q deleted", key)
+		return nil
+	}
+	if err != nil {
+		klog.V(2).Infof("error getting snapshot copy %q from informer: %v", key, err)
+		return err
+	}
+
+	err = ctrl.syncSnapshotCopy(ctx, snapshotCopy)
+	if err != nil {
+		if apierrs.IsConflict(err) {
+			// Version conflict error happens quite often and the controller
+			// recovers from it easily.
+			klog.V(3).Infof("could not sync snapshot copy %q: %+v", key, err)
+		} else {
+			klog.Errorf("could not sync snapshot copy %q: %+v", key, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// syncSnapshotCopy starts the copy of the source snapshot once it is ready
+// to use, and then tracks the progress of the copy.
+func (ctrl *csiSnapshotCommonController) syncSnapshotCopy(ctx context.Context, snapshotCopy *crdv1.VolumeSnapshotCopy) error {
+	key := utils.SnapshotCopyKey(snapshotCopy)
+	klog.V(5).Infof("synchronizing VolumeSnapshotCopy[%s]", key)
+
+	if snapshotCopy.ObjectMeta.DeletionTimestamp != nil || isSnapshotCopyFinished(snapshotCopy) {
+		return nil
+	}
+
+	content, err := ctrl.getContentFromStore(utils.GetSnapshotContentNameForSnapshotCopy(snapshotCopy))
+	if err != nil {
+		return err
+	}
+	if content == nil {
+		content, err = ctrl.startSnapshotCopy(ctx, snapshotCopy)
+		if err != nil || content == nil {
+			return err
+		}
+	}
+	return ctrl.updateSnapshotCopyStatus(ctx, snapshotCopy, content)
+}
+
+// startSnapshotCopy creates the VolumeSnapshot and the VolumeSnapshotContent
+// of a snapshot copy. It returns (nil, nil) when the copy cannot be done and
+// must not be retried.
+func (ctrl *csiSnapshotCommonController) startSnapshotCopy(ctx context.Context, snapshotCopy *crdv1.VolumeSnapshotCopy) (*crdv1.VolumeSnapshotContent, error) {
+	key := utils.SnapshotCopyKey(snapshotCopy)
+
+	sourceContent, err := ctrl.getSnapshotCopySourceContent(snapshotCopy)
+	if err != nil {
+		ctrl.updateSnapshotCopyErrorStatusWithEvent(ctx, snapshotCopy, crdv1.VolumeSnapshotCopyPending, v1.EventTypeNormal, "SnapshotCopySourceNotReady", err.Error())
+		return nil, err
+	}
+
+	class, err := ctrl.getSnapshotClass(snapshotCopy.Spec.VolumeSnapshotClassName)
+	if err != nil {
+		ctrl.updateSnapshotCopyErrorStatusWithEvent(ctx, snapshotCopy, crdv1.VolumeSnapshotCopyPending, v1.EventTypeWarning, "GetSnapshotClassFailed", err.Error())
+		return nil, err
+	}
+	if err := ctrl.checkSnapshotClassAllowed(class, snapshotCopy.Namespace); err != nil {
+		ctrl.updateSnapshotCopyErrorStatusWithEvent(ctx, snapshotCopy, crdv1.VolumeSnapshotCopyPending, v1.EventTypeWarning, "SnapshotClassNotAllowed", err.Error())
+		return nil, err
+	}
+	if class.Driver != sourceContent.Spec.Driver {
+		msg := fmt.Sprintf("VolumeSnapshotClass %s of driver %s cannot be used to copy a snapshot of driver %s", class.Name, class.Driver, sourceContent.Spec.Driver)
+		return nil, ctrl.updateSnapshotCopyErrorStatusWithEvent(ctx, snapshotCopy, crdv1.VolumeSnapshotCopyFailed, v1.EventTypeWarning, "SnapshotCopyDriverMismatch", msg)
+	}
+
+	contentName := utils.GetSnapshotContentNameForSnapshotCopy(snapshotCopy)
+	snapshot, err := ctrl.getOrCreateSnapshotForSnapshotCopy(ctx, snapshotCopy, contentName, class)
+	if err != nil {
+		ctrl.updateSnapshotCopyErrorStatusWithEvent(ctx, snapshotCopy, crdv1.VolumeSnapshotCopyPending, v1.EventTypeWarning, "CreateSnapshotFailed", err.Error())
+		return nil, err
+	}
+	if snapshot.Spec.Source.VolumeSnapshotContentName == nil || *snapshot.Spec.Source.VolumeSnapshotContentName != contentName {
+		msg := fmt.Sprintf("VolumeSnapshot %s already exists and is not the copy of %s", key, snapshotCopy.Spec.Source.VolumeSnapshotName)
+		return nil, ctrl.updateSnapshotCopyErrorStatusWithEvent(ctx, snapshotCopy, crdv1.VolumeSnapshotCopyFailed, v1.EventTypeWarning, "SnapshotCopyTargetConflict", msg)
+	}
+
+	content, err := ctrl.createSnapshotCopyContent(ctx, snapshotCopy, snapshot, sourceContent, class)
+	if err != nil {
+		ctrl.updateSnapshotCopyErrorStatusWithEvent(ctx, snapshotCopy, crdv1.VolumeSnapshotCopyPending, v1.EventTypeWarning, "CreateSnapshotContentFailed", err.Error())
+		return nil, err
+	}
+
+	msg := fmt.Sprintf("Waiting for snapshot %s to be copied by the CSI driver.", snapshotCopy.Spec.Source.VolumeSnapshotName)
+	ctrl.eventRecorder.Event(snapshotCopy, v1.EventTypeNormal, "CopyingSnapshot", msg)
+	return content, nil
+}
+
+// getSnapshotCopySourceContent returns the VolumeSnapshotContent of the source
+// snapshot of a snapshot copy, or an error if the source snapshot is not ready
+// to be copied yet.
+func (ctrl *csiSnapshotCommonController) getSnapshotCopySourceContent(snapshotCopy *crdv1.VolumeSnapshotCopy) (*crdv1.VolumeSnapshotContent, error) {
+	sourceName := snapshotCopy.Spec.Source.VolumeSnapshotName
+	snapshot, err := ctrl.snapshotLister.VolumeSnapshots(snapshotCopy.Namespace).Get(sourceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source snapshot %s: %v", sourceName, err)
+	}
+	if !utils.IsSnapshotReady(snapshot) || !utils.IsBoundVolumeSnapshotContentNameSet(snapshot) {
+		return nil, fmt.Errorf("source snapshot %s is not ready to use", sourceName)
+	}
+	content, err := ctrl.getContentFromStore(*snapshot.Status.BoundVolumeSnapshotContentName)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil || content.Spec.VolumeSnapshotRef.UID != snapshot.UID {
+		return nil, fmt.Errorf("source snapshot %s is not bound to VolumeSnapshotContent %s", sourceName, *snapshot.Status.BoundVolumeSnapshotContentName)
+	}
+	if content.Status == nil || content.Status.SnapshotHandle == nil {
+		return nil, fmt.Errorf("snapshot handle of source snapshot %s is unknown", sourceName)
+	}
+	return content, nil
+}
+
+// getOrCreateSnapshotForSnapshotCopy returns the VolumeSnapshot which
+// represents the copied snapshot, and creates it if it does not exist.
+func (ctrl *csiSnapshotCommonController) getOrCreateSnapshotForSnapshotCopy(ctx context.Context, snapshotCopy *crdv1.VolumeSnapshotCopy, contentName string, class *crdv1.VolumeSnapshotClass) (*crdv1.VolumeSnapshot, error) {
+	snapshot, err := ctrl.snapshotLister.VolumeSnapshots(snapshotCopy.Namespace).Get(snapshotCopy.Name)
+	if err == nil {
+		return snapshot, nil
+	}
+	if !apierrs.IsNotFound(err) {
+		return nil, err
+	}
+
+	snapshot = &crdv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshotCopy.Name,
+			Namespace: snapshotCopy.Namespace,
+		},
+		Spec: crdv1.VolumeSnapshotSpec{
+			Source: crdv1.VolumeSnapshotSource{
+				VolumeSnapshotContentName: &contentName,
+			},
+			VolumeSnapshotClassName: &class.Name,
+		},
+	}
+	klog.V(5).Infof("getOrCreateSnapshotForSnapshotCopy [%s]: creating volume snapshot", utils.SnapshotCopyKey(snapshotCopy))
+	newSnapshot, err := ctrl.clientset.SnapshotV1().VolumeSnapshots(snapshot.Namespace).Create(ctx, snapshot, metav1.CreateOptions{})
+	if apierrs.IsAlreadyExists(err) {
+		// The informer cache is out of date.
+		newSnapshot, err = ctrl.clientset.SnapshotV1().VolumeSnapshots(snapshot.Namespace).Get(ctx, snapshot.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volume snapshot %s: %v", utils.SnapshotKey(snapshot), err)
+	}
+	return newSnapshot, nil
+}
+
+// createSnapshotCopyContent creates the VolumeSnapshotContent which asks the
+// sidecar to copy the snapshot of sourceContent using the given class.
+func (ctrl *csiSnapshotCommonController) createSnapshotCopyContent(ctx context.Context, snapshotCopy *crdv1.VolumeSnapshotCopy, snapshot *crdv1.VolumeSnapshot, sourceContent *crdv1.VolumeSnapshotContent, class *crdv1.VolumeSnapshotClass) (*crdv1.VolumeSnapshotContent, error) {
+	contentName := utils.GetSnapshotContentNameForSnapshotCopy(snapshotCopy)
+	klog.Infof("createSnapshotCopyContent: Creating content %s for snapshot copy %s through the plugin ...", contentName, utils.SnapshotCopyKey(snapshotCopy))
+
+	snapshotRef, err := ref.GetReference(scheme.Scheme, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	snapshotterSecretRef, err := utils.GetSecretReference(utils.SnapshotterSecretParams, class.Parameters, contentName, snapshot, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceSnapshotHandle := *sourceContent.Status.SnapshotHandle
+	content := &crdv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: contentName,
+		},
+		Spec: crdv1.VolumeSnapshotContentSpec{
+			VolumeSnapshotRef: *snapshotRef,
+			Source: crdv1.VolumeSnapshotContentSource{
+				SourceSnapshotHandle: &sourceSnapshotHandle,
+			},
+			VolumeSnapshotClassName: &class.Name,
+			DeletionPolicy:          class.DeletionPolicy,
+			Driver:                  class.Driver,
+			SourceVolumeMode:        sourceContent.Spec.SourceVolumeMode,
+			NodeAffinity:            sourceContent.Spec.NodeAffinity,
+		},
+	}
+
+	// The copy is made by the sidecar which manages the source snapshot.
+	if nodeName, ok := sourceContent.Labels[utils.VolumeSnapshotContentManagedByLabel]; ok && ctrl.enableDistributedSnapshotting {
+		content.Labels = map[string]string{
+			utils.VolumeSnapshotContentManagedByLabel: nodeName,
+		}
+	}
+
+	if snapshotterSecretRef != nil {
+		metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnDeletionSecretRefName, snapshotterSecretRef.Name)
+		metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnDeletionSecretRefNamespace, snapshotterSecretRef.Namespace)
+		if err := utils.SetSecretProviderAnnotation(&content.ObjectMeta, utils.AnnDeletionSecretRefProvider, class.Parameters); err != nil {
+			return nil, err
+		}
+	}
+
+	newContent, err := ctrl.clientset.SnapshotV1().VolumeSnapshotContents().Create(ctx, content, metav1.CreateOptions{})
+	if apierrs.IsAlreadyExists(err) {
+		klog.V(3).Infof("volume snapshot content %q for snapshot copy %q already exists, reusing", contentName, utils.SnapshotCopyKey(snapshotCopy))
+		newContent, err = content, nil
+	}
+	if err != nil {
+		return nil, newControllerUpdateError(contentName, err.Error())
+	}
+
+	if _, err = ctrl.storeContentUpdate(newContent); err != nil {
+		klog.Errorf("failed to update content store %v", err)
+	}
+	return newContent, nil
+}
+
+// updateSnapshotCopyStatus mirrors the status of the content of a snapshot
+// copy into the status of the VolumeSnapshotCopy.
+func (ctrl *csiSnapshotCommonController) updateSnapshotCopyStatus(ctx context.Context, snapshotCopy *crdv1.VolumeSnapshotCopy, content *crdv1.VolumeSnapshotContent) error {
+	klog.V(5).Infof("updateSnapshotCopyStatus[%s]", utils.SnapshotCopyKey(snapshotCopy))
+
+	newStatus := &crdv1.VolumeSnapshotCopyStatus{}
+	if snapshotCopy.Status != nil {
+		newStatus = snapshotCopy.Status.DeepCopy()
+	}
+	now := metav1.Now()
+	contentName := content.Name
+	newStatus.BoundVolumeSnapshotContentName = &contentName
+	newStatus.SourceSnapshotHandle = content.Spec.Source.SourceSnapshotHandle
+	if newStatus.StartTime == nil {
+		newStatus.StartTime = &now
+	}
+	newStatus.Phase = crdv1.VolumeSnapshotCopyInProgress
+	newStatus.Error = nil
+	readyToUse := false
+	if content.Status != nil {
+		newStatus.SnapshotHandle = content.Status.SnapshotHandle
+		if content.Status.RestoreSize != nil {
+			newStatus.RestoreSize = resource.NewQuantity(*content.Status.RestoreSize, resource.BinarySI)
+		}
+		if content.Status.ReadyToUse != nil {
+			readyToUse = *content.Status.ReadyToUse
+		}
+		newStatus.Error = content.Status.Error.DeepCopy()
+	}
+	newStatus.ReadyToUse = &readyToUse
+	if readyToUse {
+		newStatus.Phase = crdv1.VolumeSnapshotCopyCompleted
+		newStatus.Error = nil
+		if newStatus.CompletionTime == nil {
+			newStatus.CompletionTime = &now
+		}
+	}
+	if snapshotCopy.Status != nil && equality.Semantic.DeepEqual(snapshotCopy.Status, newStatus) {
+		return nil
+	}
+
+	snapshotCopyClone := snapshotCopy.DeepCopy()
+	snapshotCopyClone.Status = newStatus
+	newSnapshotCopy, err := ctrl.clientset.SnapshotV1().VolumeSnapshotCopies(snapshotCopy.Namespace).UpdateStatus(ctx, snapshotCopyClone, metav1.UpdateOptions{})
+	if err != nil {
+		return newControllerUpdateError(utils.SnapshotCopyKey(snapshotCopy), err.Error())
+	}
+	if newSnapshotCopy.Status.Phase == crdv1.VolumeSnapshotCopyCompleted {
+		msg := fmt.Sprintf("Snapshot %s was copied to %s.", snapshotCopy.Spec.Source.VolumeSnapshotName, utils.SnapshotCopyKey(snapshotCopy))
+		ctrl.eventRecorder.Event(newSnapshotCopy, v1.EventTypeNormal, "SnapshotCopyCompleted", msg)
+	}
+	return nil
+}
+
+// updateSnapshotCopyErrorStatusWithEvent saves the error and the given phase
+// in the status of a snapshot copy and emits the given event. It returns the
+// error of the status update.
+func (ctrl *csiSnapshotCommonController) updateSnapshotCopyErrorStatusWithEvent(ctx context.Context, snapshotCopy *crdv1.VolumeSnapshotCopy, phase crdv1.VolumeSnapshotCopyPhase, eventtype, reason, message string) error {
+	klog.V(5).Infof("updateSnapshotCopyErrorStatusWithEvent[%s]", utils.SnapshotCopyKey(snapshotCopy))
+
+	if snapshotCopy.Status != nil && snapshotCopy.Status.Phase == phase && snapshotCopy.Status.Error != nil && *snapshotCopy.Status.Error.Message == message {
+		klog.V(4).Infof("updateSnapshotCopyErrorStatusWithEvent[%s]: the same error %v is already set", utils.SnapshotCopyKey(snapshotCopy), snapshotCopy.Status.Error)
+		return nil
+	}
+	snapshotCopyClone := snapshotCopy.DeepCopy()
+	if snapshotCopyClone.Status == nil {
+		snapshotCopyClone.Status = &crdv1.VolumeSnapshotCopyStatus{}
+	}
+	snapshotCopyClone.Status.Phase = phase
+	snapshotCopyClone.Status.Error = &crdv1.VolumeSnapshotError{
+		Time: &metav1.Time{
+			Time: time.Now(),
+		},
+		Message: &message,
+	}
+	_, err := ctrl.clientset.SnapshotV1().VolumeSnapshotCopies(snapshotCopy.Namespace).UpdateStatus(ctx, snapshotCopyClone, metav1.UpdateOptions{})
+
+	// Emit the event even if the status update fails so that user can see the error
+	ctrl.eventRecorder.Event(snapshotCopyClone, eventtype, reason, message)
+
+	if err != nil {
+		klog.V(4).Infof("updating VolumeSnapshotCopy[%s] error status failed %v", utils.SnapshotCopyKey(snapshotCopy), err)
+		return err
+	}
+	return nil
+}
+
+// isSnapshotCopyFinished returns true when the snapshot copy has completed or
+// has failed permanently.
+func isSnapshotCopyFinished(snapshotCopy *crdv1.VolumeSnapshotCopy) bool {
+	return snapshotCopy.Status != nil &&
+		(snapshotCopy.Status.Phase == crdv1.VolumeSnapshotCopyCompleted || snapshotCopy.Status.Phase == crdv1.VolumeSnapshotCopyFailed)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	snapshotscheme "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/scheme"
+	storagelisters "github.com/kubernetes-csi/external-snapshotter/client/v8/listers/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newSnapshotCopySetup returns a helper setup whose listers contain the ready
+// snapshot "snap-1" bound to "content-1", and the snapshot classes "gold" of
+// the mock driver and "other" of another driver.
+func newSnapshotCopySetup(t *testing.T, sourceReady bool) *helperSetup {
+	snapshotscheme.AddToScheme(scheme.Scheme)
+	h := newHelperSetup(t)
+
+	size := int64(1024)
+	sourceContent := newContent("content-1", "snapuid-1", "snap-1", "snap-1-handle", classSilver, "", "volume-handle-1", crdv1.VolumeSnapshotContentDelete, nil, &size, false, true)
+	modeFilesystem := v1.PersistentVolumeFilesystem
+	sourceContent.Spec.SourceVolumeMode = &modeFilesystem
+	h.ctrl.contentStore.Add(sourceContent)
+
+	snapshotIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	snapshotIndexer.Add(newSnapshot("snap-1", "snapuid-1", "claim-1", "", classSilver, "content-1", &sourceReady, nil, nil, nil, false, false, nil))
+	h.ctrl.snapshotLister = storagelisters.NewVolumeSnapshotLister(snapshotIndexer)
+
+	classIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	gold := newSnapshotClass(classGold, "gold-uid", mockDriverName, false)
+	gold.DeletionPolicy = crdv1.VolumeSnapshotContentRetain
+	gold.Parameters = map[string]string{"tier": "archive"}
+	other := newSnapshotClass("other", "other-uid", "other.csi.driver", false)
+	for _, class := range []*crdv1.VolumeSnapshotClass{gold, other} {
+		// VolumeSnapshotClasses are cluster scoped.
+		class.Namespace = ""
+		classIndexer.Add(class)
+	}
+	h.ctrl.classLister = storagelisters.NewVolumeSnapshotClassLister(classIndexer)
+	return h
+}
+
+func newTestSnapshotCopy(className string) *crdv1.VolumeSnapshotCopy {
+	return &crdv1.VolumeSnapshotCopy{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap-1-archive", Namespace: testNamespace, UID: "copy-uid", ResourceVersion: "1"},
+		Spec: crdv1.VolumeSnapshotCopySpec{
+			Source:                  crdv1.VolumeSnapshotCopySource{VolumeSnapshotName: "snap-1"},
+			VolumeSnapshotClassName: className,
+		},
+	}
+}
+
+// addSnapshotCopyReactor serves status updates of the given snapshot copy,
+// which is updated in place.
+func addSnapshotCopyReactor(h *helperSetup, snapshotCopy **crdv1.VolumeSnapshotCopy) {
+	h.client.PrependReactor("*", "volumesnapshotcopies", func(action core.Action) (bool, runtime.Object, error) {
+		if action, ok := action.(core.UpdateAction); ok {
+			*snapshotCopy = action.GetObject().(*crdv1.VolumeSnapshotCopy).DeepCopy()
+		}
+		return true, (*snapshotCopy).DeepCopy(), nil
+	})
+}
+
+func TestSyncSnapshotCopy(t *testing.T) {
+	h := newSnapshotCopySetup(t, true)
+	snapshotCopy := newTestSnapshotCopy(classGold)
+	addSnapshotCopyReactor(h, &snapshotCopy)
+
+	if err := h.ctrl.syncSnapshotCopy(context.Background(), snapshotCopy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	contentName := "snapcontent-copy-copy-uid"
+	snapshot, found := h.reactor.snapshots["snap-1-archive"]
+	if !found {
+		t.Fatalf("snapshot snap-1-archive was not created")
+	}
+	if snapshot.Spec.Source.VolumeSnapshotContentName == nil || *snapshot.Spec.Source.VolumeSnapshotContentName != contentName {
+		t.Errorf("expected snapshot to be pre-bound to %s, got %v", contentName, snapshot.Spec.Source.VolumeSnapshotContentName)
+	}
+
+	content, found := h.reactor.contents[contentName]
+	if !found {
+		t.Fatalf("snapshot content %s was not created", contentName)
+	}
+	if content.Spec.Source.SourceSnapshotHandle == nil || *content.Spec.Source.SourceSnapshotHandle != "snap-1-handle" {
+		t.Errorf("expected source snapshot handle snap-1-handle, got %v", content.Spec.Source.SourceSnapshotHandle)
+	}
+	if content.Spec.Source.VolumeHandle != nil || content.Spec.Source.SnapshotHandle != nil {
+		t.Errorf("expected only the source snapshot handle to be set, got %+v", content.Spec.Source)
+	}
+	if content.Spec.VolumeSnapshotClassName == nil || *content.Spec.VolumeSnapshotClassName != classGold {
+		t.Errorf("expected class %s, got %v", classGold, content.Spec.VolumeSnapshotClassName)
+	}
+	if content.Spec.DeletionPolicy != crdv1.VolumeSnapshotContentRetain {
+		t.Errorf("expected deletion policy of the target class, got %s", content.Spec.DeletionPolicy)
+	}
+	if content.Spec.VolumeSnapshotRef.Name != "snap-1-archive" || content.Spec.VolumeSnapshotRef.Namespace != testNamespace {
+		t.Errorf("unexpected snapshot reference %+v", content.Spec.VolumeSnapshotRef)
+	}
+	if content.Spec.SourceVolumeMode == nil || *content.Spec.SourceVolumeMode != v1.PersistentVolumeFilesystem {
+		t.Errorf("expected source volume mode of the source snapshot, got %v", content.Spec.SourceVolumeMode)
+	}
+
+	status := snapshotCopy.Status
+	if status == nil || status.Phase != crdv1.VolumeSnapshotCopyInProgress {
+		t.Fatalf("expected phase InProgress, got status %+v", status)
+	}
+	if status.BoundVolumeSnapshotContentName == nil || *status.BoundVolumeSnapshotContentName != contentName {
+		t.Errorf("expected bound content %s, got %v", contentName, status.BoundVolumeSnapshotContentName)
+	}
+	if status.StartTime == nil || status.CompletionTime != nil {
+		t.Errorf("expected start time only, got %v and %v", status.StartTime, status.CompletionTime)
+	}
+
+	// The sidecar reports the copy as ready.
+	size := int64(2048)
+	ready := true
+	handle := "snap-1-archive-handle"
+	content = content.DeepCopy()
+	content.Status = &crdv1.VolumeSnapshotContentStatus{SnapshotHandle: &handle, RestoreSize: &size, ReadyToUse: &ready}
+	h.ctrl.contentStore.Update(content)
+
+	if err := h.ctrl.syncSnapshotCopy(context.Background(), snapshotCopy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status = snapshotCopy.Status
+	if status.Phase != crdv1.VolumeSnapshotCopyCompleted || status.CompletionTime == nil {
+		t.Fatalf("expected phase Completed with a completion time, got status %+v", status)
+	}
+	if status.SnapshotHandle == nil || *status.SnapshotHandle != handle {
+		t.Errorf("expected snapshot handle %s, got %v", handle, status.SnapshotHandle)
+	}
+	if status.RestoreSize == nil || status.RestoreSize.Value() != size {
+		t.Errorf("expected restore size %d, got %v", size, status.RestoreSize)
+	}
+}
+
+func TestSyncSnapshotCopyErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		sourceReady   bool
+		className     string
+		expectErr     bool
+		expectedPhase crdv1.VolumeSnapshotCopyPhase
+	}{
+		{
+			name:          "source snapshot not ready",
+			sourceReady:   false,
+			className:     classGold,
+			expectErr:     true,
+			expectedPhase: crdv1.VolumeSnapshotCopyPending,
+		},
+		{
+			name:          "missing target class",
+			sourceReady:   true,
+			className:     classNonExisting,
+			expectErr:     true,
+			expectedPhase: crdv1.VolumeSnapshotCopyPending,
+		},
+		{
+			name:          "target class of another driver",
+			sourceReady:   true,
+			className:     "other",
+			expectErr:     false,
+			expectedPhase: crdv1.VolumeSnapshotCopyFailed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := newSnapshotCopySetup(t, test.sourceReady)
+			snapshotCopy := newTestSnapshotCopy(test.className)
+			addSnapshotCopyReactor(h, &snapshotCopy)
+
+			err := h.ctrl.syncSnapshotCopy(context.Background(), snapshotCopy)
+			if test.expectErr != (err != nil) {
+				t.Errorf("expected error %v, got %v", test.expectErr, err)
+			}
+			if snapshotCopy.Status == nil || snapshotCopy.Status.Phase != test.expectedPhase || snapshotCopy.Status.Error == nil {
+				t.Errorf("expected phase %s with an error, got status %+v", test.expectedPhase, snapshotCopy.Status)
+			}
+			if len(h.reactor.contents) != 0 {
+				t.Errorf("expected no content to be created, got %d", len(h.reactor.contents))
+			}
+
+			// A failed copy is not retried.
+			if test.expectedPhase == crdv1.VolumeSnapshotCopyFailed {
+				if err := h.ctrl.syncSnapshotCopy(context.Background(), snapshotCopy); err != nil {
+					t.Errorf("unexpected error syncing a failed copy: %v", err)
+				}
+				if _, found := h.reactor.snapshots[snapshotCopy.Name]; found {
+					t.Errorf("unexpected snapshot created for a failed copy")
+				}
+			}
+		})
+	}
+}
+
+func TestGetSnapshotContentNameForSnapshotCopy(t *testing.T) {
+	if name := utils.GetSnapshotContentNameForSnapshotCopy(newTestSnapshotCopy(classGold)); name != "snapcontent-copy-copy-uid" {
+		t.Errorf("unexpected content name %s", name)
+	}
+}
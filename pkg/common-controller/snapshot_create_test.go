@@ -22,6 +22,7 @@ import (
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -203,3 +204,79 @@ func TestCreateSnapshotSync(t *testing.T) {
 	}
 	runSyncTests(t, tests, snapshotClasses, nil)
 }
+
+func TestCreateSnapshotSyncWithSecretProvider(t *testing.T) {
+	fileSecretClass := "file-secret-class"
+	classes := append([]*crdv1.VolumeSnapshotClass{{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeSnapshotClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fileSecretClass,
+		},
+		Driver: mockDriverName,
+		Parameters: map[string]string{
+			utils.PrefixedSnapshotterSecretNameKey:      "secret",
+			utils.PrefixedSnapshotterSecretNamespaceKey: "default",
+			utils.PrefixedSecretProviderKey:             utils.CredentialProviderFile,
+		},
+		DeletionPolicy: crdv1.VolumeSnapshotContentDelete,
+	}}, snapshotClasses...)
+
+	tests := []controllerTest{
+		{
+			name:            "6-3 - successful create snapshot with the secret of a file credential provider",
+			initialContents: nocontents,
+			expectedContents: withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid6-3", "snapuid6-3", "snap6-3", "sid6-3", fileSecretClass, "", "pv-handle6-3", deletionPolicy, nil, nil, false, false),
+				map[string]string{
+					utils.AnnDeletionSecretRefName:      "secret",
+					utils.AnnDeletionSecretRefNamespace: "default",
+					utils.AnnDeletionSecretRefProvider:  utils.CredentialProviderFile,
+				}),
+			initialSnapshots:  newSnapshotArray("snap6-3", "snapuid6-3", "claim6-3", "", fileSecretClass, "", &False, nil, nil, nil, false, true, nil),
+			expectedSnapshots: newSnapshotArray("snap6-3", "snapuid6-3", "claim6-3", "", fileSecretClass, "snapcontent-snapuid6-3", &False, nil, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim6-3", "pvc-uid6-3", "1Gi", "volume6-3", v1.ClaimBound, &classEmpty),
+			initialVolumes:    newVolumeArray("volume6-3", "pv-uid6-3", "pv-handle6-3", "1Gi", "pvc-uid6-3", "claim6-3", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+			errors:            noerrors,
+			test:              testSyncSnapshot,
+		},
+	}
+	runSyncTests(t, tests, classes, nil)
+}
+
+func TestCreateSnapshotSyncWithSourceSecretTemplate(t *testing.T) {
+	templateSecretClass := "template-secret-class"
+	classes := append([]*crdv1.VolumeSnapshotClass{{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeSnapshotClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: templateSecretClass,
+		},
+		Driver: mockDriverName,
+		Parameters: map[string]string{
+			utils.PrefixedSnapshotterSecretNameKey:      "${pvc.name}-${pv.name}",
+			utils.PrefixedSnapshotterSecretNamespaceKey: "${pvc.namespace}",
+		},
+		DeletionPolicy: crdv1.VolumeSnapshotContentDelete,
+	}}, snapshotClasses...)
+
+	tests := []controllerTest{
+		{
+			name:            "6-4 - successful create snapshot with a secret templated on the source PVC and PV",
+			initialContents: nocontents,
+			expectedContents: withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid6-4", "snapuid6-4", "snap6-4", "sid6-4", templateSecretClass, "", "pv-handle6-4", deletionPolicy, nil, nil, false, false),
+				map[string]string{
+					utils.AnnDeletionSecretRefName:      "claim6-4-volume6-4",
+					utils.AnnDeletionSecretRefNamespace: "default",
+				}),
+			initialSnapshots:  newSnapshotArray("snap6-4", "snapuid6-4", "claim6-4", "", templateSecretClass, "", &False, nil, nil, nil, false, true, nil),
+			expectedSnapshots: newSnapshotArray("snap6-4", "snapuid6-4", "claim6-4", "", templateSecretClass, "snapcontent-snapuid6-4", &False, nil, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim6-4", "pvc-uid6-4", "1Gi", "volume6-4", v1.ClaimBound, &classEmpty),
+			initialVolumes:    newVolumeArray("volume6-4", "pv-uid6-4", "pv-handle6-4", "1Gi", "pvc-uid6-4", "claim6-4", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+			errors:            noerrors,
+			test:              testSyncSnapshot,
+		},
+	}
+	runSyncTests(t, tests, classes, nil)
+}
@@ -21,9 +21,12 @@ import (
 	"testing"
 
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 )
 
 var class1Parameters = map[string]string{
@@ -352,6 +355,85 @@ func TestDeleteSync(t *testing.T) {
 			errors:            noerrors,
 			test:              testSyncSnapshot,
 		},
+		{
+			name:              "3-13 - (dynamic) snapshot with deletion protection keeps its content and finalizers",
+			initialContents:   newContentArray("snapcontent-snapuid3-13", "snapuid3-13", "snap3-13", "sid3-13", validSecretClass, "", "volume3-13", deletePolicy, nil, nil, true),
+			expectedContents:  newContentArray("snapcontent-snapuid3-13", "snapuid3-13", "snap3-13", "sid3-13", validSecretClass, "", "volume3-13", deletePolicy, nil, nil, true),
+			initialSnapshots:  withSnapshotAnnotations(newSnapshotArray("snap3-13", "snapuid3-13", "claim3-13", "", validSecretClass, "snapcontent-snapuid3-13", &True, nil, nil, nil, false, true, &timeNowMetav1), map[string]string{utils.AnnDeletionProtection: "true"}),
+			expectedSnapshots: withSnapshotAnnotations(newSnapshotArray("snap3-13", "snapuid3-13", "claim3-13", "", validSecretClass, "snapcontent-snapuid3-13", &True, nil, nil, nil, false, true, &timeNowMetav1), map[string]string{utils.AnnDeletionProtection: "true"}),
+			initialClaims:     newClaimArray("claim3-13", "pvc-uid3-13", "1Gi", "volume3-13", v1.ClaimBound, &classEmpty),
+			expectedEvents:    []string{"Warning SnapshotDeletionProtected"},
+			initialSecrets:    []*v1.Secret{secret()},
+			errors:            noerrors,
+			test:              testSyncSnapshot,
+		},
+		{
+			name:              "3-14 - (dynamic) snapshot whose content has deletion protection keeps the content and its finalizers",
+			initialContents:   withContentAnnotations(newContentArray("snapcontent-snapuid3-14", "snapuid3-14", "snap3-14", "sid3-14", validSecretClass, "", "volume3-14", deletePolicy, nil, nil, true), map[string]string{utils.AnnDeletionProtection: "true"}),
+			expectedContents:  withContentAnnotations(newContentArray("snapcontent-snapuid3-14", "snapuid3-14", "snap3-14", "sid3-14", validSecretClass, "", "volume3-14", deletePolicy, nil, nil, true), map[string]string{utils.AnnDeletionProtection: "true"}),
+			initialSnapshots:  newSnapshotArray("snap3-14", "snapuid3-14", "claim3-14", "", validSecretClass, "snapcontent-snapuid3-14", &True, nil, nil, nil, false, true, &timeNowMetav1),
+			expectedSnapshots: newSnapshotArray("snap3-14", "snapuid3-14", "claim3-14", "", validSecretClass, "snapcontent-snapuid3-14", &True, nil, nil, nil, false, true, &timeNowMetav1),
+			initialClaims:     newClaimArray("claim3-14", "pvc-uid3-14", "1Gi", "volume3-14", v1.ClaimBound, &classEmpty),
+			expectedEvents:    []string{"Warning SnapshotDeletionProtected"},
+			initialSecrets:    []*v1.Secret{secret()},
+			errors:            noerrors,
+			test:              testSyncSnapshot,
+		},
+		{
+			name:            "3-15 - (dynamic) deletion grace period of the snapshot is copied to the content",
+			initialContents: newContentArray("snapcontent-snapuid3-15", "snapuid3-15", "snap3-15", "sid3-15", validSecretClass, "", "volume3-15", retainPolicy, nil, nil, true),
+			expectedContents: withContentAnnotations(newContentArray("snapcontent-snapuid3-15", "snapuid3-15", "snap3-15", "sid3-15", validSecretClass, "", "volume3-15", retainPolicy, nil, nil, true),
+				map[string]string{
+					"snapshot.storage.kubernetes.io/volumesnapshot-being-deleted": "yes",
+					utils.AnnDeletionGracePeriod:                                  "24h",
+				}),
+			initialSnapshots:  withSnapshotAnnotations(newSnapshotArray("snap3-15", "snapuid3-15", "claim3-15", "", validSecretClass, "snapcontent-snapuid3-15", &False, nil, nil, nil, false, true, &timeNowMetav1), map[string]string{utils.AnnDeletionGracePeriod: "24h"}),
+			expectedSnapshots: withSnapshotAnnotations(newSnapshotArray("snap3-15", "snapuid3-15", "claim3-15", "", validSecretClass, "snapcontent-snapuid3-15", &False, nil, nil, nil, false, false, &timeNowMetav1), map[string]string{utils.AnnDeletionGracePeriod: "24h"}),
+			initialClaims:     newClaimArray("claim3-15", "pvc-uid3-15", "1Gi", "volume3-15", v1.ClaimBound, &classEmpty),
+			expectedEvents:    noevents,
+			initialSecrets:    []*v1.Secret{secret()},
+			errors:            noerrors,
+			test:              testSyncSnapshot,
+		},
+		{
+			name:              "3-16 - (dynamic) snapshot whose content is under legal hold keeps the content and its finalizers",
+			initialContents:   withContentAnnotations(newContentArray("snapcontent-snapuid3-16", "snapuid3-16", "snap3-16", "sid3-16", validSecretClass, "", "volume3-16", deletePolicy, nil, nil, true), map[string]string{utils.AnnLegalHold: "case-42"}),
+			expectedContents:  withContentAnnotations(newContentArray("snapcontent-snapuid3-16", "snapuid3-16", "snap3-16", "sid3-16", validSecretClass, "", "volume3-16", deletePolicy, nil, nil, true), map[string]string{utils.AnnLegalHold: "case-42"}),
+			initialSnapshots:  newSnapshotArray("snap3-16", "snapuid3-16", "claim3-16", "", validSecretClass, "snapcontent-snapuid3-16", &True, nil, nil, nil, false, true, &timeNowMetav1),
+			expectedSnapshots: newSnapshotArray("snap3-16", "snapuid3-16", "claim3-16", "", validSecretClass, "snapcontent-snapuid3-16", &True, nil, nil, nil, false, true, &timeNowMetav1),
+			initialClaims:     newClaimArray("claim3-16", "pvc-uid3-16", "1Gi", "volume3-16", v1.ClaimBound, &classEmpty),
+			expectedEvents:    []string{"Warning SnapshotDeletionRefused"},
+			initialSecrets:    []*v1.Secret{secret()},
+			errors:            noerrors,
+			test:              testSyncSnapshot,
+		},
 	}
 	runSyncTests(t, tests, snapshotClasses, nil)
 }
+
+func TestDeletionProtectedEventOnce(t *testing.T) {
+	snapshot := withSnapshotAnnotations(newSnapshotArray("snap3-17", "snapuid3-17", "claim3-17", "", validSecretClass, "", &True, nil, nil, nil, false, true, &timeNowMetav1),
+		map[string]string{utils.AnnDeletionProtection: "true"})[0]
+
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), fake.NewSimpleClientset(snapshot), nil, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	recorder := ctrl.eventRecorder.(*record.FakeRecorder)
+	process := func(expectedEvents int) {
+		t.Helper()
+		if err := ctrl.processSnapshotWithDeletionTimestamp(snapshot); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(recorder.Events) != expectedEvents {
+			t.Errorf("expected %d events, got %d", expectedEvents, len(recorder.Events))
+		}
+	}
+
+	// The protected deletion is reported once while the protection lasts.
+	process(1)
+	process(1)
+	// A snapshot created again with the same name is reported again.
+	ctrl.deleteSnapshot(snapshot)
+	process(2)
+}
@@ -17,8 +17,14 @@ limitations under the License.
 package common_controller
 
 import (
-	v1 "k8s.io/api/core/v1"
 	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Test single call to checkAndUpdateSnapshotClass.
@@ -80,3 +86,87 @@ func TestUpdateSnapshotClass(t *testing.T) {
 
 	runUpdateSnapshotClassTests(t, tests, snapshotClasses)
 }
+
+// Test checkAndUpdateSnapshotClass with the namespace policy of
+// VolumeSnapshotClasses enabled. The namespace of the snapshots is labeled
+// team=a.
+func TestUpdateSnapshotClassWithNamespacePolicy(t *testing.T) {
+	policyClasses := append([]*crdv1.VolumeSnapshotClass{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "restricted",
+				Annotations: map[string]string{utils.AllowedNamespacesSnapshotClassAnnotation: "team=b"},
+			},
+			Driver:         mockDriverName,
+			DeletionPolicy: crdv1.VolumeSnapshotContentDelete,
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "team-a-default",
+				Annotations: map[string]string{
+					utils.AllowedNamespacesSnapshotClassAnnotation:    "team in (a)",
+					utils.DefaultForNamespacesSnapshotClassAnnotation: "team=a",
+				},
+			},
+			Driver:         mockDriverName,
+			DeletionPolicy: crdv1.VolumeSnapshotContentDelete,
+		},
+	}, snapshotClasses...)
+
+	tests := []controllerTest{
+		{
+			name:              "2-1 - snapshot class not allowed in the namespace",
+			initialContents:   nocontents,
+			initialSnapshots:  newSnapshotArray("snap2-1", "snapuid2-1", "claim2-1", "", "restricted", "", &False, nil, nil, nil, false, true, nil),
+			expectedSnapshots: newSnapshotArray("snap2-1", "snapuid2-1", "claim2-1", "", "restricted", "", &False, nil, nil, newVolumeError("VolumeSnapshotClass restricted is not allowed in namespace default"), false, true, nil),
+			initialClaims:     newClaimArray("claim2-1", "pvc-uid2-1", "1Gi", "volume2-1", v1.ClaimBound, &sameDriver),
+			initialVolumes:    newVolumeArray("volume2-1", "pv-uid2-1", "pv-handle2-1", "1Gi", "pvc-uid2-1", "claim2-1", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents:    []string{"Warning SnapshotClassNotAllowed"},
+			errors:            noerrors,
+			test:              testUpdateSnapshotClassWithNamespacePolicy,
+		},
+		{
+			name:              "2-2 - snapshot class allowed in the namespace",
+			initialContents:   nocontents,
+			initialSnapshots:  newSnapshotArray("snap2-2", "snapuid2-2", "claim2-2", "", "team-a-default", "", &False, nil, nil, nil, false, true, nil),
+			expectedSnapshots: newSnapshotArray("snap2-2", "snapuid2-2", "claim2-2", "", "team-a-default", "", &False, nil, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim2-2", "pvc-uid2-2", "1Gi", "volume2-2", v1.ClaimBound, &sameDriver),
+			initialVolumes:    newVolumeArray("volume2-2", "pv-uid2-2", "pv-handle2-2", "1Gi", "pvc-uid2-2", "claim2-2", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents:    noevents,
+			errors:            noerrors,
+			test:              testUpdateSnapshotClassWithNamespacePolicy,
+		},
+		{
+			name:              "2-3 - default class of the namespace takes precedence over the default class",
+			initialContents:   nocontents,
+			initialSnapshots:  newSnapshotArray("snap2-3", "snapuid2-3", "claim2-3", "", "", "", &False, nil, nil, nil, false, true, nil),
+			expectedSnapshots: newSnapshotArray("snap2-3", "snapuid2-3", "claim2-3", "", "team-a-default", "", &False, nil, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim2-3", "pvc-uid2-3", "1Gi", "volume2-3", v1.ClaimBound, &sameDriver),
+			initialVolumes:    newVolumeArray("volume2-3", "pv-uid2-3", "pv-handle2-3", "1Gi", "pvc-uid2-3", "claim2-3", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents:    noevents,
+			errors:            noerrors,
+			test:              testUpdateSnapshotClassWithNamespacePolicy,
+		},
+		{
+			name:              "2-4 - bound snapshot is not affected by the policy",
+			initialContents:   nocontents,
+			initialSnapshots:  newSnapshotArray("snap2-4", "snapuid2-4", "claim2-4", "", "restricted", "content2-4", &True, nil, nil, nil, false, true, nil),
+			expectedSnapshots: newSnapshotArray("snap2-4", "snapuid2-4", "claim2-4", "", "restricted", "content2-4", &True, nil, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim2-4", "pvc-uid2-4", "1Gi", "volume2-4", v1.ClaimBound, &sameDriver),
+			initialVolumes:    newVolumeArray("volume2-4", "pv-uid2-4", "pv-handle2-4", "1Gi", "pvc-uid2-4", "claim2-4", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents:    noevents,
+			errors:            noerrors,
+			test:              testUpdateSnapshotClassWithNamespacePolicy,
+		},
+	}
+
+	runUpdateSnapshotClassTests(t, tests, policyClasses)
+}
+
+func testUpdateSnapshotClassWithNamespacePolicy(ctrl *csiSnapshotCommonController, reactor *snapshotReactor, test controllerTest) error {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace, Labels: map[string]string{"team": "a"}}})
+	ctrl.namespaceLister = corelisters.NewNamespaceLister(indexer)
+	ctrl.enableSnapshotClassNamespacePolicy = true
+	return testUpdateSnapshotClass(ctrl, reactor, test)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dryrun keeps Kubernetes clients from changing anything in the
+// cluster. Reads are sent to the API server as usual, while every write is
+// handed to a Recorder instead and answered locally:
+//
+//   - create and update return the object of the request,
+//   - patch returns the current object, without the patch applied,
+//   - delete returns success.
+//
+// This lets a controller run against a live cluster and report what it
+// would do. Since the writes are not executed, the controller does not
+// observe their effects, and may plan the same write again on its next
+// sync.
+package dryrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	klog "k8s.io/klog/v2"
+)
+
+// Write is a request to the API server that was not executed.
+type Write struct {
+	// Verb is create, update, patch or delete.
+	Verb        string
+	Group       string
+	Version     string
+	Resource    string
+	Subresource string
+	Namespace   string
+	Name        string
+	// Body is the object of a create or update, or the patch.
+	Body []byte
+}
+
+// Recorder receives the writes that were not executed.
+type Recorder func(Write)
+
+// LogWrite is a Recorder which logs every write as a structured log entry.
+func LogWrite(w Write) {
+	klog.InfoS("Dry run: not executing write",
+		"verb", w.Verb,
+		"group", w.Group,
+		"version", w.Version,
+		"resource", w.Resource,
+		"subresource", w.Subresource,
+		"namespace", w.Namespace,
+		"name", w.Name,
+		"body", string(w.Body))
+}
+
+// WrapConfig returns a copy of config whose clients pass their writes to
+// record instead of sending them to the API server. Clients should use JSON
+// to make the recorded bodies readable.
+func WrapConfig(config *rest.Config, record Recorder) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &roundTripper{next: rt, record: record}
+	})
+	return config
+}
+
+type roundTripper struct {
+	next   http.RoundTripper
+	record Recorder
+}
+
+var verbs = map[string]string{
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "patch",
+	http.MethodDelete: "delete",
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb, ok := verbs[req.Method]
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	w := parsePath(req.URL.Path)
+	w.Verb = verb
+	w.Body = body
+	if w.Name == "" && verb == "create" {
+		w.Name = getObjectName(body)
+	}
+	t.record(w)
+
+	switch req.Method {
+	case http.MethodPatch:
+		get := req.Clone(req.Context())
+		get.Method = http.MethodGet
+		get.Body = nil
+		get.GetBody = nil
+		get.ContentLength = 0
+		get.Header.Del("Content-Type")
+		return t.next.RoundTrip(get)
+	case http.MethodDelete:
+		return newResponse(req, http.StatusOK, "application/json", []byte(`{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Success"}`)), nil
+	case http.MethodPost:
+		return newResponse(req, http.StatusCreated, req.Header.Get("Content-Type"), body), nil
+	default:
+		return newResponse(req, http.StatusOK, req.Header.Get("Content-Type"), body), nil
+	}
+}
+
+func newResponse(req *http.Request, code int, contentType string, body []byte) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	return &http.Response{
+		Status:        http.StatusText(code),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// parsePath returns the resource addressed by the path of an API request,
+// e.g. /apis/snapshot.storage.k8s.io/v1/namespaces/default/volumesnapshots/snap/status.
+func parsePath(path string) Write {
+	w := Write{}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		w.Version, parts = parts[1], parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		w.Group, w.Version, parts = parts[1], parts[2], parts[3:]
+	default:
+		return w
+	}
+	if len(parts) > 2 && parts[0] == "namespaces" {
+		w.Namespace, parts = parts[1], parts[2:]
+	}
+	if len(parts) > 0 {
+		w.Resource = parts[0]
+	}
+	if len(parts) > 1 {
+		w.Name = parts[1]
+	}
+	if len(parts) > 2 {
+		w.Subresource = strings.Join(parts[2:], "/")
+	}
+	return w
+}
+
+// getObjectName returns the name, or the generated name prefix, of a JSON
+// encoded object.
+func getObjectName(body []byte) string {
+	obj := struct {
+		Metadata struct {
+			Name         string `json:"name"`
+			GenerateName string `json:"generateName"`
+		} `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return ""
+	}
+	if obj.Metadata.Name != "" {
+		return obj.Metadata.Name
+	}
+	return obj.Metadata.GenerateName
+}
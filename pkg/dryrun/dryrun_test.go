@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
+)
+
+func TestWrapConfig(t *testing.T) {
+	stored := &crdv1.VolumeSnapshot{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "snapshot.storage.k8s.io/v1", Kind: "VolumeSnapshot"},
+		ObjectMeta: metav1.ObjectMeta{Name: "snap", Namespace: "default", ResourceVersion: "1"},
+	}
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stored)
+	}))
+	defer server.Close()
+
+	var writes []Write
+	config := WrapConfig(&rest.Config{Host: server.URL}, func(w Write) {
+		writes = append(writes, w)
+	})
+	client, err := clientset.NewForConfig(config)
+	if err != nil {
+		t.Fatalf("unexpected error creating the client: %v", err)
+	}
+	snapshots := client.SnapshotV1().VolumeSnapshots("default")
+	ctx := context.Background()
+
+	created, err := snapshots.Create(ctx, &crdv1.VolumeSnapshot{ObjectMeta: metav1.ObjectMeta{GenerateName: "snap-"}}, metav1.CreateOptions{})
+	if err != nil || created.GenerateName != "snap-" {
+		t.Errorf("expected create to return the object, got %+v: %v", created, err)
+	}
+	update := stored.DeepCopy()
+	update.Finalizers = []string{"finalizer"}
+	updated, err := snapshots.UpdateStatus(ctx, update, metav1.UpdateOptions{})
+	if err != nil || !reflect.DeepEqual(updated.Finalizers, update.Finalizers) {
+		t.Errorf("expected update to return the object, got %+v: %v", updated, err)
+	}
+	patched, err := snapshots.Patch(ctx, "snap", types.MergePatchType, []byte(`{"metadata":{"labels":{"a":"b"}}}`), metav1.PatchOptions{})
+	if err != nil || patched.ResourceVersion != "1" || len(patched.Labels) != 0 {
+		t.Errorf("expected patch to return the stored object, got %+v: %v", patched, err)
+	}
+	if err := snapshots.Delete(ctx, "snap", metav1.DeleteOptions{}); err != nil {
+		t.Errorf("unexpected error deleting: %v", err)
+	}
+	if _, err := snapshots.Get(ctx, "snap", metav1.GetOptions{}); err != nil {
+		t.Errorf("unexpected error getting: %v", err)
+	}
+
+	// Only the reads reached the server.
+	if !reflect.DeepEqual(methods, []string{http.MethodGet, http.MethodGet}) {
+		t.Errorf("expected only reads to reach the server, got %v", methods)
+	}
+	expected := []Write{
+		{Verb: "create", Name: "snap-"},
+		{Verb: "update", Name: "snap", Subresource: "status"},
+		{Verb: "patch", Name: "snap"},
+		{Verb: "delete", Name: "snap"},
+	}
+	if len(writes) != len(expected) {
+		t.Fatalf("expected %d writes, got %+v", len(expected), writes)
+	}
+	for i, w := range writes {
+		e := expected[i]
+		e.Group, e.Version, e.Resource, e.Namespace = "snapshot.storage.k8s.io", "v1", "volumesnapshots", "default"
+		e.Body = w.Body
+		if !reflect.DeepEqual(w, e) {
+			t.Errorf("write %d: expected %+v, got %+v", i, e, w)
+		}
+	}
+	if string(writes[2].Body) != `{"metadata":{"labels":{"a":"b"}}}` {
+		t.Errorf("expected the patch to be recorded, got %s", writes[2].Body)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := map[string]Write{
+		"/api/v1/namespaces/default/events":                                {Version: "v1", Namespace: "default", Resource: "events"},
+		"/api/v1/namespaces/default":                                       {Version: "v1", Resource: "namespaces", Name: "default"},
+		"/apis/snapshot.storage.k8s.io/v1/volumesnapshotcontents/c/status": {Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotcontents", Name: "c", Subresource: "status"},
+	}
+	for path, expected := range tests {
+		if w := parsePath(path); !reflect.DeepEqual(w, expected) {
+			t.Errorf("%s: expected %+v, got %+v", path, expected, w)
+		}
+	}
+}
@@ -30,6 +30,18 @@ const (
 	//
 	// Releases leader election lease on sigterm / sigint.
 	ReleaseLeaderElectionOnExit featuregate.Feature = "ReleaseLeaderElectionOnExit"
+
+	// Enable usage of cluster scoped volume group snapshots spanning namespaces.
+	// Requires CSIVolumeGroupSnapshot.
+	ClusterVolumeGroupSnapshot featuregate.Feature = "CSIClusterVolumeGroupSnapshot"
+
+	// Enable copying snapshots into another VolumeSnapshotClass through
+	// VolumeSnapshotCopy objects.
+	SnapshotCopy featuregate.Feature = "CSISnapshotCopy"
+
+	// Restrict the namespaces that may use a VolumeSnapshotClass and select
+	// default classes per namespace through VolumeSnapshotClass annotations.
+	SnapshotClassNamespacePolicy featuregate.Feature = "CSISnapshotClassNamespacePolicy"
 )
 
 func init() {
@@ -39,6 +51,9 @@ func init() {
 // defaultKubernetesFeatureGates consists of all known feature keys specific to external-snapshotter.
 // To add a new feature, define a key for it above and add it here.
 var defaultKubernetesFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-	VolumeGroupSnapshot:         {Default: true, PreRelease: featuregate.GA},
-	ReleaseLeaderElectionOnExit: {Default: false, PreRelease: featuregate.Alpha},
+	VolumeGroupSnapshot:          {Default: true, PreRelease: featuregate.GA},
+	ReleaseLeaderElectionOnExit:  {Default: false, PreRelease: featuregate.Alpha},
+	ClusterVolumeGroupSnapshot:   {Default: false, PreRelease: featuregate.Alpha},
+	SnapshotCopy:                 {Default: false, PreRelease: featuregate.Alpha},
+	SnapshotClassNamespacePolicy: {Default: false, PreRelease: featuregate.Alpha},
 }
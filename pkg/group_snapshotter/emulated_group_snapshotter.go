@@ -0,0 +1,205 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group_snapshotter
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	klog "k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+)
+
+// EmulatedGroupSnapshotIDPrefix is the prefix of the ID of a group snapshot
+// that was emulated with individual snapshots. The ID is not used to tell
+// emulated group snapshots apart, because the IDs of a CSI driver may start
+// with the same prefix.
+const EmulatedGroupSnapshotIDPrefix = "emulated-"
+
+// IsEmulated returns true if groupSnapshotter emulates group snapshots with
+// individual snapshots.
+func IsEmulated(groupSnapshotter GroupSnapshotter) bool {
+	_, ok := groupSnapshotter.(*emulatedGroupSnapshot)
+	return ok
+}
+
+// emulatedGroupSnapshot implements GroupSnapshotter for CSI drivers without the
+// GroupController service by taking an individual snapshot of each volume.
+type emulatedGroupSnapshot struct {
+	snapshotter snapshotter.Snapshotter
+	hook        QuiesceHook
+	window      time.Duration
+}
+
+// NewEmulatedGroupSnapshotter returns a GroupSnapshotter that emulates group
+// snapshots with CreateSnapshot calls issued in parallel for all volumes of the
+// group. All snapshots must be cut within window, otherwise the group snapshot
+// fails and the snapshots taken so far are deleted. If hook is not nil, the
+// volumes are quiesced before and unquiesced after the snapshots are taken.
+//
+// The snapshots are not taken at a single point in time, so the resulting
+// group snapshot is not crash consistent.
+func NewEmulatedGroupSnapshotter(snapshotter snapshotter.Snapshotter, hook QuiesceHook, window time.Duration) GroupSnapshotter {
+	return &emulatedGroupSnapshot{
+		snapshotter: snapshotter,
+		hook:        hook,
+		window:      window,
+	}
+}
+
+// emulatedSnapshotResult is the outcome of the CreateSnapshot call of a
+// single volume of an emulated group snapshot.
+type emulatedSnapshotResult struct {
+	driverName string
+	snapshot   *csi.Snapshot
+	err        error
+}
+
+func (gs *emulatedGroupSnapshot) CreateGroupSnapshot(ctx context.Context, groupSnapshotName string, volumeIDs []string, parameters map[string]string, snapshotterCredentials map[string]string) (string, string, []*csi.Snapshot, time.Time, bool, error) {
+	klog.V(5).Infof("Emulated CreateGroupSnapshot: %s", groupSnapshotName)
+	groupSnapshotID := EmulatedGroupSnapshotIDPrefix + groupSnapshotName
+
+	if gs.hook != nil {
+		if err := gs.hook.Quiesce(ctx, groupSnapshotName, volumeIDs); err != nil {
+			return "", "", nil, time.Time{}, false, fmt.Errorf("failed to quiesce volumes of group snapshot %s: %w", groupSnapshotName, err)
+		}
+		defer func() {
+			if err := gs.hook.Unquiesce(ctx, groupSnapshotName, volumeIDs); err != nil {
+				klog.Errorf("Emulated CreateGroupSnapshot: failed to unquiesce volumes of group snapshot %s: %v", groupSnapshotName, err)
+			}
+		}()
+	}
+
+	windowCtx, cancel := context.WithTimeout(ctx, gs.window)
+	defer cancel()
+	results := make([]emulatedSnapshotResult, len(volumeIDs))
+	var wg sync.WaitGroup
+	for i, volumeID := range volumeIDs {
+		wg.Add(1)
+		go func(i int, volumeID string) {
+			defer wg.Done()
+			driverName, snapshotID, creationTime, size, readyToUse, err := gs.snapshotter.CreateSnapshot(windowCtx, emulatedSnapshotName(groupSnapshotName, volumeID), volumeID, parameters, snapshotterCredentials)
+			results[i] = emulatedSnapshotResult{driverName: driverName, err: err}
+			if err == nil {
+				results[i].snapshot = &csi.Snapshot{
+					SizeBytes:       size,
+					SnapshotId:      snapshotID,
+					SourceVolumeId:  volumeID,
+					CreationTime:    timestamppb.New(creationTime),
+					ReadyToUse:      readyToUse,
+					GroupSnapshotId: groupSnapshotID,
+				}
+			}
+		}(i, volumeID)
+	}
+	wg.Wait()
+
+	var driverName string
+	var snapshots []*csi.Snapshot
+	var errs []error
+	for i, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("volume %s: %w", volumeIDs[i], result.err))
+			continue
+		}
+		driverName = result.driverName
+		snapshots = append(snapshots, result.snapshot)
+	}
+	if len(errs) > 0 {
+		// A later retry must cut all snapshots again within the window, do
+		// not leave the snapshots that succeeded behind.
+		gs.deleteSnapshots(ctx, snapshots, snapshotterCredentials)
+		return "", "", nil, time.Time{}, false, fmt.Errorf("failed to emulate group snapshot %s: %w", groupSnapshotName, utilerrors.NewAggregate(errs))
+	}
+
+	// The group snapshot is only ready when all of its snapshots are ready,
+	// and it was taken when the oldest snapshot was taken.
+	readyToUse := true
+	var creationTime, newestCreationTime time.Time
+	for _, snapshot := range snapshots {
+		readyToUse = readyToUse && snapshot.ReadyToUse
+		t := snapshot.CreationTime.AsTime()
+		if creationTime.IsZero() || t.Before(creationTime) {
+			creationTime = t
+		}
+		if t.After(newestCreationTime) {
+			newestCreationTime = t
+		}
+	}
+	// CreateSnapshot is idempotent, a snapshot left behind by an earlier
+	// attempt is returned with its original creation time.
+	if spread := newestCreationTime.Sub(creationTime); spread > gs.window {
+		gs.deleteSnapshots(ctx, snapshots, snapshotterCredentials)
+		return "", "", nil, time.Time{}, false, fmt.Errorf("failed to emulate group snapshot %s: snapshots were taken %v apart, more than the allowed window of %v", groupSnapshotName, spread, gs.window)
+	}
+	klog.V(5).Infof("Emulated CreateGroupSnapshot: %s driver name [%s] group snapshot ID [%s] time stamp [%v] snapshots [%v] readyToUse [%v]", groupSnapshotName, driverName, groupSnapshotID, creationTime, snapshots, readyToUse)
+	return driverName, groupSnapshotID, snapshots, creationTime, readyToUse, nil
+}
+
+// deleteSnapshots deletes the snapshots of a failed emulated group snapshot.
+// Errors are only logged, the snapshots are taken again with the same names
+// when the group snapshot is retried.
+func (gs *emulatedGroupSnapshot) deleteSnapshots(ctx context.Context, snapshots []*csi.Snapshot, snapshotterCredentials map[string]string) {
+	for _, snapshot := range snapshots {
+		if err := gs.snapshotter.DeleteSnapshot(ctx, snapshot.SnapshotId, snapshotterCredentials); err != nil {
+			klog.Errorf("Emulated CreateGroupSnapshot: failed to delete snapshot %s of volume %s: %v", snapshot.SnapshotId, snapshot.SourceVolumeId, err)
+		}
+	}
+}
+
+func (gs *emulatedGroupSnapshot) DeleteGroupSnapshot(ctx context.Context, groupSnapshotID string, snapshotIDs []string, snapshotterCredentials map[string]string) error {
+	klog.V(5).Infof("Emulated DeleteGroupSnapshot: %s", groupSnapshotID)
+	var errs []error
+	for _, snapshotID := range snapshotIDs {
+		if err := gs.snapshotter.DeleteSnapshot(ctx, snapshotID, snapshotterCredentials); err != nil {
+			errs = append(errs, fmt.Errorf("snapshot %s: %w", snapshotID, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (gs *emulatedGroupSnapshot) GetGroupSnapshotStatus(ctx context.Context, groupSnapshotID string, snapshotIDs []string, snapshotterCredentials map[string]string) (bool, time.Time, error) {
+	klog.V(5).Infof("Emulated GetGroupSnapshotStatus: %s", groupSnapshotID)
+	readyToUse := true
+	var creationTime time.Time
+	for _, snapshotID := range snapshotIDs {
+		ready, t, _, _, err := gs.snapshotter.GetSnapshotStatus(ctx, snapshotID, snapshotterCredentials)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("failed to get status of snapshot %s of group snapshot %s: %w", snapshotID, groupSnapshotID, err)
+		}
+		readyToUse = readyToUse && ready
+		if !t.IsZero() && (creationTime.IsZero() || t.Before(creationTime)) {
+			creationTime = t
+		}
+	}
+	return readyToUse, creationTime, nil
+}
+
+// emulatedSnapshotName returns the name of the snapshot of a volume in an
+// emulated group snapshot. It is stable, so that CreateSnapshot is idempotent
+// when the group snapshot is retried.
+func emulatedSnapshotName(groupSnapshotName, volumeID string) string {
+	hash := sha256.Sum256([]byte(volumeID))
+	return fmt.Sprintf("%s-%x", groupSnapshotName, hash[:8])
+}
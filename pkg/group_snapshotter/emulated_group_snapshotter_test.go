@@ -0,0 +1,266 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group_snapshotter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+)
+
+// fakeSnapshotter records the calls of an emulated group snapshotter.
+type fakeSnapshotter struct {
+	mutex sync.Mutex
+
+	createErrs    map[string]error
+	creationTimes map[string]time.Time
+	notReady      map[string]bool
+	created       []string
+	deleted       []string
+}
+
+func (f *fakeSnapshotter) CreateSnapshot(ctx context.Context, snapshotName string, volumeHandle string, parameters map[string]string, snapshotterCredentials map[string]string) (string, string, time.Time, int64, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.createErrs[volumeHandle]; err != nil {
+		return "", "", time.Time{}, 0, false, err
+	}
+	f.created = append(f.created, snapshotName)
+	creationTime, found := f.creationTimes[volumeHandle]
+	if !found {
+		creationTime = time.Unix(1000, 0)
+	}
+	return "test-driver", "snap-" + volumeHandle, creationTime, 1024, !f.notReady[volumeHandle], nil
+}
+
+func (f *fakeSnapshotter) DeleteSnapshot(ctx context.Context, snapshotID string, snapshotterCredentials map[string]string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.deleted = append(f.deleted, snapshotID)
+	return nil
+}
+
+func (f *fakeSnapshotter) GetSnapshotStatus(ctx context.Context, snapshotID string, snapshotterListCredentials map[string]string) (bool, time.Time, int64, string, error) {
+	volumeHandle := strings.TrimPrefix(snapshotID, "snap-")
+	return !f.notReady[volumeHandle], f.creationTimes[volumeHandle], 1024, "", nil
+}
+
+func (f *fakeSnapshotter) ListSnapshots(ctx context.Context, sourceVolumeID string, snapshotterListCredentials map[string]string) ([]snapshotter.SnapshotInfo, error) {
+	return nil, snapshotter.ErrListSnapshotsNotSupported
+}
+
+func (f *fakeSnapshotter) GetSnapshotAllocatedBytes(ctx context.Context, snapshotID string, snapshotterCredentials map[string]string) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+// fakeQuiesceHook records the calls of an emulated group snapshotter.
+type fakeQuiesceHook struct {
+	quiesceErr error
+	calls      []string
+}
+
+func (h *fakeQuiesceHook) Quiesce(ctx context.Context, groupSnapshotName string, volumeIDs []string) error {
+	h.calls = append(h.calls, "quiesce")
+	return h.quiesceErr
+}
+
+func (h *fakeQuiesceHook) Unquiesce(ctx context.Context, groupSnapshotName string, volumeIDs []string) error {
+	h.calls = append(h.calls, "unquiesce")
+	return nil
+}
+
+func TestEmulatedCreateGroupSnapshot(t *testing.T) {
+	fake := &fakeSnapshotter{notReady: map[string]bool{"vol-2": true}}
+	hook := &fakeQuiesceHook{}
+	gs := NewEmulatedGroupSnapshotter(fake, hook, 10*time.Second)
+
+	driverName, groupSnapshotID, snapshots, _, readyToUse, err := gs.CreateGroupSnapshot(context.Background(), "groupsnapshot-1", []string{"vol-1", "vol-2"}, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if driverName != "test-driver" {
+		t.Errorf("expected driver name test-driver, got %q", driverName)
+	}
+	if groupSnapshotID != EmulatedGroupSnapshotIDPrefix+"groupsnapshot-1" {
+		t.Errorf("expected an emulated group snapshot ID, got %q", groupSnapshotID)
+	}
+	if readyToUse {
+		t.Error("expected the group snapshot not to be ready while vol-2 is not ready")
+	}
+	if len(snapshots) != 2 || snapshots[0].SourceVolumeId != "vol-1" || snapshots[1].SourceVolumeId != "vol-2" {
+		t.Fatalf("unexpected snapshots %v", snapshots)
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.GroupSnapshotId != groupSnapshotID {
+			t.Errorf("snapshot %s has group snapshot ID %q, want %q", snapshot.SnapshotId, snapshot.GroupSnapshotId, groupSnapshotID)
+		}
+	}
+	if !reflect.DeepEqual(hook.calls, []string{"quiesce", "unquiesce"}) {
+		t.Errorf("expected the snapshots to be bracketed by the quiesce hook, got %v", hook.calls)
+	}
+
+	// Retrying the group snapshot must use the same snapshot names.
+	firstNames := append([]string{}, fake.created...)
+	if _, _, _, _, _, err := gs.CreateGroupSnapshot(context.Background(), "groupsnapshot-1", []string{"vol-1", "vol-2"}, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Strings(firstNames)
+	secondNames := append([]string{}, fake.created[2:]...)
+	sort.Strings(secondNames)
+	if !reflect.DeepEqual(firstNames, secondNames) {
+		t.Errorf("expected the same snapshot names on retry, got %v and %v", firstNames, secondNames)
+	}
+}
+
+func TestEmulatedCreateGroupSnapshotFailure(t *testing.T) {
+	tests := []struct {
+		name            string
+		snapshotter     *fakeSnapshotter
+		quiesceErr      error
+		expectedErr     string
+		expectedCalls   []string
+		expectedDeleted []string
+	}{
+		{
+			name:            "failed snapshot deletes the others",
+			snapshotter:     &fakeSnapshotter{createErrs: map[string]error{"vol-2": errors.New("mock create error")}},
+			expectedErr:     "volume vol-2: mock create error",
+			expectedCalls:   []string{"quiesce", "unquiesce"},
+			expectedDeleted: []string{"snap-vol-1"},
+		},
+		{
+			name: "snapshots outside of the window are deleted",
+			snapshotter: &fakeSnapshotter{creationTimes: map[string]time.Time{
+				"vol-1": time.Unix(1000, 0),
+				"vol-2": time.Unix(1060, 0),
+			}},
+			expectedErr:     "more than the allowed window",
+			expectedCalls:   []string{"quiesce", "unquiesce"},
+			expectedDeleted: []string{"snap-vol-1", "snap-vol-2"},
+		},
+		{
+			name:          "failed quiesce takes no snapshot",
+			snapshotter:   &fakeSnapshotter{},
+			quiesceErr:    errors.New("mock quiesce error"),
+			expectedErr:   "mock quiesce error",
+			expectedCalls: []string{"quiesce"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hook := &fakeQuiesceHook{quiesceErr: test.quiesceErr}
+			gs := NewEmulatedGroupSnapshotter(test.snapshotter, hook, 10*time.Second)
+
+			_, _, _, _, _, err := gs.CreateGroupSnapshot(context.Background(), "groupsnapshot-1", []string{"vol-1", "vol-2"}, nil, nil)
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Fatalf("expected error containing %q, got %v", test.expectedErr, err)
+			}
+			if !reflect.DeepEqual(hook.calls, test.expectedCalls) {
+				t.Errorf("expected hook calls %v, got %v", test.expectedCalls, hook.calls)
+			}
+			deleted := test.snapshotter.deleted
+			sort.Strings(deleted)
+			if !reflect.DeepEqual(deleted, test.expectedDeleted) {
+				t.Errorf("expected deleted snapshots %v, got %v", test.expectedDeleted, deleted)
+			}
+			if test.quiesceErr != nil && len(test.snapshotter.created) != 0 {
+				t.Errorf("expected no snapshot to be taken, got %v", test.snapshotter.created)
+			}
+		})
+	}
+}
+
+func TestEmulatedGetGroupSnapshotStatus(t *testing.T) {
+	fake := &fakeSnapshotter{
+		notReady: map[string]bool{"vol-2": true},
+		creationTimes: map[string]time.Time{
+			"vol-1": time.Unix(1002, 0),
+			"vol-2": time.Unix(1001, 0),
+		},
+	}
+	gs := NewEmulatedGroupSnapshotter(fake, nil, 10*time.Second)
+
+	readyToUse, creationTime, err := gs.GetGroupSnapshotStatus(context.Background(), "emulated-groupsnapshot-1", []string{"snap-vol-1", "snap-vol-2"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if readyToUse {
+		t.Error("expected the group snapshot not to be ready while vol-2 is not ready")
+	}
+	if !creationTime.Equal(time.Unix(1001, 0)) {
+		t.Errorf("expected the creation time of the oldest snapshot, got %v", creationTime)
+	}
+
+	if err := gs.DeleteGroupSnapshot(context.Background(), "emulated-groupsnapshot-1", []string{"snap-vol-1", "snap-vol-2"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fake.deleted, []string{"snap-vol-1", "snap-vol-2"}) {
+		t.Errorf("expected all snapshots to be deleted, got %v", fake.deleted)
+	}
+}
+
+func TestIsEmulated(t *testing.T) {
+	if !IsEmulated(NewEmulatedGroupSnapshotter(&fakeSnapshotter{}, nil, 10*time.Second)) {
+		t.Error("expected the emulated group snapshotter to be emulated")
+	}
+	if IsEmulated(NewGroupSnapshotter(nil)) {
+		t.Error("expected the CSI group snapshotter not to be emulated")
+	}
+}
+
+func TestHTTPQuiesceHook(t *testing.T) {
+	var requests []QuiesceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req QuiesceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests = append(requests, req)
+		if req.Action == QuiesceActionUnquiesce {
+			http.Error(w, "application busy", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	hook := NewHTTPQuiesceHook(server.URL, server.Client())
+	if err := hook.Quiesce(context.Background(), "groupsnapshot-1", []string{"vol-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := hook.Unquiesce(context.Background(), "groupsnapshot-1", []string{"vol-1"})
+	if err == nil || !strings.Contains(err.Error(), "application busy") {
+		t.Errorf("expected error with the response body, got %v", err)
+	}
+
+	expected := []QuiesceRequest{
+		{Action: QuiesceActionQuiesce, GroupSnapshotName: "groupsnapshot-1", VolumeIDs: []string{"vol-1"}},
+		{Action: QuiesceActionUnquiesce, GroupSnapshotName: "groupsnapshot-1", VolumeIDs: []string{"vol-1"}},
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
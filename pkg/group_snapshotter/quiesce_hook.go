@@ -0,0 +1,104 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group_snapshotter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	klog "k8s.io/klog/v2"
+)
+
+// QuiesceHook brackets the individual snapshots of an emulated group snapshot,
+// e.g. to flush and freeze the applications using the volumes, so that the
+// snapshots are consistent even though they are not taken atomically.
+type QuiesceHook interface {
+	// Quiesce is called before the snapshots are taken. If it fails, no
+	// snapshot is taken.
+	Quiesce(ctx context.Context, groupSnapshotName string, volumeIDs []string) error
+
+	// Unquiesce is called after the snapshots are taken, also when taking
+	// them failed.
+	Unquiesce(ctx context.Context, groupSnapshotName string, volumeIDs []string) error
+}
+
+// Actions sent by the HTTP quiesce hook.
+const (
+	QuiesceActionQuiesce   = "quiesce"
+	QuiesceActionUnquiesce = "unquiesce"
+)
+
+// QuiesceRequest is the body of the requests sent by the HTTP quiesce hook.
+type QuiesceRequest struct {
+	Action            string   `json:"action"`
+	GroupSnapshotName string   `json:"groupSnapshotName"`
+	VolumeIDs         []string `json:"volumeIDs"`
+}
+
+type httpQuiesceHook struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPQuiesceHook returns a QuiesceHook that POSTs a QuiesceRequest to url.
+// Any response status other than 2xx fails the hook.
+func NewHTTPQuiesceHook(url string, client *http.Client) QuiesceHook {
+	return &httpQuiesceHook{
+		url:    url,
+		client: client,
+	}
+}
+
+func (h *httpQuiesceHook) Quiesce(ctx context.Context, groupSnapshotName string, volumeIDs []string) error {
+	return h.send(ctx, QuiesceActionQuiesce, groupSnapshotName, volumeIDs)
+}
+
+func (h *httpQuiesceHook) Unquiesce(ctx context.Context, groupSnapshotName string, volumeIDs []string) error {
+	return h.send(ctx, QuiesceActionUnquiesce, groupSnapshotName, volumeIDs)
+}
+
+func (h *httpQuiesceHook) send(ctx context.Context, action, groupSnapshotName string, volumeIDs []string) error {
+	klog.V(5).Infof("Quiesce hook: %s group snapshot %s", action, groupSnapshotName)
+	body, err := json.Marshal(QuiesceRequest{
+		Action:            action,
+		GroupSnapshotName: groupSnapshotName,
+		VolumeIDs:         volumeIDs,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		return fmt.Errorf("%s hook returned %s: %s", action, rsp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,500 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klog "k8s.io/klog/v2"
+
+	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+const (
+	// BundleAPIVersion is the version of the bundle format written by
+	// Export. Bundles of other versions are rejected by PlanBundle.
+	BundleAPIVersion = "snapshotbundle.storage.k8s.io/v1alpha1"
+	// BundleKind is the kind of a bundle.
+	BundleKind = "SnapshotBundle"
+)
+
+// Bundle is a portable description of snapshots that is used to recreate
+// them as pre-provisioned objects in another cluster that can access the
+// same storage system, e.g. a standby cluster for disaster recovery.
+// It only contains what is needed to bind the snapshots again, and no
+// cluster specific data like UIDs.
+type Bundle struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Driver is the CSI driver owning all snapshots of the bundle.
+	Driver string `json:"driver"`
+	// ExportTime is the time when the bundle was exported.
+	ExportTime metav1.Time `json:"exportTime"`
+	// Snapshots are the exported individual VolumeSnapshots.
+	Snapshots []BundleSnapshot `json:"snapshots,omitempty"`
+	// GroupSnapshots are the exported VolumeGroupSnapshots with all their members.
+	GroupSnapshots []BundleGroupSnapshot `json:"groupSnapshots,omitempty"`
+}
+
+// BundleSnapshot describes a VolumeSnapshot and its snapshot on the storage
+// system.
+type BundleSnapshot struct {
+	// Name and Namespace of the VolumeSnapshot.
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// SnapshotHandle is the CSI "snapshot_id" of the snapshot.
+	SnapshotHandle string `json:"snapshotHandle"`
+	// VolumeSnapshotClassName is the class of the VolumeSnapshotContent, if any.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+	// SourceVolumeHandle is the CSI volume handle of the snapshotted volume,
+	// if known.
+	SourceVolumeHandle string `json:"sourceVolumeHandle,omitempty"`
+	// SourceVolumeMode is the mode of the snapshotted volume, if known.
+	SourceVolumeMode *v1.PersistentVolumeMode `json:"sourceVolumeMode,omitempty"`
+	// RestoreSize is the minimum size in bytes of a volume restored from the
+	// snapshot, if known.
+	RestoreSize *int64 `json:"restoreSize,omitempty"`
+	// CreationTime is the time the snapshot was taken, in nanoseconds since
+	// the epoch, if known.
+	CreationTime *int64 `json:"creationTime,omitempty"`
+	// GroupSnapshotHandle is the CSI "group_snapshot_id" of the group
+	// snapshot the snapshot belongs to, if any.
+	GroupSnapshotHandle string `json:"groupSnapshotHandle,omitempty"`
+}
+
+// BundleGroupSnapshot describes a VolumeGroupSnapshot and the snapshots of
+// all its members.
+type BundleGroupSnapshot struct {
+	// Name and Namespace of the VolumeGroupSnapshot.
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// GroupSnapshotHandle is the CSI "group_snapshot_id" of the group snapshot.
+	GroupSnapshotHandle string `json:"groupSnapshotHandle"`
+	// VolumeGroupSnapshotClassName is the class of the
+	// VolumeGroupSnapshotContent, if any.
+	VolumeGroupSnapshotClassName string `json:"volumeGroupSnapshotClassName,omitempty"`
+	// Members are the snapshots of the group.
+	Members []BundleSnapshot `json:"members"`
+}
+
+// GroupPair is a VolumeGroupSnapshot, the pre-provisioned
+// VolumeGroupSnapshotContent it is bound to, and the pairs of its members.
+type GroupPair struct {
+	GroupSnapshot *groupsnapshotv1.VolumeGroupSnapshot
+	GroupContent  *groupsnapshotv1.VolumeGroupSnapshotContent
+	Members       []Pair
+}
+
+// Export returns a bundle of the given ready VolumeSnapshots and
+// VolumeGroupSnapshots of a namespace. All snapshots must belong to the same
+// CSI driver.
+func Export(ctx context.Context, snapClient clientset.Interface, namespace string, snapshotNames, groupSnapshotNames []string) (*Bundle, error) {
+	bundle := &Bundle{
+		APIVersion: BundleAPIVersion,
+		Kind:       BundleKind,
+		ExportTime: metav1.Now(),
+	}
+	setDriver := func(driver, object string) error {
+		if bundle.Driver == "" {
+			bundle.Driver = driver
+		}
+		if bundle.Driver != driver {
+			return fmt.Errorf("%s belongs to driver %s, not %s", object, driver, bundle.Driver)
+		}
+		return nil
+	}
+
+	for _, name := range snapshotNames {
+		content, err := getReadySnapshotContent(ctx, snapClient, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := setDriver(content.Spec.Driver, "VolumeSnapshot "+namespace+"/"+name); err != nil {
+			return nil, err
+		}
+		bundle.Snapshots = append(bundle.Snapshots, newBundleSnapshot(name, namespace, content))
+	}
+
+	if len(groupSnapshotNames) == 0 {
+		return bundle, nil
+	}
+	contentList, err := snapClient.SnapshotV1().VolumeSnapshotContents().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VolumeSnapshotContents: %v", err)
+	}
+	for _, name := range groupSnapshotNames {
+		groupContent, err := getReadyGroupSnapshotContent(ctx, snapClient, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := setDriver(groupContent.Spec.Driver, "VolumeGroupSnapshot "+namespace+"/"+name); err != nil {
+			return nil, err
+		}
+		groupSnapshot, err := newBundleGroupSnapshot(name, namespace, groupContent, contentList.Items)
+		if err != nil {
+			return nil, err
+		}
+		bundle.GroupSnapshots = append(bundle.GroupSnapshots, groupSnapshot)
+	}
+	return bundle, nil
+}
+
+// getReadySnapshotContent returns the VolumeSnapshotContent of a ready
+// VolumeSnapshot.
+func getReadySnapshotContent(ctx context.Context, snapClient clientset.Interface, namespace, name string) (*crdv1.VolumeSnapshotContent, error) {
+	snapshot, err := snapClient.SnapshotV1().VolumeSnapshots(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VolumeSnapshot %s/%s: %v", namespace, name, err)
+	}
+	if !utils.IsSnapshotReady(snapshot) || !utils.IsBoundVolumeSnapshotContentNameSet(snapshot) {
+		return nil, fmt.Errorf("VolumeSnapshot %s/%s is not ready to use", namespace, name)
+	}
+	contentName := *snapshot.Status.BoundVolumeSnapshotContentName
+	content, err := snapClient.SnapshotV1().VolumeSnapshotContents().Get(ctx, contentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VolumeSnapshotContent %s: %v", contentName, err)
+	}
+	if content.Spec.VolumeSnapshotRef.UID != snapshot.UID {
+		return nil, fmt.Errorf("VolumeSnapshotContent %s is not bound to VolumeSnapshot %s/%s", contentName, namespace, name)
+	}
+	if getSnapshotHandle(content) == "" {
+		return nil, fmt.Errorf("snapshot handle of VolumeSnapshotContent %s is unknown", contentName)
+	}
+	return content, nil
+}
+
+// getReadyGroupSnapshotContent returns the VolumeGroupSnapshotContent of a
+// ready VolumeGroupSnapshot.
+func getReadyGroupSnapshotContent(ctx context.Context, snapClient clientset.Interface, namespace, name string) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
+	groupSnapshot, err := snapClient.GroupsnapshotV1().VolumeGroupSnapshots(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VolumeGroupSnapshot %s/%s: %v", namespace, name, err)
+	}
+	if !utils.IsGroupSnapshotReady(groupSnapshot) || !utils.IsBoundVolumeGroupSnapshotContentNameSet(groupSnapshot) {
+		return nil, fmt.Errorf("VolumeGroupSnapshot %s/%s is not ready to use", namespace, name)
+	}
+	contentName := *groupSnapshot.Status.BoundVolumeGroupSnapshotContentName
+	groupContent, err := snapClient.GroupsnapshotV1().VolumeGroupSnapshotContents().Get(ctx, contentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VolumeGroupSnapshotContent %s: %v", contentName, err)
+	}
+	if groupContent.Spec.VolumeGroupSnapshotRef.UID != groupSnapshot.UID {
+		return nil, fmt.Errorf("VolumeGroupSnapshotContent %s is not bound to VolumeGroupSnapshot %s/%s", contentName, namespace, name)
+	}
+	if groupContent.Status == nil || groupContent.Status.VolumeGroupSnapshotHandle == nil {
+		return nil, fmt.Errorf("group snapshot handle of VolumeGroupSnapshotContent %s is unknown", contentName)
+	}
+	return groupContent, nil
+}
+
+func newBundleSnapshot(name, namespace string, content *crdv1.VolumeSnapshotContent) BundleSnapshot {
+	snapshot := BundleSnapshot{
+		Name:             name,
+		Namespace:        namespace,
+		SnapshotHandle:   getSnapshotHandle(content),
+		SourceVolumeMode: content.Spec.SourceVolumeMode,
+	}
+	if content.Spec.VolumeSnapshotClassName != nil {
+		snapshot.VolumeSnapshotClassName = *content.Spec.VolumeSnapshotClassName
+	}
+	if content.Spec.Source.VolumeHandle != nil {
+		snapshot.SourceVolumeHandle = *content.Spec.Source.VolumeHandle
+	}
+	if content.Status != nil {
+		snapshot.RestoreSize = content.Status.RestoreSize
+		snapshot.CreationTime = content.Status.CreationTime
+		if content.Status.VolumeGroupSnapshotHandle != nil {
+			snapshot.GroupSnapshotHandle = *content.Status.VolumeGroupSnapshotHandle
+		}
+	}
+	return snapshot
+}
+
+// newBundleGroupSnapshot describes a group snapshot and its members. The
+// members are the VolumeSnapshotContents of the driver which belong to the
+// group snapshot handle.
+func newBundleGroupSnapshot(name, namespace string, groupContent *groupsnapshotv1.VolumeGroupSnapshotContent, contents []crdv1.VolumeSnapshotContent) (BundleGroupSnapshot, error) {
+	groupHandle := *groupContent.Status.VolumeGroupSnapshotHandle
+	groupSnapshot := BundleGroupSnapshot{
+		Name:                name,
+		Namespace:           namespace,
+		GroupSnapshotHandle: groupHandle,
+	}
+	if groupContent.Spec.VolumeGroupSnapshotClassName != nil {
+		groupSnapshot.VolumeGroupSnapshotClassName = *groupContent.Spec.VolumeGroupSnapshotClassName
+	}
+
+	membersByHandle := map[string]*crdv1.VolumeSnapshotContent{}
+	for idx := range contents {
+		content := &contents[idx]
+		if content.Spec.Driver != groupContent.Spec.Driver || content.Status == nil ||
+			content.Status.VolumeGroupSnapshotHandle == nil || *content.Status.VolumeGroupSnapshotHandle != groupHandle {
+			continue
+		}
+		if handle := getSnapshotHandle(content); handle != "" {
+			membersByHandle[handle] = content
+		}
+	}
+
+	for _, handle := range getGroupMemberSnapshotHandles(groupContent) {
+		content, found := membersByHandle[handle]
+		if !found {
+			return BundleGroupSnapshot{}, fmt.Errorf("no VolumeSnapshotContent found for snapshot %s of VolumeGroupSnapshot %s/%s", handle, namespace, name)
+		}
+		ref := content.Spec.VolumeSnapshotRef
+		groupSnapshot.Members = append(groupSnapshot.Members, newBundleSnapshot(ref.Name, ref.Namespace, content))
+	}
+	if len(groupSnapshot.Members) == 0 {
+		return BundleGroupSnapshot{}, fmt.Errorf("VolumeGroupSnapshot %s/%s has no members", namespace, name)
+	}
+	sort.Slice(groupSnapshot.Members, func(a, b int) bool { return groupSnapshot.Members[a].Name < groupSnapshot.Members[b].Name })
+	return groupSnapshot, nil
+}
+
+// getGroupMemberSnapshotHandles returns the snapshot handles of the members
+// of a dynamically provisioned or pre-provisioned group snapshot.
+func getGroupMemberSnapshotHandles(groupContent *groupsnapshotv1.VolumeGroupSnapshotContent) []string {
+	if groupContent.Spec.Source.GroupSnapshotHandles != nil {
+		return groupContent.Spec.Source.GroupSnapshotHandles.VolumeSnapshotHandles
+	}
+	var handles []string
+	for _, info := range groupContent.Status.VolumeSnapshotInfoList {
+		if info.SnapshotHandle != "" {
+			handles = append(handles, info.SnapshotHandle)
+		}
+	}
+	return handles
+}
+
+func getSnapshotHandle(content *crdv1.VolumeSnapshotContent) string {
+	if content.Status != nil && content.Status.SnapshotHandle != nil {
+		return *content.Status.SnapshotHandle
+	}
+	if content.Spec.Source.SnapshotHandle != nil {
+		return *content.Spec.Source.SnapshotHandle
+	}
+	return ""
+}
+
+// PlanBundle generates pre-provisioned objects for the snapshots of a
+// bundle. The VolumeSnapshots and VolumeGroupSnapshots keep their names and,
+// unless Config.Namespace is set, their namespaces. Config.SnapshotClassName
+// and Config.GroupSnapshotClassName replace the classes recorded in the
+// bundle when set. Snapshots that are already referenced by a
+// VolumeSnapshotContent which was not created by a previous import of the
+// bundle are skipped.
+func (i *Importer) PlanBundle(ctx context.Context, bundle *Bundle) (*Plan, error) {
+	if bundle.APIVersion != BundleAPIVersion || bundle.Kind != BundleKind {
+		return nil, fmt.Errorf("unsupported bundle %s %s, expected %s %s", bundle.APIVersion, bundle.Kind, BundleAPIVersion, BundleKind)
+	}
+	if bundle.Driver != i.config.DriverName {
+		return nil, fmt.Errorf("bundle belongs to driver %s, not %s", bundle.Driver, i.config.DriverName)
+	}
+	existing, err := i.getExistingSnapshotHandles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	classes := map[string]*crdv1.VolumeSnapshotClass{}
+
+	plan := &Plan{}
+	for _, snapshot := range bundle.Snapshots {
+		pair, reason, err := i.newBundlePair(ctx, snapshot, "", existing, classes)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			plan.skip(snapshot, reason)
+			continue
+		}
+		plan.Pairs = append(plan.Pairs, pair)
+	}
+
+	for _, groupSnapshot := range bundle.GroupSnapshots {
+		groupPair, err := i.newBundleGroupPair(ctx, groupSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		skipped := false
+		for _, member := range groupSnapshot.Members {
+			pair, reason, err := i.newBundlePair(ctx, member, groupSnapshot.GroupSnapshotHandle, existing, classes)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				plan.skip(member, reason)
+				skipped = true
+				continue
+			}
+			groupPair.Members = append(groupPair.Members, pair)
+		}
+		if skipped {
+			// Importing only a part of a group would bind the group
+			// snapshot to members the cluster does not know about.
+			plan.skip(BundleSnapshot{SnapshotHandle: groupSnapshot.GroupSnapshotHandle}, fmt.Sprintf("some members of VolumeGroupSnapshot %s/%s cannot be imported", groupSnapshot.Namespace, groupSnapshot.Name))
+			continue
+		}
+		plan.GroupPairs = append(plan.GroupPairs, groupPair)
+	}
+	return plan, nil
+}
+
+func (p *Plan) skip(snapshot BundleSnapshot, reason string) {
+	klog.V(4).Infof("Skipping snapshot %s of volume %s: %s", snapshot.SnapshotHandle, snapshot.SourceVolumeHandle, reason)
+	p.Skipped = append(p.Skipped, SkippedSnapshot{
+		SnapshotID:     snapshot.SnapshotHandle,
+		SourceVolumeID: snapshot.SourceVolumeHandle,
+		Reason:         reason,
+	})
+}
+
+// newBundlePair generates the pair of a snapshot of a bundle. It returns the
+// reason why the snapshot is not imported, if any. groupSnapshotHandle is
+// set for the members of a group snapshot.
+func (i *Importer) newBundlePair(ctx context.Context, snapshot BundleSnapshot, groupSnapshotHandle string, existing map[string]string, classes map[string]*crdv1.VolumeSnapshotClass) (Pair, string, error) {
+	_, contentName := getImportedSnapshotNames(i.config.DriverName, snapshot.SnapshotHandle)
+	if name, ok := existing[snapshot.SnapshotHandle]; ok && name != contentName {
+		return Pair{}, fmt.Sprintf("already referenced by VolumeSnapshotContent %s", name), nil
+	}
+
+	className := snapshot.VolumeSnapshotClassName
+	if i.config.SnapshotClassName != "" {
+		className = i.config.SnapshotClassName
+	}
+	var class *crdv1.VolumeSnapshotClass
+	if className != "" {
+		var found bool
+		if class, found = classes[className]; !found {
+			var err error
+			class, err = i.snapClient.SnapshotV1().VolumeSnapshotClasses().Get(ctx, className, metav1.GetOptions{})
+			if err != nil {
+				return Pair{}, "", fmt.Errorf("failed to get snapshot class %s: %v", className, err)
+			}
+			if class.Driver != i.config.DriverName {
+				return Pair{}, "", fmt.Errorf("snapshot class %s belongs to driver %s, not %s", class.Name, class.Driver, i.config.DriverName)
+			}
+			classes[className] = class
+		}
+	}
+
+	namespace := snapshot.Namespace
+	if i.config.Namespace != "" {
+		namespace = i.config.Namespace
+	}
+	pair, err := i.newPreprovisionedPair(snapshot.Name, namespace, contentName, snapshot.SnapshotHandle, snapshot.SourceVolumeMode, class, nil, nil)
+	if err != nil {
+		return Pair{}, "", err
+	}
+	if groupSnapshotHandle != "" {
+		// Mark the content as member of the group snapshot like the
+		// contents of dynamically provisioned group snapshots.
+		metav1.SetMetaDataAnnotation(&pair.Content.ObjectMeta, utils.VolumeGroupSnapshotHandleAnnotation, groupSnapshotHandle)
+	}
+	return pair, "", nil
+}
+
+// newBundleGroupPair generates the group snapshot and the group snapshot
+// content of a group snapshot of a bundle, without the members.
+func (i *Importer) newBundleGroupPair(ctx context.Context, groupSnapshot BundleGroupSnapshot) (GroupPair, error) {
+	namespace := groupSnapshot.Namespace
+	if i.config.Namespace != "" {
+		namespace = i.config.Namespace
+	}
+	hash := sha256.Sum256([]byte(i.config.DriverName + "^" + groupSnapshot.GroupSnapshotHandle))
+	contentName := fmt.Sprintf("groupsnapcontent-%x", hash)
+
+	var memberHandles []string
+	for _, member := range groupSnapshot.Members {
+		memberHandles = append(memberHandles, member.SnapshotHandle)
+	}
+	vgs := &groupsnapshotv1.VolumeGroupSnapshot{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: groupsnapshotv1.SchemeGroupVersion.String(),
+			Kind:       "VolumeGroupSnapshot",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      groupSnapshot.Name,
+			Namespace: namespace,
+		},
+		Spec: groupsnapshotv1.VolumeGroupSnapshotSpec{
+			Source: groupsnapshotv1.VolumeGroupSnapshotSource{
+				VolumeGroupSnapshotContentName: &contentName,
+			},
+		},
+	}
+	groupContent := &groupsnapshotv1.VolumeGroupSnapshotContent{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: groupsnapshotv1.SchemeGroupVersion.String(),
+			Kind:       "VolumeGroupSnapshotContent",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: contentName,
+		},
+		Spec: groupsnapshotv1.VolumeGroupSnapshotContentSpec{
+			VolumeGroupSnapshotRef: v1.ObjectReference{
+				APIVersion: groupsnapshotv1.SchemeGroupVersion.String(),
+				Kind:       "VolumeGroupSnapshot",
+				Name:       groupSnapshot.Name,
+				Namespace:  namespace,
+			},
+			DeletionPolicy: i.config.DeletionPolicy,
+			Driver:         i.config.DriverName,
+			Source: groupsnapshotv1.VolumeGroupSnapshotContentSource{
+				GroupSnapshotHandles: &groupsnapshotv1.GroupSnapshotHandles{
+					VolumeGroupSnapshotHandle: groupSnapshot.GroupSnapshotHandle,
+					VolumeSnapshotHandles:     memberHandles,
+				},
+			},
+		},
+	}
+
+	className := groupSnapshot.VolumeGroupSnapshotClassName
+	if i.config.GroupSnapshotClassName != "" {
+		className = i.config.GroupSnapshotClassName
+	}
+	if className != "" {
+		class, err := i.snapClient.GroupsnapshotV1().VolumeGroupSnapshotClasses().Get(ctx, className, metav1.GetOptions{})
+		if err != nil {
+			return GroupPair{}, fmt.Errorf("failed to get group snapshot class %s: %v", className, err)
+		}
+		if class.Driver != i.config.DriverName {
+			return GroupPair{}, fmt.Errorf("group snapshot class %s belongs to driver %s, not %s", class.Name, class.Driver, i.config.DriverName)
+		}
+		vgs.Spec.VolumeGroupSnapshotClassName = &class.Name
+		groupContent.Spec.VolumeGroupSnapshotClassName = &class.Name
+
+		secretRef, err := utils.GetGroupSnapshotSecretReference(utils.GroupSnapshotterSecretParams, class.Parameters, contentName, vgs)
+		if err != nil {
+			return GroupPair{}, fmt.Errorf("failed to get secret reference of group snapshot class %s: %v", class.Name, err)
+		}
+		if secretRef != nil {
+			metav1.SetMetaDataAnnotation(&groupContent.ObjectMeta, utils.AnnDeletionGroupSecretRefName, secretRef.Name)
+			metav1.SetMetaDataAnnotation(&groupContent.ObjectMeta, utils.AnnDeletionGroupSecretRefNamespace, secretRef.Namespace)
+			if err := utils.SetSecretProviderAnnotation(&groupContent.ObjectMeta, utils.AnnDeletionGroupSecretRefProvider, class.Parameters); err != nil {
+				return GroupPair{}, fmt.Errorf("failed to get secret provider of group snapshot class %s: %v", class.Name, err)
+			}
+		}
+	}
+	return GroupPair{GroupSnapshot: vgs, GroupContent: groupContent}, nil
+}
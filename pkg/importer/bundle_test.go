@@ -0,0 +1,194 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+func newReadySnapshot(name, contentName string) *crdv1.VolumeSnapshot {
+	ready := true
+	return &crdv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app", UID: types.UID(name + "-uid")},
+		Status: &crdv1.VolumeSnapshotStatus{
+			BoundVolumeSnapshotContentName: &contentName,
+			ReadyToUse:                     &ready,
+		},
+	}
+}
+
+func newReadyContent(name, snapshotName, snapshotHandle, groupSnapshotHandle string) *crdv1.VolumeSnapshotContent {
+	ready := true
+	size := int64(1 << 30)
+	volumeHandle := "vol-" + snapshotName
+	className := "class"
+	mode := v1.PersistentVolumeBlock
+	content := &crdv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: crdv1.VolumeSnapshotContentSpec{
+			Driver:                  testDriverName,
+			VolumeSnapshotRef:       v1.ObjectReference{Name: snapshotName, Namespace: "app", UID: types.UID(snapshotName + "-uid")},
+			Source:                  crdv1.VolumeSnapshotContentSource{VolumeHandle: &volumeHandle},
+			VolumeSnapshotClassName: &className,
+			SourceVolumeMode:        &mode,
+		},
+		Status: &crdv1.VolumeSnapshotContentStatus{
+			SnapshotHandle: &snapshotHandle,
+			RestoreSize:    &size,
+			ReadyToUse:     &ready,
+		},
+	}
+	if groupSnapshotHandle != "" {
+		content.Status.VolumeGroupSnapshotHandle = &groupSnapshotHandle
+	}
+	return content
+}
+
+func TestExportAndImportBundle(t *testing.T) {
+	ready := true
+	groupContentName := "groupsnapcontent-1"
+	groupHandle := "group-handle"
+	primary := fake.NewSimpleClientset(
+		newReadySnapshot("db", "content-db"),
+		newReadyContent("content-db", "db", "snap-db", ""),
+		newReadySnapshot("member-b", "content-b"),
+		newReadyContent("content-b", "member-b", "snap-b", groupHandle),
+		newReadySnapshot("member-a", "content-a"),
+		newReadyContent("content-a", "member-a", "snap-a", groupHandle),
+		&groupsnapshotv1.VolumeGroupSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "app", UID: "group-uid"},
+			Status: &groupsnapshotv1.VolumeGroupSnapshotStatus{
+				BoundVolumeGroupSnapshotContentName: &groupContentName,
+				ReadyToUse:                          &ready,
+			},
+		},
+		&groupsnapshotv1.VolumeGroupSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{Name: groupContentName},
+			Spec: groupsnapshotv1.VolumeGroupSnapshotContentSpec{
+				Driver:                 testDriverName,
+				VolumeGroupSnapshotRef: v1.ObjectReference{Name: "group", Namespace: "app", UID: "group-uid"},
+			},
+			Status: &groupsnapshotv1.VolumeGroupSnapshotContentStatus{
+				VolumeGroupSnapshotHandle: &groupHandle,
+				VolumeSnapshotInfoList: []groupsnapshotv1.VolumeSnapshotInfo{
+					{VolumeHandle: "vol-member-a", SnapshotHandle: "snap-a"},
+					{VolumeHandle: "vol-member-b", SnapshotHandle: "snap-b"},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	bundle, err := Export(ctx, primary, "app", []string{"db"}, []string{"group"})
+	if err != nil {
+		t.Fatalf("unexpected error exporting the bundle: %v", err)
+	}
+	if bundle.APIVersion != BundleAPIVersion || bundle.Kind != BundleKind || bundle.Driver != testDriverName {
+		t.Errorf("unexpected bundle header %s %s %s", bundle.APIVersion, bundle.Kind, bundle.Driver)
+	}
+	if len(bundle.Snapshots) != 1 || bundle.Snapshots[0].SnapshotHandle != "snap-db" || *bundle.Snapshots[0].RestoreSize != 1<<30 {
+		t.Errorf("unexpected snapshots %+v", bundle.Snapshots)
+	}
+	if len(bundle.GroupSnapshots) != 1 || len(bundle.GroupSnapshots[0].Members) != 2 || bundle.GroupSnapshots[0].Members[0].Name != "member-a" {
+		t.Fatalf("unexpected group snapshots %+v", bundle.GroupSnapshots)
+	}
+
+	// The bundle is transferred as a document.
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("unexpected error encoding the bundle: %v", err)
+	}
+	decoded := &Bundle{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("unexpected error decoding the bundle: %v", err)
+	}
+
+	standby := fake.NewSimpleClientset(
+		&crdv1.VolumeSnapshotClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}, Driver: testDriverName},
+	)
+	imp := NewImporter(nil, kubefake.NewSimpleClientset(), standby, Config{DriverName: testDriverName})
+	plan, err := imp.PlanBundle(ctx, decoded)
+	if err != nil {
+		t.Fatalf("unexpected error planning the import: %v", err)
+	}
+	if len(plan.Pairs) != 1 || len(plan.GroupPairs) != 1 || len(plan.Skipped) != 0 {
+		t.Fatalf("unexpected plan with %d pairs, %d group pairs and skipped %+v", len(plan.Pairs), len(plan.GroupPairs), plan.Skipped)
+	}
+
+	pair := plan.Pairs[0]
+	if pair.Snapshot.Name != "db" || pair.Snapshot.Namespace != "app" || *pair.Content.Spec.Source.SnapshotHandle != "snap-db" {
+		t.Errorf("unexpected pair %s/%s for %s", pair.Snapshot.Namespace, pair.Snapshot.Name, *pair.Content.Spec.Source.SnapshotHandle)
+	}
+	if pair.Content.Spec.SourceVolumeMode == nil || *pair.Content.Spec.SourceVolumeMode != v1.PersistentVolumeBlock {
+		t.Errorf("expected the volume mode to be preserved, got %v", pair.Content.Spec.SourceVolumeMode)
+	}
+
+	groupPair := plan.GroupPairs[0]
+	handles := groupPair.GroupContent.Spec.Source.GroupSnapshotHandles
+	if handles == nil || handles.VolumeGroupSnapshotHandle != groupHandle || !reflect.DeepEqual(handles.VolumeSnapshotHandles, []string{"snap-a", "snap-b"}) {
+		t.Errorf("unexpected group snapshot handles %+v", handles)
+	}
+	if *groupPair.GroupSnapshot.Spec.Source.VolumeGroupSnapshotContentName != groupPair.GroupContent.Name || groupPair.GroupContent.Spec.VolumeGroupSnapshotRef.Name != "group" {
+		t.Errorf("expected the group snapshot and the group content to reference each other")
+	}
+	for _, member := range groupPair.Members {
+		if member.Content.Annotations[utils.VolumeGroupSnapshotHandleAnnotation] != groupHandle {
+			t.Errorf("expected member %s to be annotated with the group snapshot handle", member.Snapshot.Name)
+		}
+	}
+
+	// Re-importing the bundle is idempotent.
+	for range 2 {
+		if err := imp.Apply(ctx, plan); err != nil {
+			t.Fatalf("unexpected error applying the import: %v", err)
+		}
+	}
+	replan, err := imp.PlanBundle(ctx, decoded)
+	if err != nil {
+		t.Fatalf("unexpected error planning the import again: %v", err)
+	}
+	if len(replan.Skipped) != 0 {
+		t.Errorf("expected no snapshot to be skipped when importing again, got %+v", replan.Skipped)
+	}
+	for _, name := range []string{"db", "member-a", "member-b"} {
+		if _, err := standby.SnapshotV1().VolumeSnapshots("app").Get(ctx, name, metav1.GetOptions{}); err != nil {
+			t.Errorf("expected VolumeSnapshot %s to be created: %v", name, err)
+		}
+	}
+	if _, err := standby.GroupsnapshotV1().VolumeGroupSnapshots("app").Get(ctx, "group", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected VolumeGroupSnapshot to be created: %v", err)
+	}
+
+	// A bundle of another driver is rejected.
+	imp = NewImporter(nil, kubefake.NewSimpleClientset(), standby, Config{DriverName: "other.csi.driver"})
+	if _, err := imp.PlanBundle(ctx, decoded); err == nil {
+		t.Errorf("expected a bundle of another driver to be rejected")
+	}
+}
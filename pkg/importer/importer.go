@@ -0,0 +1,375 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importer turns snapshots listed by a CSI driver into
+// pre-provisioned VolumeSnapshot/VolumeSnapshotContent pairs.
+//
+// Every snapshot whose source volume is backed by a PersistentVolume of the
+// driver is imported into the namespace of the claim bound to that volume.
+// The generated objects reference each other, so that the snapshot
+// controller binds them like any other pre-provisioned snapshot. Object
+// names are derived from the driver name and the snapshot handle, which
+// makes repeated imports idempotent.
+//
+// Snapshots and group snapshots can also be exported into a versioned
+// Bundle and imported from it in another cluster that can access the same
+// storage system, keeping the names of the VolumeSnapshots and
+// VolumeGroupSnapshots.
+package importer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// Config describes which snapshots are imported and how the generated
+// objects look like.
+type Config struct {
+	// DriverName is the name of the CSI driver owning the snapshots.
+	DriverName string
+	// SourceVolumeID restricts the import to snapshots of a single volume.
+	// Empty imports the snapshots of all volumes.
+	SourceVolumeID string
+	// SnapshotClassName is set on the generated objects. Its list secret,
+	// if any, is used to call ListSnapshots.
+	SnapshotClassName string
+	// DeletionPolicy of the generated contents.
+	DeletionPolicy crdv1.DeletionPolicy
+	// Namespace overrides the namespace of the snapshots imported from a
+	// bundle. Empty keeps the namespaces recorded in the bundle.
+	Namespace string
+	// GroupSnapshotClassName overrides the class of the group snapshots
+	// imported from a bundle.
+	GroupSnapshotClassName string
+	// CredentialProviders are used to get the list secret of the snapshot
+	// class. Nil reads Kubernetes Secrets only.
+	CredentialProviders utils.CredentialProviders
+}
+
+// Pair is a VolumeSnapshot and the pre-provisioned VolumeSnapshotContent it
+// is bound to.
+type Pair struct {
+	Snapshot *crdv1.VolumeSnapshot
+	Content  *crdv1.VolumeSnapshotContent
+}
+
+// SkippedSnapshot is a snapshot listed by the driver that is not imported.
+type SkippedSnapshot struct {
+	SnapshotID     string
+	SourceVolumeID string
+	Reason         string
+}
+
+// Plan is the result of matching the snapshots of the driver with the
+// PersistentVolumes of the cluster.
+type Plan struct {
+	Pairs      []Pair
+	GroupPairs []GroupPair
+	Skipped    []SkippedSnapshot
+}
+
+// Importer generates and creates pre-provisioned snapshots.
+type Importer struct {
+	snapshotter snapshotter.Snapshotter
+	kubeClient  kubernetes.Interface
+	snapClient  clientset.Interface
+	config      Config
+}
+
+// NewImporter returns a new *Importer. The snapshotter is only used by Plan
+// and may be nil when importing bundles.
+func NewImporter(snapshotter snapshotter.Snapshotter, kubeClient kubernetes.Interface, snapClient clientset.Interface, config Config) *Importer {
+	if config.DeletionPolicy == "" {
+		config.DeletionPolicy = crdv1.VolumeSnapshotContentRetain
+	}
+	if config.CredentialProviders == nil {
+		config.CredentialProviders = utils.NewCredentialProviders(kubeClient, "", nil, 0)
+	}
+	return &Importer{
+		snapshotter: snapshotter,
+		kubeClient:  kubeClient,
+		snapClient:  snapClient,
+		config:      config,
+	}
+}
+
+// Plan lists the snapshots of the driver and generates a pair for every
+// snapshot that can be mapped to a bound PersistentVolume and that is not
+// already referenced by a VolumeSnapshotContent.
+func (i *Importer) Plan(ctx context.Context) (*Plan, error) {
+	var class *crdv1.VolumeSnapshotClass
+	if i.config.SnapshotClassName != "" {
+		var err error
+		class, err = i.snapClient.SnapshotV1().VolumeSnapshotClasses().Get(ctx, i.config.SnapshotClassName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get snapshot class %s: %v", i.config.SnapshotClassName, err)
+		}
+		if class.Driver != i.config.DriverName {
+			return nil, fmt.Errorf("snapshot class %s belongs to driver %s, not %s", class.Name, class.Driver, i.config.DriverName)
+		}
+	}
+
+	credentials, err := i.getListCredentials(ctx, class)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := i.snapshotter.ListSnapshots(ctx, i.config.SourceVolumeID, credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	sort.Slice(snapshots, func(a, b int) bool { return snapshots[a].SnapshotID < snapshots[b].SnapshotID })
+
+	volumes, err := i.getVolumesByHandle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := i.getExistingSnapshotHandles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	for _, snapshot := range snapshots {
+		skip := func(reason string) {
+			klog.V(4).Infof("Skipping snapshot %s of volume %s: %s", snapshot.SnapshotID, snapshot.SourceVolumeID, reason)
+			plan.Skipped = append(plan.Skipped, SkippedSnapshot{
+				SnapshotID:     snapshot.SnapshotID,
+				SourceVolumeID: snapshot.SourceVolumeID,
+				Reason:         reason,
+			})
+		}
+
+		if name, ok := existing[snapshot.SnapshotID]; ok {
+			skip(fmt.Sprintf("already referenced by VolumeSnapshotContent %s", name))
+			continue
+		}
+		if snapshot.GroupSnapshotID != "" {
+			skip(fmt.Sprintf("member of group snapshot %s", snapshot.GroupSnapshotID))
+			continue
+		}
+		pvs := volumes[utils.PersistentVolumeKeyFuncByCSIDriverHandle(i.config.DriverName, snapshot.SourceVolumeID)]
+		switch {
+		case len(pvs) == 0:
+			skip("no PersistentVolume found for the source volume")
+			continue
+		case len(pvs) > 1:
+			skip("multiple PersistentVolumes found for the source volume")
+			continue
+		case pvs[0].Spec.ClaimRef == nil:
+			skip(fmt.Sprintf("PersistentVolume %s is not bound to a claim", pvs[0].Name))
+			continue
+		}
+		pair, err := i.newPair(ctx, snapshot, pvs[0], class)
+		if err != nil {
+			skip(err.Error())
+			continue
+		}
+		plan.Pairs = append(plan.Pairs, pair)
+	}
+	return plan, nil
+}
+
+// Apply creates the objects of the plan. Objects that already exist are
+// left untouched.
+func (i *Importer) Apply(ctx context.Context, plan *Plan) error {
+	for _, pair := range plan.Pairs {
+		// Create the content first, the snapshot controller binds the
+		// snapshot to it as soon as the snapshot exists.
+		if _, err := i.snapClient.SnapshotV1().VolumeSnapshotContents().Create(ctx, pair.Content, metav1.CreateOptions{}); err != nil && !apierrs.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create VolumeSnapshotContent %s: %v", pair.Content.Name, err)
+		}
+		if _, err := i.snapClient.SnapshotV1().VolumeSnapshots(pair.Snapshot.Namespace).Create(ctx, pair.Snapshot, metav1.CreateOptions{}); err != nil && !apierrs.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create VolumeSnapshot %s/%s: %v", pair.Snapshot.Namespace, pair.Snapshot.Name, err)
+		}
+		klog.Infof("Imported snapshot %s as VolumeSnapshot %s/%s", *pair.Content.Spec.Source.SnapshotHandle, pair.Snapshot.Namespace, pair.Snapshot.Name)
+	}
+	for _, groupPair := range plan.GroupPairs {
+		// Import the members first, so that they exist when the group
+		// snapshot becomes ready.
+		if err := i.Apply(ctx, &Plan{Pairs: groupPair.Members}); err != nil {
+			return err
+		}
+		if _, err := i.snapClient.GroupsnapshotV1().VolumeGroupSnapshotContents().Create(ctx, groupPair.GroupContent, metav1.CreateOptions{}); err != nil && !apierrs.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create VolumeGroupSnapshotContent %s: %v", groupPair.GroupContent.Name, err)
+		}
+		if _, err := i.snapClient.GroupsnapshotV1().VolumeGroupSnapshots(groupPair.GroupSnapshot.Namespace).Create(ctx, groupPair.GroupSnapshot, metav1.CreateOptions{}); err != nil && !apierrs.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create VolumeGroupSnapshot %s/%s: %v", groupPair.GroupSnapshot.Namespace, groupPair.GroupSnapshot.Name, err)
+		}
+		klog.Infof("Imported group snapshot %s as VolumeGroupSnapshot %s/%s", groupPair.GroupContent.Spec.Source.GroupSnapshotHandles.VolumeGroupSnapshotHandle, groupPair.GroupSnapshot.Namespace, groupPair.GroupSnapshot.Name)
+	}
+	return nil
+}
+
+func (i *Importer) getListCredentials(ctx context.Context, class *crdv1.VolumeSnapshotClass) (map[string]string, error) {
+	if class == nil {
+		return nil, nil
+	}
+	ref, err := utils.GetSecretReference(utils.SnapshotterListSecretParams, class.Parameters, "", nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list secret reference of snapshot class %s: %v", class.Name, err)
+	}
+	provider, err := utils.GetSecretProvider(class.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret provider of snapshot class %s: %v", class.Name, err)
+	}
+	credentials, err := i.config.CredentialProviders.GetCredentials(ctx, provider, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list credentials of snapshot class %s: %v", class.Name, err)
+	}
+	return credentials, nil
+}
+
+// getVolumesByHandle returns the CSI PersistentVolumes of the cluster
+// indexed the same way as the snapshot controller's PV indexer.
+func (i *Importer) getVolumesByHandle(ctx context.Context) (map[string][]*v1.PersistentVolume, error) {
+	pvList, err := i.kubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumes: %v", err)
+	}
+	volumes := map[string][]*v1.PersistentVolume{}
+	for idx := range pvList.Items {
+		pv := &pvList.Items[idx]
+		if key := utils.PersistentVolumeKeyFunc(pv); key != "" {
+			volumes[key] = append(volumes[key], pv)
+		}
+	}
+	return volumes, nil
+}
+
+// getExistingSnapshotHandles returns the names of the contents of the
+// driver indexed by their snapshot handle.
+func (i *Importer) getExistingSnapshotHandles(ctx context.Context) (map[string]string, error) {
+	contentList, err := i.snapClient.SnapshotV1().VolumeSnapshotContents().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VolumeSnapshotContents: %v", err)
+	}
+	handles := map[string]string{}
+	for _, content := range contentList.Items {
+		if content.Spec.Driver != i.config.DriverName {
+			continue
+		}
+		if content.Spec.Source.SnapshotHandle != nil {
+			handles[*content.Spec.Source.SnapshotHandle] = content.Name
+		}
+		if content.Status != nil && content.Status.SnapshotHandle != nil {
+			handles[*content.Status.SnapshotHandle] = content.Name
+		}
+	}
+	return handles, nil
+}
+
+func (i *Importer) newPair(ctx context.Context, snapshot snapshotter.SnapshotInfo, pv *v1.PersistentVolume, class *crdv1.VolumeSnapshotClass) (Pair, error) {
+	// The claim is only needed to resolve the secret templates of the
+	// class. Templates that refer to a claim that no longer exists fail.
+	var pvc *v1.PersistentVolumeClaim
+	if class != nil {
+		var err error
+		pvc, err = i.kubeClient.CoreV1().PersistentVolumeClaims(pv.Spec.ClaimRef.Namespace).Get(ctx, pv.Spec.ClaimRef.Name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			pvc = nil
+		} else if err != nil {
+			return Pair{}, fmt.Errorf("failed to get PersistentVolumeClaim %s/%s: %v", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name, err)
+		}
+	}
+	snapshotName, contentName := getImportedSnapshotNames(i.config.DriverName, snapshot.SnapshotID)
+	return i.newPreprovisionedPair(snapshotName, pv.Spec.ClaimRef.Namespace, contentName, snapshot.SnapshotID, pv.Spec.VolumeMode, class, pvc, pv)
+}
+
+// newPreprovisionedPair returns a VolumeSnapshot and a pre-provisioned
+// VolumeSnapshotContent of the given snapshot handle that reference each other.
+// pvc and pv are the source volume of the snapshot, if known.
+func (i *Importer) newPreprovisionedPair(snapshotName, namespace, contentName, snapshotHandle string, volumeMode *v1.PersistentVolumeMode, class *crdv1.VolumeSnapshotClass, pvc *v1.PersistentVolumeClaim, pv *v1.PersistentVolume) (Pair, error) {
+	contentRef := contentName
+
+	vs := &crdv1.VolumeSnapshot{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: crdv1.SchemeGroupVersion.String(),
+			Kind:       "VolumeSnapshot",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshotName,
+			Namespace: namespace,
+		},
+		Spec: crdv1.VolumeSnapshotSpec{
+			Source: crdv1.VolumeSnapshotSource{
+				VolumeSnapshotContentName: &contentRef,
+			},
+		},
+	}
+	content := &crdv1.VolumeSnapshotContent{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: crdv1.SchemeGroupVersion.String(),
+			Kind:       "VolumeSnapshotContent",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: contentName,
+		},
+		Spec: crdv1.VolumeSnapshotContentSpec{
+			VolumeSnapshotRef: v1.ObjectReference{
+				APIVersion: crdv1.SchemeGroupVersion.String(),
+				Kind:       "VolumeSnapshot",
+				Name:       snapshotName,
+				Namespace:  namespace,
+			},
+			DeletionPolicy: i.config.DeletionPolicy,
+			Driver:         i.config.DriverName,
+			Source: crdv1.VolumeSnapshotContentSource{
+				SnapshotHandle: &snapshotHandle,
+			},
+			SourceVolumeMode: volumeMode,
+		},
+	}
+	if class != nil {
+		className := class.Name
+		vs.Spec.VolumeSnapshotClassName = &className
+		content.Spec.VolumeSnapshotClassName = &className
+
+		// Pre-provisioned contents carry the secret used by the sidecar
+		// to delete the snapshot in annotations.
+		secretRef, err := utils.GetSecretReference(utils.SnapshotterSecretParams, class.Parameters, contentName, vs, pvc, pv)
+		if err != nil {
+			return Pair{}, fmt.Errorf("failed to get secret reference of snapshot class %s: %v", class.Name, err)
+		}
+		if secretRef != nil {
+			metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnDeletionSecretRefName, secretRef.Name)
+			metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnDeletionSecretRefNamespace, secretRef.Namespace)
+			if err := utils.SetSecretProviderAnnotation(&content.ObjectMeta, utils.AnnDeletionSecretRefProvider, class.Parameters); err != nil {
+				return Pair{}, fmt.Errorf("failed to get secret provider of snapshot class %s: %v", class.Name, err)
+			}
+		}
+	}
+	return Pair{Snapshot: vs, Content: content}, nil
+}
+
+// getImportedSnapshotNames returns the names of the VolumeSnapshot and the
+// VolumeSnapshotContent generated for a snapshot handle.
+func getImportedSnapshotNames(driverName, snapshotHandle string) (string, string) {
+	hash := sha256.Sum256([]byte(driverName + "^" + snapshotHandle))
+	return fmt.Sprintf("snapshot-%x", hash), fmt.Sprintf("snapcontent-%x", hash)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+const testDriverName = "hostpath.csi.k8s.io"
+
+type fakeSnapshotter struct {
+	snapshotter.Snapshotter
+	snapshots      []snapshotter.SnapshotInfo
+	sourceVolumeID string
+	credentials    map[string]string
+}
+
+func (f *fakeSnapshotter) ListSnapshots(ctx context.Context, sourceVolumeID string, snapshotterListCredentials map[string]string) ([]snapshotter.SnapshotInfo, error) {
+	f.sourceVolumeID = sourceVolumeID
+	f.credentials = snapshotterListCredentials
+	return f.snapshots, nil
+}
+
+func newPV(name, volumeHandle string, claimRef *v1.ObjectReference) *v1.PersistentVolume {
+	return &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{
+					Driver:       testDriverName,
+					VolumeHandle: volumeHandle,
+				},
+			},
+			ClaimRef: claimRef,
+		},
+	}
+}
+
+func TestImporter(t *testing.T) {
+	existingHandle := "snap-existing"
+	kubeClient := kubefake.NewSimpleClientset(
+		newPV("pv-1", "vol-1", &v1.ObjectReference{Namespace: "app", Name: "data"}),
+		newPV("pv-2", "vol-2", nil),
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "list-secret", Namespace: "kube-system"},
+			Data:       map[string][]byte{"token": []byte("secret")},
+		},
+	)
+	snapClient := fake.NewSimpleClientset(
+		&crdv1.VolumeSnapshotClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "class"},
+			Driver:     testDriverName,
+			Parameters: map[string]string{
+				"csi.storage.k8s.io/snapshotter-list-secret-name":      "list-secret",
+				"csi.storage.k8s.io/snapshotter-list-secret-namespace": "kube-system",
+				"csi.storage.k8s.io/snapshotter-secret-name":           "${volumesnapshot.name}",
+				"csi.storage.k8s.io/snapshotter-secret-namespace":      "${volumesnapshot.namespace}",
+			},
+			DeletionPolicy: crdv1.VolumeSnapshotContentDelete,
+		},
+		&crdv1.VolumeSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{Name: "existing-content"},
+			Spec: crdv1.VolumeSnapshotContentSpec{
+				Driver: testDriverName,
+				Source: crdv1.VolumeSnapshotContentSource{SnapshotHandle: &existingHandle},
+			},
+		},
+	)
+	fakeSnapshotter := &fakeSnapshotter{
+		snapshots: []snapshotter.SnapshotInfo{
+			{SnapshotID: "snap-1", SourceVolumeID: "vol-1", CreationTime: time.Now(), ReadyToUse: true},
+			{SnapshotID: "snap-unbound", SourceVolumeID: "vol-2"},
+			{SnapshotID: "snap-unknown", SourceVolumeID: "vol-3"},
+			{SnapshotID: "snap-group", SourceVolumeID: "vol-1", GroupSnapshotID: "group-1"},
+			{SnapshotID: existingHandle, SourceVolumeID: "vol-1"},
+		},
+	}
+
+	imp := NewImporter(fakeSnapshotter, kubeClient, snapClient, Config{
+		DriverName:        testDriverName,
+		SourceVolumeID:    "vol-1",
+		SnapshotClassName: "class",
+	})
+	ctx := context.Background()
+	plan, err := imp.Plan(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error planning the import: %v", err)
+	}
+
+	if fakeSnapshotter.sourceVolumeID != "vol-1" {
+		t.Errorf("expected ListSnapshots to be filtered by vol-1, got %q", fakeSnapshotter.sourceVolumeID)
+	}
+	if !reflect.DeepEqual(fakeSnapshotter.credentials, map[string]string{"token": "secret"}) {
+		t.Errorf("expected the list secret of the class to be used, got %v", fakeSnapshotter.credentials)
+	}
+
+	var skipped []string
+	for _, s := range plan.Skipped {
+		skipped = append(skipped, s.SnapshotID)
+	}
+	expectedSkipped := []string{"snap-existing", "snap-group", "snap-unbound", "snap-unknown"}
+	if !reflect.DeepEqual(skipped, expectedSkipped) {
+		t.Errorf("expected skipped snapshots %v, got %v", expectedSkipped, skipped)
+	}
+	if len(plan.Pairs) != 1 {
+		t.Fatalf("expected 1 snapshot to be imported, got %d", len(plan.Pairs))
+	}
+
+	pair := plan.Pairs[0]
+	if pair.Snapshot.Namespace != "app" || pair.Content.Spec.VolumeSnapshotRef.Namespace != "app" {
+		t.Errorf("expected the snapshot to be imported into the namespace of the claim")
+	}
+	if pair.Content.Spec.VolumeSnapshotRef.Name != pair.Snapshot.Name || *pair.Snapshot.Spec.Source.VolumeSnapshotContentName != pair.Content.Name {
+		t.Errorf("expected the snapshot and the content to reference each other")
+	}
+	if *pair.Content.Spec.Source.SnapshotHandle != "snap-1" {
+		t.Errorf("expected snapshot handle snap-1, got %s", *pair.Content.Spec.Source.SnapshotHandle)
+	}
+	if pair.Content.Annotations[utils.AnnDeletionSecretRefName] != pair.Snapshot.Name || pair.Content.Annotations[utils.AnnDeletionSecretRefNamespace] != "app" {
+		t.Errorf("expected the deletion secret of the class to be recorded, got annotations %v", pair.Content.Annotations)
+	}
+	if pair.Content.Spec.DeletionPolicy != crdv1.VolumeSnapshotContentRetain {
+		t.Errorf("expected deletion policy Retain by default, got %s", pair.Content.Spec.DeletionPolicy)
+	}
+
+	// Applying twice must not fail.
+	for range 2 {
+		if err := imp.Apply(ctx, plan); err != nil {
+			t.Fatalf("unexpected error applying the import: %v", err)
+		}
+	}
+	if _, err := snapClient.SnapshotV1().VolumeSnapshots("app").Get(ctx, pair.Snapshot.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected VolumeSnapshot to be created: %v", err)
+	}
+	if _, err := snapClient.SnapshotV1().VolumeSnapshotContents().Get(ctx, pair.Content.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected VolumeSnapshotContent to be created: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recyclebin lists and restores the VolumeSnapshotContents that the
+// snapshot controller moved to the recycle bin when their VolumeSnapshot was
+// deleted.
+//
+// A content in the recycle bin is not bound to a VolumeSnapshot and has the
+// snapshot.storage.kubernetes.io/purge-after annotation, after which the
+// CSI external-snapshotter sidecar deletes the content and the snapshot on
+// the storage system. Restoring the content binds it to a new pre-provisioned
+// VolumeSnapshot instead.
+package recyclebin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	clientset "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// List returns the contents in the recycle bin, ordered by their purge time.
+func List(ctx context.Context, snapClient clientset.Interface) ([]crdv1.VolumeSnapshotContent, error) {
+	contents, err := snapClient.SnapshotV1().VolumeSnapshotContents().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VolumeSnapshotContents: %v", err)
+	}
+	var recycled []crdv1.VolumeSnapshotContent
+	for _, content := range contents.Items {
+		if utils.IsContentInRecycleBin(&content) {
+			recycled = append(recycled, content)
+		}
+	}
+	sort.SliceStable(recycled, func(i, j int) bool {
+		return recycled[i].Annotations[utils.AnnPurgeAfter] < recycled[j].Annotations[utils.AnnPurgeAfter]
+	})
+	return recycled, nil
+}
+
+// Undelete restores the content with the given name from the recycle bin and
+// binds it to a new pre-provisioned VolumeSnapshot with the given namespace
+// and name. Empty namespace and name restore the deleted VolumeSnapshot. The
+// content keeps its deletion policy.
+func Undelete(ctx context.Context, snapClient clientset.Interface, contentName, namespace, name string) (*crdv1.VolumeSnapshot, error) {
+	content, err := snapClient.SnapshotV1().VolumeSnapshotContents().Get(ctx, contentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VolumeSnapshotContent %s: %v", contentName, err)
+	}
+	if !utils.IsContentInRecycleBin(content) {
+		return nil, fmt.Errorf("VolumeSnapshotContent %s is not in the recycle bin", contentName)
+	}
+	if content.DeletionTimestamp != nil {
+		return nil, fmt.Errorf("VolumeSnapshotContent %s is being purged", contentName)
+	}
+	if namespace == "" {
+		namespace = content.Spec.VolumeSnapshotRef.Namespace
+	}
+	if name == "" {
+		name = content.Spec.VolumeSnapshotRef.Name
+	}
+	_, err = snapClient.SnapshotV1().VolumeSnapshots(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return nil, fmt.Errorf("VolumeSnapshot %s/%s already exists", namespace, name)
+	}
+	if !apierrs.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get VolumeSnapshot %s/%s: %v", namespace, name, err)
+	}
+
+	// The update fails with a conflict if the sidecar purges the content
+	// at the same time.
+	restored := content.DeepCopy()
+	restored.Spec.VolumeSnapshotRef.Namespace = namespace
+	restored.Spec.VolumeSnapshotRef.Name = name
+	restored.Spec.VolumeSnapshotRef.ResourceVersion = ""
+	delete(restored.Annotations, utils.AnnPurgeAfter)
+	restored, err = snapClient.SnapshotV1().VolumeSnapshotContents().Update(ctx, restored, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore VolumeSnapshotContent %s: %v", contentName, err)
+	}
+
+	snapshot := &crdv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: crdv1.VolumeSnapshotSpec{
+			Source: crdv1.VolumeSnapshotSource{
+				VolumeSnapshotContentName: &contentName,
+			},
+			VolumeSnapshotClassName: content.Spec.VolumeSnapshotClassName,
+		},
+	}
+	snapshot, err = snapClient.SnapshotV1().VolumeSnapshots(namespace).Create(ctx, snapshot, metav1.CreateOptions{})
+	if err != nil {
+		// Put the content back into the recycle bin, so that it is not leaked.
+		restored.Spec.VolumeSnapshotRef = content.Spec.VolumeSnapshotRef
+		metav1.SetMetaDataAnnotation(&restored.ObjectMeta, utils.AnnPurgeAfter, content.Annotations[utils.AnnPurgeAfter])
+		if _, updateErr := snapClient.SnapshotV1().VolumeSnapshotContents().Update(ctx, restored, metav1.UpdateOptions{}); updateErr != nil {
+			klog.Errorf("failed to move VolumeSnapshotContent %s back to the recycle bin: %v", contentName, updateErr)
+		}
+		return nil, fmt.Errorf("failed to create VolumeSnapshot %s/%s: %v", namespace, name, err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recyclebin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+func newContent(name, snapshotUID, purgeAfter string) *crdv1.VolumeSnapshotContent {
+	volumeHandle := "volume-" + name
+	className := "gold"
+	content := &crdv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: crdv1.VolumeSnapshotContentSpec{
+			VolumeSnapshotRef: v1.ObjectReference{Namespace: "app", Name: "snap-" + name, UID: types.UID(snapshotUID)},
+			DeletionPolicy:    crdv1.VolumeSnapshotContentDelete,
+			Driver:            "hostpath.csi.k8s.io",
+			Source: crdv1.VolumeSnapshotContentSource{
+				VolumeHandle: &volumeHandle,
+			},
+			VolumeSnapshotClassName: &className,
+		},
+	}
+	if purgeAfter != "" {
+		metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnPurgeAfter, purgeAfter)
+	}
+	return content
+}
+
+func TestList(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newContent("later", "", "2026-02-01T00:00:00Z"),
+		newContent("sooner", "", "2026-01-01T00:00:00Z"),
+		newContent("bound", "uid", ""),
+	)
+	contents, err := List(context.TODO(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(contents) != 2 || contents[0].Name != "sooner" || contents[1].Name != "later" {
+		t.Errorf("expected the contents sooner and later, got %v", contents)
+	}
+}
+
+func TestUndelete(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newContent("recycled", "", "2026-01-01T00:00:00Z"),
+		newContent("bound", "uid", ""),
+	)
+	ctx := context.TODO()
+
+	if _, err := Undelete(ctx, client, "bound", "", ""); err == nil {
+		t.Errorf("expected an error for a content that is not in the recycle bin")
+	}
+
+	snapshot, err := Undelete(ctx, client, "recycled", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.Namespace != "app" || snapshot.Name != "snap-recycled" {
+		t.Errorf("expected the deleted snapshot app/snap-recycled to be restored, got %s/%s", snapshot.Namespace, snapshot.Name)
+	}
+	if snapshot.Spec.Source.VolumeSnapshotContentName == nil || *snapshot.Spec.Source.VolumeSnapshotContentName != "recycled" {
+		t.Errorf("expected the snapshot to refer to the content, got %+v", snapshot.Spec.Source)
+	}
+	content, err := client.SnapshotV1().VolumeSnapshotContents().Get(ctx, "recycled", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if utils.IsContentInRecycleBin(content) {
+		t.Errorf("expected the content to be restored, got annotations %v", content.Annotations)
+	}
+
+	// The content cannot be restored twice.
+	if _, err := Undelete(ctx, client, "recycled", "", ""); err == nil {
+		t.Errorf("expected an error for a restored content")
+	}
+}
+
+func TestUndeleteCreateFailure(t *testing.T) {
+	client := fake.NewSimpleClientset(newContent("recycled", "", "2026-01-01T00:00:00Z"))
+	client.PrependReactor("create", "volumesnapshots", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("mock create error")
+	})
+	ctx := context.TODO()
+
+	if _, err := Undelete(ctx, client, "recycled", "other", "restored"); err == nil {
+		t.Fatalf("expected an error")
+	}
+	content, err := client.SnapshotV1().VolumeSnapshotContents().Get(ctx, "recycled", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if !utils.IsContentInRecycleBin(content) || content.Spec.VolumeSnapshotRef.Namespace != "app" {
+		t.Errorf("expected the content to be back in the recycle bin, got %+v", content)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"time"
+
+	klog "k8s.io/klog/v2"
+
+	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/audit"
+)
+
+// auditContentOperation records a CreateSnapshot or DeleteSnapshot call for
+// content in the audit log. snapshotHandle is the handle returned by
+// CreateSnapshot, the handle of content is recorded if it is empty. err is
+// the error returned by the storage system.
+func (ctrl *csiSnapshotSideCarController) auditContentOperation(operation string, content *crdv1.VolumeSnapshotContent, snapshotHandle string, startTime time.Time, err error) {
+	if ctrl.auditLogger == nil {
+		return
+	}
+	record := audit.Record{
+		Operation: operation,
+		Driver:    ctrl.driverName,
+		Content:   content.Name,
+		RequestedBy: audit.ObjectRef{
+			Kind:      "VolumeSnapshot",
+			Namespace: content.Spec.VolumeSnapshotRef.Namespace,
+			Name:      content.Spec.VolumeSnapshotRef.Name,
+			UID:       string(content.Spec.VolumeSnapshotRef.UID),
+		},
+		SnapshotHandle: snapshotHandle,
+	}
+	if snapshotHandle == "" {
+		if content.Status != nil && content.Status.SnapshotHandle != nil {
+			record.SnapshotHandle = *content.Status.SnapshotHandle
+		} else if content.Spec.Source.SnapshotHandle != nil {
+			record.SnapshotHandle = *content.Spec.Source.SnapshotHandle
+		}
+	}
+	if content.Spec.VolumeSnapshotClassName != nil {
+		record.Class = *content.Spec.VolumeSnapshotClassName
+	}
+	if content.Spec.Source.VolumeHandle != nil {
+		record.SourceVolumeHandles = []string{*content.Spec.Source.VolumeHandle}
+	}
+	if content.Spec.Source.SourceSnapshotHandle != nil {
+		record.SourceSnapshotHandle = *content.Spec.Source.SourceSnapshotHandle
+	}
+	ctrl.writeAuditRecord(record, startTime, err)
+}
+
+// auditGroupSnapshotContentOperation records a CreateGroupSnapshot or
+// DeleteGroupSnapshot call for groupSnapshotContent in the audit log. The
+// group snapshot handle of groupSnapshotContent is recorded if
+// groupSnapshotHandle is empty.
+func (ctrl *csiSnapshotSideCarController) auditGroupSnapshotContentOperation(operation string, groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent, groupSnapshotHandle string, snapshotHandles []string, startTime time.Time, err error) {
+	if ctrl.auditLogger == nil {
+		return
+	}
+	record := audit.Record{
+		Operation: operation,
+		Driver:    ctrl.driverName,
+		Content:   groupSnapshotContent.Name,
+		RequestedBy: audit.ObjectRef{
+			Kind:      "VolumeGroupSnapshot",
+			Namespace: groupSnapshotContent.Spec.VolumeGroupSnapshotRef.Namespace,
+			Name:      groupSnapshotContent.Spec.VolumeGroupSnapshotRef.Name,
+			UID:       string(groupSnapshotContent.Spec.VolumeGroupSnapshotRef.UID),
+		},
+		GroupSnapshotHandle: groupSnapshotHandle,
+		SnapshotHandles:     snapshotHandles,
+		SourceVolumeHandles: groupSnapshotContent.Spec.Source.VolumeHandles,
+	}
+	if groupSnapshotHandle == "" {
+		if groupSnapshotContent.Status != nil && groupSnapshotContent.Status.VolumeGroupSnapshotHandle != nil {
+			record.GroupSnapshotHandle = *groupSnapshotContent.Status.VolumeGroupSnapshotHandle
+		} else if groupSnapshotContent.Spec.Source.GroupSnapshotHandles != nil {
+			record.GroupSnapshotHandle = groupSnapshotContent.Spec.Source.GroupSnapshotHandles.VolumeGroupSnapshotHandle
+		}
+	}
+	if groupSnapshotContent.Spec.VolumeGroupSnapshotClassName != nil {
+		record.Class = *groupSnapshotContent.Spec.VolumeGroupSnapshotClassName
+	}
+	ctrl.writeAuditRecord(record, startTime, err)
+}
+
+func (ctrl *csiSnapshotSideCarController) writeAuditRecord(record audit.Record, startTime time.Time, err error) {
+	record.StartTime = startTime.UTC()
+	record.EndTime = time.Now().UTC()
+	record.Result = audit.ResultSuccess
+	if err != nil {
+		record.Result = audit.ResultFailure
+		record.Error = err.Error()
+	}
+	if err := ctrl.auditLogger.Log(record); err != nil {
+		klog.Errorf("failed to write audit record of %s for %s: %v", record.Operation, record.Content, err)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/audit"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeAuditSink struct {
+	records []audit.Record
+}
+
+func (s *fakeAuditSink) Write(line []byte) error {
+	var record audit.Record
+	if err := json.Unmarshal(line, &record); err != nil {
+		return err
+	}
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestAuditDeleteSnapshot(t *testing.T) {
+	content := newContent("content1-1", "snapuid1-1", "snap1-1", "sid1-1", classGold, "", "volume1-1", deletionPolicy, nil, &defaultSize, true, &timeNowMetav1)
+	test := controllerTest{
+		expectedDeleteCalls: []deleteCall{
+			{snapshotID: "sid1-1", err: errors.New("mock delete error")},
+			{snapshotID: "sid1-1"},
+		},
+	}
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), fake.NewSimpleClientset(content), nil, t, test)
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	sink := &fakeAuditSink{}
+	ctrl.auditLogger = audit.NewLogger(nil, "", sink)
+
+	if _, err := ctrl.deleteCSISnapshotOperation(content); err == nil {
+		t.Errorf("expected the first deletion to fail")
+	}
+	if _, err := ctrl.deleteCSISnapshotOperation(content); err != nil {
+		t.Errorf("unexpected error deleting the snapshot: %v", err)
+	}
+
+	ctrl.auditLogger.Close()
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected 2 audit records, got %+v", sink.records)
+	}
+	failed, deleted := sink.records[0], sink.records[1]
+	if failed.Result != audit.ResultFailure || failed.Error == "" || deleted.Result != audit.ResultSuccess || deleted.Error != "" {
+		t.Errorf("unexpected results %s %q and %s %q", failed.Result, failed.Error, deleted.Result, deleted.Error)
+	}
+	expected := audit.ObjectRef{Kind: "VolumeSnapshot", Namespace: testNamespace, Name: "snap1-1", UID: "snapuid1-1"}
+	for _, record := range sink.records {
+		if record.Operation != audit.OperationDeleteSnapshot || record.Driver != mockDriverName || record.Content != "content1-1" ||
+			record.SnapshotHandle != "sid1-1" || record.Class != classGold || record.RequestedBy != expected {
+			t.Errorf("unexpected audit record %+v", record)
+		}
+		if record.StartTime.IsZero() || record.EndTime.Before(record.StartTime) {
+			t.Errorf("unexpected timestamps %v and %v", record.StartTime, record.EndTime)
+		}
+	}
+	if deleted.PreviousHash != failed.Hash {
+		t.Errorf("expected the records to be chained")
+	}
+}
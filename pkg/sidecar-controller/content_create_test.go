@@ -290,6 +290,44 @@ func TestSyncContent(t *testing.T) {
 			expectSuccess: true,
 			test:          testSyncContent,
 		},
+		{
+			name: "1-10: Sync content create snapshot with accessible topology",
+			initialContents: withContentAnnotations(withContentStatus(newContentArray("content1-10", "snapuid1-10", "snap1-10", "sid1-10", defaultClass, "", "volume-handle-1-10", retainPolicy, nil, &defaultSize, true),
+				nil), map[string]string{
+				utils.AnnAccessibleTopology: `[{"matchLabelExpressions":[{"key":"topology.kubernetes.io/zone","values":["zone-a"]}]}]`,
+			}),
+			expectedContents: withContentAnnotations(withContentStatus(newContentArray("content1-10", "snapuid1-10", "snap1-10", "sid1-10", defaultClass, "", "volume-handle-1-10", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeSnapshotContentStatus{SnapshotHandle: toStringPointer("snapuid1-10"), RestoreSize: &defaultSize, ReadyToUse: &True, AccessibleTopology: []v1.TopologySelectorTerm{
+					{
+						MatchLabelExpressions: []v1.TopologySelectorLabelRequirement{
+							{Key: "topology.kubernetes.io/zone", Values: []string{"zone-a"}},
+						},
+					},
+				}}), map[string]string{
+				utils.AnnAccessibleTopology: `[{"matchLabelExpressions":[{"key":"topology.kubernetes.io/zone","values":["zone-a"]}]}]`,
+			}),
+			expectedEvents: noevents,
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle: "volume-handle-1-10",
+					snapshotName: "snapshot-snapuid1-10",
+					driverName:   mockDriverName,
+					snapshotId:   "snapuid1-10",
+					parameters: map[string]string{
+						utils.PrefixedVolumeSnapshotNameKey:        "snap1-10",
+						utils.PrefixedVolumeSnapshotNamespaceKey:   "default",
+						utils.PrefixedVolumeSnapshotContentNameKey: "content1-10",
+					},
+					creationTime: timeNow,
+					readyToUse:   true,
+					size:         defaultSize,
+				},
+			},
+			expectedListCalls: []listCall{{"sid1-10", map[string]string{}, true, time.Now(), 1, nil, ""}},
+			expectSuccess:     true,
+			errors:            noerrors,
+			test:              testSyncContent,
+		},
 	}
 
 	runSyncContentTests(t, tests, snapshotClasses)
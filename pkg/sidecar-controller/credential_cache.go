@@ -0,0 +1,250 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	klog "k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// With --secret-cache, the Kubernetes credential provider reads Secrets
+// from an informer and converts the data of a Secret once per resource
+// version. The informer is restricted to a namespace or a label selector, so
+// that the sidecar does not cache every Secret of the cluster; Secrets that
+// are not in the informer are read from the API server. When the data of a
+// cached Secret changes, the contents that reference it and are not ready yet
+// or are being deleted are requeued without backoff, so that an operation
+// that failed with rotated credentials is retried at once.
+
+const (
+	credentialLookupFailuresMetricName    = "credential_lookup_failures_total"
+	credentialLookupFailuresMetricHelpMsg = "Number of failed lookups of the credentials referenced by snapshot classes and contents"
+
+	credentialLookupFailureNotFound = "not_found"
+	credentialLookupFailureError    = "error"
+)
+
+var (
+	credentialLookupFailures = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Subsystem: "csi_snapshotter",
+			Name:      credentialLookupFailuresMetricName,
+			Help:      credentialLookupFailuresMetricHelpMsg,
+		},
+		[]string{"driver_name", "provider", "reason"},
+	)
+	credentialMetricsOnce sync.Once
+)
+
+// registerCredentialMetrics registers the credential metrics with the legacy
+// registry and initializes the failure counters of all providers, so that
+// they are exported before the first failure.
+func registerCredentialMetrics(driverName string, providers utils.CredentialProviders) {
+	credentialMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(credentialLookupFailures)
+	})
+	names := []string{utils.CredentialProviderKubernetes}
+	for name := range providers {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		credentialLookupFailures.WithLabelValues(driverName, name, credentialLookupFailureNotFound)
+		credentialLookupFailures.WithLabelValues(driverName, name, credentialLookupFailureError)
+	}
+}
+
+type credentialCacheEntry struct {
+	resourceVersion string
+	credentials     map[string]string
+}
+
+// credentialCache is a utils.CredentialProvider which reads Kubernetes
+// Secrets from an informer, and from fallback if they are not in the
+// informer.
+type credentialCache struct {
+	lister   corelisters.SecretLister
+	fallback utils.CredentialProvider
+
+	mutex sync.Mutex
+	// entries are keyed by <namespace>/<name> of the secret reference.
+	entries map[string]credentialCacheEntry
+}
+
+func newCredentialCache(lister corelisters.SecretLister, fallback utils.CredentialProvider) *credentialCache {
+	return &credentialCache{
+		lister:   lister,
+		fallback: fallback,
+		entries:  map[string]credentialCacheEntry{},
+	}
+}
+
+func (c *credentialCache) GetCredentials(ctx context.Context, ref *v1.SecretReference) (map[string]string, error) {
+	secret, err := c.lister.Secrets(ref.Namespace).Get(ref.Name)
+	if apierrs.IsNotFound(err) {
+		// The secret is missing or outside of the namespace and the
+		// label selector of the informer.
+		return c.fallback.GetCredentials(ctx, ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting secret %s in namespace %s: %w", ref.Name, ref.Namespace, err)
+	}
+
+	key := ref.Namespace + "/" + ref.Name
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || entry.resourceVersion != secret.ResourceVersion {
+		entry = credentialCacheEntry{
+			resourceVersion: secret.ResourceVersion,
+			credentials:     map[string]string{},
+		}
+		for key, value := range secret.Data {
+			entry.credentials[key] = string(value)
+		}
+		c.entries[key] = entry
+	}
+	// The caller may modify the credentials.
+	return maps.Clone(entry.credentials), nil
+}
+
+func (c *credentialCache) forget(namespace, name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, namespace+"/"+name)
+}
+
+// getCredentials retrieves the credentials of ref from the named credential
+// provider. obj is the content the credentials are used for. Failures are
+// counted, and a missing secret is reported in an event on obj.
+func (ctrl *csiSnapshotSideCarController) getCredentials(provider string, ref *v1.SecretReference, obj runtime.Object) (map[string]string, error) {
+	providers := ctrl.credentialProviders
+	if providers == nil {
+		providers = utils.NewCredentialProviders(ctrl.client, "", nil, 0)
+	}
+	credentials, err := providers.GetCredentials(context.TODO(), provider, ref)
+	if err == nil {
+		return credentials, nil
+	}
+
+	if provider == "" {
+		provider = utils.CredentialProviderKubernetes
+	}
+	reason := credentialLookupFailureError
+	if utils.IsCredentialsNotFound(err) {
+		reason = credentialLookupFailureNotFound
+		if ctrl.eventRecorder != nil {
+			ctrl.eventRecorder.Eventf(obj, v1.EventTypeWarning, "SecretMissing", "Secret %s/%s of credential provider %s not found", ref.Namespace, ref.Name, provider)
+		}
+	}
+	credentialLookupFailures.WithLabelValues(ctrl.driverName, provider, reason).Inc()
+	return nil, err
+}
+
+// secretUpdated requeues the contents which use the updated secret, if its
+// data changed.
+func (ctrl *csiSnapshotSideCarController) secretUpdated(oldObj, newObj interface{}) {
+	oldSecret, ok := oldObj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	newSecret, ok := newObj.(*v1.Secret)
+	if !ok || reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+		return
+	}
+	klog.V(4).Infof("secretUpdated: secret %s/%s changed, requeueing the contents which use it", newSecret.Namespace, newSecret.Name)
+	ctrl.requeueSecretUsers(newSecret.Namespace, newSecret.Name)
+}
+
+// secretDeleted drops a deleted secret from the credential cache.
+func (ctrl *csiSnapshotSideCarController) secretDeleted(obj interface{}) {
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	ctrl.credentialCache.forget(secret.Namespace, secret.Name)
+}
+
+// requeueSecretUsers adds the contents and group snapshot contents that use
+// the given secret and are still waiting for the storage system to their
+// queues, without rate limiting.
+func (ctrl *csiSnapshotSideCarController) requeueSecretUsers(namespace, name string) {
+	usesSecret := func(annotations map[string]string, nameKey, namespaceKey string) bool {
+		return annotations[nameKey] == name && annotations[namespaceKey] == namespace
+	}
+
+	contents, err := ctrl.contentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("requeueSecretUsers: failed to list contents: %v", err)
+		return
+	}
+	for _, content := range contents {
+		if !usesSecret(content.Annotations, utils.AnnDeletionSecretRefName, utils.AnnDeletionSecretRefNamespace) {
+			continue
+		}
+		if content.DeletionTimestamp == nil && contentIsReady(content) {
+			continue
+		}
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(content)
+		if err != nil {
+			continue
+		}
+		ctrl.contentQueue.Forget(key)
+		ctrl.contentQueue.Add(key)
+	}
+
+	if !ctrl.enableVolumeGroupSnapshots {
+		return
+	}
+	groupSnapshotContents, err := ctrl.groupSnapshotContentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("requeueSecretUsers: failed to list group snapshot contents: %v", err)
+		return
+	}
+	for _, groupSnapshotContent := range groupSnapshotContents {
+		if !usesSecret(groupSnapshotContent.Annotations, utils.AnnDeletionGroupSecretRefName, utils.AnnDeletionGroupSecretRefNamespace) {
+			continue
+		}
+		ready := groupSnapshotContent.Status != nil && groupSnapshotContent.Status.ReadyToUse != nil && *groupSnapshotContent.Status.ReadyToUse
+		if groupSnapshotContent.DeletionTimestamp == nil && ready {
+			continue
+		}
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(groupSnapshotContent)
+		if err != nil {
+			continue
+		}
+		ctrl.groupSnapshotContentQueue.Forget(key)
+		ctrl.groupSnapshotContentQueue.Add(key)
+	}
+}
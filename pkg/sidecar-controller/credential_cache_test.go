@@ -0,0 +1,181 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"strings"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestCredentialCache(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default", ResourceVersion: "1"},
+		Data:       map[string][]byte{"password": []byte("old")},
+	}
+	withSecret := func(content *crdv1.VolumeSnapshotContent) *crdv1.VolumeSnapshotContent {
+		content.Annotations = map[string]string{
+			utils.AnnDeletionSecretRefName:      "secret",
+			utils.AnnDeletionSecretRefNamespace: "default",
+		}
+		return content
+	}
+	ready := withSecret(newContent("content-ready", "snapuid1", "snap1", "sid1", classGold, "", "volume-handle-1", deletionPolicy, nil, &defaultSize, true, nil))
+	ready.Status.ReadyToUse = &True
+	notReady := withSecret(newContent("content-not-ready", "snapuid2", "snap2", "sid2", classGold, "", "volume-handle-2", deletionPolicy, nil, &defaultSize, true, nil))
+	notReady.Status.ReadyToUse = &False
+	deleted := withSecret(newContent("content-deleted", "snapuid3", "snap3", "sid3", classGold, "", "volume-handle-3", deletionPolicy, nil, &defaultSize, true, &timeNowMetav1))
+	deleted.Status.ReadyToUse = &True
+	other := newContent("content-other", "snapuid4", "snap4", "sid4", classGold, "", "volume-handle-4", deletionPolicy, nil, &defaultSize, true, nil)
+	other.Status.ReadyToUse = &False
+
+	// A secret outside of the namespace of the informer.
+	uncached := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "other", ResourceVersion: "1"},
+		Data:       map[string][]byte{"password": []byte("uncached")},
+	}
+
+	client := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, utils.NoResyncPeriodFunc())
+	kubeClient := kubefake.NewSimpleClientset(uncached)
+	ctrl, err := newTestController(kubeClient, client, informerFactory, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+
+	// The failure counters are exported before the first failure.
+	families, err := legacyregistry.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	registered := false
+	for _, family := range families {
+		if family.GetName() == "csi_snapshotter_"+credentialLookupFailuresMetricName {
+			registered = true
+		}
+	}
+	if !registered {
+		t.Errorf("expected %s to be registered", credentialLookupFailuresMetricName)
+	}
+	recorder := ctrl.eventRecorder.(*record.FakeRecorder)
+	contentIndexer := informerFactory.Snapshot().V1().VolumeSnapshotContents().Informer().GetIndexer()
+	for _, content := range []*crdv1.VolumeSnapshotContent{ready, notReady, deleted, other} {
+		if err := contentIndexer.Add(content); err != nil {
+			t.Fatalf("failed to add content to the lister: %v", err)
+		}
+	}
+	secretInformer := kubeinformers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Secrets()
+	secretIndexer := secretInformer.Informer().GetIndexer()
+	if err := secretIndexer.Add(secret); err != nil {
+		t.Fatalf("failed to add secret to the lister: %v", err)
+	}
+	ctrl.credentialCache = newCredentialCache(secretInformer.Lister(), utils.NewKubernetesCredentialProvider(kubeClient))
+	ctrl.credentialProviders = utils.CredentialProviders{utils.CredentialProviderKubernetes: ctrl.credentialCache}
+	ref := &v1.SecretReference{Name: "secret", Namespace: "default"}
+
+	credentials, err := ctrl.getCredentials("", ref, notReady)
+	if err != nil || credentials["password"] != "old" {
+		t.Fatalf("expected the old credentials, got %v, %v", credentials, err)
+	}
+	// The cached credentials cannot be modified by the caller.
+	credentials["password"] = "modified"
+	if credentials, _ := ctrl.getCredentials("", ref, notReady); credentials["password"] != "old" {
+		t.Errorf("expected the cached credentials to be unchanged, got %v", credentials)
+	}
+
+	// A secret that is not in the informer is read from the API server.
+	if credentials, err := ctrl.getCredentials("", &v1.SecretReference{Name: "secret", Namespace: "other"}, notReady); err != nil || credentials["password"] != "uncached" {
+		t.Errorf("expected the credentials from the API server, got %v, %v", credentials, err)
+	}
+
+	// An update that does not change the data requeues nothing.
+	touched := secret.DeepCopy()
+	touched.ResourceVersion = "2"
+	ctrl.secretUpdated(secret, touched)
+	if n := ctrl.contentQueue.Len(); n != 0 {
+		t.Errorf("expected no requeued contents, got %d", n)
+	}
+
+	// A rotated secret is read again and requeues the contents waiting for
+	// the storage system.
+	rotated := touched.DeepCopy()
+	rotated.ResourceVersion = "3"
+	rotated.Data = map[string][]byte{"password": []byte("new")}
+	if err := secretIndexer.Update(rotated); err != nil {
+		t.Fatalf("failed to update secret in the lister: %v", err)
+	}
+	ctrl.secretUpdated(touched, rotated)
+	if credentials, err := ctrl.getCredentials("", ref, notReady); err != nil || credentials["password"] != "new" {
+		t.Errorf("expected the rotated credentials, got %v, %v", credentials, err)
+	}
+	requeued := map[string]bool{}
+	for ctrl.contentQueue.Len() > 0 {
+		key, _ := ctrl.contentQueue.Get()
+		requeued[key] = true
+		ctrl.contentQueue.Done(key)
+	}
+	if len(requeued) != 2 || !requeued["content-not-ready"] || !requeued["content-deleted"] {
+		t.Errorf("expected content-not-ready and content-deleted to be requeued, got %v", requeued)
+	}
+
+	// A missing secret is counted and reported on the content.
+	missing := &v1.SecretReference{Name: "missing", Namespace: "default"}
+	if _, err := ctrl.getCredentials("", missing, notReady); err == nil {
+		t.Fatalf("expected an error for a missing secret")
+	}
+	before, err := testutil.GetCounterMetricValue(credentialLookupFailures.WithLabelValues(mockDriverName, utils.CredentialProviderKubernetes, credentialLookupFailureNotFound))
+	if err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+	if _, err := ctrl.getCredentials("", missing, notReady); !utils.IsCredentialsNotFound(err) {
+		t.Fatalf("expected a not found error for a missing secret, got %v", err)
+	}
+	after, err := testutil.GetCounterMetricValue(credentialLookupFailures.WithLabelValues(mockDriverName, utils.CredentialProviderKubernetes, credentialLookupFailureNotFound))
+	if err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+	if after-before != 1 {
+		t.Errorf("expected the lookup failure to be counted once, got %v", after-before)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-recorder.Events:
+			if !strings.Contains(event, "SecretMissing") {
+				t.Errorf("unexpected event %q", event)
+			}
+		default:
+			t.Errorf("expected a SecretMissing event")
+		}
+	}
+
+	// A deleted secret is dropped from the cache.
+	ctrl.secretDeleted(rotated)
+	if len(ctrl.credentialCache.entries) != 0 {
+		t.Errorf("expected an empty cache, got %v", ctrl.credentialCache.entries)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeleteSnapshotWithFileCredentials(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "default", "secret"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "default", "secret", "password"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	content := newContent("content1-1", "snapuid1-1", "snap1-1", "sid1-1", classGold, "", "volume1-1", deletionPolicy, nil, &defaultSize, true, &timeNowMetav1)
+	content.Annotations = map[string]string{
+		utils.AnnDeletionSecretRefName:      "secret",
+		utils.AnnDeletionSecretRefNamespace: "default",
+		utils.AnnDeletionSecretRefProvider:  utils.CredentialProviderFile,
+	}
+	test := controllerTest{
+		expectedDeleteCalls: []deleteCall{{snapshotID: "sid1-1", secrets: map[string]string{"password": "secret"}}},
+	}
+	kubeClient := kubefake.NewSimpleClientset()
+	ctrl, err := newTestController(kubeClient, fake.NewSimpleClientset(content), nil, t, test)
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	ctrl.credentialProviders = utils.NewCredentialProviders(kubeClient, dir, nil, 0)
+
+	if _, err := ctrl.deleteCSISnapshotOperation(content); err != nil {
+		t.Errorf("unexpected error deleting the snapshot: %v", err)
+	}
+
+	// Without the file provider the credentials cannot be read.
+	ctrl.credentialProviders = utils.NewCredentialProviders(kubeClient, "", nil, 0)
+	if _, err := ctrl.GetCredentialsFromAnnotation(content); err == nil {
+		t.Errorf("expected an error when the file provider is not configured")
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 )
 
 // Handler is responsible for handling VolumeSnapshot events from informer.
@@ -35,6 +36,7 @@ type Handler interface {
 	CreateSnapshot(content *crdv1.VolumeSnapshotContent, parameters map[string]string, snapshotterCredentials map[string]string) (string, string, time.Time, int64, bool, error)
 	DeleteSnapshot(content *crdv1.VolumeSnapshotContent, snapshotterCredentials map[string]string) error
 	GetSnapshotStatus(content *crdv1.VolumeSnapshotContent, snapshotterListCredentials map[string]string) (bool, time.Time, int64, string, error)
+	GetSnapshotAllocatedBytes(content *crdv1.VolumeSnapshotContent, snapshotterListCredentials map[string]string) (int64, error)
 	CreateGroupSnapshot(content *groupsnapshotv1.VolumeGroupSnapshotContent, parameters map[string]string, snapshotterCredentials map[string]string) (string, string, []*csi.Snapshot, time.Time, bool, error)
 	GetGroupSnapshotStatus(groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent, snapshotIDs []string, snapshotterCredentials map[string]string) (bool, time.Time, error)
 	DeleteGroupSnapshot(content *groupsnapshotv1.VolumeGroupSnapshotContent, SnapshotID []string, snapshotterCredentials map[string]string) error
@@ -80,7 +82,7 @@ func (handler *csiHandler) CreateSnapshot(content *crdv1.VolumeSnapshotContent,
 		return "", "", time.Time{}, 0, false, fmt.Errorf("cannot create snapshot. Snapshot content %s not bound to a snapshot", content.Name)
 	}
 
-	if content.Spec.Source.VolumeHandle == nil {
+	if content.Spec.Source.VolumeHandle == nil && content.Spec.Source.SourceSnapshotHandle == nil {
 		return "", "", time.Time{}, 0, false, fmt.Errorf("cannot create snapshot. Volume handle not found in snapshot content %s", content.Name)
 	}
 
@@ -88,6 +90,20 @@ func (handler *csiHandler) CreateSnapshot(content *crdv1.VolumeSnapshotContent,
 	if err != nil {
 		return "", "", time.Time{}, 0, false, err
 	}
+
+	if content.Spec.Source.SourceSnapshotHandle != nil {
+		// CSI has no RPC to copy a snapshot. The copy is requested with
+		// CreateSnapshot, passing the handle of the source snapshot both as
+		// the source and as a parameter, so that drivers which do not
+		// support copies reject the request instead of snapshotting a volume.
+		sourceSnapshotHandle := *content.Spec.Source.SourceSnapshotHandle
+		copyParameters := make(map[string]string, len(parameters)+1)
+		for k, v := range parameters {
+			copyParameters[k] = v
+		}
+		copyParameters[utils.PrefixedSourceSnapshotHandleKey] = sourceSnapshotHandle
+		return handler.snapshotter.CreateSnapshot(ctx, snapshotName, sourceSnapshotHandle, copyParameters, snapshotterCredentials)
+	}
 	return handler.snapshotter.CreateSnapshot(ctx, snapshotName, *content.Spec.Source.VolumeHandle, parameters, snapshotterCredentials)
 }
 
@@ -129,12 +145,33 @@ func (handler *csiHandler) GetSnapshotStatus(content *crdv1.VolumeSnapshotConten
 
 	csiSnapshotStatus, timestamp, size, groupSnapshotID, err := handler.snapshotter.GetSnapshotStatus(ctx, snapshotHandle, snapshotterListCredentials)
 	if err != nil {
-		return false, time.Time{}, 0, "", fmt.Errorf("failed to list snapshot for content %s: %q", content.Name, err)
+		return false, time.Time{}, 0, "", fmt.Errorf("failed to list snapshot for content %s: %w", content.Name, err)
 	}
 
 	return csiSnapshotStatus, timestamp, size, groupSnapshotID, nil
 }
 
+func (handler *csiHandler) GetSnapshotAllocatedBytes(content *crdv1.VolumeSnapshotContent, snapshotterListCredentials map[string]string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), handler.timeout)
+	defer cancel()
+
+	var snapshotHandle string
+	if content.Status != nil && content.Status.SnapshotHandle != nil {
+		snapshotHandle = *content.Status.SnapshotHandle
+	} else if content.Spec.Source.SnapshotHandle != nil {
+		snapshotHandle = *content.Spec.Source.SnapshotHandle
+	} else {
+		return 0, fmt.Errorf("failed to get allocated bytes for content %s: snapshotHandle is missing", content.Name)
+	}
+
+	allocatedBytes, err := handler.snapshotter.GetSnapshotAllocatedBytes(ctx, snapshotHandle, snapshotterListCredentials)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get allocated bytes for content %s: %w", content.Name, err)
+	}
+
+	return allocatedBytes, nil
+}
+
 func makeSnapshotName(prefix, snapshotUID string, snapshotNameUUIDLength int) (string, error) {
 	// create persistent name based on a volumeNamePrefix and volumeNameUUIDLength
 	// of PVC's UID
@@ -24,7 +24,9 @@ import (
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/group_snapshotter"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -331,3 +333,45 @@ func contains(s, sub string) bool {
 	}
 	return false
 }
+
+// TestCreateSnapshotCopy validates that copying a snapshot passes the source
+// snapshot handle to the driver with the parameters of the target class.
+func TestCreateSnapshotCopy(t *testing.T) {
+	sourceSnapshotHandle := "source-snapshot-handle"
+	parameters := map[string]string{"tier": "archive"}
+	f := &fakeSnapshotter{
+		createCalls: []createCall{
+			{
+				snapshotName: "snapshot-copyuid1",
+				volumeHandle: sourceSnapshotHandle,
+				parameters: map[string]string{
+					"tier":                                parameters["tier"],
+					utils.PrefixedSourceSnapshotHandleKey: sourceSnapshotHandle,
+				},
+				driverName: "driver",
+				snapshotId: "copied-snapshot-handle",
+				readyToUse: false,
+			},
+		},
+		t: t,
+	}
+	handler := NewCSIHandler(f, nil, 5*time.Second, "snapshot", 8, "group-snapshot", 8)
+	content := &crdv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "snapcontent-copy-1"},
+		Spec: crdv1.VolumeSnapshotContentSpec{
+			VolumeSnapshotRef: corev1.ObjectReference{UID: "copyuid-1234"},
+			Source:            crdv1.VolumeSnapshotContentSource{SourceSnapshotHandle: &sourceSnapshotHandle},
+		},
+	}
+
+	_, snapshotID, _, _, _, err := handler.CreateSnapshot(content, parameters, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshotID != "copied-snapshot-handle" {
+		t.Errorf("expected snapshot ID copied-snapshot-handle, got %s", snapshotID)
+	}
+	if _, found := parameters[utils.PrefixedSourceSnapshotHandleKey]; found {
+		t.Errorf("the parameters of the class were modified: %v", parameters)
+	}
+}
@@ -34,6 +34,7 @@ import (
 	snapshotscheme "github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/scheme"
 	informers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions"
 	storagelisters "github.com/kubernetes-csi/external-snapshotter/client/v8/listers/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -580,6 +581,11 @@ func newTestController(kubeClient kubernetes.Interface, clientset clientset.Inte
 		informerFactory.Groupsnapshot().V1().VolumeGroupSnapshotContents(),
 		informerFactory.Groupsnapshot().V1().VolumeGroupSnapshotClasses(),
 		workqueue.NewTypedItemExponentialFailureRateLimiter[string](1*time.Millisecond, 1*time.Minute),
+		0,
+		0,
+		nil,
+		nil,
+		nil,
 	)
 
 	ctrl.eventRecorder = record.NewFakeRecorder(1000)
@@ -928,7 +934,15 @@ type fakeSnapshotter struct {
 	deleteCallCounter int
 	listCalls         []listCall
 	listCallCounter   int
-	t                 *testing.T
+	// allocatedBytes are returned by GetSnapshotAllocatedBytes, by snapshot ID.
+	allocatedBytes map[string]int64
+	// allocatedBytesErrs are returned by GetSnapshotAllocatedBytes, by
+	// snapshot ID.
+	allocatedBytesErrs map[string]error
+	// allocatedBytesCalls are the snapshot IDs passed to
+	// GetSnapshotAllocatedBytes.
+	allocatedBytesCalls []string
+	t                   *testing.T
 }
 
 func (f *fakeSnapshotter) CreateSnapshot(ctx context.Context, snapshotName string, volumeHandle string, parameters map[string]string, snapshotterCredentials map[string]string) (string, string, time.Time, int64, bool, error) {
@@ -1018,6 +1032,24 @@ func (f *fakeSnapshotter) GetSnapshotStatus(ctx context.Context, snapshotID stri
 	return call.readyToUse, call.createTime, call.size, call.groupSnapshotID, call.err
 }
 
+func (f *fakeSnapshotter) ListSnapshots(ctx context.Context, sourceVolumeID string, snapshotterListCredentials map[string]string) ([]snapshotter.SnapshotInfo, error) {
+	f.t.Errorf("Unexpected CSI ListSnapshots call: sourceVolumeID=%s", sourceVolumeID)
+	return nil, fmt.Errorf("unexpected call")
+}
+
+func (f *fakeSnapshotter) GetSnapshotAllocatedBytes(ctx context.Context, snapshotID string, snapshotterCredentials map[string]string) (int64, error) {
+	f.allocatedBytesCalls = append(f.allocatedBytesCalls, snapshotID)
+	if err := f.allocatedBytesErrs[snapshotID]; err != nil {
+		return 0, err
+	}
+	allocatedBytes, ok := f.allocatedBytes[snapshotID]
+	if !ok {
+		f.t.Errorf("Unexpected CSI GetSnapshotAllocatedBytes call: snapshotID=%s", snapshotID)
+		return 0, fmt.Errorf("unexpected call")
+	}
+	return allocatedBytes, nil
+}
+
 func newSnapshotError(message string) *crdv1.VolumeSnapshotError {
 	return &crdv1.VolumeSnapshotError{
 		Time:    &metav1.Time{},
@@ -982,6 +982,27 @@ func TestSetAnnVolumeGroupSnapshotBeingCreated(t *testing.T) {
 	if !metav1.HasAnnotation(got2.ObjectMeta, utils.AnnVolumeGroupSnapshotBeingCreated) {
 		t.Error("expected AnnVolumeGroupSnapshotBeingCreated annotation to be set")
 	}
+	if metav1.HasAnnotation(got2.ObjectMeta, utils.AnnVolumeGroupSnapshotEmulated) {
+		t.Error("expected AnnVolumeGroupSnapshotEmulated annotation not to be set")
+	}
+
+	// Emulated group snapshot: both annotations are set, also when the
+	// creation was started before
+	content3 := newGroupSnapshotContent(
+		"set-ann", "uid", "snap", testNamespace,
+		"", "class-a", []string{"vol-1"},
+		crdv1.VolumeSnapshotContentDelete, nil, false, nil,
+	)
+	metav1.SetMetaDataAnnotation(&content3.ObjectMeta, utils.AnnVolumeGroupSnapshotBeingCreated, "yes")
+	client3 := fake.NewSimpleClientset(content3)
+	ctrl3 := &csiSnapshotSideCarController{clientset: client3, contentStore: cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc), emulateGroupSnapshots: true}
+	got3, err := ctrl3.setAnnVolumeGroupSnapshotBeingCreated(content3)
+	if err != nil {
+		t.Fatalf("setAnnVolumeGroupSnapshotBeingCreated (emulated) failed: %v", err)
+	}
+	if !metav1.HasAnnotation(got3.ObjectMeta, utils.AnnVolumeGroupSnapshotBeingCreated) || !metav1.HasAnnotation(got3.ObjectMeta, utils.AnnVolumeGroupSnapshotEmulated) {
+		t.Errorf("expected AnnVolumeGroupSnapshotBeingCreated and AnnVolumeGroupSnapshotEmulated annotations to be set, got %v", got3.Annotations)
+	}
 }
 
 // TestRemoveAnnVolumeGroupSnapshotBeingCreated tests removeAnnVolumeGroupSnapshotBeingCreated.
@@ -33,6 +33,7 @@ import (
 
 	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/audit"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 )
 
@@ -283,7 +284,9 @@ func (ctrl *csiSnapshotSideCarController) deleteCSIGroupSnapshotOperation(groupS
 		}
 	}
 
+	startTime := time.Now()
 	err = ctrl.handler.DeleteGroupSnapshot(groupSnapshotContent, snapshotIDs, snapshotterCredentials)
+	ctrl.auditGroupSnapshotContentOperation(audit.OperationDeleteGroupSnapshot, groupSnapshotContent, "", snapshotIDs, startTime, err)
 	if err != nil {
 		ctrl.eventRecorder.Event(groupSnapshotContent, v1.EventTypeWarning, "GroupSnapshotDeleteError", "Failed to delete group snapshot")
 		return fmt.Errorf("failed to delete group snapshot %#v, err: %v", groupSnapshotContent.Name, err)
@@ -349,7 +352,8 @@ func (ctrl *csiSnapshotSideCarController) GetCredentialsFromAnnotationForGroupSn
 		snapshotterSecretRef.Name = annDeletionSecretName
 		snapshotterSecretRef.Namespace = annDeletionSecretNamespace
 
-		snapshotterCredentials, err = utils.GetCredentials(ctrl.client, snapshotterSecretRef)
+		provider := groupSnapshotContent.Annotations[utils.AnnDeletionGroupSecretRefProvider]
+		snapshotterCredentials, err = ctrl.getCredentials(provider, snapshotterSecretRef, groupSnapshotContent)
 		if err != nil {
 			// Continue with deletion, as the secret may have already been deleted.
 			klog.Errorf("Failed to get credentials for group snapshot content %s: %s", groupSnapshotContent.Name, err.Error())
@@ -446,7 +450,13 @@ func (ctrl *csiSnapshotSideCarController) createGroupSnapshotWrapper(groupSnapsh
 		parameters[utils.PrefixedVolumeGroupSnapshotContentNameKey] = groupSnapshotContent.Name
 	}
 
+	startTime := time.Now()
 	driverName, groupSnapshotID, snapshots, creationTime, readyToUse, err := ctrl.handler.CreateGroupSnapshot(groupSnapshotContent, parameters, snapshotterCredentials)
+	var snapshotIDs []string
+	for _, snapshot := range snapshots {
+		snapshotIDs = append(snapshotIDs, snapshot.SnapshotId)
+	}
+	ctrl.auditGroupSnapshotContentOperation(audit.OperationCreateGroupSnapshot, groupSnapshotContent, groupSnapshotID, snapshotIDs, startTime, err)
 	if err != nil {
 		// NOTE(xyang): handle create timeout
 		// If it is a final error, remove annotation to indicate
@@ -538,8 +548,10 @@ func (ctrl *csiSnapshotSideCarController) getGroupSnapshotClass(className string
 // setAnnVolumeGroupSnapshotBeingCreated sets VolumeGroupSnapshotBeingCreated annotation
 // on VolumeGroupSnapshotContent
 // If set, it indicates group snapshot is being created
+// If group snapshots are emulated, VolumeGroupSnapshotEmulated is set as well.
 func (ctrl *csiSnapshotSideCarController) setAnnVolumeGroupSnapshotBeingCreated(groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) (*groupsnapshotv1.VolumeGroupSnapshotContent, error) {
-	if metav1.HasAnnotation(groupSnapshotContent.ObjectMeta, utils.AnnVolumeGroupSnapshotBeingCreated) {
+	if metav1.HasAnnotation(groupSnapshotContent.ObjectMeta, utils.AnnVolumeGroupSnapshotBeingCreated) &&
+		(!ctrl.emulateGroupSnapshots || metav1.HasAnnotation(groupSnapshotContent.ObjectMeta, utils.AnnVolumeGroupSnapshotEmulated)) {
 		// the annotation already exists, return directly
 		return groupSnapshotContent, nil
 	}
@@ -553,6 +565,9 @@ func (ctrl *csiSnapshotSideCarController) setAnnVolumeGroupSnapshotBeingCreated(
 		patchedAnnotations[k] = v
 	}
 	patchedAnnotations[utils.AnnVolumeGroupSnapshotBeingCreated] = "yes"
+	if ctrl.emulateGroupSnapshots {
+		patchedAnnotations[utils.AnnVolumeGroupSnapshotEmulated] = "yes"
+	}
 
 	var patches []utils.PatchOp
 	patches = append(patches, utils.PatchOp{
@@ -628,16 +643,8 @@ func (ctrl *csiSnapshotSideCarController) updateGroupSnapshotContentStatus(
 			ReadyToUse:                &readyToUse,
 			CreationTime:              &createdAt,
 		}
-		for _, snapshot := range snapshotList {
-			newStatus.VolumeSnapshotInfoList = append(newStatus.VolumeSnapshotInfoList, groupsnapshotv1.VolumeSnapshotInfo{
-				VolumeHandle:   snapshot.SourceVolumeId,
-				SnapshotHandle: snapshot.SnapshotId,
-				CreationTime:   utils.CSITimestampToKubernetes(snapshot.CreationTime),
-				ReadyToUse:     &snapshot.ReadyToUse,
-				RestoreSize:    utils.CSISizeToKubernetes(snapshot.SizeBytes),
-			})
-		}
-
+		newStatus.VolumeSnapshotInfoList = newVolumeSnapshotInfoList(snapshotList)
+		newStatus.CrashConsistent = isCrashConsistent(groupSnapshotContentObj)
 		updated = true
 	} else {
 		newStatus = groupSnapshotContentObj.Status.DeepCopy()
@@ -657,15 +664,13 @@ func (ctrl *csiSnapshotSideCarController) updateGroupSnapshotContentStatus(
 			updated = true
 		}
 		if len(newStatus.VolumeSnapshotInfoList) == 0 {
-			for _, snapshot := range snapshotList {
-				newStatus.VolumeSnapshotInfoList = append(newStatus.VolumeSnapshotInfoList, groupsnapshotv1.VolumeSnapshotInfo{
-					VolumeHandle:   snapshot.SourceVolumeId,
-					SnapshotHandle: snapshot.SnapshotId,
-					CreationTime:   utils.CSITimestampToKubernetes(snapshot.CreationTime),
-					ReadyToUse:     &snapshot.ReadyToUse,
-					RestoreSize:    utils.CSISizeToKubernetes(snapshot.SizeBytes),
-				})
-			}
+			newStatus.VolumeSnapshotInfoList = newVolumeSnapshotInfoList(snapshotList)
+			updated = true
+		} else if updateVolumeSnapshotInfoReadiness(newStatus.VolumeSnapshotInfoList, snapshotList) {
+			updated = true
+		}
+		if newStatus.CrashConsistent == nil {
+			newStatus.CrashConsistent = isCrashConsistent(groupSnapshotContentObj)
 			updated = true
 		}
 	}
@@ -683,6 +688,56 @@ func (ctrl *csiSnapshotSideCarController) updateGroupSnapshotContentStatus(
 	return groupSnapshotContentObj, nil
 }
 
+// isCrashConsistent returns false if the group snapshot of the content was
+// emulated with individual snapshots.
+func isCrashConsistent(groupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent) *bool {
+	crashConsistent := !metav1.HasAnnotation(groupSnapshotContent.ObjectMeta, utils.AnnVolumeGroupSnapshotEmulated)
+	return &crashConsistent
+}
+
+// newVolumeSnapshotInfoList returns the status of the snapshots returned by
+// the CSI driver for a group snapshot.
+func newVolumeSnapshotInfoList(snapshotList []*csi.Snapshot) []groupsnapshotv1.VolumeSnapshotInfo {
+	var infoList []groupsnapshotv1.VolumeSnapshotInfo
+	now := metav1.Now()
+	for _, snapshot := range snapshotList {
+		infoList = append(infoList, groupsnapshotv1.VolumeSnapshotInfo{
+			VolumeHandle:       snapshot.SourceVolumeId,
+			SnapshotHandle:     snapshot.SnapshotId,
+			CreationTime:       utils.CSITimestampToKubernetes(snapshot.CreationTime),
+			ReadyToUse:         &snapshot.ReadyToUse,
+			RestoreSize:        utils.CSISizeToKubernetes(snapshot.SizeBytes),
+			LastTransitionTime: &now,
+		})
+	}
+	return infoList
+}
+
+// updateVolumeSnapshotInfoReadiness updates ReadyToUse of the snapshots in
+// infoList from the snapshots returned by the CSI driver, so that a snapshot
+// which was not ready when the group snapshot was cut is reported once it
+// becomes ready. It returns true if any snapshot was updated.
+func updateVolumeSnapshotInfoReadiness(infoList []groupsnapshotv1.VolumeSnapshotInfo, snapshotList []*csi.Snapshot) bool {
+	updated := false
+	now := metav1.Now()
+	for _, snapshot := range snapshotList {
+		for i := range infoList {
+			info := &infoList[i]
+			if info.SnapshotHandle != snapshot.SnapshotId {
+				continue
+			}
+			if info.ReadyToUse == nil || *info.ReadyToUse != snapshot.ReadyToUse {
+				readyToUse := snapshot.ReadyToUse
+				info.ReadyToUse = &readyToUse
+				info.LastTransitionTime = &now
+				updated = true
+			}
+			break
+		}
+	}
+	return updated
+}
+
 // updateContentStatusWithEvent saves new groupSnapshotContent.Status to API server
 // and emits given event on the groupSnapshotContent. It saves the status and emits
 // the event only when the status has actually changed from the version saved in API server.
@@ -815,7 +870,13 @@ func (ctrl *csiSnapshotSideCarController) checkandUpdateGroupSnapshotContentStat
 				return groupSnapshotContent, fmt.Errorf("failed to get secret reference for group snapshot content %s: %v", groupSnapshotContent.Name, err)
 			}
 
-			groupSnapshotCredentials, err = utils.GetCredentials(ctrl.client, groupSnapshotSecretRef)
+			provider, err := utils.GetSecretProvider(class.Parameters)
+			if err != nil {
+				klog.Errorf("Failed to get secret provider for group snapshot content %s: %v", groupSnapshotContent.Name, err)
+				return groupSnapshotContent, fmt.Errorf("failed to get secret provider for group snapshot content %s: %v", groupSnapshotContent.Name, err)
+			}
+
+			groupSnapshotCredentials, err = ctrl.getCredentials(provider, groupSnapshotSecretRef, groupSnapshotContent)
 			if err != nil {
 				// Continue with deletion, as the secret may have already been deleted.
 				klog.Errorf("Failed to get credentials for group snapshot content %s: %v", groupSnapshotContent.Name, err)
@@ -83,6 +83,9 @@ func (f *fakeGroupSnapshotHandler) DeleteSnapshot(_ *v1.VolumeSnapshotContent, _
 func (f *fakeGroupSnapshotHandler) GetSnapshotStatus(_ *v1.VolumeSnapshotContent, _ map[string]string) (bool, time.Time, int64, string, error) {
 	return false, time.Time{}, 0, "", errors.NewServiceUnavailable("not implemented")
 }
+func (f *fakeGroupSnapshotHandler) GetSnapshotAllocatedBytes(_ *v1.VolumeSnapshotContent, _ map[string]string) (int64, error) {
+	return 0, errors.NewServiceUnavailable("not implemented")
+}
 func (f *fakeGroupSnapshotHandler) CreateGroupSnapshot(_ *groupsnapshotv1.VolumeGroupSnapshotContent, _ map[string]string, _ map[string]string) (string, string, []*csi.Snapshot, time.Time, bool, error) {
 	if f.createGroupSnapshotErr != nil {
 		return "", "", nil, time.Time{}, false, f.createGroupSnapshotErr
@@ -286,6 +289,82 @@ func TestUpdateGroupSnapshotContentStatusExistingStatus(t *testing.T) {
 	}
 }
 
+// TestUpdateGroupSnapshotContentStatusMemberReadiness tests that a member which
+// was not ready when the group snapshot was cut is updated once it becomes ready.
+func TestUpdateGroupSnapshotContentStatusMemberReadiness(t *testing.T) {
+	handle := "group-handle"
+	created := metav1.NewTime(metav1.Now().Time)
+	content := newGroupSnapshotContent(
+		"member-ready", "uid", "snap", testNamespace,
+		"", "class-a", []string{"vol-1", "vol-2"},
+		v1.VolumeSnapshotContentDelete, nil, false, nil,
+	)
+	content.Status = &groupsnapshotv1.VolumeGroupSnapshotContentStatus{
+		VolumeGroupSnapshotHandle: &handle,
+		ReadyToUse:                ptr(false),
+		CreationTime:              &created,
+		VolumeSnapshotInfoList: []groupsnapshotv1.VolumeSnapshotInfo{
+			{VolumeHandle: "vol-1", SnapshotHandle: "snap-1", ReadyToUse: ptr(true), LastTransitionTime: &created},
+			{VolumeHandle: "vol-2", SnapshotHandle: "snap-2", ReadyToUse: ptr(false), LastTransitionTime: &created},
+		},
+	}
+	client := fake.NewSimpleClientset(content)
+	ctrl := &csiSnapshotSideCarController{clientset: client}
+	snapList := []*csi.Snapshot{
+		{SnapshotId: "snap-1", SourceVolumeId: "vol-1", ReadyToUse: true},
+		{SnapshotId: "snap-2", SourceVolumeId: "vol-2", ReadyToUse: true},
+	}
+	got, err := ctrl.updateGroupSnapshotContentStatus(content, handle, true, created, snapList)
+	if err != nil {
+		t.Fatalf("updateGroupSnapshotContentStatus failed: %v", err)
+	}
+	unchanged, became := got.Status.VolumeSnapshotInfoList[0], got.Status.VolumeSnapshotInfoList[1]
+	if !unchanged.LastTransitionTime.Equal(&created) {
+		t.Errorf("expected LastTransitionTime of the unchanged member to be kept, got %v", unchanged.LastTransitionTime)
+	}
+	if became.ReadyToUse == nil || !*became.ReadyToUse || became.LastTransitionTime.Equal(&created) {
+		t.Errorf("expected the member to become ready with a new LastTransitionTime, got %+v", became)
+	}
+}
+
+// TestUpdateGroupSnapshotContentStatusCrashConsistent tests that emulated group
+// snapshots are marked as not crash consistent, independent of their handle.
+func TestUpdateGroupSnapshotContentStatusCrashConsistent(t *testing.T) {
+	tests := []struct {
+		name     string
+		handle   string
+		emulated bool
+		expected bool
+	}{
+		{name: "driver", handle: "group-handle", expected: true},
+		{name: "driver with emulated- handle", handle: "emulated-groupsnapshot-1234-abcd", expected: true},
+		{name: "emulated", handle: "emulated-groupsnapshot-1234-abcd", emulated: true, expected: false},
+	}
+	for _, test := range tests {
+		handle, expected := test.handle, test.expected
+		content := newGroupSnapshotContent(
+			"crash-consistent", "uid", "snap", testNamespace,
+			"", "class-a", []string{"vol-1"},
+			v1.VolumeSnapshotContentDelete, nil, false, nil,
+		)
+		if test.emulated {
+			metav1.SetMetaDataAnnotation(&content.ObjectMeta, utils.AnnVolumeGroupSnapshotEmulated, "yes")
+		}
+		client := fake.NewSimpleClientset(content)
+		ctrl := &csiSnapshotSideCarController{clientset: client}
+		snapList := []*csi.Snapshot{
+			{SnapshotId: "snap-1", SourceVolumeId: "vol-1", ReadyToUse: true},
+		}
+		got, err := ctrl.updateGroupSnapshotContentStatus(content, handle, true, metav1.Now(), snapList)
+		if err != nil {
+			t.Fatalf("updateGroupSnapshotContentStatus failed: %v", err)
+		}
+		if got.Status.CrashConsistent == nil || *got.Status.CrashConsistent != expected {
+			t.Errorf("%s: expected CrashConsistent %v, got %v", test.name, expected, got.Status.CrashConsistent)
+		}
+	}
+}
+
 // TestUpdateGroupSnapshotContentStatusNoUpdate tests when nothing changes (updated=false).
 func TestUpdateGroupSnapshotContentStatusNoUpdate(t *testing.T) {
 	handle := "same-handle"
@@ -0,0 +1,117 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"context"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	klog "k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// NodeHeartbeat renews a Lease that tells the snapshot controller that the
+// sidecar deployed on a node is running, so that VolumeSnapshotContents are
+// only assigned to nodes that can handle them.
+type NodeHeartbeat struct {
+	client        kubernetes.Interface
+	namespace     string
+	driver        string
+	node          string
+	leaseDuration time.Duration
+}
+
+// NewNodeHeartbeat returns a heartbeat for the sidecar of driver deployed on
+// node, whose Lease in namespace expires after leaseDuration.
+func NewNodeHeartbeat(client kubernetes.Interface, namespace, driver, node string, leaseDuration time.Duration) *NodeHeartbeat {
+	return &NodeHeartbeat{
+		client:        client,
+		namespace:     namespace,
+		driver:        driver,
+		node:          node,
+		leaseDuration: leaseDuration,
+	}
+}
+
+// Run renews the Lease until stopCh is closed, and then deletes it.
+func (h *NodeHeartbeat) Run(stopCh <-chan struct{}) {
+	klog.Infof("Renewing the heartbeat Lease %s/%s", h.namespace, h.name())
+	wait.Until(h.renew, h.leaseDuration/3, stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.leaseDuration/3)
+	defer cancel()
+	if err := h.client.CoordinationV1().Leases(h.namespace).Delete(ctx, h.name(), metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+		klog.Errorf("failed to delete the heartbeat Lease %s/%s: %v", h.namespace, h.name(), err)
+	}
+}
+
+func (h *NodeHeartbeat) name() string {
+	return utils.NodeHeartbeatLeaseName(h.driver, h.node)
+}
+
+func (h *NodeHeartbeat) renew() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.leaseDuration/3)
+	defer cancel()
+	leases := h.client.CoordinationV1().Leases(h.namespace)
+	now := metav1.NewMicroTime(time.Now())
+	leaseDurationSeconds := int32(h.leaseDuration.Seconds())
+	lease, err := leases.Get(ctx, h.name(), metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      h.name(),
+				Namespace: h.namespace,
+				Labels:    utils.NodeHeartbeatLabels(h.driver, h.node),
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &h.node,
+				LeaseDurationSeconds: &leaseDurationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			klog.Errorf("failed to create the heartbeat Lease %s/%s: %v", h.namespace, h.name(), err)
+		}
+		return
+	}
+	if err != nil {
+		klog.Errorf("failed to get the heartbeat Lease %s/%s: %v", h.namespace, h.name(), err)
+		return
+	}
+	// Restore the labels and the holder as well, the snapshot controller
+	// selects the Lease by them.
+	lease = lease.DeepCopy()
+	if lease.Labels == nil {
+		lease.Labels = map[string]string{}
+	}
+	for key, value := range utils.NodeHeartbeatLabels(h.driver, h.node) {
+		lease.Labels[key] = value
+	}
+	lease.Spec.HolderIdentity = &h.node
+	lease.Spec.LeaseDurationSeconds = &leaseDurationSeconds
+	lease.Spec.RenewTime = &now
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to renew the heartbeat Lease %s/%s: %v", h.namespace, h.name(), err)
+	}
+}
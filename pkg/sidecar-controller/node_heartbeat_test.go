@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNodeHeartbeat(t *testing.T) {
+	client := kubefake.NewSimpleClientset()
+	heartbeat := NewNodeHeartbeat(client, "default", "hostpath.csi.k8s.io", "node1", 300*time.Millisecond)
+	name := utils.NodeHeartbeatLeaseName("hostpath.csi.k8s.io", "node1")
+	leases := client.CoordinationV1().Leases("default")
+
+	// The Lease is created.
+	heartbeat.renew()
+	lease, err := leases.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the Lease: %v", err)
+	}
+	expectedLabels := utils.NodeHeartbeatLabels("hostpath.csi.k8s.io", "node1")
+	if !reflect.DeepEqual(labels.Set(lease.Labels), expectedLabels) {
+		t.Errorf("expected labels %v, got %v", expectedLabels, lease.Labels)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "node1" {
+		t.Errorf("expected holder node1, got %v", lease.Spec.HolderIdentity)
+	}
+	if lease.Spec.LeaseDurationSeconds == nil || lease.Spec.RenewTime == nil {
+		t.Fatalf("expected the lease duration and the renew time to be set, got %+v", lease.Spec)
+	}
+
+	// The Lease is renewed, and its labels, holder and duration are
+	// restored.
+	renewTime := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	lease.Labels = nil
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.LeaseDurationSeconds = nil
+	lease.Spec.RenewTime = &renewTime
+	if _, err := leases.Update(context.TODO(), lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update the Lease: %v", err)
+	}
+	heartbeat.renew()
+	lease, err = leases.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the Lease: %v", err)
+	}
+	if !reflect.DeepEqual(labels.Set(lease.Labels), expectedLabels) {
+		t.Errorf("expected labels %v after renew, got %v", expectedLabels, lease.Labels)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "node1" {
+		t.Errorf("expected holder node1 after renew, got %v", lease.Spec.HolderIdentity)
+	}
+	if lease.Spec.LeaseDurationSeconds == nil {
+		t.Errorf("expected the lease duration to be set after renew")
+	}
+	if lease.Spec.RenewTime == nil || !renewTime.Before(lease.Spec.RenewTime) {
+		t.Errorf("expected the renew time to be updated, got %v", lease.Spec.RenewTime)
+	}
+
+	// The Lease is deleted when the heartbeat stops.
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		heartbeat.Run(stopCh)
+		close(done)
+	}()
+	close(stopCh)
+	select {
+	case <-done:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("the heartbeat did not stop")
+	}
+	if _, err := leases.Get(context.TODO(), name, metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Errorf("expected the Lease to be deleted, got %v", err)
+	}
+}
@@ -26,10 +26,13 @@ import (
 	codes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	klog "k8s.io/klog/v2"
 
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/audit"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 )
 
@@ -60,14 +63,34 @@ func (ctrl *csiSnapshotSideCarController) syncContent(content *crdv1.VolumeSnaps
 
 	if ctrl.shouldDelete(content) {
 		klog.V(4).Infof("VolumeSnapshotContent[%s]: the policy is %s", content.Name, content.Spec.DeletionPolicy)
+		if utils.IsUnderLegalHold(content.ObjectMeta) {
+			// Keep the content and the snapshot until the hold is
+			// removed, which enqueues the content again.
+			klog.V(4).Infof("VolumeSnapshotContent[%s]: deletion is refused because of legal hold %q", content.Name, content.Annotations[utils.AnnLegalHold])
+			ctrl.reportedEvents.Warn(ctrl.eventRecorder, content, content.Name, "SnapshotDeletionRefused", fmt.Sprintf("Snapshot content is not deleted while it is under legal hold %q", content.Annotations[utils.AnnLegalHold]))
+			return false, nil
+		}
+		if utils.IsDeletionProtected(content.ObjectMeta) {
+			// Keep the content and the snapshot until the protection is
+			// removed, which enqueues the content again.
+			klog.V(4).Infof("VolumeSnapshotContent[%s]: deletion is protected", content.Name)
+			ctrl.reportedEvents.Warn(ctrl.eventRecorder, content, content.Name, "SnapshotDeletionProtected", fmt.Sprintf("Snapshot content is not deleted until the %s annotation is removed", utils.AnnDeletionProtection))
+			return false, nil
+		}
 		if content.Spec.DeletionPolicy == crdv1.VolumeSnapshotContentDelete &&
 			content.Status != nil && content.Status.SnapshotHandle != nil && content.Status.VolumeGroupSnapshotHandle == nil {
+			deferred, err := ctrl.deferSnapshotDeletion(content)
+			if err != nil {
+				return true, err
+			}
+			if deferred {
+				return false, nil
+			}
 			// issue a CSI deletion call if the snapshot does not belong to volumegroupsnapshot
 			// and it has not been deleted yet from underlying storage system.
 			// Note that the deletion snapshot operation will update content SnapshotHandle
 			// to nil upon a successful deletion. At this
 			// point, the finalizer on content should NOT be removed to avoid leaking.
-			var err error
 			content, err = ctrl.deleteCSISnapshot(content)
 			if err != nil {
 				return true, err
@@ -85,10 +108,15 @@ func (ctrl *csiSnapshotSideCarController) syncContent(content *crdv1.VolumeSnaps
 		return false, nil
 	}
 
+	if utils.IsContentInRecycleBin(content) && content.ObjectMeta.DeletionTimestamp == nil {
+		return ctrl.purgeRecycledContent(content)
+	}
+
 	// Create snapshot calling the CSI driver only if it is a dynamic
-	// provisioning for an independent snapshot.
+	// provisioning for an independent snapshot, or a copy of a snapshot.
 	_, groupSnapshotMember := content.Annotations[utils.VolumeGroupSnapshotHandleAnnotation]
-	if content.Spec.Source.VolumeHandle != nil && content.Status == nil && !groupSnapshotMember {
+	dynamicSnapshot := content.Spec.Source.VolumeHandle != nil || content.Spec.Source.SourceSnapshotHandle != nil
+	if dynamicSnapshot && content.Status == nil && !groupSnapshotMember {
 		klog.V(5).Infof("syncContent: Call CreateSnapshot for content %s", content.Name)
 		return ctrl.createSnapshot(content)
 	}
@@ -99,15 +127,137 @@ func (ctrl *csiSnapshotSideCarController) syncContent(content *crdv1.VolumeSnaps
 	// performance reasons.
 	if contentIsReady(content) {
 		// Try to remove AnnVolumeSnapshotBeingCreated if it is not removed yet for some reason
-		_, err = ctrl.removeAnnVolumeSnapshotBeingCreated(content)
+		content, err = ctrl.removeAnnVolumeSnapshotBeingCreated(content)
 		if err != nil {
 			return true, err
 		}
+		if err := ctrl.syncAccessibleTopology(content); err != nil {
+			return true, err
+		}
 		return false, nil
 	}
 	return ctrl.checkandUpdateContentStatus(content)
 }
 
+// getAccessibleTopology returns the topology in the AnnAccessibleTopology
+// annotation of content. An invalid annotation is reported with an event and
+// ignored.
+func (ctrl *csiSnapshotSideCarController) getAccessibleTopology(content *crdv1.VolumeSnapshotContent) []v1.TopologySelectorTerm {
+	topology, err := utils.GetAccessibleTopology(content)
+	if err != nil {
+		klog.Errorf("getAccessibleTopology[%s]: %v", content.Name, err)
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "InvalidAccessibleTopology", err.Error())
+		return nil
+	}
+	return topology
+}
+
+// syncAccessibleTopology copies the topology in the AnnAccessibleTopology
+// annotation of a ready content to its status, e.g. when the annotation is
+// set on a pre-existing snapshot after it became ready.
+func (ctrl *csiSnapshotSideCarController) syncAccessibleTopology(content *crdv1.VolumeSnapshotContent) error {
+	topology := ctrl.getAccessibleTopology(content)
+	if topology == nil || equality.Semantic.DeepEqual(content.Status.AccessibleTopology, topology) {
+		return nil
+	}
+	klog.V(5).Infof("syncAccessibleTopology[%s]: updating the accessible topology", content.Name)
+	patches := []utils.PatchOp{
+		{
+			Op:    "add",
+			Path:  "/status/accessibleTopology",
+			Value: topology,
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset, "status")
+	if err != nil {
+		return newControllerUpdateError(content.Name, err.Error())
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("syncAccessibleTopology[%s]: cannot update internal cache %v", content.Name, err)
+	}
+	return nil
+}
+
+// deferSnapshotDeletion returns true if the deletion grace period of content
+// has not passed yet, and enqueues content again for the end of the grace
+// period. Until then, the snapshot can be kept by changing the deletion
+// policy of content to Retain.
+func (ctrl *csiSnapshotSideCarController) deferSnapshotDeletion(content *crdv1.VolumeSnapshotContent) (bool, error) {
+	gracePeriod, err := utils.GetDeletionGracePeriod(content.ObjectMeta)
+	if err != nil {
+		// Do not delete a snapshot that was meant to be kept for a while.
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "SnapshotDeleteError", err.Error())
+		return false, err
+	}
+	remaining := time.Until(content.DeletionTimestamp.Add(gracePeriod))
+	if remaining <= 0 {
+		return false, nil
+	}
+	deleteTime := content.DeletionTimestamp.Add(gracePeriod).UTC().Format(time.RFC3339)
+	klog.V(4).Infof("VolumeSnapshotContent[%s]: deletion of the snapshot is deferred until %s", content.Name, deleteTime)
+	ctrl.eventRecorder.Event(content, v1.EventTypeNormal, "SnapshotDeletionDeferred", fmt.Sprintf("Snapshot is deleted from the storage system at %s, unless the deletion policy is changed to Retain", deleteTime))
+	ctrl.contentQueue.AddAfter(content.Name, remaining)
+	return true, nil
+}
+
+// purgeRecycledContent deletes a content in the recycle bin once its
+// AnnPurgeAfter time has passed, and enqueues it again for that time
+// otherwise. The deleted content is handled like the content of a deleted
+// snapshot, i.e. the snapshot on the storage system is deleted according to
+// its deletion policy.
+func (ctrl *csiSnapshotSideCarController) purgeRecycledContent(content *crdv1.VolumeSnapshotContent) (bool, error) {
+	purgeAfter, err := utils.GetPurgeAfter(content)
+	if err != nil {
+		// Keep the content until the annotation is fixed.
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "SnapshotDeleteError", err.Error())
+		return false, nil
+	}
+	if remaining := time.Until(purgeAfter); remaining > 0 {
+		klog.V(5).Infof("VolumeSnapshotContent[%s]: purging the content from the recycle bin at %s", content.Name, purgeAfter)
+		ctrl.contentQueue.AddAfter(content.Name, remaining)
+		return false, nil
+	}
+	if utils.IsUnderLegalHold(content.ObjectMeta) {
+		// Purge the content when the hold is removed, which enqueues the
+		// content again.
+		klog.V(4).Infof("VolumeSnapshotContent[%s]: purge is refused because of legal hold %q", content.Name, content.Annotations[utils.AnnLegalHold])
+		ctrl.reportedEvents.Warn(ctrl.eventRecorder, content, content.Name, "SnapshotDeletionRefused", fmt.Sprintf("Snapshot content is not purged from the recycle bin while it is under legal hold %q", content.Annotations[utils.AnnLegalHold]))
+		return false, nil
+	}
+
+	klog.V(4).Infof("VolumeSnapshotContent[%s]: purging the content from the recycle bin", content.Name)
+	annotations := map[string]string{}
+	for key, value := range content.Annotations {
+		annotations[key] = value
+	}
+	annotations[utils.AnnVolumeSnapshotBeingDeleted] = "yes"
+	patches := []utils.PatchOp{
+		// Fail if the content was restored in the meantime.
+		{
+			Op:    "test",
+			Path:  "/metadata/annotations/" + strings.ReplaceAll(utils.AnnPurgeAfter, "/", "~1"),
+			Value: content.Annotations[utils.AnnPurgeAfter],
+		},
+		{
+			Op:    "replace",
+			Path:  "/metadata/annotations",
+			Value: annotations,
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset)
+	if err != nil {
+		return true, newControllerUpdateError(content.Name, err.Error())
+	}
+	err = ctrl.clientset.SnapshotV1().VolumeSnapshotContents().Delete(context.TODO(), content.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &newContent.UID, ResourceVersion: &newContent.ResourceVersion},
+	})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return true, err
+	}
+	ctrl.eventRecorder.Event(content, v1.EventTypeNormal, "SnapshotContentPurged", "Snapshot content is deleted from the recycle bin")
+	return false, nil
+}
+
 // deleteCSISnapshot starts delete action.
 func (ctrl *csiSnapshotSideCarController) deleteCSISnapshot(content *crdv1.VolumeSnapshotContent) (*crdv1.VolumeSnapshotContent, error) {
 	klog.V(5).Infof("Deleting snapshot for content: %s", content.Name)
@@ -237,7 +387,7 @@ func (ctrl *csiSnapshotSideCarController) getCSISnapshotInput(content *crdv1.Vol
 	} else {
 		// If dynamic provisioning for an independent snapshot, return failure if no snapshot class
 		_, groupSnapshotMember := content.Annotations[utils.VolumeGroupSnapshotHandleAnnotation]
-		if content.Spec.Source.VolumeHandle != nil && !groupSnapshotMember {
+		if (content.Spec.Source.VolumeHandle != nil || content.Spec.Source.SourceSnapshotHandle != nil) && !groupSnapshotMember {
 			klog.Errorf("failed to getCSISnapshotInput %s without a snapshot class", content.Name)
 			return nil, nil, fmt.Errorf("failed to take snapshot %s without a snapshot class", content.Name)
 		}
@@ -269,34 +419,9 @@ func (ctrl *csiSnapshotSideCarController) checkandUpdateContentStatusOperation(c
 	if content.Spec.Source.SnapshotHandle != nil || (volumeGroupSnapshotMemberWithGroupSnapshotHandle && content.Status.SnapshotHandle != nil) {
 		klog.V(5).Infof("checkandUpdateContentStatusOperation: call GetSnapshotStatus for snapshot content [%s]", content.Name)
 
-		if content.Spec.VolumeSnapshotClassName != nil {
-			class, err := ctrl.getSnapshotClass(*content.Spec.VolumeSnapshotClassName)
-			if err != nil {
-				klog.Errorf("Failed to get snapshot class %s for snapshot content %s: %v", *content.Spec.VolumeSnapshotClassName, content.Name, err)
-				return content, fmt.Errorf("failed to get snapshot class %s for snapshot content %s: %v", *content.Spec.VolumeSnapshotClassName, content.Name, err)
-			}
-
-			snapshotterListSecretRef, err := utils.GetSecretReference(utils.SnapshotterListSecretParams, class.Parameters, content.GetObjectMeta().GetName(), nil)
-			if err != nil {
-				klog.Errorf("Failed to get secret reference for snapshot content %s: %v", content.Name, err)
-				return content, fmt.Errorf("failed to get secret reference for snapshot content %s: %v", content.Name, err)
-			}
-
-			snapshotterListCredentials, err = utils.GetCredentials(ctrl.client, snapshotterListSecretRef)
-			if err != nil {
-				// Continue with deletion, as the secret may have already been deleted.
-				klog.Errorf("Failed to get credentials for snapshot content %s: %v", content.Name, err)
-				return content, fmt.Errorf("failed to get credentials for snapshot content %s: %v", content.Name, err)
-			}
-		}
-
-		// The VolumeSnapshotContents that are a member of a VolumeGroupSnapshot will always
-		// have Spec.VolumeSnapshotClassName unset, use annotations for secrets in such case.
-		if volumeGroupSnapshotMemberWithGroupSnapshotHandle {
-			snapshotterListCredentials, err = ctrl.GetCredentialsFromAnnotation(content)
-			if err != nil {
-				return content, fmt.Errorf("failed to get credentials from annotation for snapshot content %s: %v", content.Name, err)
-			}
+		snapshotterListCredentials, err = ctrl.getSnapshotterListCredentials(content)
+		if err != nil {
+			return content, err
 		}
 
 		readyToUse, creationTime, size, groupSnapshotID, err = ctrl.handler.GetSnapshotStatus(content, snapshotterListCredentials)
@@ -334,6 +459,51 @@ func (ctrl *csiSnapshotSideCarController) checkandUpdateContentStatusOperation(c
 	return content, nil
 }
 
+// getSnapshotterListCredentials resolves the credentials used to call
+// ListSnapshots for the given content.
+func (ctrl *csiSnapshotSideCarController) getSnapshotterListCredentials(content *crdv1.VolumeSnapshotContent) (map[string]string, error) {
+	var snapshotterListCredentials map[string]string
+
+	if content.Spec.VolumeSnapshotClassName != nil {
+		class, err := ctrl.getSnapshotClass(*content.Spec.VolumeSnapshotClassName)
+		if err != nil {
+			klog.Errorf("Failed to get snapshot class %s for snapshot content %s: %v", *content.Spec.VolumeSnapshotClassName, content.Name, err)
+			return nil, fmt.Errorf("failed to get snapshot class %s for snapshot content %s: %v", *content.Spec.VolumeSnapshotClassName, content.Name, err)
+		}
+
+		snapshotterListSecretRef, err := utils.GetSecretReference(utils.SnapshotterListSecretParams, class.Parameters, content.GetObjectMeta().GetName(), nil, nil, nil)
+		if err != nil {
+			klog.Errorf("Failed to get secret reference for snapshot content %s: %v", content.Name, err)
+			return nil, fmt.Errorf("failed to get secret reference for snapshot content %s: %v", content.Name, err)
+		}
+
+		provider, err := utils.GetSecretProvider(class.Parameters)
+		if err != nil {
+			klog.Errorf("Failed to get secret provider for snapshot content %s: %v", content.Name, err)
+			return nil, fmt.Errorf("failed to get secret provider for snapshot content %s: %v", content.Name, err)
+		}
+
+		snapshotterListCredentials, err = ctrl.getCredentials(provider, snapshotterListSecretRef, content)
+		if err != nil {
+			// Continue with deletion, as the secret may have already been deleted.
+			klog.Errorf("Failed to get credentials for snapshot content %s: %v", content.Name, err)
+			return nil, fmt.Errorf("failed to get credentials for snapshot content %s: %v", content.Name, err)
+		}
+	}
+
+	// The VolumeSnapshotContents that are a member of a VolumeGroupSnapshot will always
+	// have Spec.VolumeSnapshotClassName unset, use annotations for secrets in such case.
+	if content.Status != nil && content.Status.VolumeGroupSnapshotHandle != nil {
+		var err error
+		snapshotterListCredentials, err = ctrl.GetCredentialsFromAnnotation(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credentials from annotation for snapshot content %s: %v", content.Name, err)
+		}
+	}
+
+	return snapshotterListCredentials, nil
+}
+
 // This is a wrapper function for the snapshot creation process.
 func (ctrl *csiSnapshotSideCarController) createSnapshotWrapper(content *crdv1.VolumeSnapshotContent) (*crdv1.VolumeSnapshotContent, error) {
 	klog.Infof("createSnapshotWrapper: Creating snapshot for content %s through the plugin ...", content.Name)
@@ -365,7 +535,9 @@ func (ctrl *csiSnapshotSideCarController) createSnapshotWrapper(content *crdv1.V
 		parameters[utils.PrefixedVolumeSnapshotContentNameKey] = content.Name
 	}
 
+	startTime := time.Now()
 	driverName, snapshotID, creationTime, size, readyToUse, err := ctrl.handler.CreateSnapshot(content, parameters, snapshotterCredentials)
+	ctrl.auditContentOperation(audit.OperationCreateSnapshot, content, snapshotID, startTime, err)
 	if err != nil {
 		// NOTE(xyang): handle create timeout
 		// If it is a final error, remove annotation to indicate
@@ -378,6 +550,9 @@ func (ctrl *csiSnapshotSideCarController) createSnapshotWrapper(content *crdv1.V
 			}
 		}
 
+		if content.Spec.Source.SourceSnapshotHandle != nil {
+			return content, fmt.Errorf("failed to copy snapshot %s: %q", *content.Spec.Source.SourceSnapshotHandle, err)
+		}
 		return content, fmt.Errorf("failed to take snapshot of the volume %s: %q", *content.Spec.Source.VolumeHandle, err)
 	}
 
@@ -415,7 +590,9 @@ func (ctrl *csiSnapshotSideCarController) deleteCSISnapshotOperation(content *cr
 		return content, fmt.Errorf("failed to get input parameters to delete snapshot for content %s: %q", content.Name, err)
 	}
 
+	startTime := time.Now()
 	err = ctrl.handler.DeleteSnapshot(content, snapshotterCredentials)
+	ctrl.auditContentOperation(audit.OperationDeleteSnapshot, content, "", startTime, err)
 	if err != nil {
 		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "SnapshotDeleteError", "Failed to delete snapshot")
 		return content, fmt.Errorf("failed to delete snapshot %#v, err: %v", content.Name, err)
@@ -483,6 +660,7 @@ func (ctrl *csiSnapshotSideCarController) updateSnapshotContentStatus(
 		if size > 0 {
 			newStatus.RestoreSize = &size
 		}
+		newStatus.AccessibleTopology = ctrl.getAccessibleTopology(contentObj)
 		updated = true
 	} else {
 		newStatus = contentObj.Status.DeepCopy()
@@ -509,6 +687,10 @@ func (ctrl *csiSnapshotSideCarController) updateSnapshotContentStatus(
 			newStatus.VolumeGroupSnapshotHandle = &groupSnapshotID
 			updated = true
 		}
+		if topology := ctrl.getAccessibleTopology(contentObj); topology != nil && !equality.Semantic.DeepEqual(newStatus.AccessibleTopology, topology) {
+			newStatus.AccessibleTopology = topology
+			updated = true
+		}
 	}
 
 	if updated {
@@ -580,7 +762,8 @@ func (ctrl *csiSnapshotSideCarController) GetCredentialsFromAnnotation(content *
 		snapshotterSecretRef.Name = annDeletionSecretName
 		snapshotterSecretRef.Namespace = annDeletionSecretNamespace
 
-		snapshotterCredentials, err = utils.GetCredentials(ctrl.client, snapshotterSecretRef)
+		provider := content.Annotations[utils.AnnDeletionSecretRefProvider]
+		snapshotterCredentials, err = ctrl.getCredentials(provider, snapshotterSecretRef, content)
 		if err != nil {
 			// Continue with deletion, as the secret may have already been deleted.
 			klog.Errorf("Failed to get credentials for snapshot %s: %s", content.Name, err.Error())
@@ -633,6 +816,11 @@ func (ctrl *csiSnapshotSideCarController) shouldDelete(content *crdv1.VolumeSnap
 	if content.Spec.Source.SnapshotHandle != nil && content.Spec.VolumeSnapshotRef.UID == "" {
 		return true
 	}
+	// shouldDelete also returns true if a content in the recycle bin is
+	// deleted before it is purged
+	if utils.IsContentInRecycleBin(content) {
+		return true
+	}
 
 	// NOTE(xyang): Handle create snapshot timeout
 	// 2) shouldDelete returns false if AnnVolumeSnapshotBeingCreated
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -44,6 +45,7 @@ import (
 	snapshotinformers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions/volumesnapshot/v1"
 	groupsnapshotlisters "github.com/kubernetes-csi/external-snapshotter/client/v8/listers/volumegroupsnapshot/v1"
 	snapshotlisters "github.com/kubernetes-csi/external-snapshotter/client/v8/listers/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/audit"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 )
@@ -56,6 +58,10 @@ type csiSnapshotSideCarController struct {
 	contentQueue        workqueue.TypedRateLimitingInterface[string]
 	extraCreateMetadata bool
 
+	// reportedEvents are the Warning events emitted for contents whose
+	// deletion is blocked, so that they are not emitted on every sync.
+	reportedEvents *utils.ReportedEvents
+
 	contentLister       snapshotlisters.VolumeSnapshotContentLister
 	contentListerSynced cache.InformerSynced
 	classLister         snapshotlisters.VolumeSnapshotClassLister
@@ -67,6 +73,36 @@ type csiSnapshotSideCarController struct {
 
 	resyncPeriod time.Duration
 
+	// snapshotVerificationInterval is the interval at which ready contents
+	// are checked against the storage system. Zero disables verification.
+	snapshotVerificationInterval time.Duration
+
+	// snapshotUsageInterval is the interval at which the allocated bytes of
+	// ready contents are read from the storage system. Zero disables snapshot
+	// usage accounting.
+	snapshotUsageInterval time.Duration
+	// usageReadsPerPass is the maximum number of contents whose allocated
+	// bytes are read in a snapshot usage pass.
+	usageReadsPerPass int
+	// usageStates track when the allocated bytes of ready contents are read
+	// again, by content name. They are only used by updateSnapshotUsage.
+	usageStates map[string]snapshotUsageState
+	// usage is the allocated bytes reported by the last snapshot usage pass,
+	// by namespace and class. It is only used by updateSnapshotUsage.
+	usage map[snapshotUsageKey]int64
+
+	// auditLogger records the operations sent to the storage system, nil
+	// disables the audit log.
+	auditLogger *audit.Logger
+
+	// credentialProviders retrieve the secrets referenced by classes and
+	// contents, nil reads Kubernetes Secrets only.
+	credentialProviders utils.CredentialProviders
+	// credentialCache reads Kubernetes Secrets from an informer, nil reads
+	// them from the API server.
+	credentialCache    *credentialCache
+	secretListerSynced cache.InformerSynced
+
 	enableVolumeGroupSnapshots       bool
 	groupSnapshotContentQueue        workqueue.TypedRateLimitingInterface[string]
 	groupSnapshotContentLister       groupsnapshotlisters.VolumeGroupSnapshotContentLister
@@ -74,6 +110,9 @@ type csiSnapshotSideCarController struct {
 	groupSnapshotClassLister         groupsnapshotlisters.VolumeGroupSnapshotClassLister
 	groupSnapshotClassListerSynced   cache.InformerSynced
 	groupSnapshotContentStore        cache.Store
+	// emulateGroupSnapshots is true if group snapshots are emulated with
+	// individual snapshots.
+	emulateGroupSnapshots bool
 }
 
 // NewCSISnapshotSideCarController returns a new *csiSnapshotSideCarController
@@ -97,6 +136,11 @@ func NewCSISnapshotSideCarController(
 	volumeGroupSnapshotContentInformer groupsnapshotinformers.VolumeGroupSnapshotContentInformer,
 	volumeGroupSnapshotClassInformer groupsnapshotinformers.VolumeGroupSnapshotClassInformer,
 	groupSnapshotContentRateLimiter workqueue.TypedRateLimiter[string],
+	snapshotVerificationInterval time.Duration,
+	snapshotUsageInterval time.Duration,
+	auditLogger *audit.Logger,
+	credentialProviders utils.CredentialProviders,
+	secretInformer coreinformers.SecretInformer,
 ) *csiSnapshotSideCarController {
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartLogging(klog.Infof)
@@ -115,7 +159,13 @@ func NewCSISnapshotSideCarController(
 		contentQueue: workqueue.NewTypedRateLimitingQueueWithConfig(
 			contentRateLimiter, workqueue.TypedRateLimitingQueueConfig[string]{
 				Name: "csi-snapshotter-content"}),
-		extraCreateMetadata: extraCreateMetadata,
+		extraCreateMetadata:          extraCreateMetadata,
+		reportedEvents:               utils.NewReportedEvents(),
+		snapshotVerificationInterval: snapshotVerificationInterval,
+		snapshotUsageInterval:        snapshotUsageInterval,
+		usageReadsPerPass:            snapshotUsageReadsPerPass,
+		auditLogger:                  auditLogger,
+		credentialProviders:          credentialProviders,
 	}
 
 	volumeSnapshotContentInformer.Informer().AddEventHandlerWithResyncPeriod(
@@ -138,8 +188,41 @@ func NewCSISnapshotSideCarController(
 	ctrl.classLister = volumeSnapshotClassInformer.Lister()
 	ctrl.classListerSynced = volumeSnapshotClassInformer.Informer().HasSynced
 
+	if snapshotVerificationInterval > 0 {
+		registerVerificationMetrics()
+		backendMissingContents.WithLabelValues(driverName).Set(0)
+	}
+
+	if snapshotUsageInterval > 0 {
+		registerUsageMetrics()
+	}
+
+	registerCredentialMetrics(driverName, credentialProviders)
+	if secretInformer != nil {
+		fallback, ok := credentialProviders[utils.CredentialProviderKubernetes]
+		if !ok {
+			fallback = utils.NewKubernetesCredentialProvider(client)
+		}
+		ctrl.credentialCache = newCredentialCache(secretInformer.Lister(), fallback)
+		providers := utils.CredentialProviders{}
+		for name, provider := range credentialProviders {
+			providers[name] = provider
+		}
+		providers[utils.CredentialProviderKubernetes] = ctrl.credentialCache
+		ctrl.credentialProviders = providers
+
+		secretInformer.Informer().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				UpdateFunc: ctrl.secretUpdated,
+				DeleteFunc: ctrl.secretDeleted,
+			},
+		)
+		ctrl.secretListerSynced = secretInformer.Informer().HasSynced
+	}
+
 	ctrl.enableVolumeGroupSnapshots = enableVolumeGroupSnapshots
 	if enableVolumeGroupSnapshots {
+		ctrl.emulateGroupSnapshots = group_snapshotter.IsEmulated(groupSnapshotter)
 		ctrl.groupSnapshotContentStore = cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc)
 		ctrl.groupSnapshotContentQueue = workqueue.NewTypedRateLimitingQueueWithConfig(
 			groupSnapshotContentRateLimiter, workqueue.TypedRateLimitingQueueConfig[string]{
@@ -180,6 +263,9 @@ func (ctrl *csiSnapshotSideCarController) Run(workers int, stopCh <-chan struct{
 	if ctrl.enableVolumeGroupSnapshots {
 		informersSynced = append(informersSynced, []cache.InformerSynced{ctrl.groupSnapshotContentListerSynced, ctrl.groupSnapshotClassListerSynced}...)
 	}
+	if ctrl.secretListerSynced != nil {
+		informersSynced = append(informersSynced, ctrl.secretListerSynced)
+	}
 
 	if !cache.WaitForCacheSync(stopCh, informersSynced...) {
 		klog.Errorf("Cannot sync caches")
@@ -212,6 +298,14 @@ func (ctrl *csiSnapshotSideCarController) Run(workers int, stopCh <-chan struct{
 		}
 	}
 
+	if ctrl.snapshotVerificationInterval > 0 {
+		go wait.Until(ctrl.verifyReadyContents, ctrl.snapshotVerificationInterval, stopCh)
+	}
+
+	if ctrl.snapshotUsageInterval > 0 {
+		go wait.Until(ctrl.updateSnapshotUsage, ctrl.snapshotUsageInterval, stopCh)
+	}
+
 	<-stopCh
 }
 
@@ -378,6 +472,7 @@ func (ctrl *csiSnapshotSideCarController) isDriverMatch(object interface{}) bool
 // deleteContent runs in worker thread and handles "content deleted" event.
 func (ctrl *csiSnapshotSideCarController) deleteContentInCacheStore(content *crdv1.VolumeSnapshotContent) {
 	_ = ctrl.contentStore.Delete(content)
+	ctrl.reportedEvents.ForgetObject(content.Name)
 	klog.V(4).Infof("content %q deleted", content.Name)
 }
 
@@ -17,9 +17,12 @@ import (
 	"testing"
 
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
 	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 var deletionPolicy = crdv1.VolumeSnapshotContentDelete
@@ -140,3 +143,31 @@ func TestShouldDelete(t *testing.T) {
 
 	}
 }
+
+func TestSyncContentLegalHoldEventOnce(t *testing.T) {
+	content := withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-1", "sid1-1", "snap1-1", "sid1-1", classGold, "", "snap1-1-volumehandle", deletionPolicy, nil, &defaultSize, true, &nonFractionalTime),
+		map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnLegalHold: "case-42"})[0]
+
+	client := fake.NewSimpleClientset(content)
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, nil, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	recorder := ctrl.eventRecorder.(*record.FakeRecorder)
+	sync := func(expectedEvents int) {
+		t.Helper()
+		if _, err := ctrl.syncContent(content); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(recorder.Events) != expectedEvents {
+			t.Errorf("expected %d events, got %d", expectedEvents, len(recorder.Events))
+		}
+	}
+
+	// The refused deletion is reported once while the hold lasts.
+	sync(1)
+	sync(1)
+	// A content created again with the same name is reported again.
+	ctrl.deleteContentInCacheStore(content)
+	sync(2)
+}
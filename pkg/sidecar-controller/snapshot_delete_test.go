@@ -373,6 +373,83 @@ func TestDeleteSync(t *testing.T) {
 			expectedDeleteCalls: []deleteCall{},
 			test:                testSyncContent,
 		},
+		{
+			name:                "1-18 - (dynamic)content with deletion protection is not deleted",
+			initialContents:     withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-18", "sid1-18", "snap1-18", "sid1-18", classGold, "", "snap1-18-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnDeletionProtection: "true"}),
+			expectedContents:    withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-18", "sid1-18", "snap1-18", "sid1-18", classGold, "", "snap1-18-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnDeletionProtection: "true"}),
+			expectedEvents:      []string{"Warning SnapshotDeletionProtected"},
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{},
+			test:                testSyncContent,
+		},
+		{
+			name:                "1-19 - (dynamic)deletion of the snapshot is deferred during the deletion grace period",
+			initialContents:     withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-19", "sid1-19", "snap1-19", "sid1-19", classGold, "", "snap1-19-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnDeletionGracePeriod: "24h"}),
+			expectedContents:    withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-19", "sid1-19", "snap1-19", "sid1-19", classGold, "", "snap1-19-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnDeletionGracePeriod: "24h"}),
+			expectedEvents:      []string{"Normal SnapshotDeletionDeferred"},
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{},
+			test:                testSyncContent,
+		},
+		{
+			name:                "1-20 - (dynamic)snapshot is deleted after the deletion grace period",
+			initialContents:     withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-20", "sid1-20", "snap1-20", "sid1-20", classGold, "", "snap1-20-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnDeletionGracePeriod: "1ns"}),
+			expectedContents:    withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-20", "sid1-20", "snap1-20", "", classGold, "", "snap1-20-volumehandle", deletePolicy, nil, &defaultSize, false, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnDeletionGracePeriod: "1ns"}),
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{{"sid1-20", nil, nil}},
+			test:                testSyncContent,
+		},
+		{
+			name:                "1-21 - (dynamic)content in the recycle bin is deleted after its purge time",
+			initialContents:     withContentAnnotations(newContentArrayWithReadyToUse("content1-21", "", "snap1-21", "sid1-21", classGold, "", "snap1-21-volumehandle", deletePolicy, nil, &defaultSize, &True, true), map[string]string{utils.AnnPurgeAfter: "2020-01-01T00:00:00Z"}),
+			expectedContents:    nocontents,
+			expectedEvents:      []string{"Normal SnapshotContentPurged"},
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{},
+			test:                testSyncContent,
+		},
+		{
+			name:                "1-22 - (dynamic)content in the recycle bin is kept until its purge time",
+			initialContents:     withContentAnnotations(newContentArrayWithReadyToUse("content1-22", "", "snap1-22", "sid1-22", classGold, "", "snap1-22-volumehandle", deletePolicy, nil, &defaultSize, &True, true), map[string]string{utils.AnnPurgeAfter: "2100-01-01T00:00:00Z"}),
+			expectedContents:    withContentAnnotations(newContentArrayWithReadyToUse("content1-22", "", "snap1-22", "sid1-22", classGold, "", "snap1-22-volumehandle", deletePolicy, nil, &defaultSize, &True, true), map[string]string{utils.AnnPurgeAfter: "2100-01-01T00:00:00Z"}),
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{},
+			test:                testSyncContent,
+		},
+		{
+			name:                "1-23 - (dynamic)deleted content in the recycle bin deletes the snapshot",
+			initialContents:     withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-23", "", "snap1-23", "sid1-23", classGold, "", "snap1-23-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnPurgeAfter: "2100-01-01T00:00:00Z"}),
+			expectedContents:    withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-23", "", "snap1-23", "", classGold, "", "snap1-23-volumehandle", deletePolicy, nil, &defaultSize, false, &nonFractionalTime), map[string]string{utils.AnnPurgeAfter: "2100-01-01T00:00:00Z"}),
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{{"sid1-23", nil, nil}},
+			test:                testSyncContent,
+		},
+		{
+			name:                "1-24 - (dynamic)content under legal hold is not deleted",
+			initialContents:     withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-24", "sid1-24", "snap1-24", "sid1-24", classGold, "", "snap1-24-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnLegalHold: "case-42"}),
+			expectedContents:    withContentAnnotations(newContentArrayWithDeletionTimestamp("content1-24", "sid1-24", "snap1-24", "sid1-24", classGold, "", "snap1-24-volumehandle", deletePolicy, nil, &defaultSize, true, &nonFractionalTime), map[string]string{utils.AnnVolumeSnapshotBeingDeleted: "yes", utils.AnnLegalHold: "case-42"}),
+			expectedEvents:      []string{"Warning SnapshotDeletionRefused"},
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{},
+			test:                testSyncContent,
+		},
+		{
+			name:                "1-25 - (dynamic)content in the recycle bin under legal hold is not purged",
+			initialContents:     withContentAnnotations(newContentArrayWithReadyToUse("content1-25", "", "snap1-25", "sid1-25", classGold, "", "snap1-25-volumehandle", deletePolicy, nil, &defaultSize, &True, true), map[string]string{utils.AnnPurgeAfter: "2020-01-01T00:00:00Z", utils.AnnLegalHold: "case-42"}),
+			expectedContents:    withContentAnnotations(newContentArrayWithReadyToUse("content1-25", "", "snap1-25", "sid1-25", classGold, "", "snap1-25-volumehandle", deletePolicy, nil, &defaultSize, &True, true), map[string]string{utils.AnnPurgeAfter: "2020-01-01T00:00:00Z", utils.AnnLegalHold: "case-42"}),
+			expectedEvents:      []string{"Warning SnapshotDeletionRefused"},
+			expectSuccess:       true,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{},
+			test:                testSyncContent,
+		},
 	}
 	runSyncContentTests(t, tests, snapshotClasses)
 }
@@ -0,0 +1,205 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/labels"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// Design:
+//
+// RestoreSize is the minimum size of a volume restored from a snapshot, not
+// the space that the snapshot takes on the storage system. CSI has no field
+// for the latter in CreateSnapshot or ListSnapshots, so when snapshot usage
+// accounting is enabled, the sidecar periodically sums the allocated blocks
+// that the SnapshotMetadata service of the driver reports for every ready
+// snapshot. The sum is recorded in Status.AllocatedBytes, and the total per
+// namespace and VolumeSnapshotClass is exported as a metric.
+//
+// Summing the blocks of a snapshot is expensive for the driver, so a pass
+// reads at most snapshotUsageReadsPerPass contents. A value read successfully
+// is fresh for snapshotUsageRefreshPasses passes, and a content whose read
+// failed is skipped for an exponentially growing number of passes. The
+// totals include the last known value of the contents that are skipped.
+
+const (
+	snapshotAllocatedBytesMetricName    = "snapshot_allocated_bytes"
+	snapshotAllocatedBytesMetricHelpMsg = "Number of bytes allocated on the storage backend to the ready VolumeSnapshotContents of a namespace and a VolumeSnapshotClass"
+
+	// snapshotUsageReadsPerPass is the default maximum number of contents
+	// whose allocated bytes are read in a pass.
+	snapshotUsageReadsPerPass = 50
+	// snapshotUsageRefreshPasses is the number of passes after which the
+	// allocated bytes of a content are read again.
+	snapshotUsageRefreshPasses = 10
+	// snapshotUsageMaxBackoffPasses is the maximum number of passes a content
+	// whose read failed is skipped for.
+	snapshotUsageMaxBackoffPasses = 32
+)
+
+var (
+	snapshotAllocatedBytes = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Subsystem: "csi_snapshotter",
+			Name:      snapshotAllocatedBytesMetricName,
+			Help:      snapshotAllocatedBytesMetricHelpMsg,
+		},
+		[]string{"driver_name", "namespace", "snapshot_class"},
+	)
+	usageMetricsOnce sync.Once
+)
+
+// registerUsageMetrics registers the snapshot usage metrics with the legacy
+// registry. It is called when a controller with snapshot usage accounting
+// enabled is created, so that the metrics are exported before the first pass.
+func registerUsageMetrics() {
+	usageMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(snapshotAllocatedBytes)
+	})
+}
+
+// snapshotUsageKey identifies the label values of snapshotAllocatedBytes.
+type snapshotUsageKey struct {
+	namespace     string
+	snapshotClass string
+}
+
+// snapshotUsageState tracks when the allocated bytes of a content are read
+// again.
+type snapshotUsageState struct {
+	// skipPasses is the number of passes left until the next read.
+	skipPasses int
+	// backoffPasses is the number of passes skipped after the last failed
+	// read, zero if the last read succeeded.
+	backoffPasses int
+	// allocatedBytes is the last value read, nil if none was read yet.
+	allocatedBytes *int64
+}
+
+// updateSnapshotUsage reads the allocated bytes of the ready contents of this
+// driver that are due and exports the total per namespace and
+// VolumeSnapshotClass.
+func (ctrl *csiSnapshotSideCarController) updateSnapshotUsage() {
+	contents, err := ctrl.contentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("updateSnapshotUsage: failed to list contents: %v", err)
+		return
+	}
+	// Read in a stable order to keep the load on the driver predictable.
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Name < contents[j].Name })
+
+	usageStates := map[string]snapshotUsageState{}
+	usage := map[snapshotUsageKey]int64{}
+	reads := 0
+	for _, content := range contents {
+		// The same contents as the verification: ready, not deleted and
+		// with a snapshot handle.
+		if !ctrl.shouldVerifyContent(content) {
+			continue
+		}
+
+		state := ctrl.usageStates[content.Name]
+		switch {
+		case state.skipPasses > 0:
+			state.skipPasses--
+		case reads >= ctrl.usageReadsPerPass:
+			// Read in one of the next passes.
+		default:
+			reads++
+			value, err := ctrl.updateContentAllocatedBytes(content)
+			if err != nil {
+				state.backoffPasses = min(max(2*state.backoffPasses, 1), snapshotUsageMaxBackoffPasses)
+				state.skipPasses = state.backoffPasses
+				klog.Errorf("updateSnapshotUsage: failed to get the allocated bytes of content %s, skipping it for %d passes: %v", content.Name, state.skipPasses, err)
+			} else {
+				state = snapshotUsageState{skipPasses: snapshotUsageRefreshPasses - 1, allocatedBytes: &value}
+			}
+		}
+		usageStates[content.Name] = state
+
+		// Keep the last known value in the total. The value read last may
+		// not be in the informer yet.
+		allocatedBytes := state.allocatedBytes
+		if allocatedBytes == nil {
+			allocatedBytes = content.Status.AllocatedBytes
+		}
+		if allocatedBytes == nil {
+			continue
+		}
+		key := snapshotUsageKey{namespace: content.Spec.VolumeSnapshotRef.Namespace}
+		if content.Spec.VolumeSnapshotClassName != nil {
+			key.snapshotClass = *content.Spec.VolumeSnapshotClassName
+		}
+		usage[key] += *allocatedBytes
+	}
+	// Drop the states of the contents that are gone or not ready anymore.
+	ctrl.usageStates = usageStates
+
+	for key, allocatedBytes := range usage {
+		snapshotAllocatedBytes.WithLabelValues(ctrl.driverName, key.namespace, key.snapshotClass).Set(float64(allocatedBytes))
+	}
+	// Drop the namespaces and classes without snapshots anymore.
+	for key := range ctrl.usage {
+		if _, ok := usage[key]; !ok {
+			snapshotAllocatedBytes.DeleteLabelValues(ctrl.driverName, key.namespace, key.snapshotClass)
+		}
+	}
+	ctrl.usage = usage
+	klog.V(4).Infof("updateSnapshotUsage: read the allocated bytes of %d contents, updated the allocated bytes of %d namespaces and classes", reads, len(usage))
+}
+
+// updateContentAllocatedBytes reads the allocated bytes of the snapshot of a
+// single content and records them in its status if they changed.
+func (ctrl *csiSnapshotSideCarController) updateContentAllocatedBytes(content *crdv1.VolumeSnapshotContent) (int64, error) {
+	credentials, err := ctrl.getSnapshotterListCredentials(content)
+	if err != nil {
+		return 0, err
+	}
+
+	allocatedBytes, err := ctrl.handler.GetSnapshotAllocatedBytes(content, credentials)
+	if err != nil {
+		return 0, err
+	}
+	if content.Status.AllocatedBytes != nil && *content.Status.AllocatedBytes == allocatedBytes {
+		return allocatedBytes, nil
+	}
+
+	patches := []utils.PatchOp{
+		{
+			Op:    "add",
+			Path:  "/status/allocatedBytes",
+			Value: allocatedBytes,
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset, "status")
+	if err != nil {
+		return 0, newControllerUpdateError(content.Name, err.Error())
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("updateContentAllocatedBytes [%s]: cannot update internal cache %v", content.Name, err)
+	}
+	return allocatedBytes, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestUpdateSnapshotUsage(t *testing.T) {
+	first := newContent("content-first", "snapuid1", "snap1", "sid1", "", "", "volume-handle-1", deletionPolicy, nil, &defaultSize, true, nil)
+	first.Status.ReadyToUse = &True
+	second := newContent("content-second", "snapuid2", "snap2", "sid2", "", "", "volume-handle-2", deletionPolicy, nil, &defaultSize, true, nil)
+	second.Status.ReadyToUse = &True
+	allocatedBytes := int64(300)
+	second.Status.AllocatedBytes = &allocatedBytes
+	notReady := newContent("content-not-ready", "snapuid3", "snap3", "sid3", "", "", "volume-handle-3", deletionPolicy, nil, &defaultSize, true, nil)
+	notReady.Status.ReadyToUse = &False
+
+	contents := []*crdv1.VolumeSnapshotContent{first, second, notReady}
+	client := fake.NewSimpleClientset(first, second, notReady)
+	informerFactory := informers.NewSharedInformerFactory(client, utils.NoResyncPeriodFunc())
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, informerFactory, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	fakeSnapshot := ctrl.handler.(*csiHandler).snapshotter.(*fakeSnapshotter)
+	fakeSnapshot.allocatedBytes = map[string]int64{"sid1": 100, "sid2": 300}
+	indexer := informerFactory.Snapshot().V1().VolumeSnapshotContents().Informer().GetIndexer()
+	for _, content := range contents {
+		if err := indexer.Add(content); err != nil {
+			t.Fatalf("failed to add content to the lister: %v", err)
+		}
+	}
+
+	registerUsageMetrics()
+	ctrl.updateSnapshotUsage()
+
+	for name, expected := range map[string]int64{"content-first": 100, "content-second": 300} {
+		got, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get content %s: %v", name, err)
+		}
+		if got.Status.AllocatedBytes == nil || *got.Status.AllocatedBytes != expected {
+			t.Errorf("expected %s to have %d allocated bytes, got %v", name, expected, got.Status.AllocatedBytes)
+		}
+	}
+	got, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), "content-not-ready", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if got.Status.AllocatedBytes != nil {
+		t.Errorf("expected content-not-ready to have no allocated bytes, got %d", *got.Status.AllocatedBytes)
+	}
+
+	value, err := testutil.GetGaugeMetricValue(snapshotAllocatedBytes.WithLabelValues(mockDriverName, testNamespace, ""))
+	if err != nil {
+		t.Fatalf("failed to get metric: %v", err)
+	}
+	if value != 400 {
+		t.Errorf("expected 400 allocated bytes in namespace %s, got %v", testNamespace, value)
+	}
+
+	// The namespace without ready snapshots anymore is dropped.
+	for _, content := range []*crdv1.VolumeSnapshotContent{first, second} {
+		if err := indexer.Delete(content); err != nil {
+			t.Fatalf("failed to delete content from the lister: %v", err)
+		}
+	}
+	ctrl.updateSnapshotUsage()
+	if snapshotAllocatedBytes.DeleteLabelValues(mockDriverName, testNamespace, "") {
+		t.Errorf("expected the allocated bytes of namespace %s to be dropped", testNamespace)
+	}
+}
+
+func TestUpdateSnapshotUsagePasses(t *testing.T) {
+	var contents []*crdv1.VolumeSnapshotContent
+	for _, id := range []string{"1", "2", "3"} {
+		content := newContent("content-"+id, "snapuid"+id, "snap"+id, "sid"+id, "", "", "volume-handle-"+id, deletionPolicy, nil, &defaultSize, true, nil)
+		content.Status.ReadyToUse = &True
+		contents = append(contents, content)
+	}
+
+	client := fake.NewSimpleClientset(contents[0], contents[1], contents[2])
+	informerFactory := informers.NewSharedInformerFactory(client, utils.NoResyncPeriodFunc())
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, informerFactory, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	ctrl.usageReadsPerPass = 2
+	registerUsageMetrics()
+	fakeSnapshot := ctrl.handler.(*csiHandler).snapshotter.(*fakeSnapshotter)
+	fakeSnapshot.allocatedBytes = map[string]int64{"sid1": 100, "sid3": 300}
+	fakeSnapshot.allocatedBytesErrs = map[string]error{"sid2": errors.New("mock error")}
+	indexer := informerFactory.Snapshot().V1().VolumeSnapshotContents().Informer().GetIndexer()
+	for _, content := range contents {
+		if err := indexer.Add(content); err != nil {
+			t.Fatalf("failed to add content to the lister: %v", err)
+		}
+	}
+
+	pass := func(expected ...string) {
+		t.Helper()
+		fakeSnapshot.allocatedBytesCalls = nil
+		ctrl.updateSnapshotUsage()
+		if !reflect.DeepEqual(fakeSnapshot.allocatedBytesCalls, expected) {
+			t.Errorf("expected reads of %v, got %v", expected, fakeSnapshot.allocatedBytesCalls)
+		}
+	}
+
+	// The first pass reads two contents, the next one the third.
+	pass("sid1", "sid2")
+	pass("sid3")
+	// The failed read of sid2 is retried with backoff, fresh values are not
+	// read again.
+	pass("sid2")
+	for i := 0; i < 2; i++ {
+		pass()
+	}
+	pass("sid2")
+
+	// A successful read ends the backoff.
+	delete(fakeSnapshot.allocatedBytesErrs, "sid2")
+	fakeSnapshot.allocatedBytes["sid2"] = 200
+	for i := 0; i < 4; i++ {
+		pass()
+	}
+	// sid1 is not fresh anymore either.
+	pass("sid1", "sid2")
+	if state := ctrl.usageStates["content-2"]; state.backoffPasses != 0 {
+		t.Errorf("expected no backoff after a successful read, got %+v", state)
+	}
+	pass("sid3")
+
+	value, err := testutil.GetGaugeMetricValue(snapshotAllocatedBytes.WithLabelValues(mockDriverName, testNamespace, ""))
+	if err != nil {
+		t.Fatalf("failed to get metric: %v", err)
+	}
+	if value != 600 {
+		t.Errorf("expected 600 allocated bytes in namespace %s, got %v", testNamespace, value)
+	}
+}
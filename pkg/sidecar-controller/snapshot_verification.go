@@ -0,0 +1,203 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	klog "k8s.io/klog/v2"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+)
+
+// Design:
+//
+// Once a VolumeSnapshotContent is ReadyToUse, syncContent does not call the
+// CSI driver for it anymore. When snapshot verification is enabled, the
+// sidecar periodically lists every ready snapshot by ID and flags the
+// contents whose snapshot is no longer known to the storage system, e.g.
+// because it was deleted out-of-band.
+//
+// A missing snapshot is reported with Status.Error and a warning event. The
+// common controller copies the error to the bound VolumeSnapshot. ReadyToUse
+// is intentionally left untouched: a dynamically provisioned content that is
+// not ready falls back into the creation path, which would silently cut a new
+// snapshot with the same name. The error is cleared if the snapshot is listed
+// again.
+
+const (
+	// snapshotMissingOnBackendMsg prefixes the Status.Error message set on a
+	// content whose snapshot is no longer listed by the CSI driver.
+	snapshotMissingOnBackendMsg = "snapshot is missing on the storage backend"
+
+	backendMissingContentsMetricName    = "backend_missing_contents"
+	backendMissingContentsMetricHelpMsg = "Number of ready VolumeSnapshotContents whose snapshot was not found on the storage backend during the last verification"
+)
+
+var (
+	backendMissingContents = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Subsystem: "csi_snapshotter",
+			Name:      backendMissingContentsMetricName,
+			Help:      backendMissingContentsMetricHelpMsg,
+		},
+		[]string{"driver_name"},
+	)
+	verificationMetricsOnce sync.Once
+)
+
+// registerVerificationMetrics registers the verification metrics with the
+// legacy registry. It is called when a controller with verification enabled
+// is created, so that the metrics are exported before the first pass.
+func registerVerificationMetrics() {
+	verificationMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(backendMissingContents)
+	})
+}
+
+// verifyReadyContents lists all ready contents of this driver and checks
+// that the storage system still knows their snapshots.
+func (ctrl *csiSnapshotSideCarController) verifyReadyContents() {
+	contents, err := ctrl.contentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("verifyReadyContents: failed to list contents: %v", err)
+		return
+	}
+	// Verify in a stable order to keep the load on the driver predictable.
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Name < contents[j].Name })
+
+	missing := 0
+	for _, content := range contents {
+		if !ctrl.shouldVerifyContent(content) {
+			continue
+		}
+		found, err := ctrl.verifyContent(content)
+		if err != nil {
+			klog.Errorf("verifyReadyContents: failed to verify content %s: %v", content.Name, err)
+			continue
+		}
+		if !found {
+			missing++
+		}
+	}
+	backendMissingContents.WithLabelValues(ctrl.driverName).Set(float64(missing))
+	klog.V(4).Infof("verifyReadyContents: verified contents, %d missing on the storage backend", missing)
+}
+
+// shouldVerifyContent returns true for ready, not deleted contents of this
+// driver that have a snapshot handle.
+func (ctrl *csiSnapshotSideCarController) shouldVerifyContent(content *crdv1.VolumeSnapshotContent) bool {
+	if content.DeletionTimestamp != nil || !contentIsReady(content) || !ctrl.isDriverMatch(content) {
+		return false
+	}
+	return content.Status.SnapshotHandle != nil || content.Spec.Source.SnapshotHandle != nil
+}
+
+// verifyContent checks the snapshot of a single content and updates its
+// error status accordingly. It returns false if the snapshot is missing.
+func (ctrl *csiSnapshotSideCarController) verifyContent(content *crdv1.VolumeSnapshotContent) (bool, error) {
+	credentials, err := ctrl.getSnapshotterListCredentials(content)
+	if err != nil {
+		return false, err
+	}
+
+	_, _, _, _, err = ctrl.handler.GetSnapshotStatus(content, credentials)
+	if err != nil {
+		if !errors.Is(err, snapshotter.ErrSnapshotNotFound) {
+			return false, err
+		}
+		return false, ctrl.markContentMissingOnBackend(content)
+	}
+	return true, ctrl.clearContentMissingOnBackend(content)
+}
+
+// markContentMissingOnBackend sets Status.Error on the content and emits a
+// warning event, unless the content is already marked.
+func (ctrl *csiSnapshotSideCarController) markContentMissingOnBackend(content *crdv1.VolumeSnapshotContent) error {
+	if isContentMissingOnBackend(content) {
+		return nil
+	}
+
+	snapshotHandle := content.Spec.Source.SnapshotHandle
+	if content.Status.SnapshotHandle != nil {
+		snapshotHandle = content.Status.SnapshotHandle
+	}
+	message := fmt.Sprintf("%s: snapshot %s was not found by the CSI driver", snapshotMissingOnBackendMsg, *snapshotHandle)
+	patches := []utils.PatchOp{
+		{
+			Op:   "replace",
+			Path: "/status/error",
+			Value: &crdv1.VolumeSnapshotError{
+				Time:    &metav1.Time{Time: time.Now()},
+				Message: &message,
+			},
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset, "status")
+
+	// Emit the event even if the status update fails so that user can see the error
+	ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "SnapshotMissingOnBackend", message)
+
+	if err != nil {
+		return newControllerUpdateError(content.Name, err.Error())
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("markContentMissingOnBackend [%s]: cannot update internal cache %v", content.Name, err)
+	}
+	return nil
+}
+
+// clearContentMissingOnBackend removes the error set by
+// markContentMissingOnBackend once the snapshot is listed again.
+func (ctrl *csiSnapshotSideCarController) clearContentMissingOnBackend(content *crdv1.VolumeSnapshotContent) error {
+	if !isContentMissingOnBackend(content) {
+		return nil
+	}
+
+	patches := []utils.PatchOp{
+		{
+			Op:   "remove",
+			Path: "/status/error",
+		},
+	}
+	newContent, err := utils.PatchVolumeSnapshotContent(content, patches, ctrl.clientset, "status")
+	if err != nil {
+		return newControllerUpdateError(content.Name, err.Error())
+	}
+	ctrl.eventRecorder.Event(newContent, v1.EventTypeNormal, "SnapshotFoundOnBackend", "Snapshot is listed by the CSI driver again")
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("clearContentMissingOnBackend [%s]: cannot update internal cache %v", content.Name, err)
+	}
+	return nil
+}
+
+func isContentMissingOnBackend(content *crdv1.VolumeSnapshotContent) bool {
+	return content.Status != nil && content.Status.Error != nil && content.Status.Error.Message != nil &&
+		strings.HasPrefix(*content.Status.Error.Message, snapshotMissingOnBackendMsg)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/kubernetes-csi/external-snapshotter/client/v8/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-snapshotter/client/v8/informers/externalversions"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/snapshotter"
+	"github.com/kubernetes-csi/external-snapshotter/v8/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestVerifyReadyContents(t *testing.T) {
+	ready := newContent("content-ready", "snapuid1", "snap1", "sid1", "", "", "volume-handle-1", deletionPolicy, nil, &defaultSize, true, nil)
+	ready.Status.ReadyToUse = &True
+	missing := newContent("content-missing", "snapuid2", "snap2", "sid2", "", "", "volume-handle-2", deletionPolicy, nil, &defaultSize, true, nil)
+	missing.Status.ReadyToUse = &True
+	notReady := newContent("content-not-ready", "snapuid3", "snap3", "sid3", "", "", "volume-handle-3", deletionPolicy, nil, &defaultSize, true, nil)
+	notReady.Status.ReadyToUse = &False
+	deleted := newContent("content-deleted", "snapuid4", "snap4", "sid4", "", "", "volume-handle-4", deletionPolicy, nil, &defaultSize, true, &timeNowMetav1)
+	deleted.Status.ReadyToUse = &True
+
+	test := controllerTest{
+		expectedListCalls: []listCall{
+			// Contents are verified in name order.
+			{snapshotID: "sid2", err: fmt.Errorf("%w for snapshotID sid2", snapshotter.ErrSnapshotNotFound)},
+			{snapshotID: "sid1", readyToUse: true},
+			// Second pass: the missing snapshot is listed again.
+			{snapshotID: "sid2", readyToUse: true},
+			{snapshotID: "sid1", readyToUse: true},
+		},
+	}
+
+	contents := []*crdv1.VolumeSnapshotContent{ready, missing, notReady, deleted}
+	client := fake.NewSimpleClientset(ready, missing, notReady, deleted)
+	informerFactory := informers.NewSharedInformerFactory(client, utils.NoResyncPeriodFunc())
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, informerFactory, t, test)
+	if err != nil {
+		t.Fatalf("failed to create test controller: %v", err)
+	}
+	recorder := ctrl.eventRecorder.(*record.FakeRecorder)
+	indexer := informerFactory.Snapshot().V1().VolumeSnapshotContents().Informer().GetIndexer()
+	for _, content := range contents {
+		if err := indexer.Add(content); err != nil {
+			t.Fatalf("failed to add content to the lister: %v", err)
+		}
+	}
+
+	ctrl.verifyReadyContents()
+
+	got, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), "content-missing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if !isContentMissingOnBackend(got) {
+		t.Errorf("expected content-missing to report a missing snapshot, got status %+v", got.Status)
+	}
+	if got.Status.ReadyToUse == nil || !*got.Status.ReadyToUse {
+		t.Errorf("expected content-missing to stay ready")
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "SnapshotMissingOnBackend") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Errorf("expected a SnapshotMissingOnBackend event")
+	}
+	for _, name := range []string{"content-ready", "content-not-ready", "content-deleted"} {
+		other, err := client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get content %s: %v", name, err)
+		}
+		if other.Status.Error != nil {
+			t.Errorf("expected %s to have no error, got %+v", name, other.Status.Error)
+		}
+	}
+
+	if err := indexer.Update(got); err != nil {
+		t.Fatalf("failed to update content in the lister: %v", err)
+	}
+	ctrl.verifyReadyContents()
+
+	got, err = client.SnapshotV1().VolumeSnapshotContents().Get(context.TODO(), "content-missing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get content: %v", err)
+	}
+	if got.Status.Error != nil {
+		t.Errorf("expected the error to be cleared once the snapshot is listed again, got %+v", got.Status.Error)
+	}
+
+	fakeSnapshot := ctrl.handler.(*csiHandler).snapshotter.(*fakeSnapshotter)
+	if fakeSnapshot.listCallCounter != len(test.expectedListCalls) {
+		t.Errorf("expected %d list calls, got %d", len(test.expectedListCalls), fakeSnapshot.listCallCounter)
+	}
+}
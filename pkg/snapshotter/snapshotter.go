@@ -18,7 +18,9 @@ package snapshotter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
@@ -39,8 +41,33 @@ type Snapshotter interface {
 
 	// GetSnapshotStatus returns if a snapshot is ready to use, creation time, and restore size.
 	GetSnapshotStatus(ctx context.Context, snapshotID string, snapshotterListCredentials map[string]string) (bool, time.Time, int64, string, error)
+
+	// ListSnapshots returns all snapshots known to the driver. If sourceVolumeID is set, only snapshots of that volume are returned.
+	ListSnapshots(ctx context.Context, sourceVolumeID string, snapshotterListCredentials map[string]string) ([]SnapshotInfo, error)
+
+	// GetSnapshotAllocatedBytes returns the number of bytes allocated to a snapshot on the storage system,
+	// from the allocated blocks reported by the SnapshotMetadata service of the driver.
+	GetSnapshotAllocatedBytes(ctx context.Context, snapshotID string, snapshotterCredentials map[string]string) (int64, error)
 }
 
+// SnapshotInfo describes a snapshot returned by ListSnapshots.
+type SnapshotInfo struct {
+	SnapshotID      string
+	SourceVolumeID  string
+	CreationTime    time.Time
+	Size            int64
+	ReadyToUse      bool
+	GroupSnapshotID string
+}
+
+// ErrListSnapshotsNotSupported is returned by ListSnapshots when the driver
+// does not have the LIST_SNAPSHOTS capability.
+var ErrListSnapshotsNotSupported = errors.New("driver does not support ListSnapshots")
+
+// ErrSnapshotNotFound is returned by GetSnapshotStatus when the driver does
+// not list the requested snapshot.
+var ErrSnapshotNotFound = errors.New("can not find snapshot")
+
 type snapshot struct {
 	conn *grpc.ClientConn
 }
@@ -132,9 +159,80 @@ func (s *snapshot) GetSnapshotStatus(ctx context.Context, snapshotID string, sna
 	}
 
 	if rsp.Entries == nil || len(rsp.Entries) == 0 {
-		return false, time.Time{}, 0, "", fmt.Errorf("can not find snapshot for snapshotID %s", snapshotID)
+		return false, time.Time{}, 0, "", fmt.Errorf("%w for snapshotID %s", ErrSnapshotNotFound, snapshotID)
 	}
 
 	creationTime := rsp.Entries[0].Snapshot.CreationTime.AsTime()
 	return rsp.Entries[0].Snapshot.ReadyToUse, creationTime, rsp.Entries[0].Snapshot.SizeBytes, rsp.Entries[0].Snapshot.GroupSnapshotId, nil
 }
+
+func (s *snapshot) ListSnapshots(ctx context.Context, sourceVolumeID string, snapshotterListCredentials map[string]string) ([]SnapshotInfo, error) {
+	klog.V(5).Infof("ListSnapshots: source volume %q", sourceVolumeID)
+
+	client := csi.NewControllerClient(s.conn)
+
+	listSnapshotsSupported, err := s.isListSnapshotsSupported(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if ListSnapshots is supported: %s", err.Error())
+	}
+	if !listSnapshotsSupported {
+		return nil, ErrListSnapshotsNotSupported
+	}
+
+	var snapshots []SnapshotInfo
+	req := csi.ListSnapshotsRequest{
+		SourceVolumeId: sourceVolumeID,
+		Secrets:        snapshotterListCredentials,
+	}
+	for {
+		rsp, err := client.ListSnapshots(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range rsp.Entries {
+			if entry.Snapshot == nil {
+				continue
+			}
+			snapshots = append(snapshots, SnapshotInfo{
+				SnapshotID:      entry.Snapshot.SnapshotId,
+				SourceVolumeID:  entry.Snapshot.SourceVolumeId,
+				CreationTime:    entry.Snapshot.CreationTime.AsTime(),
+				Size:            entry.Snapshot.SizeBytes,
+				ReadyToUse:      entry.Snapshot.ReadyToUse,
+				GroupSnapshotID: entry.Snapshot.GroupSnapshotId,
+			})
+		}
+		if rsp.NextToken == "" {
+			return snapshots, nil
+		}
+		req.StartingToken = rsp.NextToken
+	}
+}
+
+func (s *snapshot) GetSnapshotAllocatedBytes(ctx context.Context, snapshotID string, snapshotterCredentials map[string]string) (int64, error) {
+	klog.V(5).Infof("GetSnapshotAllocatedBytes: %s", snapshotID)
+
+	client := csi.NewSnapshotMetadataClient(s.conn)
+
+	req := csi.GetMetadataAllocatedRequest{
+		SnapshotId: snapshotID,
+		Secrets:    snapshotterCredentials,
+	}
+	stream, err := client.GetMetadataAllocated(ctx, &req)
+	if err != nil {
+		return 0, err
+	}
+	var allocated int64
+	for {
+		rsp, err := stream.Recv()
+		if err == io.EOF {
+			return allocated, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		for _, block := range rsp.BlockMetadata {
+			allocated += block.SizeBytes
+		}
+	}
+}
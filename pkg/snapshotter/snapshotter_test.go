@@ -19,6 +19,7 @@ package snapshotter
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 	"testing"
 	"time"
@@ -488,6 +489,208 @@ func TestGetSnapshotStatus(t *testing.T) {
 	}
 }
 
+func TestListSnapshots(t *testing.T) {
+	createTimestamp := timestamppb.Now()
+	secret := map[string]string{"foo": "bar"}
+
+	firstPage := &csi.ListSnapshotsResponse{
+		Entries: []*csi.ListSnapshotsResponse_Entry{
+			{
+				Snapshot: &csi.Snapshot{
+					SnapshotId:     "snap1",
+					SourceVolumeId: "volumeid",
+					SizeBytes:      1000,
+					CreationTime:   createTimestamp,
+					ReadyToUse:     true,
+				},
+			},
+		},
+		NextToken: "page2",
+	}
+	secondPage := &csi.ListSnapshotsResponse{
+		Entries: []*csi.ListSnapshotsResponse_Entry{
+			{
+				Snapshot: &csi.Snapshot{
+					SnapshotId:     "snap2",
+					SourceVolumeId: "volumeid",
+					SizeBytes:      2000,
+					CreationTime:   createTimestamp,
+					ReadyToUse:     false,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                   string
+		sourceVolumeID         string
+		listSnapshotsSupported bool
+		expectCalls            []*csi.ListSnapshotsRequest
+		output                 []*csi.ListSnapshotsResponse
+		injectError            codes.Code
+		expectError            bool
+		expectSnapshots        []SnapshotInfo
+	}{
+		{
+			name:                   "success with pagination",
+			sourceVolumeID:         "volumeid",
+			listSnapshotsSupported: true,
+			expectCalls: []*csi.ListSnapshotsRequest{
+				{SourceVolumeId: "volumeid", Secrets: secret},
+				{SourceVolumeId: "volumeid", Secrets: secret, StartingToken: "page2"},
+			},
+			output: []*csi.ListSnapshotsResponse{firstPage, secondPage},
+			expectSnapshots: []SnapshotInfo{
+				{SnapshotID: "snap1", SourceVolumeID: "volumeid", Size: 1000, CreationTime: createTimestamp.AsTime(), ReadyToUse: true},
+				{SnapshotID: "snap2", SourceVolumeID: "volumeid", Size: 2000, CreationTime: createTimestamp.AsTime(), ReadyToUse: false},
+			},
+		},
+		{
+			name:                   "ListSnapshots not supported",
+			listSnapshotsSupported: false,
+			expectError:            true,
+		},
+		{
+			name:                   "gRPC error",
+			listSnapshotsSupported: true,
+			expectCalls: []*csi.ListSnapshotsRequest{
+				{Secrets: secret},
+			},
+			output:      []*csi.ListSnapshotsResponse{nil},
+			injectError: codes.Unavailable,
+			expectError: true,
+		},
+	}
+
+	mockController, driver, _, controllerServer, csiConn, err := createMockServer(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mockController.Finish()
+	defer driver.Stop()
+	defer csiConn.Close()
+
+	for _, test := range tests {
+		var injectedErr error
+		if test.injectError != codes.OK {
+			injectedErr = status.Error(test.injectError, fmt.Sprintf("Injecting error %d", test.injectError))
+		}
+
+		var controllerCapabilities []*csi.ControllerServiceCapability
+		if test.listSnapshotsSupported {
+			controllerCapabilities = append(controllerCapabilities, &csi.ControllerServiceCapability{
+				Type: &csi.ControllerServiceCapability_Rpc{
+					Rpc: &csi.ControllerServiceCapability_RPC{
+						Type: csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+					},
+				},
+			})
+		}
+		controllerServer.EXPECT().ControllerGetCapabilities(gomock.Any(), gomock.Any()).Return(&csi.ControllerGetCapabilitiesResponse{
+			Capabilities: controllerCapabilities,
+		}, nil).Times(1)
+		for i, in := range test.expectCalls {
+			controllerServer.EXPECT().ListSnapshots(gomock.Any(), utils.Protobuf(in)).Return(test.output[i], injectedErr).Times(1)
+		}
+
+		s := NewSnapshotter(csiConn)
+		snapshots, err := s.ListSnapshots(context.Background(), test.sourceVolumeID, secret)
+		if test.expectError && err == nil {
+			t.Errorf("test %q: Expected error, got none", test.name)
+		}
+		if !test.expectError && err != nil {
+			t.Errorf("test %q: got error: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(test.expectSnapshots, snapshots) {
+			t.Errorf("test %q: expected snapshots %+v, got %+v", test.name, test.expectSnapshots, snapshots)
+		}
+	}
+}
+
+// fakeSnapshotMetadataServer streams the allocated blocks of a snapshot,
+// one response per entry of blocks.
+type fakeSnapshotMetadataServer struct {
+	csi.UnimplementedSnapshotMetadataServer
+	blocks [][]*csi.BlockMetadata
+	err    error
+}
+
+func (f *fakeSnapshotMetadataServer) GetMetadataAllocated(req *csi.GetMetadataAllocatedRequest, stream csi.SnapshotMetadata_GetMetadataAllocatedServer) error {
+	for _, blocks := range f.blocks {
+		rsp := &csi.GetMetadataAllocatedResponse{
+			BlockMetadataType:   csi.BlockMetadataType_VARIABLE_LENGTH,
+			VolumeCapacityBytes: 1 << 20,
+			BlockMetadata:       blocks,
+		}
+		if err := stream.Send(rsp); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func TestGetSnapshotAllocatedBytes(t *testing.T) {
+	tests := []struct {
+		name          string
+		blocks        [][]*csi.BlockMetadata
+		err           error
+		expectError   bool
+		expectedBytes int64
+	}{
+		{
+			name: "success",
+			blocks: [][]*csi.BlockMetadata{
+				{{ByteOffset: 0, SizeBytes: 4096}, {ByteOffset: 8192, SizeBytes: 4096}},
+				{{ByteOffset: 65536, SizeBytes: 1024}},
+			},
+			expectedBytes: 9216,
+		},
+		{
+			name:          "no allocated blocks",
+			expectedBytes: 0,
+		},
+		{
+			name:        "gRPC error",
+			blocks:      [][]*csi.BlockMetadata{{{ByteOffset: 0, SizeBytes: 4096}}},
+			err:         status.Error(codes.NotFound, "snapshot not found"),
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			drv := driver.NewCSIDriver(&driver.CSIDriverServers{
+				SnapshotMetadata: &fakeSnapshotMetadataServer{blocks: test.blocks, err: test.err},
+			})
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to listen: %v", err)
+			}
+			if err := drv.Start(l); err != nil {
+				t.Fatalf("failed to start the driver: %v", err)
+			}
+			defer drv.Stop()
+			csiConn, err := connection.Connect(context.Background(), drv.Address(), metrics.NewCSIMetricsManager(""))
+			if err != nil {
+				t.Fatalf("failed to connect to the driver: %v", err)
+			}
+			defer csiConn.Close()
+
+			s := NewSnapshotter(csiConn)
+			allocatedBytes, err := s.GetSnapshotAllocatedBytes(context.Background(), "testid", nil)
+			if test.expectError && err == nil {
+				t.Errorf("expected error, got none")
+			}
+			if !test.expectError && err != nil {
+				t.Errorf("got error: %v", err)
+			}
+			if allocatedBytes != test.expectedBytes {
+				t.Errorf("expected %d allocated bytes, got %d", test.expectedBytes, allocatedBytes)
+			}
+		})
+	}
+}
+
 func FakeCSIVolume() *v1.PersistentVolume {
 	volume := v1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
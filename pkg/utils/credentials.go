@@ -0,0 +1,239 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Names of the credential providers, used as the value of the
+// PrefixedSecretProviderKey class parameter.
+const (
+	// CredentialProviderKubernetes reads the credentials from Kubernetes
+	// Secrets. It is used when a class does not select a provider.
+	CredentialProviderKubernetes = "kubernetes"
+	// CredentialProviderFile reads the credentials from files, e.g. from a
+	// projected volume mounted into the sidecar.
+	CredentialProviderFile = "file"
+	// CredentialProviderGRPC fetches the credentials from a local gRPC
+	// server, e.g. a Secrets Store CSI driver provider.
+	CredentialProviderGRPC = "grpc"
+)
+
+// GRPCCredentialProviderMethod is the method called on the gRPC credential
+// provider. The request is a google.protobuf.Struct with the string fields
+// "name" and "namespace" of the secret reference, the response is a
+// google.protobuf.Struct whose string fields are the credentials.
+const GRPCCredentialProviderMethod = "/snapshotter.credentials.v1.CredentialProvider/GetCredentials"
+
+// CredentialProvider retrieves the credentials identified by a secret
+// reference resolved from the secret parameters of a class.
+type CredentialProvider interface {
+	GetCredentials(ctx context.Context, ref *v1.SecretReference) (map[string]string, error)
+}
+
+// CredentialProviders are the providers available to a controller, by name.
+type CredentialProviders map[string]CredentialProvider
+
+// NewCredentialProviders returns the Kubernetes provider, the file provider
+// reading from dir if dir is not empty, and the gRPC provider calling conn
+// with timeout if conn is not nil.
+func NewCredentialProviders(client kubernetes.Interface, dir string, conn grpc.ClientConnInterface, timeout time.Duration) CredentialProviders {
+	providers := CredentialProviders{
+		CredentialProviderKubernetes: NewKubernetesCredentialProvider(client),
+	}
+	if dir != "" {
+		providers[CredentialProviderFile] = NewFileCredentialProvider(dir)
+	}
+	if conn != nil {
+		providers[CredentialProviderGRPC] = NewGRPCCredentialProvider(conn, timeout)
+	}
+	return providers
+}
+
+// GetCredentials retrieves the credentials of ref from the provider with the
+// given name. The Kubernetes provider is used if name is empty.
+func (p CredentialProviders) GetCredentials(ctx context.Context, name string, ref *v1.SecretReference) (map[string]string, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if name == "" {
+		name = CredentialProviderKubernetes
+	}
+	provider, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("credential provider %q is not configured", name)
+	}
+	return provider.GetCredentials(ctx, ref)
+}
+
+// IsCredentialsNotFound returns true if err reports that the credentials of
+// a secret reference do not exist in their provider.
+func IsCredentialsNotFound(err error) bool {
+	return apierrs.IsNotFound(err) || errors.Is(err, fs.ErrNotExist) || status.Code(err) == codes.NotFound
+}
+
+// GetSecretProvider returns the credential provider selected by the
+// PrefixedSecretProviderKey parameter of a class, or an empty string if the
+// class does not select one.
+func GetSecretProvider(classParams map[string]string) (string, error) {
+	provider := classParams[PrefixedSecretProviderKey]
+	switch provider {
+	case "", CredentialProviderKubernetes, CredentialProviderFile, CredentialProviderGRPC:
+		return provider, nil
+	default:
+		return "", fmt.Errorf("unknown credential provider %q in parameter %s", provider, PrefixedSecretProviderKey)
+	}
+}
+
+// SetSecretProviderAnnotation records the credential provider selected by
+// classParams in the annotation key of meta, so that the deletion secret is
+// read from the same provider that was used at creation time. Nothing is
+// recorded when Kubernetes Secrets are used.
+func SetSecretProviderAnnotation(meta *metav1.ObjectMeta, key string, classParams map[string]string) error {
+	provider, err := GetSecretProvider(classParams)
+	if err != nil {
+		return err
+	}
+	if provider != "" && provider != CredentialProviderKubernetes {
+		metav1.SetMetaDataAnnotation(meta, key, provider)
+	}
+	return nil
+}
+
+type kubernetesCredentialProvider struct {
+	client kubernetes.Interface
+}
+
+// NewKubernetesCredentialProvider returns a CredentialProvider that reads
+// the credentials from Kubernetes Secrets.
+func NewKubernetesCredentialProvider(client kubernetes.Interface) CredentialProvider {
+	return &kubernetesCredentialProvider{client: client}
+}
+
+func (p *kubernetesCredentialProvider) GetCredentials(ctx context.Context, ref *v1.SecretReference) (map[string]string, error) {
+	return GetCredentials(p.client, ref)
+}
+
+type fileCredentialProvider struct {
+	dir string
+}
+
+// NewFileCredentialProvider returns a CredentialProvider that reads the
+// credentials of a reference from the directory <dir>/<namespace>/<name>.
+// Every regular file in that directory is a credential, named like the file.
+// Hidden files are skipped, so the directory can be a projected volume.
+func NewFileCredentialProvider(dir string) CredentialProvider {
+	return &fileCredentialProvider{dir: dir}
+}
+
+func (p *fileCredentialProvider) GetCredentials(ctx context.Context, ref *v1.SecretReference) (map[string]string, error) {
+	// The reference is resolved from templates and must not escape dir.
+	for _, s := range []string{ref.Namespace, ref.Name} {
+		if errs := validation.IsDNS1123Subdomain(s); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid secret reference %s/%s: %v", ref.Namespace, ref.Name, errs)
+		}
+	}
+	dir := filepath.Join(p.dir, ref.Namespace, ref.Name)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials %s in namespace %s: %w", ref.Name, ref.Namespace, err)
+	}
+	credentials := map[string]string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		// os.Stat follows the symlinks of projected volumes.
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading credentials %s in namespace %s: %v", ref.Name, ref.Namespace, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading credentials %s in namespace %s: %v", ref.Name, ref.Namespace, err)
+		}
+		credentials[entry.Name()] = string(value)
+	}
+	return credentials, nil
+}
+
+type grpcCredentialProvider struct {
+	conn    grpc.ClientConnInterface
+	timeout time.Duration
+}
+
+// NewGRPCCredentialProvider returns a CredentialProvider that calls
+// GRPCCredentialProviderMethod on conn. Every call is limited by timeout.
+func NewGRPCCredentialProvider(conn grpc.ClientConnInterface, timeout time.Duration) CredentialProvider {
+	return &grpcCredentialProvider{conn: conn, timeout: timeout}
+}
+
+// DialCredentialProvider connects to the gRPC credential provider listening
+// on the Unix domain socket at path. Unlike the connection to the CSI driver,
+// the messages are never logged, because they contain the credentials.
+func DialCredentialProvider(path string) (*grpc.ClientConn, error) {
+	return grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func (p *grpcCredentialProvider) GetCredentials(ctx context.Context, ref *v1.SecretReference) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := structpb.NewStruct(map[string]interface{}{
+		"name":      ref.Name,
+		"namespace": ref.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rsp := &structpb.Struct{}
+	if err := p.conn.Invoke(ctx, GRPCCredentialProviderMethod, req, rsp); err != nil {
+		return nil, fmt.Errorf("error getting credentials %s in namespace %s: %w", ref.Name, ref.Namespace, err)
+	}
+	credentials := map[string]string{}
+	for key, value := range rsp.GetFields() {
+		s, ok := value.GetKind().(*structpb.Value_StringValue)
+		if !ok {
+			return nil, fmt.Errorf("error getting credentials %s in namespace %s: value of %q is not a string", ref.Name, ref.Namespace, key)
+		}
+		credentials[key] = s.StringValue
+	}
+	return credentials, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFileCredentialProvider(t *testing.T) {
+	dir := t.TempDir()
+	// Lay out the secret like a projected volume does.
+	secretDir := filepath.Join(dir, "ns", "secret")
+	dataDir := filepath.Join(secretDir, "..2026_01_01")
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "password"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("..2026_01_01", filepath.Join(secretDir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "password"), filepath.Join(secretDir, "password")); err != nil {
+		t.Fatal(err)
+	}
+	provider := NewFileCredentialProvider(dir)
+
+	testcases := []struct {
+		name      string
+		ref       *v1.SecretReference
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name:     "projected secret",
+			ref:      &v1.SecretReference{Name: "secret", Namespace: "ns"},
+			expected: map[string]string{"password": "secret"},
+		},
+		{
+			name:      "missing secret",
+			ref:       &v1.SecretReference{Name: "missing", Namespace: "ns"},
+			expectErr: true,
+		},
+		{
+			name:      "reference outside of the directory",
+			ref:       &v1.SecretReference{Name: "..", Namespace: "ns"},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		credentials, err := provider.GetCredentials(context.Background(), tc.ref)
+		if (err != nil) != tc.expectErr {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.expectErr, err)
+			continue
+		}
+		if !reflect.DeepEqual(credentials, tc.expected) {
+			t.Errorf("%s: expected credentials %v, got %v", tc.name, tc.expected, credentials)
+		}
+	}
+}
+
+func TestGRPCCredentialProvider(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "provider.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "snapshotter.credentials.v1.CredentialProvider",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "GetCredentials",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &structpb.Struct{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				fields := req.GetFields()
+				if fields["namespace"].GetStringValue() != "ns" || fields["name"].GetStringValue() != "secret" {
+					return nil, status.Error(codes.NotFound, "not found")
+				}
+				return structpb.NewStruct(map[string]interface{}{"password": "secret"})
+			},
+		}},
+	}, struct{}{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := DialCredentialProvider(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	provider := NewGRPCCredentialProvider(conn, 10*time.Second)
+
+	credentials, err := provider.GetCredentials(context.Background(), &v1.SecretReference{Name: "secret", Namespace: "ns"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(credentials, map[string]string{"password": "secret"}) {
+		t.Errorf("unexpected credentials %v", credentials)
+	}
+	if _, err := provider.GetCredentials(context.Background(), &v1.SecretReference{Name: "missing", Namespace: "ns"}); err == nil {
+		t.Errorf("expected an error for a missing secret")
+	}
+}
+
+func TestCredentialProviders(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"password": []byte("kubernetes")},
+	})
+	providers := NewCredentialProviders(client, "", nil, 0)
+	ref := &v1.SecretReference{Name: "secret", Namespace: "ns"}
+
+	for _, name := range []string{"", CredentialProviderKubernetes} {
+		credentials, err := providers.GetCredentials(context.Background(), name, ref)
+		if err != nil {
+			t.Errorf("provider %q: unexpected error: %v", name, err)
+		} else if credentials["password"] != "kubernetes" {
+			t.Errorf("provider %q: unexpected credentials %v", name, credentials)
+		}
+	}
+	if _, err := providers.GetCredentials(context.Background(), CredentialProviderFile, ref); err == nil {
+		t.Errorf("expected an error for a provider that is not configured")
+	}
+	if credentials, err := providers.GetCredentials(context.Background(), CredentialProviderFile, nil); err != nil || credentials != nil {
+		t.Errorf("expected no credentials without a reference, got %v, %v", credentials, err)
+	}
+
+	if _, err := GetSecretProvider(map[string]string{PrefixedSecretProviderKey: "vault"}); err == nil {
+		t.Errorf("expected an error for an unknown provider")
+	}
+	meta := &metav1.ObjectMeta{}
+	if err := SetSecretProviderAnnotation(meta, AnnDeletionSecretRefProvider, map[string]string{PrefixedSecretProviderKey: CredentialProviderKubernetes}); err != nil || len(meta.Annotations) != 0 {
+		t.Errorf("expected no annotation for the kubernetes provider, got %v, %v", meta.Annotations, err)
+	}
+	if err := SetSecretProviderAnnotation(meta, AnnDeletionSecretRefProvider, map[string]string{PrefixedSecretProviderKey: CredentialProviderGRPC}); err != nil || meta.Annotations[AnnDeletionSecretRefProvider] != CredentialProviderGRPC {
+		t.Errorf("expected the grpc provider annotation, got %v, %v", meta.Annotations, err)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// ReportedEvents remembers the Warning events emitted for conditions that
+// block an object over many syncs, e.g. a deletion refused because of a legal
+// hold, so that each condition is reported once instead of on every sync.
+type ReportedEvents struct {
+	mutex sync.Mutex
+	// reported are the messages reported, by object key and reason.
+	reported map[string]map[string]string
+}
+
+// NewReportedEvents returns a ReportedEvents without reported events.
+func NewReportedEvents() *ReportedEvents {
+	return &ReportedEvents{reported: map[string]map[string]string{}}
+}
+
+// Warn emits a Warning event with reason and message on obj, whose key is
+// key, unless the same message was the last one emitted for key and reason.
+// Returns true if the event was emitted.
+func (r *ReportedEvents) Warn(recorder record.EventRecorder, obj runtime.Object, key, reason, message string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	reasons, ok := r.reported[key]
+	if !ok {
+		reasons = map[string]string{}
+		r.reported[key] = reasons
+	}
+	if last, ok := reasons[reason]; ok && last == message {
+		return false
+	}
+	reasons[reason] = message
+	recorder.Event(obj, v1.EventTypeWarning, reason, message)
+	return true
+}
+
+// Forget forgets the event emitted for key and reason, so that the condition
+// is reported again if it comes back.
+func (r *ReportedEvents) Forget(key, reason string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.reported[key], reason)
+	if len(r.reported[key]) == 0 {
+		delete(r.reported, key)
+	}
+}
+
+// ForgetObject forgets all events emitted for key, e.g. when the object is
+// deleted.
+func (r *ReportedEvents) ForgetObject(key string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.reported, key)
+}
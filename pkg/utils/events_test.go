@@ -0,0 +1,59 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReportedEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	obj := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "obj"}}
+	reported := NewReportedEvents()
+
+	steps := []struct {
+		name     string
+		do       func() bool
+		expected bool
+	}{
+		{"first event is emitted", func() bool { return reported.Warn(recorder, obj, "obj", "Refused", "held") }, true},
+		{"same event is not emitted again", func() bool { return reported.Warn(recorder, obj, "obj", "Refused", "held") }, false},
+		{"other reason is emitted", func() bool { return reported.Warn(recorder, obj, "obj", "Protected", "protected") }, true},
+		{"changed message is emitted", func() bool { return reported.Warn(recorder, obj, "obj", "Refused", "held again") }, true},
+		{"other object is emitted", func() bool { return reported.Warn(recorder, obj, "other", "Refused", "held again") }, true},
+		{"forgotten reason is emitted", func() bool {
+			reported.Forget("obj", "Refused")
+			return reported.Warn(recorder, obj, "obj", "Refused", "held again")
+		}, true},
+		{"forgotten object is emitted", func() bool {
+			reported.ForgetObject("obj")
+			return reported.Warn(recorder, obj, "obj", "Protected", "protected")
+		}, true},
+	}
+	for _, step := range steps {
+		if emitted := step.do(); emitted != step.expected {
+			t.Errorf("%s: expected %v, got %v", step.name, step.expected, emitted)
+		}
+	}
+	if len(recorder.Events) != 6 {
+		t.Errorf("expected 6 events, got %d", len(recorder.Events))
+	}
+}
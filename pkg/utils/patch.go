@@ -78,6 +78,26 @@ func PatchVolumeGroupSnapshot(
 	return newGroupSnapshot, nil
 }
 
+// PatchClusterVolumeGroupSnapshot patches a cluster volume group snapshot object
+func PatchClusterVolumeGroupSnapshot(
+	existingGroupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot,
+	patch []PatchOp,
+	client clientset.Interface,
+	subresources ...string,
+) (*groupsnapshotv1.ClusterVolumeGroupSnapshot, error) {
+	data, err := json.Marshal(patch)
+	if nil != err {
+		return existingGroupSnapshot, err
+	}
+
+	newGroupSnapshot, err := client.GroupsnapshotV1().ClusterVolumeGroupSnapshots().Patch(context.TODO(), existingGroupSnapshot.Name, types.JSONPatchType, data, metav1.PatchOptions{}, subresources...)
+	if err != nil {
+		return existingGroupSnapshot, err
+	}
+
+	return newGroupSnapshot, nil
+}
+
 // PatchVolumeGroupSnapshotContent patches a volume group snapshot content object
 func PatchVolumeGroupSnapshotContent(
 	existingGroupSnapshotContent *groupsnapshotv1.VolumeGroupSnapshotContent,
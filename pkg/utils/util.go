@@ -18,6 +18,7 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"slices"
@@ -29,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
@@ -68,10 +70,14 @@ const (
 	PrefixedGroupSnapshotterGetSecretNameKey      = csiParameterPrefix + "group-snapshotter-get-secret-name"      // Prefixed name key for GetVolumeGroupSnapshot secret
 	PrefixedGroupSnapshotterGetSecretNamespaceKey = csiParameterPrefix + "group-snapshotter-get-secret-namespace" // Prefixed namespace key for GetVolumeGroupSnapshot secret
 
+	PrefixedSecretProviderKey = csiParameterPrefix + "secret-provider" // Prefixed key for the credential provider of all secrets of a class
+
 	PrefixedVolumeSnapshotNameKey        = csiParameterPrefix + "volumesnapshot/name"        // Prefixed VolumeSnapshot name key
 	PrefixedVolumeSnapshotNamespaceKey   = csiParameterPrefix + "volumesnapshot/namespace"   // Prefixed VolumeSnapshot namespace key
 	PrefixedVolumeSnapshotContentNameKey = csiParameterPrefix + "volumesnapshotcontent/name" // Prefixed VolumeSnapshotContent name key
 
+	PrefixedSourceSnapshotHandleKey = csiParameterPrefix + "source-snapshot-handle" // Prefixed key for the snapshot to copy in CreateSnapshot
+
 	PrefixedVolumeGroupSnapshotNameKey        = csiParameterPrefix + "volumegroupsnapshot/name"        // Prefixed VolumeGroupSnapshot name key
 	PrefixedVolumeGroupSnapshotNamespaceKey   = csiParameterPrefix + "volumegroupsnapshot/namespace"   // Prefixed VolumeGroupSnapshot namespace key
 	PrefixedVolumeGroupSnapshotContentNameKey = csiParameterPrefix + "volumegroupsnapshotcontent/name" // Prefixed VolumeGroupSnapshotContent name key
@@ -94,6 +100,14 @@ const (
 	IsDefaultSnapshotClassAnnotation      = "snapshot.storage.kubernetes.io/is-default-class"
 	IsDefaultGroupSnapshotClassAnnotation = "groupsnapshot.storage.kubernetes.io/is-default-class"
 
+	// AllowedNamespacesSnapshotClassAnnotation is a label selector of the
+	// namespaces whose VolumeSnapshots may use a VolumeSnapshotClass.
+	AllowedNamespacesSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/allowed-namespaces"
+	// DefaultForNamespacesSnapshotClassAnnotation is a label selector of the
+	// namespaces a VolumeSnapshotClass is the default class of. It takes
+	// precedence over IsDefaultSnapshotClassAnnotation.
+	DefaultForNamespacesSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/default-for-namespaces"
+
 	// AnnVolumeSnapshotBeingDeleted annotation applies to VolumeSnapshotContents.
 	// It indicates that the common snapshot controller has verified that volume
 	// snapshot has a deletion timestamp and is being deleted.
@@ -136,6 +150,12 @@ const (
 	// backing the group snapshot content.
 	AnnVolumeGroupSnapshotBeingDeleted = "groupsnapshot.storage.kubernetes.io/volumegroupsnapshot-being-deleted"
 
+	// AnnVolumeGroupSnapshotEmulated annotation applies to VolumeGroupSnapshotContents.
+	// It is set by the sidecar controller before it emulates the group snapshot
+	// with individual snapshots, because the CSI driver does not support
+	// CreateVolumeGroupSnapshot. Such a group snapshot is not crash consistent.
+	AnnVolumeGroupSnapshotEmulated = "groupsnapshot.storage.kubernetes.io/volumegroupsnapshot-emulated"
+
 	// Annotation for secret name and namespace will be added to the content
 	// and used at snapshot content deletion time.
 	AnnDeletionSecretRefName      = "snapshot.storage.kubernetes.io/deletion-secret-name"
@@ -146,6 +166,90 @@ const (
 	AnnDeletionGroupSecretRefName      = "groupsnapshot.storage.kubernetes.io/deletion-secret-name"
 	AnnDeletionGroupSecretRefNamespace = "groupsnapshot.storage.kubernetes.io/deletion-secret-namespace"
 
+	// Annotation for the credential provider of the deletion secret, added
+	// to the content or group snapshot content if the class selects a
+	// provider other than Kubernetes Secrets.
+	AnnDeletionSecretRefProvider      = "snapshot.storage.kubernetes.io/deletion-secret-provider"
+	AnnDeletionGroupSecretRefProvider = "groupsnapshot.storage.kubernetes.io/deletion-secret-provider"
+
+	// AnnDeletionProtection annotation applies to VolumeSnapshots and
+	// VolumeSnapshotContents. If it is set to "true", a deleted object is
+	// kept, and the snapshot on the storage system is not deleted, until the
+	// annotation is removed.
+	AnnDeletionProtection = "snapshot.storage.kubernetes.io/deletion-protection"
+
+	// AnnDeletionGracePeriod annotation applies to VolumeSnapshots,
+	// VolumeSnapshotContents and VolumeSnapshotClasses. Its value is a
+	// duration, e.g. "24h", by which the deletion of the snapshot on the
+	// storage system is deferred after the content was deleted. The common
+	// controller copies it from the VolumeSnapshot, or else from its class,
+	// to the content when the VolumeSnapshot is deleted.
+	AnnDeletionGracePeriod = "snapshot.storage.kubernetes.io/deletion-grace-period"
+
+	// AnnRecycleBinPeriod annotation applies to VolumeSnapshots,
+	// VolumeSnapshotContents and VolumeSnapshotClasses. Its value is a
+	// duration, e.g. "72h". If it is set, a content with DeletionPolicy
+	// Delete is not deleted together with its VolumeSnapshot, but moved to
+	// the recycle bin for that duration.
+	AnnRecycleBinPeriod = "snapshot.storage.kubernetes.io/recycle-bin-period"
+
+	// AnnPurgeAfter annotation is set by the common controller on
+	// VolumeSnapshotContents in the recycle bin, which are not bound to a
+	// VolumeSnapshot. Its value is the RFC 3339 time after which the
+	// sidecar deletes the content and the snapshot on the storage system.
+	AnnPurgeAfter = "snapshot.storage.kubernetes.io/purge-after"
+
+	// AnnLegalHold annotation applies to VolumeSnapshotContents. Its value
+	// describes the hold, e.g. a case number. While it is set, neither the
+	// content nor the snapshot on the storage system is deleted, whatever the
+	// deletion policy, deletion protection or recycle bin period, and the
+	// common controller reverts changes of the deletion policy. Changing the
+	// annotation and the deletion policy of held contents can be restricted
+	// with the ValidatingAdmissionPolicy in
+	// deploy/kubernetes/snapshot-controller/legal-hold-policy.yaml.
+	AnnLegalHold = "snapshot.storage.kubernetes.io/legal-hold"
+
+	// AnnDeletionPolicy annotation applies to VolumeSnapshots. It requests
+	// the DeletionPolicy, "Delete" or "Retain", of the bound
+	// VolumeSnapshotContent, which the common controller changes
+	// accordingly. This lets namespace users change the policy without
+	// permissions on the cluster-scoped contents.
+	AnnDeletionPolicy = "snapshot.storage.kubernetes.io/deletion-policy"
+
+	// AnnDeletionPolicyRequestHandled annotation is set by the common
+	// controller on VolumeSnapshots. Its value is the value of the
+	// AnnDeletionPolicy annotation that was handled, whether the policy was
+	// changed or not. A request is only handled once, so that the policy can
+	// still be changed directly on the content afterwards. It is removed with
+	// AnnDeletionPolicy.
+	AnnDeletionPolicyRequestHandled = "snapshot.storage.kubernetes.io/deletion-policy-request-handled"
+
+	// AnnAllowDeletionPolicyRequest annotation applies to
+	// VolumeSnapshotClasses and VolumeSnapshotContents. If it is "true",
+	// AnnDeletionPolicy may change the DeletionPolicy of pre-provisioned
+	// contents, or of the contents of the class, from "Retain" to "Delete".
+	// Without it, only dynamically created contents can be changed to
+	// "Delete" this way.
+	AnnAllowDeletionPolicyRequest = "snapshot.storage.kubernetes.io/allow-deletion-policy-request"
+
+	// AnnDeletionPolicyHistory annotation is set by the common controller on
+	// VolumeSnapshotContents whose DeletionPolicy was changed. Its value is
+	// a JSON list of DeletionPolicyChange, the oldest first.
+	AnnDeletionPolicyHistory = "snapshot.storage.kubernetes.io/deletion-policy-history"
+
+	// AnnAccessibleTopology annotation applies to VolumeSnapshotContents. Its
+	// value is a JSON list of TopologySelectorTerms, like the
+	// allowedTopologies of a StorageClass, that the CSI snapshotter sidecar
+	// copies to the accessibleTopology of the content status. The common
+	// controller sets it from the node affinity of the source volume of
+	// dynamically created snapshots; it can be set from metadata of the CSI
+	// driver for pre-existing snapshots.
+	AnnAccessibleTopology = "snapshot.storage.kubernetes.io/accessible-topology"
+
+	// MaxDeletionPolicyHistory is the number of changes kept in the
+	// AnnDeletionPolicyHistory annotation.
+	MaxDeletionPolicyHistory = 10
+
 	// VolumeGroupSnapshotHandleAnnotation is applied to VolumeSnapshotContents that are member
 	// of a VolumeGroupSnapshotContent, and indicates the handle of the latter.
 	//
@@ -156,6 +260,12 @@ const (
 	// VolumeSnapshotContentManagedByLabel is applied by the snapshot controller to the VolumeSnapshotContent object in case distributed snapshotting is enabled.
 	// The value contains the name of the node that handles the snapshot for the volume local to that node.
 	VolumeSnapshotContentManagedByLabel = "snapshot.storage.kubernetes.io/managed-by"
+
+	// NodeHeartbeatDriverLabel is applied together with
+	// VolumeSnapshotContentManagedByLabel to the Leases renewed by CSI
+	// snapshotter sidecars deployed on nodes. The value contains the
+	// sanitized name of the CSI driver.
+	NodeHeartbeatDriverLabel = "snapshot.storage.kubernetes.io/driver"
 )
 
 var SnapshotterSecretParams = secretParamsMap{
@@ -222,6 +332,10 @@ func SnapshotRefKey(vsref *v1.ObjectReference) string {
 	return fmt.Sprintf("%s/%s", vsref.Namespace, vsref.Name)
 }
 
+func SnapshotCopyKey(vscopy *crdv1.VolumeSnapshotCopy) string {
+	return fmt.Sprintf("%s/%s", vscopy.Namespace, vscopy.Name)
+}
+
 // storeObjectUpdate updates given cache with a new object version from Informer
 // callback (i.e. with events from etcd) or with an object modified by the
 // controller itself. Returns "true", if the cache was updated, false if the
@@ -290,6 +404,36 @@ func IsVolumeSnapshotClassDefaultAnnotation(obj metav1.ObjectMeta) bool {
 	return obj.Annotations[IsDefaultSnapshotClassAnnotation] == "true"
 }
 
+// IsVolumeSnapshotClassAllowedInNamespace returns true if the VolumeSnapshotClass
+// with the given metadata may be used in namespace. Classes without the
+// AllowedNamespacesSnapshotClassAnnotation may be used in all namespaces.
+func IsVolumeSnapshotClassAllowedInNamespace(obj metav1.ObjectMeta, namespace *v1.Namespace) (bool, error) {
+	selector, ok := obj.Annotations[AllowedNamespacesSnapshotClassAnnotation]
+	if !ok {
+		return true, nil
+	}
+	return namespaceMatchesSelector(AllowedNamespacesSnapshotClassAnnotation, selector, namespace)
+}
+
+// IsVolumeSnapshotClassDefaultForNamespace returns true if the VolumeSnapshotClass
+// with the given metadata is the default class of namespace according to its
+// DefaultForNamespacesSnapshotClassAnnotation.
+func IsVolumeSnapshotClassDefaultForNamespace(obj metav1.ObjectMeta, namespace *v1.Namespace) (bool, error) {
+	selector, ok := obj.Annotations[DefaultForNamespacesSnapshotClassAnnotation]
+	if !ok {
+		return false, nil
+	}
+	return namespaceMatchesSelector(DefaultForNamespacesSnapshotClassAnnotation, selector, namespace)
+}
+
+func namespaceMatchesSelector(annotation, selector string, namespace *v1.Namespace) (bool, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector %q in annotation %s: %v", selector, annotation, err)
+	}
+	return parsed.Matches(labels.Set(namespace.Labels)), nil
+}
+
 // IsVolumeGroupSnapshotClassDefaultAnnotation returns a true boolean if
 // a VolumeGroupSnapshotClass is marked as the default one
 func IsVolumeGroupSnapshotClassDefaultAnnotation(obj metav1.ObjectMeta) bool {
@@ -335,16 +479,25 @@ func verifyAndGetSecretNameAndNamespaceTemplate(secret secretParamsMap, snapshot
 // - ${volumesnapshotcontent.name}
 // - ${volumesnapshot.namespace}
 // - ${volumesnapshot.name}
+// - ${pv.name}
+// - ${pvc.namespace}
+// - ${pvc.name}
+// - ${pvc.annotations['<ANNOTATION_KEY>']} (e.g. ${pvc.annotations['example.com/snapshot-secret']})
 //
 // supported tokens for namespace resolution:
 // - ${volumesnapshotcontent.name}
 // - ${volumesnapshot.namespace}
+// - ${pv.name}
+// - ${pvc.namespace}
+//
+// The pv and pvc tokens refer to the source volume of the snapshot and can
+// only be resolved when pv and pvc are given.
 //
 // an error is returned in the following situations:
 // - the nameTemplate or namespaceTemplate contains a token that cannot be resolved
 // - the resolved name is not a valid secret name
 // - the resolved namespace is not a valid namespace name
-func GetSecretReference(secretParams secretParamsMap, snapshotClassParams map[string]string, snapContentName string, snapshot *crdv1.VolumeSnapshot) (*v1.SecretReference, error) {
+func GetSecretReference(secretParams secretParamsMap, snapshotClassParams map[string]string, snapContentName string, snapshot *crdv1.VolumeSnapshot, pvc *v1.PersistentVolumeClaim, pv *v1.PersistentVolume) (*v1.SecretReference, error) {
 	nameTemplate, namespaceTemplate, err := verifyAndGetSecretNameAndNamespaceTemplate(secretParams, snapshotClassParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get name and namespace template from params: %v", err)
@@ -364,6 +517,14 @@ func GetSecretReference(secretParams secretParamsMap, snapshotClassParams map[st
 	if snapshot != nil {
 		namespaceParams["volumesnapshot.namespace"] = snapshot.Namespace
 	}
+	// The PV name and the PVC namespace are not under the control of the
+	// PVC user either.
+	if pv != nil {
+		namespaceParams["pv.name"] = pv.Name
+	}
+	if pvc != nil {
+		namespaceParams["pvc.namespace"] = pvc.Namespace
+	}
 
 	resolvedNamespace, err := resolveTemplate(namespaceTemplate, namespaceParams)
 	if err != nil {
@@ -386,6 +547,17 @@ func GetSecretReference(secretParams secretParamsMap, snapshotClassParams map[st
 		nameParams["volumesnapshot.name"] = snapshot.Name
 		nameParams["volumesnapshot.namespace"] = snapshot.Namespace
 	}
+	// The PVC name and annotations are under the PVC user's control.
+	if pv != nil {
+		nameParams["pv.name"] = pv.Name
+	}
+	if pvc != nil {
+		nameParams["pvc.name"] = pvc.Name
+		nameParams["pvc.namespace"] = pvc.Namespace
+		for k, v := range pvc.Annotations {
+			nameParams["pvc.annotations['"+k+"']"] = v
+		}
+	}
 	resolvedName, err := resolveTemplate(nameTemplate, nameParams)
 	if err != nil {
 		return nil, fmt.Errorf("error resolving value %q: %v", nameTemplate, err)
@@ -481,7 +653,7 @@ func GetCredentials(k8s kubernetes.Interface, ref *v1.SecretReference) (map[stri
 
 	secret, err := k8s.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("error getting secret %s in namespace %s: %v", ref.Name, ref.Namespace, err)
+		return nil, fmt.Errorf("error getting secret %s in namespace %s: %w", ref.Name, ref.Namespace, err)
 	}
 
 	credentials := map[string]string{}
@@ -512,6 +684,172 @@ func IsSnapshotDeletionCandidate(snapshot *crdv1.VolumeSnapshot) bool {
 	return snapshot.ObjectMeta.DeletionTimestamp != nil && (slices.Contains(snapshot.ObjectMeta.Finalizers, VolumeSnapshotAsSourceFinalizer) || slices.Contains(snapshot.ObjectMeta.Finalizers, VolumeSnapshotBoundFinalizer))
 }
 
+// IsDeletionProtected returns true if the AnnDeletionProtection annotation
+// of an object is set to "true".
+func IsDeletionProtected(meta metav1.ObjectMeta) bool {
+	return meta.Annotations[AnnDeletionProtection] == "true"
+}
+
+// IsUnderLegalHold returns true if an object has the AnnLegalHold
+// annotation.
+func IsUnderLegalHold(meta metav1.ObjectMeta) bool {
+	return metav1.HasAnnotation(meta, AnnLegalHold)
+}
+
+// GetDeletionGracePeriod returns the duration of the AnnDeletionGracePeriod
+// annotation of an object, or zero if it is not set.
+func GetDeletionGracePeriod(meta metav1.ObjectMeta) (time.Duration, error) {
+	value, ok := meta.Annotations[AnnDeletionGracePeriod]
+	if !ok {
+		return 0, nil
+	}
+	return ParseDurationAnnotation(AnnDeletionGracePeriod, value)
+}
+
+// ParseDurationAnnotation parses the value of a duration annotation like
+// AnnDeletionGracePeriod and AnnRecycleBinPeriod.
+func ParseDurationAnnotation(key, value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid annotation %s: %v", key, err)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("invalid annotation %s: negative duration %s", key, value)
+	}
+	return duration, nil
+}
+
+// IsContentInRecycleBin returns true if the content was unbound from its
+// deleted VolumeSnapshot and waits in the recycle bin to be purged.
+func IsContentInRecycleBin(content *crdv1.VolumeSnapshotContent) bool {
+	return metav1.HasAnnotation(content.ObjectMeta, AnnPurgeAfter) && content.Spec.VolumeSnapshotRef.UID == ""
+}
+
+// GetPurgeAfter returns the time of the AnnPurgeAfter annotation of a
+// content in the recycle bin.
+func GetPurgeAfter(content *crdv1.VolumeSnapshotContent) (time.Time, error) {
+	purgeAfter, err := time.Parse(time.RFC3339, content.Annotations[AnnPurgeAfter])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid annotation %s: %v", AnnPurgeAfter, err)
+	}
+	return purgeAfter, nil
+}
+
+// NodeHeartbeatLeaseName returns the name of the Lease renewed by the CSI
+// snapshotter sidecar of driver deployed on node.
+func NodeHeartbeatLeaseName(driver, node string) string {
+	driver = strings.ToLower(strings.ReplaceAll(SanitizeLabelValue(driver), "_", "-"))
+	return fmt.Sprintf("csi-snapshotter-%s-%s", driver, node)
+}
+
+// NodeHeartbeatLabels returns the labels of the Lease renewed by the CSI
+// snapshotter sidecar of driver deployed on node.
+func NodeHeartbeatLabels(driver, node string) labels.Set {
+	return labels.Set{
+		VolumeSnapshotContentManagedByLabel: node,
+		NodeHeartbeatDriverLabel:            SanitizeLabelValue(driver),
+	}
+}
+
+// SanitizeLabelValue replaces the characters of value that are not allowed
+// in label values with "-" and truncates it to 63 characters.
+func SanitizeLabelValue(value string) string {
+	sanitized := []byte(value)
+	for i, c := range sanitized {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			sanitized[i] = '-'
+		}
+	}
+	if len(sanitized) > validation.LabelValueMaxLength {
+		sanitized = sanitized[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(string(sanitized), "-_.")
+}
+
+// DeletionPolicyChange is an entry of the AnnDeletionPolicyHistory
+// annotation.
+type DeletionPolicyChange struct {
+	Time metav1.Time          `json:"time"`
+	From crdv1.DeletionPolicy `json:"from"`
+	To   crdv1.DeletionPolicy `json:"to"`
+	// RequestedBy is the key of the VolumeSnapshot whose AnnDeletionPolicy
+	// annotation requested the change. It is empty if the content was
+	// changed directly.
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// GetDeletionPolicyHistory returns the changes recorded in the
+// AnnDeletionPolicyHistory annotation of content.
+func GetDeletionPolicyHistory(content *crdv1.VolumeSnapshotContent) ([]DeletionPolicyChange, error) {
+	value, ok := content.Annotations[AnnDeletionPolicyHistory]
+	if !ok {
+		return nil, nil
+	}
+	var history []DeletionPolicyChange
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %v", AnnDeletionPolicyHistory, err)
+	}
+	return history, nil
+}
+
+// AppendDeletionPolicyHistory returns the value of the
+// AnnDeletionPolicyHistory annotation with change appended to history, which
+// is truncated to the last MaxDeletionPolicyHistory changes.
+func AppendDeletionPolicyHistory(history []DeletionPolicyChange, change DeletionPolicyChange) (string, error) {
+	history = append(history, change)
+	if len(history) > MaxDeletionPolicyHistory {
+		history = history[len(history)-MaxDeletionPolicyHistory:]
+	}
+	value, err := json.Marshal(history)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// GetAccessibleTopology returns the topology in the AnnAccessibleTopology
+// annotation of content, or nil if there is none.
+func GetAccessibleTopology(content *crdv1.VolumeSnapshotContent) ([]v1.TopologySelectorTerm, error) {
+	value, ok := content.Annotations[AnnAccessibleTopology]
+	if !ok {
+		return nil, nil
+	}
+	var topology []v1.TopologySelectorTerm
+	if err := json.Unmarshal([]byte(value), &topology); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %v", AnnAccessibleTopology, err)
+	}
+	return topology, nil
+}
+
+// NodeAffinityToTopology converts the required node affinity of a volume to
+// an accessible topology. Only the requirements with the In operator can be
+// expressed as a topology and the others are dropped, so the topology may be
+// broader than the node affinity. Returns nil if a term has no such
+// requirement, i.e. if the topology is not restricted.
+func NodeAffinityToTopology(affinity *v1.VolumeNodeAffinity) []v1.TopologySelectorTerm {
+	if affinity == nil || affinity.Required == nil {
+		return nil
+	}
+	var topology []v1.TopologySelectorTerm
+	for _, term := range affinity.Required.NodeSelectorTerms {
+		var requirements []v1.TopologySelectorLabelRequirement
+		for _, expression := range term.MatchExpressions {
+			if expression.Operator != v1.NodeSelectorOpIn {
+				continue
+			}
+			requirements = append(requirements, v1.TopologySelectorLabelRequirement{
+				Key:    expression.Key,
+				Values: append([]string(nil), expression.Values...),
+			})
+		}
+		if len(requirements) == 0 {
+			return nil
+		}
+		topology = append(topology, v1.TopologySelectorTerm{MatchLabelExpressions: requirements})
+	}
+	return topology
+}
+
 // IsGroupSnapshotDeletionCandidate checks if a volume group snapshot deletionTimestamp
 // is set and any finalizer is on the group snapshot.
 func IsGroupSnapshotDeletionCandidate(groupSnapshot *groupsnapshotv1.VolumeGroupSnapshot) bool {
@@ -558,6 +896,7 @@ func RemovePrefixedParameters(param map[string]string) (map[string]string, error
 			case PrefixedGroupSnapshotterGetSecretNamespaceKey:
 			case PrefixedGroupSnapshotterSecretNameKey:
 			case PrefixedGroupSnapshotterSecretNamespaceKey:
+			case PrefixedSecretProviderKey:
 			default:
 				return map[string]string{}, fmt.Errorf("found unknown parameter key \"%s\" with reserved namespace %s", k, csiParameterPrefix)
 			}
@@ -665,6 +1004,18 @@ func GetDynamicSnapshotContentNameForGroupSnapshot(groupSnapshot *groupsnapshotv
 	return "groupsnapcontent-" + string(groupSnapshot.UID)
 }
 
+// GetDynamicSnapshotContentNameForClusterGroupSnapshot returns a unique content name for the
+// passed in ClusterVolumeGroupSnapshot to dynamically provision a group snapshot.
+func GetDynamicSnapshotContentNameForClusterGroupSnapshot(groupSnapshot *groupsnapshotv1.ClusterVolumeGroupSnapshot) string {
+	return "groupsnapcontent-" + string(groupSnapshot.UID)
+}
+
+// GetSnapshotContentNameForSnapshotCopy returns a unique content name for the
+// passed in VolumeSnapshotCopy to dynamically copy a snapshot.
+func GetSnapshotContentNameForSnapshotCopy(snapshotCopy *crdv1.VolumeSnapshotCopy) string {
+	return "snapcontent-copy-" + string(snapshotCopy.UID)
+}
+
 // ShouldEnqueueContentChange indicated whether or not a change to a VolumeSnapshotContent object
 // is a change that should be enqueued for sync
 //
@@ -19,6 +19,7 @@ package utils
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	v1 "k8s.io/api/core/v1"
@@ -77,6 +78,8 @@ func TestGetSecretReference(t *testing.T) {
 		params          map[string]string
 		snapContentName string
 		snapshot        *crdv1.VolumeSnapshot
+		pvc             *v1.PersistentVolumeClaim
+		pv              *v1.PersistentVolume
 		expectRef       *v1.SecretReference
 		expectErr       bool
 	}{
@@ -120,11 +123,62 @@ func TestGetSecretReference(t *testing.T) {
 			expectRef: nil,
 			expectErr: true,
 		},
+		"template - pvc and pv": {
+			secretParams: SnapshotterSecretParams,
+			params: map[string]string{
+				PrefixedSnapshotterSecretNameKey:      "${pvc.annotations['example.com/tenant']}-${pvc.name}-${pv.name}",
+				PrefixedSnapshotterSecretNamespaceKey: "${pvc.namespace}",
+			},
+			snapContentName: "snapcontentname",
+			snapshot: &crdv1.VolumeSnapshot{
+				ObjectMeta: metav1.ObjectMeta{Name: "snapshotname", Namespace: "snapshotnamespace"},
+			},
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "claim",
+					Namespace:   "snapshotnamespace",
+					Annotations: map[string]string{"example.com/tenant": "tenant-a"},
+				},
+			},
+			pv:        &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv"}},
+			expectRef: &v1.SecretReference{Name: "tenant-a-claim-pv", Namespace: "snapshotnamespace"},
+		},
+		"template - pvc annotation in namespace": {
+			secretParams: SnapshotterSecretParams,
+			params: map[string]string{
+				PrefixedSnapshotterSecretNameKey:      "name",
+				PrefixedSnapshotterSecretNamespaceKey: "${pvc.annotations['example.com/tenant']}",
+			},
+			snapshot: &crdv1.VolumeSnapshot{},
+			pvc: &v1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"example.com/tenant": "tenant-a"}},
+			},
+			expectErr: true,
+		},
+		"template - missing pvc annotation": {
+			secretParams: SnapshotterSecretParams,
+			params: map[string]string{
+				PrefixedSnapshotterSecretNameKey:      "${pvc.annotations['example.com/tenant']}",
+				PrefixedSnapshotterSecretNamespaceKey: "ns",
+			},
+			snapshot:  &crdv1.VolumeSnapshot{},
+			pvc:       &v1.PersistentVolumeClaim{},
+			expectErr: true,
+		},
+		"template - pv without source volume": {
+			secretParams: SnapshotterSecretParams,
+			params: map[string]string{
+				PrefixedSnapshotterSecretNameKey:      "${pv.name}",
+				PrefixedSnapshotterSecretNamespaceKey: "ns",
+			},
+			snapshot:  &crdv1.VolumeSnapshot{},
+			expectErr: true,
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			ref, err := GetSecretReference(tc.secretParams, tc.params, tc.snapContentName, tc.snapshot)
+			ref, err := GetSecretReference(tc.secretParams, tc.params, tc.snapContentName, tc.snapshot, tc.pvc, tc.pv)
 			if err != nil {
 				if tc.expectErr {
 					return
@@ -167,6 +221,7 @@ func TestRemovePrefixedCSIParams(t *testing.T) {
 				PrefixedSnapshotterSecretNamespaceKey:     "csiBar",
 				PrefixedSnapshotterListSecretNameKey:      "csiBar",
 				PrefixedSnapshotterListSecretNamespaceKey: "csiBar",
+				PrefixedSecretProviderKey:                 "csiBar",
 			},
 			expectedParams: map[string]string{},
 		},
@@ -284,6 +339,62 @@ func TestIsVolumeGroupSnapshotClassDefaultAnnotation(t *testing.T) {
 	}
 }
 
+func TestIsVolumeSnapshotClassAllowedInNamespace(t *testing.T) {
+	namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: map[string]string{"team": "a"}}}
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		allowed     bool
+		isDefault   bool
+		expectErr   bool
+	}{
+		{
+			name:    "no policy annotations",
+			allowed: true,
+		},
+		{
+			name: "namespace matches",
+			annotations: map[string]string{
+				AllowedNamespacesSnapshotClassAnnotation:    "team in (a, b)",
+				DefaultForNamespacesSnapshotClassAnnotation: "team=a",
+			},
+			allowed:   true,
+			isDefault: true,
+		},
+		{
+			name: "namespace does not match",
+			annotations: map[string]string{
+				AllowedNamespacesSnapshotClassAnnotation:    "kubernetes.io/metadata.name=other",
+				DefaultForNamespacesSnapshotClassAnnotation: "team!=a",
+			},
+		},
+		{
+			name: "invalid selector",
+			annotations: map[string]string{
+				AllowedNamespacesSnapshotClassAnnotation: "team in a",
+			},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		objectMeta := metav1.ObjectMeta{Annotations: tc.annotations}
+		allowed, err := IsVolumeSnapshotClassAllowedInNamespace(objectMeta, namespace)
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.name)
+			}
+			continue
+		}
+		if err != nil || allowed != tc.allowed {
+			t.Errorf("%s: expected allowed %v, got %v: %v", tc.name, tc.allowed, allowed, err)
+		}
+		isDefault, err := IsVolumeSnapshotClassDefaultForNamespace(objectMeta, namespace)
+		if err != nil || isDefault != tc.isDefault {
+			t.Errorf("%s: expected default %v, got %v: %v", tc.name, tc.isDefault, isDefault, err)
+		}
+	}
+}
+
 func TestShouldEnqueueContentChange(t *testing.T) {
 	oldValue := "old"
 	newValue := "new"
@@ -541,3 +652,104 @@ func TestShouldEnqueueContentChange(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDeletionGracePeriod(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		expected    time.Duration
+		expectErr   bool
+	}{
+		{
+			name:     "no annotation",
+			expected: 0,
+		},
+		{
+			name:        "duration",
+			annotations: map[string]string{AnnDeletionGracePeriod: "24h"},
+			expected:    24 * time.Hour,
+		},
+		{
+			name:        "invalid duration",
+			annotations: map[string]string{AnnDeletionGracePeriod: "one day"},
+			expectErr:   true,
+		},
+		{
+			name:        "negative duration",
+			annotations: map[string]string{AnnDeletionGracePeriod: "-1h"},
+			expectErr:   true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gracePeriod, err := GetDeletionGracePeriod(metav1.ObjectMeta{Annotations: tc.annotations})
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if gracePeriod != tc.expected {
+				t.Errorf("expected grace period %v, got %v", tc.expected, gracePeriod)
+			}
+		})
+	}
+}
+
+func TestAppendDeletionPolicyHistory(t *testing.T) {
+	var history []DeletionPolicyChange
+	for i := 0; i < MaxDeletionPolicyHistory+2; i++ {
+		value, err := AppendDeletionPolicyHistory(history, DeletionPolicyChange{From: crdv1.VolumeSnapshotContentDelete, To: crdv1.VolumeSnapshotContentRetain, RequestedBy: string(rune('a' + i))})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content := &crdv1.VolumeSnapshotContent{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnDeletionPolicyHistory: value}}}
+		history, err = GetDeletionPolicyHistory(content)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(history) != MaxDeletionPolicyHistory || history[0].RequestedBy != "c" {
+		t.Errorf("expected the last %d changes, got %+v", MaxDeletionPolicyHistory, history)
+	}
+}
+
+func TestNodeAffinityToTopology(t *testing.T) {
+	zoneIn := v1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: v1.NodeSelectorOpIn, Values: []string{"zone-a", "zone-b"}}
+	hostNotIn := v1.NodeSelectorRequirement{Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpNotIn, Values: []string{"node1"}}
+	affinity := func(terms ...v1.NodeSelectorTerm) *v1.VolumeNodeAffinity {
+		return &v1.VolumeNodeAffinity{Required: &v1.NodeSelector{NodeSelectorTerms: terms}}
+	}
+	testcases := []struct {
+		name     string
+		affinity *v1.VolumeNodeAffinity
+		expected []v1.TopologySelectorTerm
+	}{
+		{
+			name: "no node affinity",
+		},
+		{
+			name:     "In requirement",
+			affinity: affinity(v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{zoneIn, hostNotIn}}),
+			expected: []v1.TopologySelectorTerm{
+				{
+					MatchLabelExpressions: []v1.TopologySelectorLabelRequirement{
+						{Key: "topology.kubernetes.io/zone", Values: []string{"zone-a", "zone-b"}},
+					},
+				},
+			},
+		},
+		{
+			name: "term without In requirement",
+			affinity: affinity(
+				v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{zoneIn}},
+				v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{hostNotIn}},
+			),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			topology := NodeAffinityToTopology(tc.affinity)
+			if !reflect.DeepEqual(topology, tc.expected) {
+				t.Errorf("expected topology %+v, got %+v", tc.expected, topology)
+			}
+		})
+	}
+}
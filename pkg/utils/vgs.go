@@ -21,6 +21,7 @@ import (
 
 	groupsnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumegroupsnapshot/v1"
 	crdv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -32,6 +33,16 @@ const VolumeSnapshotParentGroupIndex = "ByVolumeGroupSnapshotMembership"
 // getVolumeGroupSnapshotParentObjectName returns the name of the parent group snapshot, if present.
 // The second return value is true when the parent object have been found, false otherwise.
 func getVolumeGroupSnapshotParentObjectName(snapshot *crdv1.VolumeSnapshot) string {
+	return getParentObjectName(snapshot, "VolumeGroupSnapshot")
+}
+
+// getClusterVolumeGroupSnapshotParentObjectName returns the name of the parent
+// cluster group snapshot, if present.
+func getClusterVolumeGroupSnapshotParentObjectName(snapshot *crdv1.VolumeSnapshot) string {
+	return getParentObjectName(snapshot, "ClusterVolumeGroupSnapshot")
+}
+
+func getParentObjectName(snapshot *crdv1.VolumeSnapshot, kind string) string {
 	if snapshot == nil {
 		return ""
 	}
@@ -43,7 +54,7 @@ func getVolumeGroupSnapshotParentObjectName(snapshot *crdv1.VolumeSnapshot) stri
 	)
 
 	for _, owner := range snapshot.ObjectMeta.OwnerReferences {
-		if owner.Kind == "VolumeGroupSnapshot" && owner.APIVersion == apiVersion {
+		if owner.Kind == kind && owner.APIVersion == apiVersion {
 			return owner.Name
 		}
 	}
@@ -52,15 +63,35 @@ func getVolumeGroupSnapshotParentObjectName(snapshot *crdv1.VolumeSnapshot) stri
 }
 
 // IsVolumeGroupSnapshotMember returns true if the passed VolumeSnapshot object
-// is a member of a VolumeGroupSnapshot.
+// is a member of a VolumeGroupSnapshot or of a ClusterVolumeGroupSnapshot.
 func IsVolumeGroupSnapshotMember(snapshot *crdv1.VolumeSnapshot) bool {
 	parentName := getVolumeGroupSnapshotParentObjectName(snapshot)
+	return len(parentName) > 0 || IsClusterVolumeGroupSnapshotMember(snapshot)
+}
+
+// IsClusterVolumeGroupSnapshotMember returns true if the passed VolumeSnapshot
+// object is a member of a ClusterVolumeGroupSnapshot.
+func IsClusterVolumeGroupSnapshotMember(snapshot *crdv1.VolumeSnapshot) bool {
+	parentName := getClusterVolumeGroupSnapshotParentObjectName(snapshot)
 	return len(parentName) > 0
 }
 
+// IsClusterVolumeGroupSnapshotRef returns true if the passed reference of a
+// VolumeGroupSnapshotContent points to a ClusterVolumeGroupSnapshot.
+func IsClusterVolumeGroupSnapshotRef(ref *v1.ObjectReference) bool {
+	return ref != nil && ref.Kind == "ClusterVolumeGroupSnapshot"
+}
+
 // VolumeSnapshotParentGroupKeyFunc maps a member snapshot to the name
-// of the parent VolumeGroupSnapshot
+// of the parent VolumeGroupSnapshot. Members of a ClusterVolumeGroupSnapshot
+// are mapped to the name of the parent with an empty namespace.
 func VolumeSnapshotParentGroupKeyFunc(snapshot *crdv1.VolumeSnapshot) string {
+	if clusterParentName := getClusterVolumeGroupSnapshotParentObjectName(snapshot); len(clusterParentName) > 0 {
+		return VolumeSnapshotParentGroupKeyFuncByComponents(types.NamespacedName{
+			Name: clusterParentName,
+		})
+	}
+
 	parentName := getVolumeGroupSnapshotParentObjectName(snapshot)
 	if len(parentName) == 0 {
 		return ""
@@ -82,7 +113,7 @@ func VolumeSnapshotParentGroupKeyFuncByComponents(objectKey types.NamespacedName
 // of a volume group snapshot but the ownership is missing
 func NeedToAddVolumeGroupSnapshotOwnership(snapshot *crdv1.VolumeSnapshot) bool {
 	parentObjectName := getVolumeGroupSnapshotParentObjectName(snapshot)
-	if len(parentObjectName) > 0 {
+	if len(parentObjectName) > 0 || IsClusterVolumeGroupSnapshotMember(snapshot) {
 		return false
 	}
 
@@ -112,3 +143,18 @@ func BuildVolumeGroupSnapshotOwnerReference(parentGroup *groupsnapshotv1.VolumeG
 		UID:  parentGroup.UID,
 	}
 }
+
+// BuildClusterVolumeGroupSnapshotOwnerReference creates a OwnerReference record
+// declaring an object as a child of passed ClusterVolumeGroupSnapshot
+func BuildClusterVolumeGroupSnapshotOwnerReference(parentGroup *groupsnapshotv1.ClusterVolumeGroupSnapshot) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: fmt.Sprintf(
+			"%s/%s",
+			groupsnapshotv1.SchemeGroupVersion.Group,
+			groupsnapshotv1.SchemeGroupVersion.Version,
+		),
+		Kind: "ClusterVolumeGroupSnapshot",
+		Name: parentGroup.Name,
+		UID:  parentGroup.UID,
+	}
+}
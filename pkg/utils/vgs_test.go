@@ -100,6 +100,25 @@ func TestIsVolumeSnapshotGroupMember(t *testing.T) {
 			expectedParentObjectName: "vgs",
 			expectedIndexKey:         "default^vgs",
 		},
+		{
+			name: "with cluster group snapshot ownership",
+			snapshot: &crdv1.VolumeSnapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-vs",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "groupsnapshot.storage.k8s.io/v1",
+							Kind:       "ClusterVolumeGroupSnapshot",
+							Name:       "cvgs",
+						},
+					},
+				},
+			},
+			expected:                 true,
+			expectedParentObjectName: "",
+			expectedIndexKey:         "^cvgs",
+		},
 	}
 
 	for _, test := range testCases {
@@ -203,6 +222,24 @@ func TestNeedToAddVolumeGroupSnapshotOwnership(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "snapshot with the cluster group ownership already set",
+			snapshot: &crdv1.VolumeSnapshot{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "groupsnapshot.storage.k8s.io/v1",
+							Kind:       "ClusterVolumeGroupSnapshot",
+							Name:       "cvgs",
+						},
+					},
+				},
+				Status: &crdv1.VolumeSnapshotStatus{
+					VolumeGroupSnapshotName: ptr.To("cvgs"),
+				},
+			},
+			expected: false,
+		},
 	}
 
 	for _, test := range testCases {
@@ -0,0 +1,530 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Keys of the certificate Secret. tls.crt and tls.key follow the
+	// kubernetes.io/tls Secret convention. ca.crt holds the CA bundle that is
+	// patched into the CRDs: the active CA first, followed by any previous CA
+	// that is still valid so that rotation does not break in-flight requests.
+	secretCAKey           = "ca.crt"
+	secretCAPrivateKeyKey = "ca.key"
+
+	certFileName = "tls.crt"
+	keyFileName  = "tls.key"
+
+	// DefaultSelfManagedCAValidity is the default validity of the generated CA.
+	DefaultSelfManagedCAValidity = 5 * 365 * 24 * time.Hour
+	// DefaultSelfManagedCertValidity is the default validity of the generated serving certificate.
+	DefaultSelfManagedCertValidity = 365 * 24 * time.Hour
+	// DefaultSelfManagedRotationThreshold is how long before expiry a certificate is regenerated.
+	DefaultSelfManagedRotationThreshold = 30 * 24 * time.Hour
+	// DefaultSelfManagedCheckInterval is how often the certificates are checked for rotation.
+	DefaultSelfManagedCheckInterval = time.Hour
+)
+
+var crdResource = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// SelfManagedCertConfig configures the self-managed certificate mode of the
+// conversion webhook.
+type SelfManagedCertConfig struct {
+	// SecretName and Namespace identify the Secret storing the CA and the
+	// serving certificate. The Secret is shared by all webhook replicas.
+	SecretName string
+	Namespace  string
+	// ServiceName is the name of the Service in Namespace that fronts the
+	// webhook. It determines the DNS names of the serving certificate.
+	ServiceName string
+	// CRDNames are the CustomResourceDefinitions whose conversion webhook
+	// caBundle is kept in sync with the CA.
+	CRDNames []string
+	// CertDir is the local directory the serving certificate and key are
+	// written to, so that the CertWatcher can pick them up.
+	CertDir string
+
+	CAValidity        time.Duration
+	CertValidity      time.Duration
+	RotationThreshold time.Duration
+	CheckInterval     time.Duration
+}
+
+// CertBootstrapper generates a self-signed CA and serving certificate for
+// the webhook, stores them in a Secret, writes the serving pair to disk,
+// patches the CA into the CRDs' conversion configuration and rotates the
+// certificates before they expire.
+type CertBootstrapper struct {
+	config        SelfManagedCertConfig
+	kubeClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+}
+
+// NewCertBootstrapper returns a new CertBootstrapper. It returns an error if
+// the certificates would be rotated as soon as they are generated, i.e. if
+// the rotation threshold is not shorter than their validity.
+func NewCertBootstrapper(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, config SelfManagedCertConfig) (*CertBootstrapper, error) {
+	if config.CAValidity == 0 {
+		config.CAValidity = DefaultSelfManagedCAValidity
+	}
+	if config.CertValidity == 0 {
+		config.CertValidity = DefaultSelfManagedCertValidity
+	}
+	if config.RotationThreshold == 0 {
+		config.RotationThreshold = DefaultSelfManagedRotationThreshold
+	}
+	if config.CheckInterval == 0 {
+		config.CheckInterval = DefaultSelfManagedCheckInterval
+	}
+	if config.RotationThreshold >= config.CertValidity {
+		return nil, fmt.Errorf("the rotation threshold %v must be shorter than the certificate validity %v", config.RotationThreshold, config.CertValidity)
+	}
+	if config.RotationThreshold >= config.CAValidity {
+		return nil, fmt.Errorf("the rotation threshold %v must be shorter than the CA validity %v", config.RotationThreshold, config.CAValidity)
+	}
+	return &CertBootstrapper{
+		config:        config,
+		kubeClient:    kubeClient,
+		dynamicClient: dynamicClient,
+	}, nil
+}
+
+// CertFile returns the path of the serving certificate written by the bootstrapper.
+func (b *CertBootstrapper) CertFile() string {
+	return filepath.Join(b.config.CertDir, certFileName)
+}
+
+// KeyFile returns the path of the serving key written by the bootstrapper.
+func (b *CertBootstrapper) KeyFile() string {
+	return filepath.Join(b.config.CertDir, keyFileName)
+}
+
+// Bootstrap ensures the certificates exist, retrying until it succeeds or
+// the context is done. It must succeed before the CertWatcher is created, as
+// the watcher expects the certificate files to exist.
+func (b *CertBootstrapper) Bootstrap(ctx context.Context) error {
+	backoff := wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Steps:    6,
+		Cap:      30 * time.Second,
+	}
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		if lastErr = b.Ensure(ctx); lastErr != nil {
+			klog.Errorf("failed to bootstrap webhook certificates: %v", lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil && lastErr != nil {
+		return lastErr
+	}
+	return err
+}
+
+// Run periodically rotates the certificates until the context is done.
+func (b *CertBootstrapper) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := b.Ensure(ctx); err != nil {
+			klog.Errorf("failed to rotate webhook certificates: %v", err)
+		}
+	}, b.config.CheckInterval)
+}
+
+// Ensure makes sure the Secret holds a valid CA and serving certificate,
+// writes the serving pair to CertDir and patches the CA bundle into the CRDs.
+func (b *CertBootstrapper) Ensure(ctx context.Context) error {
+	secret, err := b.kubeClient.CoreV1().Secrets(b.config.Namespace).Get(ctx, b.config.SecretName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to get secret %s/%s: %w", b.config.Namespace, b.config.SecretName, err)
+		}
+		secret = nil
+	}
+
+	var data map[string][]byte
+	if secret != nil {
+		data = secret.Data
+	}
+	newData, changed, err := b.reconcileCertificates(data, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		if secret == nil {
+			secret = &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      b.config.SecretName,
+					Namespace: b.config.Namespace,
+				},
+				Type: v1.SecretTypeTLS,
+				Data: newData,
+			}
+			secret, err = b.kubeClient.CoreV1().Secrets(b.config.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+		} else {
+			secret = secret.DeepCopy()
+			secret.Data = newData
+			secret, err = b.kubeClient.CoreV1().Secrets(b.config.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			// Another replica may have won the race, the next
+			// attempt picks up its certificates.
+			return fmt.Errorf("failed to store certificates in secret %s/%s: %w", b.config.Namespace, b.config.SecretName, err)
+		}
+		klog.Infof("Stored new webhook certificates in secret %s/%s", b.config.Namespace, b.config.SecretName)
+	}
+
+	if err := b.writeServingCertificate(secret.Data); err != nil {
+		return err
+	}
+	return b.patchCABundles(ctx, secret.Data[secretCAKey])
+}
+
+// reconcileCertificates returns the Secret data holding a valid CA and
+// serving certificate at the given time, and whether it differs from the
+// given data.
+func (b *CertBootstrapper) reconcileCertificates(data map[string][]byte, now time.Time) (map[string][]byte, bool, error) {
+	threshold := now.Add(b.config.RotationThreshold)
+
+	caCerts := parseCertificates(data[secretCAKey])
+	caKey, _ := parsePrivateKey(data[secretCAPrivateKeyKey])
+	rotateCA := len(caCerts) == 0 || caKey == nil || !caCerts[0].IsCA ||
+		!publicKeysEqual(caCerts[0].PublicKey, caKey) ||
+		caCerts[0].NotAfter.Before(threshold)
+
+	var caCert *x509.Certificate
+	caBundle := caCerts
+	if rotateCA {
+		var err error
+		caCert, caKey, err = generateCA(now, b.config.CAValidity)
+		if err != nil {
+			return nil, false, err
+		}
+		caBundle = append([]*x509.Certificate{caCert}, caCerts...)
+		klog.Info("Generated new webhook CA")
+	} else {
+		caCert = caCerts[0]
+	}
+	caBundle = pruneCABundle(caBundle, now)
+
+	servingCerts := parseCertificates(data[v1.TLSCertKey])
+	servingKey, _ := parsePrivateKey(data[v1.TLSPrivateKeyKey])
+	dnsNames := b.dnsNames()
+	rotateServing := rotateCA || len(servingCerts) == 0 || servingKey == nil ||
+		!publicKeysEqual(servingCerts[0].PublicKey, servingKey) ||
+		servingCerts[0].NotAfter.Before(threshold) ||
+		servingCerts[0].CheckSignatureFrom(caCert) != nil ||
+		!containsAll(servingCerts[0].DNSNames, dnsNames)
+
+	newData := map[string][]byte{}
+	for k, v := range data {
+		newData[k] = v
+	}
+	newData[secretCAKey] = encodeCertificates(caBundle...)
+	if rotateCA {
+		keyPEM, err := encodePrivateKey(caKey)
+		if err != nil {
+			return nil, false, err
+		}
+		newData[secretCAPrivateKeyKey] = keyPEM
+	}
+	if rotateServing {
+		cert, key, err := generateServingCert(now, b.config.CertValidity, dnsNames, caCert, caKey)
+		if err != nil {
+			return nil, false, err
+		}
+		keyPEM, err := encodePrivateKey(key)
+		if err != nil {
+			return nil, false, err
+		}
+		newData[v1.TLSCertKey] = encodeCertificates(cert)
+		newData[v1.TLSPrivateKeyKey] = keyPEM
+		klog.Infof("Generated new webhook serving certificate for %v", dnsNames)
+	}
+
+	changed := rotateCA || rotateServing || !bytes.Equal(newData[secretCAKey], data[secretCAKey])
+	return newData, changed, nil
+}
+
+func (b *CertBootstrapper) dnsNames() []string {
+	service, namespace := b.config.ServiceName, b.config.Namespace
+	return []string{
+		service,
+		fmt.Sprintf("%s.%s", service, namespace),
+		fmt.Sprintf("%s.%s.svc", service, namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace),
+	}
+}
+
+// writeServingCertificate writes the serving pair to CertDir if it differs
+// from what is already on disk. The key is written first so that the
+// CertWatcher observes a matching pair once the certificate changes.
+func (b *CertBootstrapper) writeServingCertificate(data map[string][]byte) error {
+	if err := os.MkdirAll(b.config.CertDir, 0o700); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		path    string
+		content []byte
+	}{
+		{b.KeyFile(), data[v1.TLSPrivateKeyKey]},
+		{b.CertFile(), data[v1.TLSCertKey]},
+	} {
+		current, err := os.ReadFile(f.path)
+		if err == nil && bytes.Equal(current, f.content) {
+			continue
+		}
+		if err := os.WriteFile(f.path, f.content, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.path, err)
+		}
+	}
+	return nil
+}
+
+// patchCABundles sets the conversion webhook caBundle of the configured
+// CRDs. CRDs that do not use webhook conversion are left untouched.
+func (b *CertBootstrapper) patchCABundles(ctx context.Context, caBundle []byte) error {
+	desired := base64.StdEncoding.EncodeToString(caBundle)
+	var errs []error
+	for _, name := range b.config.CRDNames {
+		crd, err := b.dynamicClient.Resource(crdResource).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get CRD %s: %w", name, err))
+			continue
+		}
+		strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+		if strategy != "Webhook" {
+			klog.Warningf("CRD %s does not use webhook conversion, not patching its caBundle", name)
+			continue
+		}
+		current, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+		if current == desired {
+			continue
+		}
+		patch := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"conversion": map[string]interface{}{
+					"webhook": map[string]interface{}{
+						"clientConfig": map[string]interface{}{
+							"caBundle": desired,
+						},
+					},
+				},
+			},
+		}
+		patchBytes, err := json.Marshal(patch)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := b.dynamicClient.Resource(crdResource).Patch(ctx, name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to patch caBundle of CRD %s: %w", name, err))
+			continue
+		}
+		klog.Infof("Patched conversion webhook caBundle of CRD %s", name)
+	}
+	return errors.Join(errs...)
+}
+
+func generateCA(now time.Time, validity time.Duration) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("snapshot-conversion-webhook-ca@%d", now.Unix())},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func generateServingCert(now time.Time, validity time.Duration, dnsNames []string, caCert *x509.Certificate, caKey crypto.Signer) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serving key: %w", err)
+	}
+	serial, err := randomSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	notAfter := now.Add(validity)
+	if notAfter.After(caCert.NotAfter) {
+		notAfter = caCert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[len(dnsNames)-2]},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create serving certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func randomSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+func parseCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			klog.V(4).Infof("ignoring unparsable certificate: %v", err)
+			continue
+		}
+		certs = append(certs, cert)
+	}
+}
+
+func encodeCertificates(certs ...*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+func encodePrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func publicKeysEqual(pub crypto.PublicKey, key crypto.Signer) bool {
+	if key == nil {
+		return false
+	}
+	equal, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && equal.Equal(pub)
+}
+
+// pruneCABundle drops certificates that are expired or are not CAs.
+func pruneCABundle(certs []*x509.Certificate, now time.Time) []*x509.Certificate {
+	var valid []*x509.Certificate
+	for _, cert := range certs {
+		if cert.IsCA && cert.NotAfter.After(now) {
+			valid = append(valid, cert)
+		}
+	}
+	return valid
+}
+
+func containsAll(have, want []string) bool {
+	set := make(map[string]struct{}, len(have))
+	for _, h := range have {
+		set[h] = struct{}{}
+	}
+	for _, w := range want {
+		if _, ok := set[w]; !ok {
+			return false
+		}
+	}
+	return true
+}
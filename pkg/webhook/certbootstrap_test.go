@@ -0,0 +1,213 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testCRDName = "volumegroupsnapshotcontents.groupsnapshot.storage.k8s.io"
+
+func newTestCRD(strategy string) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]interface{}{
+			"name": testCRDName,
+		},
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"strategy": strategy,
+			},
+		},
+	}}
+	return crd
+}
+
+func newTestBootstrapper(t *testing.T, crd *unstructured.Unstructured) (*CertBootstrapper, *fake.Clientset, *dynamicfake.FakeDynamicClient) {
+	kubeClient := fake.NewSimpleClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), crd)
+	b, err := NewCertBootstrapper(kubeClient, dynamicClient, SelfManagedCertConfig{
+		SecretName:  "webhook-certs",
+		Namespace:   "snapshot",
+		ServiceName: "webhook",
+		CRDNames:    []string{testCRDName},
+		CertDir:     t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create bootstrapper: %v", err)
+	}
+	return b, kubeClient, dynamicClient
+}
+
+func TestNewCertBootstrapperRotationThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      SelfManagedCertConfig
+		expectError bool
+	}{
+		{
+			name:   "defaults",
+			config: SelfManagedCertConfig{},
+		},
+		{
+			name:        "threshold equal to the certificate validity",
+			config:      SelfManagedCertConfig{CertValidity: 24 * time.Hour, RotationThreshold: 24 * time.Hour},
+			expectError: true,
+		},
+		{
+			name:        "threshold longer than the certificate validity",
+			config:      SelfManagedCertConfig{CertValidity: 24 * time.Hour, RotationThreshold: 48 * time.Hour},
+			expectError: true,
+		},
+		{
+			name:        "threshold longer than the CA validity",
+			config:      SelfManagedCertConfig{CAValidity: 24 * time.Hour, CertValidity: 72 * time.Hour, RotationThreshold: 48 * time.Hour},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewCertBootstrapper(fake.NewSimpleClientset(), nil, test.config)
+			if test.expectError && err == nil {
+				t.Errorf("expected an error")
+			}
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCertBootstrapperEnsure(t *testing.T) {
+	b, kubeClient, dynamicClient := newTestBootstrapper(t, newTestCRD("Webhook"))
+	ctx := context.Background()
+
+	if err := b.Ensure(ctx); err != nil {
+		t.Fatalf("unexpected error bootstrapping certificates: %v", err)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets("snapshot").Get(ctx, "webhook-certs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected secret to be created: %v", err)
+	}
+	if secret.Type != v1.SecretTypeTLS {
+		t.Errorf("expected secret type %q, got %q", v1.SecretTypeTLS, secret.Type)
+	}
+
+	// The serving pair on disk must match the secret and verify against the CA.
+	pair, err := tls.LoadX509KeyPair(b.CertFile(), b.KeyFile())
+	if err != nil {
+		t.Fatalf("failed to load serving certificate from disk: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(secret.Data[secretCAKey])
+	if _, err := pair.Leaf.Verify(x509.VerifyOptions{
+		DNSName: "webhook.snapshot.svc",
+		Roots:   roots,
+	}); err != nil {
+		t.Errorf("serving certificate does not verify against the CA: %v", err)
+	}
+
+	crd, err := dynamicClient.Resource(crdResource).Get(ctx, testCRDName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get CRD: %v", err)
+	}
+	caBundle, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	if caBundle != base64.StdEncoding.EncodeToString(secret.Data[secretCAKey]) {
+		t.Errorf("CRD caBundle was not patched with the CA")
+	}
+
+	// A second run with valid certificates must not change anything.
+	if err := b.Ensure(ctx); err != nil {
+		t.Fatalf("unexpected error on second run: %v", err)
+	}
+	again, err := kubeClient.CoreV1().Secrets("snapshot").Get(ctx, "webhook-certs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	if string(again.Data[v1.TLSCertKey]) != string(secret.Data[v1.TLSCertKey]) {
+		t.Errorf("serving certificate was regenerated although it is still valid")
+	}
+}
+
+func TestCertBootstrapperRotation(t *testing.T) {
+	b, _, _ := newTestBootstrapper(t, newTestCRD("Webhook"))
+	now := time.Now()
+
+	data, changed, err := b.reconcileCertificates(nil, now)
+	if err != nil || !changed {
+		t.Fatalf("expected initial certificates to be generated, changed=%v err=%v", changed, err)
+	}
+
+	// Serving certificate close to expiry: only the serving certificate rotates.
+	later := now.Add(b.config.CertValidity - b.config.RotationThreshold/2)
+	rotated, changed, err := b.reconcileCertificates(data, later)
+	if err != nil || !changed {
+		t.Fatalf("expected serving certificate rotation, changed=%v err=%v", changed, err)
+	}
+	if string(rotated[secretCAKey]) != string(data[secretCAKey]) {
+		t.Errorf("CA bundle changed although the CA is still valid")
+	}
+	if string(rotated[v1.TLSCertKey]) == string(data[v1.TLSCertKey]) {
+		t.Errorf("serving certificate was not rotated")
+	}
+
+	// CA close to expiry: a new CA is generated and the old one is kept in
+	// the bundle until it expires.
+	muchLater := now.Add(b.config.CAValidity - b.config.RotationThreshold/2)
+	rotated, changed, err = b.reconcileCertificates(data, muchLater)
+	if err != nil || !changed {
+		t.Fatalf("expected CA rotation, changed=%v err=%v", changed, err)
+	}
+	bundle := parseCertificates(rotated[secretCAKey])
+	if len(bundle) != 2 {
+		t.Fatalf("expected CA bundle with the new and the previous CA, got %d certificates", len(bundle))
+	}
+	serving := parseCertificates(rotated[v1.TLSCertKey])
+	if err := serving[0].CheckSignatureFrom(bundle[0]); err != nil {
+		t.Errorf("serving certificate is not signed by the new CA: %v", err)
+	}
+}
+
+func TestCertBootstrapperSkipsCRDWithoutWebhookConversion(t *testing.T) {
+	b, _, dynamicClient := newTestBootstrapper(t, newTestCRD("None"))
+	ctx := context.Background()
+
+	if err := b.Ensure(ctx); err != nil {
+		t.Fatalf("unexpected error bootstrapping certificates: %v", err)
+	}
+	crd, err := dynamicClient.Resource(crdResource).Get(ctx, testCRDName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get CRD: %v", err)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(crd.Object, "spec", "conversion", "webhook"); found {
+		t.Errorf("CRD without webhook conversion must not be patched")
+	}
+}
@@ -19,7 +19,12 @@ package webhook
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"k8s.io/klog/v2"
@@ -37,19 +42,29 @@ type CertWatcher struct {
 	sync.Mutex
 
 	currentCert *tls.Certificate
+	clientCAs   *x509.CertPool
 	watcher     *fsnotify.Watcher
 
-	certPath string
-	keyPath  string
+	certPath     string
+	keyPath      string
+	clientCAPath string
 }
 
 // NewCertWatcher returns a new CertWatcher watching the given certificate and key.
 func NewCertWatcher(certPath, keyPath string) (*CertWatcher, error) {
+	return NewCertWatcherWithClientCA(certPath, keyPath, "")
+}
+
+// NewCertWatcherWithClientCA returns a new CertWatcher watching the given
+// certificate and key, and the CA bundle used to verify client certificates.
+// An empty clientCAPath disables client certificate verification.
+func NewCertWatcherWithClientCA(certPath, keyPath, clientCAPath string) (*CertWatcher, error) {
 	var err error
 
 	cw := &CertWatcher{
-		certPath: certPath,
-		keyPath:  keyPath,
+		certPath:     certPath,
+		keyPath:      keyPath,
+		clientCAPath: clientCAPath,
 	}
 
 	// Initial read of certificate and key.
@@ -57,6 +72,13 @@ func NewCertWatcher(certPath, keyPath string) (*CertWatcher, error) {
 		return nil, err
 	}
 
+	// Initial read of the client CA bundle.
+	if cw.clientCAPath != "" {
+		if err := cw.ReadClientCA(); err != nil {
+			return nil, err
+		}
+	}
+
 	cw.watcher, err = fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
@@ -72,9 +94,59 @@ func (cw *CertWatcher) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate,
 	return cw.currentCert, nil
 }
 
+// GetClientCAs fetches the currently loaded client CA pool, which is nil when
+// client certificate verification is disabled.
+func (cw *CertWatcher) GetClientCAs() *x509.CertPool {
+	cw.Lock()
+	defer cw.Unlock()
+	return cw.clientCAs
+}
+
+// VerifiesClientCertificates returns true if the watcher was configured with
+// a client CA bundle.
+func (cw *CertWatcher) VerifiesClientCertificates() bool {
+	return cw.clientCAPath != ""
+}
+
+// Ready returns an error if the watcher does not hold a certificate that is
+// valid at the current time, or if a client CA bundle is configured but not
+// loaded.
+func (cw *CertWatcher) Ready() error {
+	cw.Lock()
+	cert := cw.currentCert
+	clientCAs := cw.clientCAs
+	cw.Unlock()
+
+	if cw.VerifiesClientCertificates() && clientCAs == nil {
+		return errors.New("no client CA bundle loaded")
+	}
+	if cert == nil || len(cert.Certificate) == 0 {
+		return errors.New("no serving certificate loaded")
+	}
+	leaf := cert.Leaf
+	if leaf == nil {
+		var err error
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse serving certificate: %w", err)
+		}
+	}
+	now := time.Now()
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("serving certificate is not valid before %s", leaf.NotBefore)
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("serving certificate expired at %s", leaf.NotAfter)
+	}
+	return nil
+}
+
 // Start starts the watch on the certificate and key files.
 func (cw *CertWatcher) Start(ctx context.Context) error {
 	files := []string{cw.certPath, cw.keyPath}
+	if cw.clientCAPath != "" {
+		files = append(files, cw.clientCAPath)
+	}
 
 	for _, f := range files {
 		if err := cw.watcher.Add(f); err != nil {
@@ -131,6 +203,26 @@ func (cw *CertWatcher) ReadCertificate() error {
 	return nil
 }
 
+// ReadClientCA reads the client CA bundle from disk, parses it, and updates
+// the client CA pool on the watcher.
+func (cw *CertWatcher) ReadClientCA() error {
+	caBundle, err := os.ReadFile(cw.clientCAPath)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		return fmt.Errorf("no valid certificates found in client CA file %q", cw.clientCAPath)
+	}
+
+	cw.Lock()
+	cw.clientCAs = pool
+	cw.Unlock()
+
+	klog.Info("Updated current client CA bundle")
+	return nil
+}
+
 func (cw *CertWatcher) handleEvent(event fsnotify.Event) {
 	// Only care about events which may modify the contents of the file.
 	if !(isWrite(event) || isRemove(event) || isCreate(event)) {
@@ -146,6 +238,13 @@ func (cw *CertWatcher) handleEvent(event fsnotify.Event) {
 		}
 	}
 
+	if cw.clientCAPath != "" && event.Name == cw.clientCAPath {
+		if err := cw.ReadClientCA(); err != nil {
+			klog.Error(err, "error re-reading client CA bundle")
+		}
+		return
+	}
+
 	if err := cw.ReadCertificate(); err != nil {
 		klog.Error(err, "error re-reading certificate")
 	}
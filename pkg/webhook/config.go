@@ -37,3 +37,26 @@ func configTLS(config Config) *tls.Config {
 		Certificates: []tls.Certificate{sCert},
 	}
 }
+
+// NewTLSConfig returns a TLS configuration serving the certificate held by
+// the watcher. When the watcher also tracks a client CA bundle, clients must
+// present a certificate signed by the currently loaded bundle.
+func NewTLSConfig(cw *CertWatcher) *tls.Config {
+	tlsConfig := &tls.Config{
+		GetCertificate: cw.GetCertificate,
+	}
+	if !cw.VerifiesClientCertificates() {
+		return tlsConfig
+	}
+
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	// The client CA pool is resolved on every handshake so that a reloaded
+	// bundle takes effect without restarting the listener.
+	tlsConfig.GetConfigForClient = func(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+		config := tlsConfig.Clone()
+		config.GetConfigForClient = nil
+		config.ClientCAs = cw.GetClientCAs()
+		return config, nil
+	}
+	return tlsConfig
+}
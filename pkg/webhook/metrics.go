@@ -0,0 +1,141 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+const (
+	metricsSubSystem = "snapshot_conversion_webhook"
+
+	labelKind        = "kind"
+	labelFromVersion = "from_version"
+	labelToVersion   = "to_version"
+
+	conversionsTotalName      = "conversions_total"
+	conversionsTotalHelpMsg   = "Total number of objects the webhook was asked to convert"
+	conversionFailuresName    = "conversion_failures_total"
+	conversionFailuresHelpMsg = "Total number of objects the webhook failed to convert"
+	conversionLatencyName     = "conversion_duration_seconds"
+	conversionLatencyHelpMsg  = "Number of seconds spent by the webhook converting a single object"
+	unknownLabelValue         = "unknown"
+)
+
+var conversionBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// ConversionMetrics collects the Prometheus metrics exposed by the
+// conversion webhook.
+type ConversionMetrics struct {
+	registry k8smetrics.KubeRegistry
+
+	// conversions counts the objects handed to the conversion function
+	conversions *k8smetrics.CounterVec
+
+	// failures counts the objects that could not be converted
+	failures *k8smetrics.CounterVec
+
+	// latency is a Histogram of the time spent converting a single object
+	latency *k8smetrics.HistogramVec
+}
+
+// NewConversionMetrics creates a new ConversionMetrics instance with its own
+// registry.
+func NewConversionMetrics() *ConversionMetrics {
+	labels := []string{labelKind, labelFromVersion, labelToVersion}
+	m := &ConversionMetrics{
+		registry: k8smetrics.NewKubeRegistry(),
+		conversions: k8smetrics.NewCounterVec(
+			&k8smetrics.CounterOpts{
+				Subsystem: metricsSubSystem,
+				Name:      conversionsTotalName,
+				Help:      conversionsTotalHelpMsg,
+			},
+			labels,
+		),
+		failures: k8smetrics.NewCounterVec(
+			&k8smetrics.CounterOpts{
+				Subsystem: metricsSubSystem,
+				Name:      conversionFailuresName,
+				Help:      conversionFailuresHelpMsg,
+			},
+			labels,
+		),
+		latency: k8smetrics.NewHistogramVec(
+			&k8smetrics.HistogramOpts{
+				Subsystem: metricsSubSystem,
+				Name:      conversionLatencyName,
+				Help:      conversionLatencyHelpMsg,
+				Buckets:   conversionBuckets,
+			},
+			labels,
+		),
+	}
+	k8smetrics.RegisterProcessStartTime(m.registry.Register)
+	m.registry.MustRegister(m.conversions)
+	m.registry.MustRegister(m.failures)
+	m.registry.MustRegister(m.latency)
+	return m
+}
+
+// PrepareMetricsPath registers the metrics handler on the given mux.
+func (m *ConversionMetrics) PrepareMetricsPath(mux *http.ServeMux, pattern string, logger promhttp.Logger) {
+	mux.Handle(pattern, k8smetrics.HandlerFor(
+		m.registry,
+		k8smetrics.HandlerOpts{
+			ErrorLog:      logger,
+			ErrorHandling: k8smetrics.ContinueOnError,
+		}))
+}
+
+// GetRegistry returns the metrics.KubeRegistry used by the webhook.
+func (m *ConversionMetrics) GetRegistry() k8smetrics.KubeRegistry {
+	return m.registry
+}
+
+// instrument wraps a conversion function so that every object it handles is
+// counted and timed. A nil receiver returns the conversion function unchanged.
+func (m *ConversionMetrics) instrument(convert convertFunc) convertFunc {
+	if m == nil {
+		return convert
+	}
+	return func(obj *unstructured.Unstructured, toVersion string) (*unstructured.Unstructured, metav1.Status) {
+		kind := obj.GetKind()
+		if kind == "" {
+			kind = unknownLabelValue
+		}
+		fromVersion := obj.GetAPIVersion()
+		if fromVersion == "" {
+			fromVersion = unknownLabelValue
+		}
+
+		start := time.Now()
+		converted, status := convert(obj, toVersion)
+		m.latency.WithLabelValues(kind, fromVersion, toVersion).Observe(time.Since(start).Seconds())
+		m.conversions.WithLabelValues(kind, fromVersion, toVersion).Inc()
+		if status.Status != metav1.StatusSuccess {
+			m.failures.WithLabelValues(kind, fromVersion, toVersion).Inc()
+		}
+		return converted, status
+	}
+}
@@ -20,17 +20,22 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
 	"k8s.io/klog/v2"
 )
 
+// StartServer starts the certificate watcher and serves the conversion
+// endpoint over TLS on the given port. If metrics is not nil, every
+// conversion is recorded in it.
 func StartServer(
 	ctx context.Context,
 	tlsConfig *tls.Config,
 	cw *CertWatcher,
 	port int,
+	metrics *ConversionMetrics,
 ) error {
 	go func() {
 		klog.Info("Starting certificate watcher")
@@ -41,7 +46,8 @@ func StartServer(
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/readyz", func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("ok")) })
-	mux.HandleFunc("/convert", func(w http.ResponseWriter, req *http.Request) { serve(w, req, convertGroupSnapshotCRD) })
+	convert := metrics.instrument(convertGroupSnapshotCRD)
+	mux.HandleFunc("/convert", func(w http.ResponseWriter, req *http.Request) { serve(w, req, convert) })
 
 	srv := &http.Server{
 		Handler:      mux,
@@ -59,3 +65,60 @@ func StartServer(
 
 	return srv.Serve(listener)
 }
+
+// StartDiagnosticsServer serves /healthz, /readyz and, if metrics is not nil,
+// the Prometheus metrics on a plain HTTP listener at the given address.
+// /readyz fails while the certificate watcher does not hold a valid
+// certificate. The server is shut down when the context is done.
+func StartDiagnosticsServer(
+	ctx context.Context,
+	address string,
+	metricsPath string,
+	cw *CertWatcher,
+	metrics *ConversionMetrics,
+) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("ok")) })
+	mux.HandleFunc("/readyz", readyzHandler(cw))
+	if metrics != nil && metricsPath != "" {
+		metrics.PrepareMetricsPath(mux, metricsPath, promklog{})
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  15 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			klog.Errorf("failed to shutdown diagnostics server: %v", err)
+		}
+	}()
+	if err := srv.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func readyzHandler(cw *CertWatcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if err := cw.Ready(); err != nil {
+			klog.V(4).Infof("readiness check failed: %v", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}
+}
+
+type promklog struct{}
+
+func (pl promklog) Println(v ...interface{}) {
+	klog.Error(v...)
+}
@@ -18,6 +18,8 @@ package webhook
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
@@ -27,10 +29,17 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/component-base/metrics/testutil"
 )
 
 func TestWebhookCertReload(t *testing.T) {
@@ -69,6 +78,7 @@ func TestWebhookCertReload(t *testing.T) {
 			tlsConfig,
 			cw,
 			port,
+			nil,
 		)
 		if err != nil {
 			panic(err)
@@ -195,3 +205,193 @@ func generateTestCertKeyPair(t *testing.T, certPath, keyPath string) error {
 
 	return nil
 }
+
+func TestDiagnosticsReadyz(t *testing.T) {
+	tmpDir := t.TempDir()
+	certFile := path.Join(tmpDir, "tls.crt")
+	keyFile := path.Join(tmpDir, "tls.key")
+	if err := generateTestCertKeyPair(t, certFile, keyFile); err != nil {
+		t.Fatalf("unexpected error occurred while generating test certs: %v", err)
+	}
+	cw, err := NewCertWatcher(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("failed to initialize new cert watcher: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		watcher        *CertWatcher
+		expectedStatus int
+	}{
+		{
+			name:           "valid certificate",
+			watcher:        cw,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "no certificate",
+			watcher:        &CertWatcher{},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			readyzHandler(test.watcher)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != test.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", test.expectedStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestConversionMetrics(t *testing.T) {
+	m := NewConversionMetrics()
+	convert := m.instrument(convertGroupSnapshotCRD)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("groupsnapshot.storage.k8s.io/v1beta1")
+	obj.SetKind("VolumeGroupSnapshotContent")
+	if _, status := convert(obj, "groupsnapshot.storage.k8s.io/v1beta2"); status.Status != metav1.StatusSuccess {
+		t.Fatalf("unexpected conversion failure: %s", status.Message)
+	}
+	if _, status := convert(obj, "groupsnapshot.storage.k8s.io/v1beta1"); status.Status == metav1.StatusSuccess {
+		t.Fatalf("expected conversion to the same version to fail")
+	}
+
+	expected := `
+# HELP snapshot_conversion_webhook_conversion_failures_total [ALPHA] Total number of objects the webhook failed to convert
+# TYPE snapshot_conversion_webhook_conversion_failures_total counter
+snapshot_conversion_webhook_conversion_failures_total{from_version="groupsnapshot.storage.k8s.io/v1beta1",kind="VolumeGroupSnapshotContent",to_version="groupsnapshot.storage.k8s.io/v1beta1"} 1
+# HELP snapshot_conversion_webhook_conversions_total [ALPHA] Total number of objects the webhook was asked to convert
+# TYPE snapshot_conversion_webhook_conversions_total counter
+snapshot_conversion_webhook_conversions_total{from_version="groupsnapshot.storage.k8s.io/v1beta1",kind="VolumeGroupSnapshotContent",to_version="groupsnapshot.storage.k8s.io/v1beta1"} 1
+snapshot_conversion_webhook_conversions_total{from_version="groupsnapshot.storage.k8s.io/v1beta1",kind="VolumeGroupSnapshotContent",to_version="groupsnapshot.storage.k8s.io/v1beta2"} 1
+`
+	if err := testutil.GatherAndCompare(m.GetRegistry(), strings.NewReader(expected),
+		"snapshot_conversion_webhook_conversions_total",
+		"snapshot_conversion_webhook_conversion_failures_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWebhookClientCAReload(t *testing.T) {
+	tmpDir := t.TempDir()
+	certFile := path.Join(tmpDir, "tls.crt")
+	keyFile := path.Join(tmpDir, "tls.key")
+	caFile := path.Join(tmpDir, "ca.crt")
+	port := 30444
+	if err := generateTestCertKeyPair(t, certFile, keyFile); err != nil {
+		t.Fatalf("unexpected error occurred while generating test certs: %v", err)
+	}
+	firstClientCert := generateTestClientCert(t, caFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cw, err := NewCertWatcherWithClientCA(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("failed to initialize new cert watcher: %v", err)
+	}
+	go func() {
+		if err := StartServer(ctx, NewTLSConfig(cw), cw, port, nil); err != nil {
+			panic(err)
+		}
+	}()
+	time.Sleep(250 * time.Millisecond) // Give some time for watcher to start
+
+	url := fmt.Sprintf("https://127.0.0.1:%d/readyz", port)
+
+	// TC: requests without a client certificate are rejected
+	if err := getWithClientCert(url, nil); err == nil {
+		t.Error("expected request without client certificate to fail")
+	}
+
+	// TC: requests with a client certificate signed by the CA are accepted
+	if err := getWithClientCert(url, firstClientCert); err != nil {
+		t.Errorf("unexpected error with a valid client certificate: %v", err)
+	}
+
+	// TC: a new CA bundle is picked up without restarting the server
+	secondClientCert := generateTestClientCert(t, caFile)
+	time.Sleep(250 * time.Millisecond) // Wait for certwatcher to update
+	if err := getWithClientCert(url, firstClientCert); err == nil {
+		t.Error("expected request with a certificate signed by the old CA to fail")
+	}
+	if err := getWithClientCert(url, secondClientCert); err != nil {
+		t.Errorf("unexpected error with a certificate signed by the new CA: %v", err)
+	}
+}
+
+func getWithClientCert(url string, cert *tls.Certificate) error {
+	tlsConfig := &tls.Config{
+		// The server certificate is self-signed, only client
+		// verification is under test here.
+		InsecureSkipVerify: true,
+	}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   5 * time.Second,
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// generateTestClientCert generates a new CA, writes it to caPath and returns
+// a client certificate signed by it.
+func generateTestClientCert(t *testing.T, caPath string) *tls.Certificate {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca-" + time.Now().String()},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+	if err := os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600); err != nil {
+		t.Fatalf("failed to write CA certificate: %v", err)
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate client key: %v", err)
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "kube-apiserver"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create client certificate: %v", err)
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{clientDER},
+		PrivateKey:  clientKey,
+	}
+}